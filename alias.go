@@ -0,0 +1,128 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var aliasRegistry struct {
+	sync.RWMutex
+	byType map[reflect.Type]map[string]string // alias -> Go path of the field
+}
+
+// RegisterAlias maps legacy JSON keys onto the field of struct type t whose
+// JSON name is canonicalName. Aliases are accepted wherever a value of type t
+// is decoded, before unknown-field checks run, which lets teams adapt types
+// they do not own (vendored SDK structs, for example) in one place.
+//
+// canonicalName is the name given by the field's json tag. The aliases
+// apply to that Go field whatever names a decoder's WithTagKey or
+// WithProtoNames settings give the fields; an alias that collides with one
+// of those names is reported as a *FieldConflictError when values of t are
+// decoded with such a decoder.
+//
+// RegisterAlias panics if t is not a struct type, if canonicalName is not a
+// field of t, or if an alias collides with an existing field name. It is
+// intended to be called during program initialization.
+func RegisterAlias(t reflect.Type, canonicalName string, aliases ...string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("strictjson: RegisterAlias of non-struct type %s", t))
+	}
+
+	sf := buildStructFields(t, fieldConfig{})
+	fi, ok := sf.fields[canonicalName]
+	if !ok {
+		panic(fmt.Sprintf("strictjson: RegisterAlias: %s has no field %q", t, canonicalName))
+	}
+	for _, alias := range aliases {
+		if _, ok := sf.fields[alias]; ok {
+			panic(fmt.Sprintf("strictjson: RegisterAlias: alias %q collides with a field of %s", alias, t))
+		}
+	}
+
+	aliasRegistry.Lock()
+	if aliasRegistry.byType == nil {
+		aliasRegistry.byType = make(map[reflect.Type]map[string]string)
+	}
+	m := aliasRegistry.byType[t]
+	if m == nil {
+		m = make(map[string]string)
+		aliasRegistry.byType[t] = m
+	}
+	for _, alias := range aliases {
+		m[alias] = fi.goPath
+	}
+	aliasRegistry.Unlock()
+
+	fieldCache.Range(func(k, _ any) bool {
+		if k.(fieldKey).typ == t {
+			fieldCache.Delete(k)
+		}
+		return true
+	})
+	resetPlans()
+}
+
+// applyAliases adds the registered aliases of t to its field table, built
+// under any field config. An alias that names another field or an ambiguous
+// name of the table is recorded as a conflict instead.
+func applyAliases(t reflect.Type, sf *structFields) {
+	aliasRegistry.RLock()
+	defer aliasRegistry.RUnlock()
+
+	m := aliasRegistry.byType[t]
+	if len(m) == 0 {
+		return
+	}
+	aliases := make([]string, 0, len(m))
+	for alias := range m {
+		aliases = append(aliases, alias)
+	}
+	sort.Strings(aliases)
+
+	for _, alias := range aliases {
+		var fi *fieldInfo
+		for _, f := range sf.list {
+			if f.goPath == m[alias] {
+				fi = f
+				break
+			}
+		}
+		if fi == nil {
+			continue // the field is not decoded under this config
+		}
+		if c := aliasConflict(sf, alias, fi); c != nil {
+			sf.conflicts = append(sf.conflicts, c)
+			continue
+		}
+		sf.fields[alias] = fi
+	}
+}
+
+// aliasConflict returns the conflict between alias, registered for fi, and
+// the field or ambiguous name of sf it collides with, if any.
+func aliasConflict(sf *structFields, alias string, fi *fieldInfo) *FieldConflictError {
+	if other, ok := sf.fields[alias]; ok && other != fi {
+		return &FieldConflictError{fieldName: alias, sources: []string{other.goPath, fi.goPath}}
+	}
+	for _, c := range sf.conflicts {
+		if c.fieldName == alias {
+			return &FieldConflictError{fieldName: alias, sources: append(append([]string(nil), c.sources...), fi.goPath)}
+		}
+	}
+	return nil
+}
+
+// hasAliases reports whether aliases are registered for t. Generated
+// decoders do not know about aliases, so such types use the reflective
+// decoder.
+func hasAliases(t reflect.Type) bool {
+	aliasRegistry.RLock()
+	defer aliasRegistry.RUnlock()
+	return len(aliasRegistry.byType[t]) > 0
+}
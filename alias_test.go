@@ -0,0 +1,98 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type vendorCustomer struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+func TestRegisterAlias(t *testing.T) {
+	type Order struct {
+		Customer vendorCustomer `json:"customer"`
+	}
+
+	// Populate the cache first to check that registration invalidates it.
+	var warm vendorCustomer
+	_ = Unmarshal([]byte(`{"id": "1"}`), &warm)
+
+	RegisterAlias(reflect.TypeOf(vendorCustomer{}), "id", "customer_id", "customerId")
+
+	var o Order
+	err := Unmarshal([]byte(`{"customer": {"customer_id": "42", "email": "a@b.c"}}`), &o)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if o.Customer.ID != "42" {
+		t.Errorf("Expected ID='42', got '%s'", o.Customer.ID)
+	}
+
+	var c vendorCustomer
+	if err := Unmarshal([]byte(`{"customerId": "7"}`), &c); err != nil || c.ID != "7" {
+		t.Errorf("Expected alias on top-level value, got %+v (%v)", c, err)
+	}
+
+	// Aliases are exact: other casings are still rejected.
+	err = Unmarshal([]byte(`{"Customer_ID": "7"}`), &c)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) {
+		t.Errorf("Expected *UnknownFieldError, got %v", err)
+	}
+}
+
+func TestRegisterAliasPanics(t *testing.T) {
+	tests := []struct {
+		name      string
+		canonical string
+		alias     string
+	}{
+		{name: "unknown canonical", canonical: "missing", alias: "x"},
+		{name: "alias collides", canonical: "id", alias: "email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			RegisterAlias(reflect.TypeOf(vendorCustomer{}), tt.canonical, tt.alias)
+		})
+	}
+}
+
+// aliasTagged names its fields differently under the json and api tag keys.
+type aliasTagged struct {
+	ID    string `json:"id" api:"ident"`
+	Email string `json:"email" api:"legacy_email"`
+}
+
+func TestRegisterAliasTagKey(t *testing.T) {
+	RegisterAlias(reflect.TypeOf(aliasTagged{}), "id", "legacy_id", "legacy_email")
+
+	// Under json tags the aliases apply to ID, whatever its name.
+	var v aliasTagged
+	if err := Unmarshal([]byte(`{"legacy_email": "1"}`), &v); err != nil || v.ID != "1" {
+		t.Errorf("Expected alias with json tags, got %+v (%v)", v, err)
+	}
+
+	// Under api tags legacy_email is a field name, which the alias of ID
+	// collides with.
+	var fce *FieldConflictError
+	err := NewDecoder(WithTagKey("api")).Unmarshal([]byte(`{"ident": "3"}`), &v)
+	if !errors.As(err, &fce) || fce.Field() != "legacy_email" || !reflect.DeepEqual(fce.Sources(), []string{"Email", "ID"}) {
+		t.Fatalf("Expected a conflict on legacy_email, got %v", err)
+	}
+
+	// Ignoring the conflict keeps the field and the other alias.
+	v = aliasTagged{}
+	err = NewDecoder(WithTagKey("api"), WithIgnoreConflicts(true)).Unmarshal([]byte(`{"legacy_id": "4", "legacy_email": "e"}`), &v)
+	if err != nil || v.ID != "4" || v.Email != "e" {
+		t.Errorf("Expected the alias and the field, got %+v (%v)", v, err)
+	}
+}
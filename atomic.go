@@ -0,0 +1,112 @@
+package strictjson
+
+import "reflect"
+
+// WithAtomic(true) makes Unmarshal, UnmarshalWithReport, DecodeMap and
+// TypeDecoder.Unmarshal leave their destination untouched when they fail.
+// Without it an error part-way through a document leaves the fields decoded
+// before it populated, which is dangerous when decoding into a long-lived
+// value such as a config struct. With it the input is decoded into a copy
+// of the destination, which replaces the destination only once the whole
+// input has decoded successfully; merging into existing values works as
+// without the option.
+//
+// The copy is deep, except for values reachable only through unexported
+// fields, which the decoder does not set. Atomic decoding costs the copy and
+// an extra assignment, so it is off by default.
+func WithAtomic(atomic bool) DecoderOption {
+	return func(d *Decoder) {
+		d.Atomic = atomic
+	}
+}
+
+// target returns the value to decode into in place of dst: dst itself, or a
+// deep copy of it when the decoder is atomic. Pass it to commit once the
+// decode succeeds.
+func (d *Decoder) target(dst reflect.Value) reflect.Value {
+	if !d.Atomic {
+		return dst
+	}
+	c := reflect.New(dst.Type()).Elem()
+	if !dst.IsZero() {
+		cp := copier{ptrs: make(map[ptrKey]reflect.Value)}
+		cp.copy(c, dst)
+	}
+	return c
+}
+
+// commit stores target, returned by target for dst, in dst.
+func (d *Decoder) commit(dst, target reflect.Value) {
+	if d.Atomic {
+		dst.Set(target)
+	}
+}
+
+type ptrKey struct {
+	typ reflect.Type
+	ptr uintptr
+}
+
+// copier deep-copies values, copying each pointer once so that shared and
+// cyclic pointers keep their shape.
+type copier struct {
+	ptrs map[ptrKey]reflect.Value
+}
+
+// copy sets dst, a settable value, to a deep copy of src.
+func (cp *copier) copy(dst, src reflect.Value) {
+	dst.Set(src)
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		key := ptrKey{src.Type(), src.Pointer()}
+		if p, ok := cp.ptrs[key]; ok {
+			dst.Set(p)
+			return
+		}
+		p := reflect.New(src.Type().Elem())
+		cp.ptrs[key] = p
+		cp.copy(p.Elem(), src.Elem())
+		dst.Set(p)
+	case reflect.Struct:
+		for i := 0; i < src.NumField(); i++ {
+			if f := dst.Field(i); f.CanSet() {
+				cp.copy(f, src.Field(i))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			cp.copy(dst.Index(i), src.Index(i))
+		}
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		s := reflect.MakeSlice(src.Type(), src.Len(), src.Cap())
+		for i := 0; i < src.Len(); i++ {
+			cp.copy(s.Index(i), src.Index(i))
+		}
+		dst.Set(s)
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		m := reflect.MakeMapWithSize(src.Type(), src.Len())
+		elem := reflect.New(src.Type().Elem()).Elem()
+		iter := src.MapRange()
+		for iter.Next() {
+			cp.copy(elem, iter.Value())
+			m.SetMapIndex(iter.Key(), elem)
+		}
+		dst.Set(m)
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		e := reflect.New(src.Elem().Type()).Elem()
+		cp.copy(e, src.Elem())
+		dst.Set(e)
+	}
+}
@@ -0,0 +1,105 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type atomicConfig struct {
+	Name    string            `json:"name"`
+	Limits  map[string]int    `json:"limits"`
+	Servers []string          `json:"servers"`
+	Owner   *atomicOwner      `json:"owner"`
+	Extra   any               `json:"extra"`
+	Labels  map[string]string `json:"labels"`
+}
+
+type atomicOwner struct {
+	Email string `json:"email"`
+}
+
+func newAtomicConfig() *atomicConfig {
+	return &atomicConfig{
+		Name:    "prod",
+		Limits:  map[string]int{"cpu": 2},
+		Servers: []string{"a", "b"},
+		Owner:   &atomicOwner{Email: "ops@example.com"},
+		Extra:   map[string]any{"k": "v"},
+	}
+}
+
+func TestAtomic(t *testing.T) {
+	data := []byte(`{"name": "staging", "limits": {"mem": 4}, "servers": ["c"], "owner": {"email": "dev@example.com"}, "extra": {"k": "w"}, "bogus": 1}`)
+
+	// Without the option the fields before the error are decoded.
+	partial := newAtomicConfig()
+	if err := NewDecoder().Unmarshal(data, partial); err == nil {
+		t.Fatal("Unmarshal() expected error")
+	}
+	if partial.Name != "staging" {
+		t.Fatalf("Expected partial decode, got %+v", partial)
+	}
+
+	d := NewDecoder(WithAtomic(true))
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	decoders := map[string]func(v *atomicConfig) error{
+		"Unmarshal": func(v *atomicConfig) error { return d.Unmarshal(data, v) },
+		"UnmarshalWithReport": func(v *atomicConfig) error {
+			_, err := d.UnmarshalWithReport(data, v)
+			return err
+		},
+		"DecodeMap":     func(v *atomicConfig) error { return d.DecodeMap(m, v) },
+		"TypeDecoder":   func(v *atomicConfig) error { return CompileFor[atomicConfig](WithAtomic(true)).Unmarshal(data, v) },
+		"trailing data": func(v *atomicConfig) error { return d.Unmarshal([]byte(`{"name": "x"} {}`), v) },
+	}
+	for name, decode := range decoders {
+		t.Run(name, func(t *testing.T) {
+			got := newAtomicConfig()
+			if err := decode(got); err == nil {
+				t.Fatal("expected error")
+			}
+			if want := newAtomicConfig(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Destination modified: got %+v, want %+v", got, want)
+			}
+		})
+	}
+
+	// On success the input is decoded into the destination as usual.
+	got := newAtomicConfig()
+	if err := d.Unmarshal([]byte(`{"limits": {"mem": 4}, "owner": {"email": "dev@example.com"}}`), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := newAtomicConfig()
+	want.Limits = map[string]int{"mem": 4}
+	want.Owner.Email = "dev@example.com"
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAtomicCopyKeepsSharedPointers(t *testing.T) {
+	type node struct {
+		Name string `json:"name"`
+		Next *node  `json:"next"`
+	}
+	type graph struct {
+		A *node `json:"a"`
+		B *node `json:"b"`
+	}
+	n := &node{Name: "n"}
+	n.Next = n
+	v := &graph{A: n, B: n}
+	if err := NewDecoder(WithAtomic(true)).Unmarshal([]byte(`{"a": {"name": "m"}}`), v); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if v.A == n || v.A != v.B || v.A.Next != v.A || v.A.Name != "m" {
+		t.Errorf("Unexpected copy: a=%p b=%p next=%p name=%q", v.A, v.B, v.A.Next, v.A.Name)
+	}
+	if n.Name != "n" {
+		t.Errorf("Original modified: %q", n.Name)
+	}
+}
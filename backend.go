@@ -0,0 +1,107 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Backend is the JSON engine strictjson delegates to. The decoder's own
+// scanner reads object keys and enforces the strict rules; values that need
+// no key validation (scalars, and subtrees containing no structs) are handed
+// to the backend's Unmarshal. This lets callers plug in a faster engine such
+// as json-iterator, go-json or sonic:
+//
+//	type sonicBackend struct{}
+//
+//	func (sonicBackend) Unmarshal(data []byte, v any) error { return sonic.Unmarshal(data, v) }
+//	func (sonicBackend) RawIterate(data []byte, fn func(key string, value []byte) error) error {
+//		return strictjson.StandardBackend.RawIterate(data, fn)
+//	}
+//
+// A backend's Unmarshal must handle json.Unmarshaler implementations the way
+// encoding/json does. Errors it returns are wrapped so that they match
+// ErrDecode.
+type Backend interface {
+	// Unmarshal decodes a single complete JSON value into v.
+	Unmarshal(data []byte, v any) error
+	// RawIterate calls fn with each key and raw value of the JSON object in
+	// data, in document order, stopping at the first error.
+	RawIterate(data []byte, fn func(key string, value []byte) error) error
+}
+
+// StandardBackend is the default Backend, built on encoding/json.
+var StandardBackend Backend = stdBackend{}
+
+// numberBackend is StandardBackend with json.Decoder.UseNumber set, used
+// when Decoder.UseNumber is.
+var numberBackend Backend = stdBackend{useNumber: true}
+
+type stdBackend struct {
+	useNumber bool
+}
+
+func (b stdBackend) Unmarshal(data []byte, v any) error {
+	if !b.useNumber {
+		return json.Unmarshal(data, v)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	// Decode stops after the first value, where json.Unmarshal would
+	// reject anything else.
+	if _, err := dec.Token(); err != io.EOF {
+		return newSyntaxError("invalid character after top-level value", dec.InputOffset())
+	}
+	return nil
+}
+
+func (stdBackend) RawIterate(data []byte, fn func(key string, value []byte) error) error {
+	s := scanner{data: data}
+	if err := s.expect('{', "looking for beginning of object"); err != nil {
+		return err
+	}
+	if !s.consume('}') {
+		for {
+			key, err := s.readKey()
+			if err != nil {
+				return err
+			}
+			raw, err := s.skipValue()
+			if err != nil {
+				return err
+			}
+			if err := fn(key, raw); err != nil {
+				return err
+			}
+			if s.consume(',') {
+				continue
+			}
+			if err := s.expect('}', "after object key:value pair"); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return s.end()
+}
+
+// WithBackend sets the engine used to decode values that need no key
+// validation. The default is StandardBackend.
+func WithBackend(b Backend) DecoderOption {
+	return func(d *Decoder) {
+		d.Backend = b
+	}
+}
+
+func (d *Decoder) backend() Backend {
+	if d.Backend == nil {
+		if d.UseNumber {
+			return numberBackend
+		}
+		return StandardBackend
+	}
+	return d.Backend
+}
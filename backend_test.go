@@ -0,0 +1,101 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// countingBackend records the values delegated to it.
+type countingBackend struct {
+	values []string
+	err    error
+}
+
+func (b *countingBackend) Unmarshal(data []byte, v any) error {
+	b.values = append(b.values, string(data))
+	if b.err != nil {
+		return b.err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (b *countingBackend) RawIterate(data []byte, fn func(key string, value []byte) error) error {
+	return StandardBackend.RawIterate(data, fn)
+}
+
+func TestWithBackend(t *testing.T) {
+	type Item struct {
+		Name string         `json:"name"`
+		Meta map[string]int `json:"meta"`
+	}
+	type Order struct {
+		ID    int    `json:"id"`
+		Items []Item `json:"items"`
+	}
+
+	b := &countingBackend{}
+	d := NewDecoder(WithBackend(b))
+	var o Order
+	err := d.Unmarshal([]byte(`{"id": 7, "items": [{"name": "a", "meta": {"x": 1}}]}`), &o)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := []string{`7`, `"a"`, `{"x": 1}`}
+	if !reflect.DeepEqual(b.values, want) {
+		t.Errorf("Expected backend to receive %q, got %q", want, b.values)
+	}
+	if o.ID != 7 || o.Items[0].Meta["x"] != 1 {
+		t.Errorf("Unexpected result %+v", o)
+	}
+
+	// Key validation still happens before the backend is consulted.
+	b.values = nil
+	if err := d.Unmarshal([]byte(`{"ID": 7}`), &o); err == nil {
+		t.Error("Expected error for mis-cased key")
+	}
+	if len(b.values) != 0 {
+		t.Errorf("Expected no delegated values, got %q", b.values)
+	}
+
+	boom := errors.New("boom")
+	b.err = boom
+	err = d.Unmarshal([]byte(`{"id": 7}`), &o)
+	if !errors.Is(err, boom) || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected wrapped backend error, got %v", err)
+	}
+}
+
+func TestStandardBackendRawIterate(t *testing.T) {
+	var keys, values []string
+	err := StandardBackend.RawIterate([]byte(` {"b": [1, 2], "aé": {"x": null}} `), func(key string, value []byte) error {
+		keys = append(keys, key)
+		values = append(values, string(value))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RawIterate() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"b", "aé"}) || !reflect.DeepEqual(values, []string{"[1, 2]", `{"x": null}`}) {
+		t.Errorf("Unexpected members %q %q", keys, values)
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err = StandardBackend.RawIterate([]byte(`{"a": 1, "b": 2}`), func(string, []byte) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected iteration to stop on error, got %v after %d calls", err, calls)
+	}
+
+	var syntaxErr *SyntaxError
+	for _, input := range []string{`[1]`, `{"a": 1,}`, `{"a": 1} x`} {
+		err := StandardBackend.RawIterate([]byte(input), func(string, []byte) error { return nil })
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("RawIterate(%s): expected SyntaxError, got %v", input, err)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package strictjson
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// WithStrictBase64(true) makes []byte values decode only from canonical
+// standard base64 with padding. encoding/json ignores line breaks and
+// tolerates non-zero trailing bits, both of which can hide corrupted data;
+// such input, like any other invalid base64, is reported with a *ValueError
+// naming its location.
+//
+// A field can require another encoding, whatever the decoder's options,
+// with one of the struct tag options b64 (standard), b64url (URL-safe),
+// b64raw (standard without padding) and b64rawurl (URL-safe without
+// padding), as in `strict:"b64url"`. Input in the other alphabet is then
+// rejected too.
+func WithStrictBase64(strict bool) DecoderOption {
+	return func(d *Decoder) {
+		d.StrictBase64 = strict
+	}
+}
+
+// base64Encodings maps the strict tag options that select a base64
+// encoding to it.
+var base64Encodings = map[string]*base64.Encoding{
+	"b64":       base64.StdEncoding.Strict(),
+	"b64url":    base64.URLEncoding.Strict(),
+	"b64raw":    base64.RawStdEncoding.Strict(),
+	"b64rawurl": base64.RawURLEncoding.Strict(),
+}
+
+var base64Names = map[string]string{
+	"b64":       "standard base64",
+	"b64url":    "URL-safe base64",
+	"b64raw":    "unpadded standard base64",
+	"b64rawurl": "unpadded URL-safe base64",
+}
+
+var byteType = reflect.TypeOf(byte(0))
+
+// isBytes reports whether t is a byte slice, which encoding/json decodes
+// from base64 strings.
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem() == byteType
+}
+
+// checkFormat returns an error if the strict tag format of the field named
+// name does not apply to its type t.
+func checkFormat(name string, t reflect.Type, format string) error {
+	if base64Encodings[format] != nil {
+		if !isBytes(t) {
+			return newInvalidTagError(name, fmt.Sprintf(`strict:"%s" requires type []byte`, format))
+		}
+		return nil
+	}
+	if t != timeType {
+		return newInvalidTagError(name, `strict:"time=..." requires type time.Time`)
+	}
+	return nil
+}
+
+// base64Format returns the strict tag option naming the encoding []byte
+// values at the current position must be written in, or "" if they are
+// left to the backend.
+func (s *decodeState) base64Format() string {
+	if s.current != nil && s.current.format != "" {
+		return s.current.format
+	}
+	if s.d.StrictBase64 {
+		return "b64"
+	}
+	return ""
+}
+
+// base64String decodes the next value, a JSON string, into v, a []byte,
+// from the base64 encoding named by format.
+func (s *decodeState) base64String(v reflect.Value, format string) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	str := scanner{data: raw}
+	contents, escaped, _ := str.readString()
+	return s.decodeBase64(v, raw, unquote(contents, escaped), format)
+}
+
+// decodeBase64 decodes str, written as raw, from the base64 encoding named
+// by format into v.
+func (s *decodeState) decodeBase64(v reflect.Value, raw []byte, str, format string) error {
+	b, err := base64Encodings[format].DecodeString(str)
+	if err == nil && strings.ContainsAny(str, "\r\n") {
+		err = errors.New("line breaks are not allowed")
+	}
+	if err != nil {
+		return newValueError(s.pathString(), v.Type(), s.snippet(raw), "is not valid "+base64Names[format], err)
+	}
+	bv := reflect.ValueOf(b)
+	if bv.Type() != v.Type() {
+		bv = bv.Convert(v.Type())
+	}
+	v.Set(bv)
+	return nil
+}
@@ -0,0 +1,111 @@
+package strictjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestStrictBase64(t *testing.T) {
+	type Blob []byte
+	type Upload struct {
+		Data   []byte            `json:"data"`
+		Token  []byte            `json:"token" strict:"b64url"`
+		Raw    Blob              `json:"raw" strict:"b64rawurl"`
+		Chunks [][]byte          `json:"chunks"`
+		ByName map[string][]byte `json:"byName"`
+	}
+
+	tests := []struct {
+		name     string
+		strict   bool
+		data     string
+		wantPath string
+		wantMsg  string
+	}{
+		{name: "canonical", strict: true, data: `{"data": "aGk=", "token": "-_8=", "raw": "-_8", "chunks": ["aGk="]}`},
+		{name: "line break tolerated by default", data: `{"data": "aG\nk="}`},
+		{
+			name:     "line break",
+			strict:   true,
+			data:     `{"chunks": ["aG\r\nk="]}`,
+			wantPath: "chunks[0]",
+			wantMsg:  `strictjson: value at "chunks[0]" is not valid standard base64: line breaks are not allowed`,
+		},
+		{name: "trailing bits tolerated by default", data: `{"data": "aGl="}`},
+		{
+			name:     "trailing bits",
+			strict:   true,
+			data:     `{"byName": {"a": "aGl="}}`,
+			wantPath: "byName.a",
+			wantMsg:  `strictjson: value at "byName.a" is not valid standard base64`,
+		},
+		{
+			name:     "missing padding",
+			strict:   true,
+			data:     `{"data": "aGk"}`,
+			wantPath: "data",
+		},
+		{
+			name:     "URL-safe alphabet for standard",
+			strict:   true,
+			data:     `{"data": "-_8="}`,
+			wantPath: "data",
+		},
+		{
+			name:     "standard alphabet for URL-safe field",
+			data:     `{"token": "+/8="}`,
+			wantPath: "token",
+			wantMsg:  `strictjson: value at "token" is not valid URL-safe base64: illegal base64 data at input byte 0`,
+		},
+		{
+			name:     "padding for unpadded field",
+			data:     `{"raw": "-_8="}`,
+			wantPath: "raw",
+			wantMsg:  `strictjson: value at "raw" is not valid unpadded URL-safe base64`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(WithStrictBase64(tt.strict))
+			var v Upload
+			err := d.Unmarshal([]byte(tt.data), &v)
+			verr := d.Validate([]byte(tt.data), &Upload{})
+			if tt.wantPath == "" {
+				if err != nil || verr != nil {
+					t.Fatalf("Unexpected errors %v, %v", err, verr)
+				}
+				return
+			}
+			for _, err := range []error{err, verr} {
+				var ve *ValueError
+				if !errors.As(err, &ve) || ve.Path() != tt.wantPath {
+					t.Fatalf("Expected *ValueError at %q, got %v", tt.wantPath, err)
+				}
+				if !strings.HasPrefix(err.Error(), tt.wantMsg) {
+					t.Errorf("Error() = %q, want prefix %q", err.Error(), tt.wantMsg)
+				}
+			}
+		})
+	}
+
+	var v Upload
+	if err := NewDecoder(WithStrictBase64(true)).Unmarshal([]byte(`{"data": "aGk=", "raw": "aGk"}`), &v); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if string(v.Data) != "hi" || string(v.Raw) != "hi" {
+		t.Errorf("Unexpected bytes %q, %q", v.Data, v.Raw)
+	}
+	v = Upload{}
+	if err := DecodeMap(map[string]any{"token": "aGk="}, &v); err != nil || string(v.Token) != "hi" {
+		t.Errorf("DecodeMap() = %q, %v", v.Token, err)
+	}
+
+	var ite *InvalidTagError
+	var bad struct {
+		Name string `json:"name" strict:"b64url"`
+	}
+	if err := Unmarshal([]byte(`{}`), &bad); !errors.As(err, &ite) {
+		t.Errorf("Expected *InvalidTagError, got %v", err)
+	}
+}
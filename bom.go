@@ -0,0 +1,48 @@
+package strictjson
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+)
+
+// WithRejectLeadingBOM(true) rejects input that starts with a UTF-8 byte
+// order mark (U+FEFF) with a *SyntaxError. RFC 8259 forbids generators from
+// adding one but lets parsers ignore it, and by default a leading byte order
+// mark, as written by some editors, is skipped. A byte order mark anywhere
+// else is an invalid character.
+//
+// Whatever the option, input must hold exactly one value: anything but
+// whitespace after it is rejected by every entry point.
+func WithRejectLeadingBOM(reject bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RejectLeadingBOM = reject
+	}
+}
+
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// skipBOM returns the length of the byte order mark data starts with, if
+// any, or an error if the decoder rejects it.
+func (d *Decoder) skipBOM(data []byte) (int, error) {
+	if !bytes.HasPrefix(data, utf8BOM) {
+		return 0, nil
+	}
+	if d.RejectLeadingBOM {
+		return 0, newSyntaxError("invalid byte order mark at beginning of input", 0)
+	}
+	return len(utf8BOM), nil
+}
+
+// bomReader returns a reader of the input of r past its byte order mark, if
+// any, and the length of that mark, or an error if the decoder rejects it.
+func (d *Decoder) bomReader(r io.Reader) (io.Reader, int, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(utf8BOM))
+	n, err := d.skipBOM(head)
+	if err != nil {
+		return nil, 0, err
+	}
+	br.Discard(n)
+	return br, n, nil
+}
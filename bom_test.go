@@ -0,0 +1,108 @@
+package strictjson
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type bomDoc struct {
+	A int `json:"a"`
+}
+
+// entryPoints decodes data as a bomDoc through every byte-slice entry point.
+func entryPoints(opts ...DecoderOption) map[string]func(data []byte) error {
+	d := NewDecoder(opts...)
+	return map[string]func(data []byte) error{
+		"Unmarshal": func(data []byte) error { return d.Unmarshal(data, &bomDoc{}) },
+		"Validate":  func(data []byte) error { return d.Validate(data, &bomDoc{}) },
+		"Check": func(data []byte) error {
+			_, err := d.Check(data, &bomDoc{})
+			return err
+		},
+		"UnmarshalWithReport": func(data []byte) error {
+			_, err := d.UnmarshalWithReport(data, &bomDoc{})
+			return err
+		},
+		"TypeDecoder": func(data []byte) error {
+			_, err := CompileFor[bomDoc](opts...).Decode(data)
+			return err
+		},
+	}
+}
+
+func TestTrailingData(t *testing.T) {
+	for name, decode := range entryPoints() {
+		t.Run(name, func(t *testing.T) {
+			if err := decode([]byte("{\"a\": 1} \n\t")); err != nil {
+				t.Errorf("Trailing whitespace: unexpected error: %v", err)
+			}
+			var se *SyntaxError
+			for _, data := range []string{`{"a": 1} garbage`, `{"a": 1}{}`, `{"a": 1}]`} {
+				if err := decode([]byte(data)); !errors.As(err, &se) {
+					t.Errorf("%s: expected *SyntaxError, got %v", data, err)
+				}
+			}
+		})
+	}
+
+	err := ForEach([]byte(`[{"a": 1}] x`), &bomDoc{}, func(i int, elem any) error { return nil })
+	if err == nil {
+		t.Error("ForEach() with trailing data: expected error")
+	}
+	err = ForEachReader(bytes.NewReader([]byte(`[{"a": 1}] x`)), &bomDoc{}, func(i int, elem any) error { return nil })
+	if err == nil {
+		t.Error("ForEachReader() with trailing data: expected error")
+	}
+
+	// The backend used for UseNumber rejects trailing data like
+	// json.Unmarshal.
+	var n any
+	if err := NewDecoder(WithUseNumber(true)).backend().Unmarshal([]byte(`1 2`), &n); err == nil {
+		t.Errorf("UseNumber backend accepted trailing data: %v", n)
+	}
+}
+
+func TestLeadingBOM(t *testing.T) {
+	data := []byte("\xef\xbb\xbf{\"a\": 1}")
+	for name, decode := range entryPoints() {
+		if err := decode(data); err != nil {
+			t.Errorf("%s: unexpected error: %v", name, err)
+		}
+	}
+	var got bomDoc
+	if err := Unmarshal(data, &got); err != nil || got.A != 1 {
+		t.Errorf("Unmarshal() = %+v, %v", got, err)
+	}
+
+	for name, decode := range entryPoints(WithRejectLeadingBOM(true)) {
+		var se *SyntaxError
+		if err := decode(data); !errors.As(err, &se) || se.Offset != 0 {
+			t.Errorf("%s: expected *SyntaxError at offset 0, got %v", name, err)
+		}
+	}
+
+	// Only a leading byte order mark is skipped.
+	if err := Unmarshal([]byte("{\"a\": \xef\xbb\xbf1}"), &got); err == nil {
+		t.Error("Unmarshal() accepted a byte order mark inside the value")
+	}
+
+	// Offsets still count the byte order mark.
+	var se *SyntaxError
+	if err := Unmarshal([]byte("\xef\xbb\xbf{\"a\": x}"), &got); !errors.As(err, &se) || se.Offset != 9 {
+		t.Errorf("Expected *SyntaxError at offset 9, got %v", err)
+	}
+
+	array := []byte("\xef\xbb\xbf[{\"a\": 1}, {\"a\": 2}]")
+	count := 0
+	if err := ForEachReader(bytes.NewReader(array), &bomDoc{}, func(i int, elem any) error {
+		count++
+		return nil
+	}); err != nil || count != 2 {
+		t.Errorf("ForEachReader() = %d elements, %v", count, err)
+	}
+	err := NewDecoder(WithRejectLeadingBOM(true)).ForEachReader(bytes.NewReader(array), &bomDoc{}, func(i int, elem any) error { return nil })
+	if !errors.As(err, &se) {
+		t.Errorf("ForEachReader() expected *SyntaxError, got %v", err)
+	}
+}
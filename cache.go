@@ -0,0 +1,158 @@
+package strictjson
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// Decode plans and field tables are cached per type and field config. The
+// cache is unbounded by default; SetCacheLimit bounds it for processes that
+// decode into an open-ended set of types, such as types built with
+// reflect.StructOf or loaded from plugins.
+var planLRU struct {
+	sync.Mutex
+	order     *list.List // of fieldKey, most recently used first
+	elems     map[fieldKey]*list.Element
+	evictions uint64
+}
+
+var (
+	cacheLimit             atomic.Int64 // read without the lock on hits
+	cacheHits, cacheMisses atomic.Uint64
+)
+
+// CacheStats reports the activity of the type cache.
+type CacheStats struct {
+	// Hits and Misses count plan lookups by decode calls since the program
+	// started. A miss builds the plans of the type and of every type
+	// reachable from it.
+	Hits, Misses uint64
+	// Entries is the number of types currently cached.
+	Entries int
+	// Evictions counts types dropped to stay within the cache limit.
+	Evictions uint64
+}
+
+// ReadCacheStats returns the current cache statistics.
+func ReadCacheStats() CacheStats {
+	planLRU.Lock()
+	defer planLRU.Unlock()
+	stats := CacheStats{
+		Hits:      cacheHits.Load(),
+		Misses:    cacheMisses.Load(),
+		Evictions: planLRU.evictions,
+	}
+	if planLRU.order != nil {
+		stats.Entries = planLRU.order.Len()
+	}
+	return stats
+}
+
+// SetCacheLimit bounds the number of types whose decode plans or field
+// tables are cached, evicting the least recently used beyond n. Zero, the
+// default, means no limit. An evicted type is rebuilt on its next use;
+// values that hold a plan, such as a TypeDecoder, keep working.
+func SetCacheLimit(n int) {
+	planMu.Lock()
+	defer planMu.Unlock()
+	planLRU.Lock()
+	defer planLRU.Unlock()
+	cacheLimit.Store(int64(n))
+	evictLocked()
+}
+
+// ClearCache discards all cached decode plans and field tables, e.g. after
+// a plugin's types are no longer used. Statistics are kept.
+func ClearCache() {
+	fieldCache.Range(func(k, _ any) bool {
+		fieldCache.Delete(k)
+		return true
+	})
+	resetPlans()
+}
+
+// cacheHit records a lookup that found key in the cache.
+func cacheHit(key fieldKey) {
+	cacheHits.Add(1)
+	cacheTouch(key)
+}
+
+// cacheTouch marks key as recently used.
+func cacheTouch(key fieldKey) {
+	if cacheLimit.Load() <= 0 {
+		return
+	}
+	planLRU.Lock()
+	if e, ok := planLRU.elems[key]; ok {
+		planLRU.order.MoveToFront(e)
+	}
+	planLRU.Unlock()
+}
+
+// cacheStore caches p under key. The caller holds planMu.
+func cacheStore(key fieldKey, p *typePlan) {
+	planCache.Store(key, p)
+	cacheTrack(key)
+}
+
+// cacheStoreFields caches the field table sf under key. Field tables are
+// also built without a plan, by FieldsOf, Diff and SchemaFor, so they count
+// against the limit on their own.
+func cacheStoreFields(key fieldKey, sf *structFields) {
+	fieldCache.Store(key, sf)
+	cacheTrack(key)
+}
+
+// cacheTrack marks key as most recently used and evicts beyond the limit.
+func cacheTrack(key fieldKey) {
+	planLRU.Lock()
+	defer planLRU.Unlock()
+	trackLocked(key)
+	evictLocked()
+}
+
+// trackLocked marks key as most recently used. The caller holds planLRU.
+func trackLocked(key fieldKey) {
+	if planLRU.order == nil {
+		planLRU.order = list.New()
+		planLRU.elems = make(map[fieldKey]*list.Element)
+	}
+	if e, ok := planLRU.elems[key]; ok {
+		planLRU.order.MoveToFront(e)
+	} else {
+		planLRU.elems[key] = planLRU.order.PushFront(key)
+	}
+}
+
+// evictLocked drops least recently used types until the cache is within its
+// limit. The caller holds planLRU. A plan being built under planMu may be
+// evicted before it is used; it still works, and is rebuilt on next use.
+func evictLocked() {
+	limit := int(cacheLimit.Load())
+	if limit <= 0 || planLRU.order == nil {
+		return
+	}
+	for planLRU.order.Len() > limit {
+		e := planLRU.order.Back()
+		key := e.Value.(fieldKey)
+		planLRU.order.Remove(e)
+		delete(planLRU.elems, key)
+		planCache.Delete(key)
+		fieldCache.Delete(key)
+		planLRU.evictions++
+	}
+}
+
+// resetCacheOrder forgets the recency of every type, keeping count of the
+// field tables that remain cached. The caller holds planMu.
+func resetCacheOrder() {
+	planLRU.Lock()
+	defer planLRU.Unlock()
+	planLRU.order = nil
+	planLRU.elems = nil
+	fieldCache.Range(func(k, _ any) bool {
+		trackLocked(k.(fieldKey))
+		return true
+	})
+}
@@ -0,0 +1,96 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func dynamicStruct(i int) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{{
+		Name: "Value",
+		Type: reflect.TypeOf(""),
+		Tag:  reflect.StructTag(fmt.Sprintf(`json:"value%d"`, i)),
+	}})
+}
+
+func TestSetCacheLimit(t *testing.T) {
+	ClearCache()
+	SetCacheLimit(3)
+	defer SetCacheLimit(0)
+
+	before := ReadCacheStats()
+	for i := 0; i < 10; i++ {
+		v := reflect.New(dynamicStruct(i))
+		if err := Unmarshal([]byte(fmt.Sprintf(`{"value%d": "x"}`, i)), v.Interface()); err != nil {
+			t.Fatalf("Unmarshal() type %d: %v", i, err)
+		}
+		if err := Unmarshal([]byte(fmt.Sprintf(`{"VALUE%d": "x"}`, i)), v.Interface()); err == nil {
+			t.Fatalf("Expected unknown field error for type %d", i)
+		}
+	}
+	stats := ReadCacheStats()
+	if stats.Entries > 3 {
+		t.Errorf("Entries = %d, want at most 3", stats.Entries)
+	}
+	if stats.Evictions == before.Evictions {
+		t.Error("Expected evictions")
+	}
+	if hits := stats.Hits - before.Hits; hits < 10 {
+		t.Errorf("Hits = %d, want at least 10", hits)
+	}
+	if misses := stats.Misses - before.Misses; misses < 10 {
+		t.Errorf("Misses = %d, want at least 10", misses)
+	}
+
+	// An evicted type is rebuilt on its next use.
+	v := reflect.New(dynamicStruct(0))
+	if err := Unmarshal([]byte(`{"value0": "again"}`), v.Interface()); err != nil {
+		t.Errorf("Unmarshal() after eviction: %v", err)
+	}
+}
+
+func TestSetCacheLimitFieldTables(t *testing.T) {
+	ClearCache()
+	SetCacheLimit(3)
+	defer SetCacheLimit(0)
+
+	// FieldsOf builds field tables without plans; they are evicted too.
+	for i := 0; i < 10; i++ {
+		if _, err := FieldsOf(dynamicStruct(100 + i)); err != nil {
+			t.Fatalf("FieldsOf() type %d: %v", i, err)
+		}
+	}
+	n := 0
+	fieldCache.Range(func(_, _ any) bool {
+		n++
+		return true
+	})
+	if n > 3 {
+		t.Errorf("%d field tables cached, want at most 3", n)
+	}
+	if entries := ReadCacheStats().Entries; entries > 3 {
+		t.Errorf("Entries = %d, want at most 3", entries)
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	var it Item
+	if err := Unmarshal([]byte(`{"name": "a"}`), &it); err != nil {
+		t.Fatal(err)
+	}
+	if ReadCacheStats().Entries == 0 {
+		t.Fatal("Expected cached entries")
+	}
+
+	ClearCache()
+	if n := ReadCacheStats().Entries; n != 0 {
+		t.Errorf("Entries after ClearCache() = %d, want 0", n)
+	}
+	if err := Unmarshal([]byte(`{"Name": "a"}`), &it); err == nil {
+		t.Error("Expected unknown field error after ClearCache()")
+	}
+}
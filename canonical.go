@@ -0,0 +1,314 @@
+package strictjson
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// WithRequireCanonical(true) rejects input that is not already in the
+// canonical form of the JSON Canonicalization Scheme (RFC 8785) with a
+// *CanonicalError: no whitespace outside strings, object keys sorted by
+// their UTF-16 code units, strings escaped only where required, and numbers
+// written as ECMAScript writes doubles. Duplicate keys, invalid UTF-8 and
+// unpaired surrogate escapes are rejected as well. Verifying a signature
+// over canonical JSON is then sound: the bytes signed are the bytes decoded.
+func WithRequireCanonical(require bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RequireCanonical = require
+	}
+}
+
+// Canonicalize re-serializes the JSON document data in the canonical form
+// of the JSON Canonicalization Scheme (RFC 8785), as required before hashing
+// or signing it. A leading byte order mark is dropped. It fails with a
+// *DuplicateKeyError for a repeated key, a *SyntaxError for invalid JSON,
+// including invalid UTF-8 and unpaired surrogate escapes, and a
+// *CanonicalError for a number outside the range of a double.
+func Canonicalize(data []byte) ([]byte, error) {
+	c := canonicalizer{
+		scan: scanner{data: data, strictUTF8: true},
+		out:  make([]byte, 0, len(data)),
+	}
+	if bytes.HasPrefix(data, utf8BOM) {
+		c.scan.pos = len(utf8BOM)
+	}
+	if err := c.document(); err != nil {
+		return nil, err
+	}
+	return c.out, nil
+}
+
+// checkCanonical returns a *CanonicalError if data is not canonical JSON.
+func checkCanonical(data []byte) error {
+	if bytes.HasPrefix(data, utf8BOM) {
+		return newCanonicalError("", 0, "leading byte order mark")
+	}
+	c := canonicalizer{scan: scanner{data: data, strictUTF8: true}, verify: true}
+	return c.document()
+}
+
+// canonicalDepthLimit bounds the nesting canonicalizer recurses into.
+const canonicalDepthLimit = 10000
+
+// canonicalizer writes the canonical form of a document to out or, with
+// verify, checks that the document is written in it.
+type canonicalizer struct {
+	scan   scanner
+	out    []byte
+	path   []pathSegment
+	depth  int
+	verify bool
+}
+
+// member locates a member of the object being written in out.
+type member struct {
+	key        string
+	start, end int
+}
+
+func (c *canonicalizer) document() error {
+	if err := c.value(); err != nil {
+		return err
+	}
+	if err := c.space(); err != nil {
+		return err
+	}
+	return c.scan.end()
+}
+
+// fail reports the value at offset as not canonical.
+func (c *canonicalizer) fail(offset int, reason string) error {
+	return newCanonicalError(formatPath(c.path), int64(offset), reason)
+}
+
+// space rejects whitespace at the current position when verifying.
+func (c *canonicalizer) space() error {
+	if c.verify && c.scan.pos < len(c.scan.data) && isSpace(c.scan.data[c.scan.pos]) {
+		return c.fail(c.scan.pos, "whitespace outside strings")
+	}
+	return nil
+}
+
+// written checks, when verifying, that the input from start to the current
+// position reads as out from outStart on.
+func (c *canonicalizer) written(start, outStart int, reason string) error {
+	if c.verify && !bytes.Equal(c.out[outStart:], c.scan.data[start:c.scan.pos]) {
+		return c.fail(start, reason)
+	}
+	return nil
+}
+
+func (c *canonicalizer) value() error {
+	if err := c.space(); err != nil {
+		return err
+	}
+	b := c.scan.peek()
+	start, outStart := c.scan.pos, len(c.out)
+	switch {
+	case b == 0:
+		return c.scan.errorf("looking for beginning of value")
+	case b == '{':
+		return c.object()
+	case b == '[':
+		return c.array()
+	case b == '"':
+		raw, escaped, err := c.scan.readString()
+		if err != nil {
+			return err
+		}
+		c.out = appendCanonicalString(c.out, unquote(raw, escaped))
+		return c.written(start, outStart, "string is not escaped canonically")
+	case b == '-' || isDigit(b):
+		if err := c.scan.readNumber(); err != nil {
+			return err
+		}
+		raw := string(c.scan.data[start:c.scan.pos])
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsInf(f, 0) {
+			return c.fail(start, fmt.Sprintf("number %s is outside the range of a double", raw))
+		}
+		c.out = appendCanonicalNumber(c.out, f)
+		if c.verify && string(c.out[outStart:]) != raw {
+			return c.fail(start, fmt.Sprintf("number %s is written %s in canonical form", raw, c.out[outStart:]))
+		}
+		return nil
+	default:
+		if err := c.scan.readScalar(); err != nil {
+			return err
+		}
+		c.out = append(c.out, c.scan.data[start:c.scan.pos]...)
+		return nil
+	}
+}
+
+func (c *canonicalizer) enter() error {
+	c.depth++
+	if c.depth > canonicalDepthLimit {
+		return newMaxDepthError(formatPath(c.path), canonicalDepthLimit)
+	}
+	c.scan.pos++
+	return nil
+}
+
+func (c *canonicalizer) object() error {
+	if err := c.enter(); err != nil {
+		return err
+	}
+	open := len(c.out)
+	c.out = append(c.out, '{')
+	var members []member
+	seen := make(map[string]struct{})
+	sorted := true
+	if err := c.space(); err != nil {
+		return err
+	}
+	for !c.scan.consume('}') {
+		if len(members) > 0 {
+			if err := c.scan.expect(',', "after object key:value pair"); err != nil {
+				return err
+			}
+			c.out = append(c.out, ',')
+			if err := c.space(); err != nil {
+				return err
+			}
+		}
+		if c.scan.peek() != '"' {
+			return c.scan.errorf("looking for beginning of object key string")
+		}
+		start, outStart := c.scan.pos, len(c.out)
+		raw, escaped, err := c.scan.readString()
+		if err != nil {
+			return err
+		}
+		key := unquote(raw, escaped)
+		c.path = append(c.path, pathSegment{key: key, index: -1})
+		if _, dup := seen[key]; dup {
+			return newDuplicateKeyError(key, formatPath(c.path))
+		}
+		seen[key] = struct{}{}
+		c.out = appendCanonicalString(c.out, key)
+		if err := c.written(start, outStart, "key is not escaped canonically"); err != nil {
+			return err
+		}
+		if n := len(members); n > 0 && !lessUTF16(members[n-1].key, key) {
+			if c.verify {
+				return c.fail(start, fmt.Sprintf("key %q is out of order after %q", key, members[n-1].key))
+			}
+			sorted = false
+		}
+		if err := c.space(); err != nil {
+			return err
+		}
+		if err := c.scan.expect(':', "after object key"); err != nil {
+			return err
+		}
+		c.out = append(c.out, ':')
+		if err := c.value(); err != nil {
+			return err
+		}
+		c.path = c.path[:len(c.path)-1]
+		members = append(members, member{key: key, start: outStart, end: len(c.out)})
+		if err := c.space(); err != nil {
+			return err
+		}
+	}
+	if !sorted {
+		sort.Slice(members, func(i, j int) bool { return lessUTF16(members[i].key, members[j].key) })
+		body := append([]byte(nil), c.out[open+1:]...)
+		c.out = c.out[:open+1]
+		for i, m := range members {
+			if i > 0 {
+				c.out = append(c.out, ',')
+			}
+			c.out = append(c.out, body[m.start-open-1:m.end-open-1]...)
+		}
+	}
+	c.out = append(c.out, '}')
+	c.depth--
+	return nil
+}
+
+func (c *canonicalizer) array() error {
+	if err := c.enter(); err != nil {
+		return err
+	}
+	c.out = append(c.out, '[')
+	if err := c.space(); err != nil {
+		return err
+	}
+	for i := 0; !c.scan.consume(']'); i++ {
+		if i > 0 {
+			if err := c.scan.expect(',', "after array element"); err != nil {
+				return err
+			}
+			c.out = append(c.out, ',')
+		}
+		c.path = append(c.path, pathSegment{index: i})
+		if err := c.value(); err != nil {
+			return err
+		}
+		c.path = c.path[:len(c.path)-1]
+		if err := c.space(); err != nil {
+			return err
+		}
+	}
+	c.out = append(c.out, ']')
+	c.depth--
+	return nil
+}
+
+// lessUTF16 reports whether a sorts before b when both are compared as
+// sequences of UTF-16 code units, as RFC 8785 orders keys.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// appendCanonicalString appends s as a JSON string in which only quotes,
+// backslashes and control characters are escaped, the latter with their
+// short forms where JSON has one.
+func appendCanonicalString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			dst = append(dst, '\\', c)
+		case '\b':
+			dst = append(dst, `\b`...)
+		case '\t':
+			dst = append(dst, `\t`...)
+		case '\n':
+			dst = append(dst, `\n`...)
+		case '\f':
+			dst = append(dst, `\f`...)
+		case '\r':
+			dst = append(dst, `\r`...)
+		default:
+			if c < 0x20 {
+				dst = append(dst, fmt.Sprintf(`\u%04x`, c)...)
+				continue
+			}
+			dst = append(dst, c)
+		}
+	}
+	return append(dst, '"')
+}
+
+// appendCanonicalNumber appends f as ECMAScript's Number.prototype.toString
+// writes it: the shortest digits that round-trip, in plain notation for
+// magnitudes from 1e-6 up to 1e21 and in exponential notation otherwise.
+func appendCanonicalNumber(dst []byte, f float64) []byte {
+	if f == 0 {
+		return append(dst, '0') // including -0
+	}
+	return appendFloat(dst, f, 64)
+}
@@ -0,0 +1,114 @@
+package strictjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		// The example of RFC 8785, section 3.2.2.
+		{
+			`{"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+			  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+			  "literals": [null, true, false]}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		// Keys sort by UTF-16 code units, so U+1F600 (a surrogate pair)
+		// sorts before U+FB33.
+		{"{\"\ufb33\": 1, \"\U0001f600\": 2, \"\\r\": 3, \"1\": 4, \"\u00f6\": 5}", "{\"\\r\":3,\"1\":4,\"\u00f6\":5,\"\U0001f600\":2,\"\ufb33\":1}"},
+		{`[-0, 0.0, 1e21, 1e20, 1e-6, 1e-7, -1.5e-7, 100]`, `[0,0,1e+21,100000000000000000000,0.000001,1e-7,-1.5e-7,100]`},
+		{`{"b": {"d": 1, "c": [{"f": 1, "e": 2}]}, "a": "x"}`, `{"a":"x","b":{"c":[{"e":2,"f":1}],"d":1}}`},
+		{"\ufeff\"bom\"", `"bom"`},
+		{"\"\u2028\x7f\"", "\"\u2028\x7f\""},
+	}
+	for _, tt := range tests {
+		got, err := Canonicalize([]byte(tt.in))
+		if err != nil {
+			t.Errorf("Canonicalize(%s) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("Canonicalize(%s) =\n%s, want\n%s", tt.in, got, tt.want)
+		}
+		if err := checkCanonical(got); err != nil {
+			t.Errorf("checkCanonical(%s) unexpected error: %v", got, err)
+		}
+	}
+}
+
+func TestCanonicalizeErrors(t *testing.T) {
+	var dke *DuplicateKeyError
+	if _, err := Canonicalize([]byte(`{"a": {"b": 1, "b": 2}}`)); !errors.As(err, &dke) || dke.Path() != "a.b" {
+		t.Errorf("Expected *DuplicateKeyError at a.b, got %v", err)
+	}
+	var ce *CanonicalError
+	if _, err := Canonicalize([]byte(`[1e400]`)); !errors.As(err, &ce) || ce.Path() != "[0]" {
+		t.Errorf("Expected *CanonicalError at [0], got %v", err)
+	}
+	for _, bad := range []string{`{"a": 1,}`, `"\ud800"`, "\"\xff\"", `[1] 2`, ``} {
+		var se *SyntaxError
+		if _, err := Canonicalize([]byte(bad)); !errors.As(err, &se) {
+			t.Errorf("Canonicalize(%q): expected *SyntaxError, got %v", bad, err)
+		}
+	}
+	var mde *MaxDepthError
+	deep := strings.Repeat("[", canonicalDepthLimit+1) + strings.Repeat("]", canonicalDepthLimit+1)
+	if _, err := Canonicalize([]byte(deep)); !errors.As(err, &mde) {
+		t.Errorf("Expected *MaxDepthError, got %v", err)
+	}
+}
+
+func TestRequireCanonical(t *testing.T) {
+	type payload struct {
+		Amount float64 `json:"amount"`
+		Payee  string  `json:"payee"`
+	}
+	d := NewDecoder(WithRequireCanonical(true))
+	var got payload
+	if err := d.Unmarshal([]byte(`{"amount":12.5,"payee":"bob"}`), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.Amount != 12.5 || got.Payee != "bob" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	tests := []struct {
+		in         string
+		wantOffset int64
+		wantMsg    string
+	}{
+		{`{"amount": 12.5}`, 10, `strictjson: non-canonical JSON at offset 10 ("amount"): whitespace outside strings`},
+		{`{"payee":"bob","amount":12.5}`, 15, `strictjson: non-canonical JSON at offset 15 ("amount"): key "amount" is out of order after "payee"`},
+		{`{"amount":12.50}`, 10, `strictjson: non-canonical JSON at offset 10 ("amount"): number 12.50 is written 12.5 in canonical form`},
+		{`{"payee":"\u0062ob"}`, 9, `strictjson: non-canonical JSON at offset 9 ("payee"): string is not escaped canonically`},
+		{`{"p\u0061yee":"bob"}`, 1, `strictjson: non-canonical JSON at offset 1 ("payee"): key is not escaped canonically`},
+		{"\ufeff{}", 0, `strictjson: non-canonical JSON at offset 0: leading byte order mark`},
+		{"{}\n", 2, `strictjson: non-canonical JSON at offset 2: whitespace outside strings`},
+	}
+	for _, tt := range tests {
+		err := d.Unmarshal([]byte(tt.in), &payload{})
+		verr := d.Validate([]byte(tt.in), &payload{})
+		for _, err := range []error{err, verr} {
+			var ce *CanonicalError
+			if !errors.As(err, &ce) || ce.Offset() != tt.wantOffset {
+				t.Errorf("%s: expected *CanonicalError at offset %d, got %v", tt.in, tt.wantOffset, err)
+				continue
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("%s: Error() = %q, want %q", tt.in, err.Error(), tt.wantMsg)
+			}
+			if ErrorCode(err) != CodeNotCanonical {
+				t.Errorf("%s: ErrorCode() = %q, want %q", tt.in, ErrorCode(err), CodeNotCanonical)
+			}
+		}
+	}
+
+	var dke *DuplicateKeyError
+	if err := d.Unmarshal([]byte(`{"amount":1,"amount":2}`), &payload{}); !errors.As(err, &dke) {
+		t.Errorf("Expected *DuplicateKeyError, got %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Violation describes one strict rule broken by a document.
+type Violation struct {
+	Path       string // location of the offending key or value
+	Field      string // offending key, for unknown and duplicate keys
+	Suggestion string // closest known field, when SuggestClosest is enabled
+	Err        error  // the error strict decoding would have returned
+}
+
+func (v Violation) String() string {
+	return v.Err.Error()
+}
+
+// Check decodes data into v leniently, like encoding/json, and returns every
+// violation that strict decoding would have rejected. Mis-cased keys are
+// decoded into their case-insensitive match, unknown keys are skipped, and
+// duplicate keys keep the last value. The returned error is non-nil only for
+// failures encoding/json would also report, such as malformed input.
+//
+// Check lets teams migrating to strictjson measure breakage before enforcing
+// it.
+func (d *Decoder) Check(data []byte, v any) (_ []Violation, err error) {
+	defer func() { err = d.formatError(err) }()
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, newNonPointerError()
+	}
+
+	var violations []Violation
+	s, err := d.newState(data)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release()
+	defer s.observe(rv.Elem().Type(), &err)
+	s.violations = &violations
+	err = s.document(func() error {
+		return s.value(rv.Elem(), s.d.planFor(rv.Elem().Type()))
+	})
+	return violations, err
+}
+
+// violation reports err as a strict-rule violation. In Check mode it is
+// recorded and decoding continues; otherwise it aborts the decode.
+func (s *decodeState) violation(err error) error {
+	if s.violations == nil {
+		return err
+	}
+	*s.violations = append(*s.violations, newViolation(s.d.formatError(err)))
+	return nil
+}
+
+func newViolation(err error) Violation {
+	v := Violation{Err: err}
+
+	var ufe *UnknownFieldError
+	var dke *DuplicateKeyError
+	var eke *EscapedKeyError
+	var koe *KeyOrderError
+	var nve *NullValueError
+	var uve *UnquotedValueError
+	var ale *ArrayLengthError
+	var ene *EnumError
+	var ce *ConstraintError
+	var sce *SchemaError
+	switch {
+	case errors.As(err, &ufe):
+		v.Path, v.Field, v.Suggestion = ufe.Path(), ufe.Field(), ufe.Suggestion()
+	case errors.As(err, &dke):
+		v.Path, v.Field = dke.Path(), dke.Key()
+	case errors.As(err, &eke):
+		v.Path, v.Field = eke.Path(), eke.Key()
+	case errors.As(err, &koe):
+		v.Path, v.Field = koe.Path(), koe.Key()
+	case errors.As(err, &nve):
+		v.Path = nve.Path()
+	case errors.As(err, &uve):
+		v.Path = uve.Path()
+	case errors.As(err, &ale):
+		v.Path = ale.Path()
+	case errors.As(err, &ene):
+		v.Path, v.Suggestion = ene.Path(), ene.Suggestion()
+	case errors.As(err, &ce):
+		v.Path = ce.Path()
+	case errors.As(err, &sce):
+		v.Path = sce.Path()
+	}
+	return v
+}
@@ -0,0 +1,61 @@
+package strictjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckCollectsViolations(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Person struct {
+		Name    string    `json:"name"`
+		Age     int       `json:"age"`
+		Address []Address `json:"address"`
+	}
+
+	d := NewDecoder(WithSuggestClosest(true), WithDisallowDuplicateKeys(true), WithDisallowNullForNonPointer(true))
+
+	var p Person
+	violations, err := d.Check([]byte(`{
+		"Name": "John",
+		"age": null,
+		"address": [{"CITY": "NYC"}],
+		"bogus": {"x": 1, "x": 2}
+	}`), &p)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Path+"|"+v.Suggestion)
+	}
+	want := []string{"Name|name", "age|", "address[0].CITY|city", "bogus|", "bogus.x|"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Violations = %v, want %v", got, want)
+	}
+
+	// Lenient decoding populates mis-cased fields like encoding/json.
+	if p.Name != "John" || p.Address[0].City != "NYC" {
+		t.Errorf("Unexpected lenient result: %+v", p)
+	}
+}
+
+func TestCheckReportsSyntaxErrors(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	var p Person
+	_, err := NewDecoder().Check([]byte(`{"name": }`), &p)
+	if err == nil {
+		t.Error("Expected syntax error")
+	}
+
+	violations, err := NewDecoder().Check([]byte(`{"name": "ok"}`), &p)
+	if err != nil || len(violations) != 0 {
+		t.Errorf("Expected no violations, got %v (%v)", violations, err)
+	}
+}
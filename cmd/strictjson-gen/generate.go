@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const annotation = "//strictjson:generate"
+
+// generator emits UnmarshalStrictJSON methods for the struct types of one
+// package.
+type generator struct {
+	pkg        string
+	importPath string   // "" when generating inside the strictjson package
+	only       []string // explicit type names; nil selects annotated types
+}
+
+// structType is a struct type selected for generation.
+type structType struct {
+	name   string
+	fields []field
+}
+
+// field is one decodable struct field.
+type field struct {
+	goName   string
+	jsonName string
+	method   string // Stream method for the field, "Decode" when none fits
+}
+
+// streamMethods maps field types to the Stream helpers that decode them
+// without reflection.
+var streamMethods = map[string]string{
+	"string":  "String",
+	"bool":    "Bool",
+	"int":     "Int",
+	"int64":   "Int64",
+	"float64": "Float64",
+}
+
+func (g *generator) generate(files []*ast.File) ([]byte, error) {
+	types, err := g.collect(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		return nil, fmt.Errorf("no types to generate in package %s", g.pkg)
+	}
+
+	qual := ""
+	if g.importPath != "" {
+		qual = g.importPath[strings.LastIndex(g.importPath, "/")+1:] + "."
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by strictjson-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", g.pkg)
+	if g.importPath != "" {
+		fmt.Fprintf(&b, "import %q\n", g.importPath)
+	}
+	for _, t := range types {
+		names := make([]string, len(t.fields))
+		for i, f := range t.fields {
+			names[i] = strconv.Quote(f.jsonName)
+		}
+		fieldSet := "strictjsonFields" + strings.ToUpper(t.name[:1]) + t.name[1:]
+		fmt.Fprintf(&b, "\nvar %s = %sNewFieldSet(%s)\n", fieldSet, qual, strings.Join(names, ", "))
+		fmt.Fprintf(&b, "\n// UnmarshalStrictJSON implements %sStrictUnmarshaler.\n", qual)
+		fmt.Fprintf(&b, "func (v *%s) UnmarshalStrictJSON(dec *%sStream) error {\n", t.name, qual)
+		fmt.Fprintf(&b, "return dec.Object(%s, func(key string) error {\n", fieldSet)
+		fmt.Fprintf(&b, "switch key {\n")
+		for _, f := range t.fields {
+			fmt.Fprintf(&b, "case %q:\nreturn dec.%s(&v.%s)\n", f.jsonName, f.method, f.goName)
+		}
+		fmt.Fprintf(&b, "}\nreturn dec.Skip()\n})\n}\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+// collect returns the selected struct types in source order.
+func (g *generator) collect(files []*ast.File) ([]structType, error) {
+	sort.Slice(files, func(i, j int) bool { return files[i].Pos() < files[j].Pos() })
+
+	var types []structType
+	found := make(map[string]bool)
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts, ok := spec.(*ast.TypeSpec)
+				if !ok {
+					continue
+				}
+				if !g.selected(ts, gd) {
+					continue
+				}
+				found[ts.Name.Name] = true
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || ts.TypeParams != nil {
+					return nil, fmt.Errorf("%s: only non-generic struct types are supported", ts.Name.Name)
+				}
+				fields, err := structFields(ts.Name.Name, st)
+				if err != nil {
+					return nil, err
+				}
+				types = append(types, structType{name: ts.Name.Name, fields: fields})
+			}
+		}
+	}
+	for _, name := range g.only {
+		if !found[name] {
+			return nil, fmt.Errorf("type %s not found in package %s", name, g.pkg)
+		}
+	}
+	return types, nil
+}
+
+func (g *generator) selected(ts *ast.TypeSpec, gd *ast.GenDecl) bool {
+	if g.only != nil {
+		for _, name := range g.only {
+			if name == ts.Name.Name {
+				return true
+			}
+		}
+		return false
+	}
+	return hasAnnotation(ts.Doc) || len(gd.Specs) == 1 && hasAnnotation(gd.Doc)
+}
+
+func hasAnnotation(doc *ast.CommentGroup) bool {
+	if doc == nil {
+		return false
+	}
+	for _, c := range doc.List {
+		if strings.TrimSpace(c.Text) == annotation {
+			return true
+		}
+	}
+	return false
+}
+
+// structFields lists the decodable fields of a struct. Features that the
+// generated code cannot reproduce are rejected so that such types are left
+// to the reflective decoder.
+func structFields(typeName string, st *ast.StructType) ([]field, error) {
+	var fields []field
+	seen := make(map[string]bool)
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded fields are not supported", typeName)
+		}
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", typeName, err)
+			}
+			tag = reflect.StructTag(s)
+		}
+		if _, ok := tag.Lookup("strict"); ok {
+			return nil, fmt.Errorf("%s: strict tags are not supported", typeName)
+		}
+		jsonTag := tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+		for _, opt := range strings.Split(opts, ",") {
+			if opt == "string" {
+				return nil, fmt.Errorf("%s: the string option is not supported", typeName)
+			}
+		}
+
+		method := "Decode"
+		if ident, ok := f.Type.(*ast.Ident); ok && streamMethods[ident.Name] != "" {
+			method = streamMethods[ident.Name]
+		}
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			jsonName := name
+			if jsonName == "" {
+				jsonName = n.Name
+			}
+			if seen[jsonName] {
+				return nil, fmt.Errorf("%s: duplicate JSON name %q", typeName, jsonName)
+			}
+			seen[jsonName] = true
+			fields = append(fields, field{goName: n.Name, jsonName: jsonName, method: method})
+		}
+	}
+	return fields, nil
+}
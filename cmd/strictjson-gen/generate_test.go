@@ -0,0 +1,116 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func parseSource(t *testing.T, src string) []*ast.File {
+	t.Helper()
+	f, err := parser.ParseFile(token.NewFileSet(), "src.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*ast.File{f}
+}
+
+func TestGenerateAnnotated(t *testing.T) {
+	src := `package shop
+
+//strictjson:generate
+type Order struct {
+	ID       int64             ` + "`json:\"id\"`" + `
+	Customer string            ` + "`json:\"customer,omitempty\"`" + `
+	Paid     bool
+	Total    float64           ` + "`json:\"total\"`" + `
+	Items    []string          ` + "`json:\"items\"`" + `
+	Internal string            ` + "`json:\"-\"`" + `
+	note     string
+}
+
+type Ignored struct {
+	Name string
+}
+`
+	g := &generator{pkg: "shop", importPath: "strictjson"}
+	out, err := g.generate(parseSource(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `// Code generated by strictjson-gen. DO NOT EDIT.
+
+package shop
+
+import "strictjson"
+
+var strictjsonFieldsOrder = strictjson.NewFieldSet("id", "customer", "Paid", "total", "items")
+
+// UnmarshalStrictJSON implements strictjson.StrictUnmarshaler.
+func (v *Order) UnmarshalStrictJSON(dec *strictjson.Stream) error {
+	return dec.Object(strictjsonFieldsOrder, func(key string) error {
+		switch key {
+		case "id":
+			return dec.Int64(&v.ID)
+		case "customer":
+			return dec.String(&v.Customer)
+		case "Paid":
+			return dec.Bool(&v.Paid)
+		case "total":
+			return dec.Float64(&v.Total)
+		case "items":
+			return dec.Decode(&v.Items)
+		}
+		return dec.Skip()
+	})
+}
+`
+	if string(out) != want {
+		t.Errorf("generated code mismatch:\n%s", out)
+	}
+}
+
+func TestGenerateTypeFlag(t *testing.T) {
+	src := `package shop
+
+type A struct{ Name string }
+type B struct{ Name string }
+`
+	g := &generator{pkg: "shop", importPath: "strictjson", only: []string{"B"}}
+	out, err := g.generate(parseSource(t, src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(out), "*A)") || !strings.Contains(string(out), "*B)") {
+		t.Errorf("expected only B to be generated:\n%s", out)
+	}
+
+	g.only = []string{"C"}
+	if _, err := g.generate(parseSource(t, src)); err == nil {
+		t.Error("expected error for missing type")
+	}
+}
+
+func TestGenerateUnsupported(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"embedded", "type T struct{ Base }\ntype Base struct{}"},
+		{"strict tag", "type T struct{ Name string `strict:\"nocase\"` }"},
+		{"string option", "type T struct{ N int `json:\",string\"` }"},
+		{"duplicate name", "type T struct{ A string `json:\"x\"`; B string `json:\"x\"` }"},
+		{"not a struct", "type T []int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := &generator{pkg: "p", importPath: "strictjson", only: []string{"T"}}
+			if _, err := g.generate(parseSource(t, "package p\n"+tt.src)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
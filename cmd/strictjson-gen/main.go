@@ -0,0 +1,81 @@
+// Command strictjson-gen generates reflection-free UnmarshalStrictJSON
+// methods for struct types, for use with go:generate:
+//
+//	//go:generate strictjson-gen
+//
+// Types are selected with a //strictjson:generate line in their doc comment
+// or listed with -type. The output is written to <package>_strictjson.go in
+// the package directory unless -output is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("strictjson-gen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of type names; default is annotated types")
+	output := flag.String("output", "", "output file name; default <package>_strictjson.go")
+	importPath := flag.String("import", "strictjson", "import path of the strictjson package")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	pkg, files, err := parseDir(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g := &generator{pkg: pkg, importPath: *importPath}
+	if *typeNames != "" {
+		g.only = strings.Split(*typeNames, ",")
+	}
+	src, err := g.generate(files)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, pkg+"_strictjson.go")
+	}
+	if err := os.WriteFile(name, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parseDir parses the non-test, non-generated Go files of the package in dir.
+func parseDir(dir string) (string, []*ast.File, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		name := fi.Name()
+		return !strings.HasSuffix(name, "_test.go") && !strings.HasSuffix(name, "_strictjson.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(pkgs) != 1 {
+		return "", nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	for name, pkg := range pkgs {
+		var files []*ast.File
+		for _, f := range pkg.Files {
+			files = append(files, f)
+		}
+		return name, files, nil
+	}
+	panic("unreachable")
+}
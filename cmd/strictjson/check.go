@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// check runs the check command with the arguments that follow it and
+// returns the exit status.
+func check(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "usage: strictjson check -pkg <package> -type <type> [flags] [file ...]")
+		fmt.Fprintln(stderr, "\nWith no file, or a file named -, check reads standard input.")
+		fs.PrintDefaults()
+	}
+	pkg := fs.String("pkg", ".", "package that declares the type, as passed to go list")
+	typeName := fs.String("type", "", "name of the type to decode into")
+	suggest := fs.Bool("suggest", true, "suggest the closest field name for unknown keys")
+	importPath := fs.String("import", "strictjson", "import path of the strictjson package")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if !token.IsIdentifier(*typeName) || !token.IsExported(*typeName) {
+		fmt.Fprintf(stderr, "strictjson: -type must name an exported type, got %q\n", *typeName)
+		return 2
+	}
+
+	target, err := resolvePackage(*pkg)
+	if err != nil {
+		fmt.Fprintf(stderr, "strictjson: %v\n", err)
+		return 2
+	}
+	src, err := checkProgram(checkConfig{
+		ImportPath: target,
+		Type:       *typeName,
+		StrictJSON: *importPath,
+		Suggest:    *suggest,
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "strictjson: %v\n", err)
+		return 2
+	}
+
+	// The program is built inside the current module, so that it resolves
+	// the package and strictjson as the module does.
+	dir, err := os.MkdirTemp(".", "strictjson-check-")
+	if err != nil {
+		fmt.Fprintf(stderr, "strictjson: %v\n", err)
+		return 2
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		fmt.Fprintf(stderr, "strictjson: %v\n", err)
+		return 2
+	}
+	bin := filepath.Join(dir, "check")
+	build := exec.Command("go", "build", "-o", bin, "./"+filepath.ToSlash(dir))
+	build.Stdout, build.Stderr = stderr, stderr
+	if err := build.Run(); err != nil {
+		fmt.Fprintf(stderr, "strictjson: cannot build a checker for %s.%s: %v\n", target, *typeName, err)
+		return 2
+	}
+
+	run := exec.Command(bin, fs.Args()...)
+	run.Stdin, run.Stdout, run.Stderr = stdin, stdout, stderr
+	if err := run.Run(); err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) {
+			return exit.ExitCode()
+		}
+		fmt.Fprintf(stderr, "strictjson: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// resolvePackage returns the import path of the package pattern names.
+func resolvePackage(pattern string) (string, error) {
+	var out, errOut bytes.Buffer
+	cmd := exec.Command("go", "list", "-f", "{{.ImportPath}} {{.Name}}", pattern)
+	cmd.Stdout, cmd.Stderr = &out, &errOut
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("go list %s: %s", pattern, strings.TrimSpace(errOut.String()))
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		return "", fmt.Errorf("-pkg %s matches %d packages, want one", pattern, len(lines))
+	}
+	path, name, _ := strings.Cut(lines[0], " ")
+	if name == "main" {
+		return "", fmt.Errorf("-pkg %s is a main package, which cannot be imported", pattern)
+	}
+	return path, nil
+}
+
+type checkConfig struct {
+	ImportPath string // package that declares Type
+	Type       string
+	StrictJSON string // import path of strictjson
+	Suggest    bool
+}
+
+// checkProgram returns the source of the program that checks documents
+// against the type of cfg.
+func checkProgram(cfg checkConfig) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := checkTemplate.Execute(&buf, cfg); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+var checkTemplate = template.Must(template.New("check").Parse(`// Code generated by strictjson check. DO NOT EDIT.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	strictjson {{printf "%q" .StrictJSON}}
+	target {{printf "%q" .ImportPath}}
+)
+
+func main() {
+	d := strictjson.NewDecoder({{if .Suggest}}strictjson.WithSuggestClosest(true){{end}})
+	files := os.Args[1:]
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	status := 0
+	for _, name := range files {
+		var data []byte
+		var err error
+		if name == "-" {
+			name = "<stdin>"
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "strictjson: %v\n", err)
+			os.Exit(2)
+		}
+		violations, err := d.Check(data, new(target.{{.Type}}))
+		if err != nil {
+			fmt.Printf("%s: %s\n", name, message(err))
+			status = 1
+			continue
+		}
+		for _, v := range violations {
+			if v.Path == "" {
+				fmt.Printf("%s: %s\n", name, message(v.Err))
+			} else {
+				fmt.Printf("%s: %s: %s\n", name, v.Path, message(v.Err))
+			}
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+func message(err error) string {
+	return strings.TrimPrefix(err.Error(), "strictjson: ")
+}
+`))
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestCheckProgram(t *testing.T) {
+	src, err := checkProgram(checkConfig{
+		ImportPath: "example.com/shop/api",
+		Type:       "Order",
+		StrictJSON: "strictjson",
+		Suggest:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`target "example.com/shop/api"`,
+		`strictjson.NewDecoder(strictjson.WithSuggestClosest(true))`,
+		`d.Check(data, new(target.Order))`,
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("program lacks %s:\n%s", want, src)
+		}
+	}
+}
+
+func runCheck(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a program with the go command")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go command not found")
+	}
+	var stdout, stderr bytes.Buffer
+	status := check(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestCheck(t *testing.T) {
+	status, stdout, stderr := runCheck(t, "", "-pkg", "./testdata/api", "-type", "CreateUserRequest", "testdata/valid.json", "testdata/invalid.json")
+	want := `testdata/invalid.json: Email: unknown field "Email" (did you mean "email"?)
+testdata/invalid.json: address.citty: unknown field "citty" (did you mean "city"?)
+`
+	if status != 1 || stdout != want {
+		t.Errorf("check() = %d, output\n%s\nwant 1, output\n%s\nstderr: %s", status, stdout, want, stderr)
+	}
+
+	status, stdout, stderr = runCheck(t, `{"name": 1}`, "-pkg", "./testdata/api", "-type", "CreateUserRequest", "-suggest=false")
+	if want := "<stdin>: cannot decode JSON number into Go string at \"name\"\n"; status != 1 || stdout != want {
+		t.Errorf("check() of stdin = %d, %q, want 1, %q (stderr: %s)", status, stdout, want, stderr)
+	}
+
+	status, stdout, stderr = runCheck(t, "", "-pkg", "./testdata/api", "-type", "CreateUserRequest", "testdata/valid.json")
+	if status != 0 || stdout != "" {
+		t.Errorf("check() of valid document = %d, %q (stderr: %s)", status, stdout, stderr)
+	}
+}
+
+func TestCheckUsage(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"-pkg", "./testdata/api"}, `-type must name an exported type, got ""`},
+		{[]string{"-type", "order"}, `-type must name an exported type, got "order"`},
+		{[]string{"-pkg", ".", "-type", "Order"}, `-pkg . is a main package, which cannot be imported`},
+		{[]string{"-pkg", "./testdata/api", "-type", "Order"}, `cannot build a checker for strictjson/cmd/strictjson/testdata/api.Order`},
+	}
+	for _, tt := range tests {
+		status, _, stderr := runCheck(t, "", tt.args...)
+		if status != 2 || !strings.Contains(stderr, tt.want) {
+			t.Errorf("check(%q) = %d, stderr %q, want 2 and %s", tt.args, status, stderr, tt.want)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+// Command strictjson validates JSON documents against Go types without a Go
+// program of one's own:
+//
+//	strictjson check -pkg ./api -type CreateUserRequest payload.json
+//
+// check decodes each file, or standard input, into a new value of the type
+// and prints every violation strict decoding reports, with its path and a
+// did-you-mean suggestion for unknown keys. It exits with status 1 if any
+// document has violations and 2 on usage or build errors.
+//
+// Since Go cannot load types at run time, check writes a small program that
+// imports the package, builds it and runs it, so it must be invoked inside
+// the module that contains the package.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const usage = `usage: strictjson check -pkg <package> -type <type> [flags] [file ...]
+
+Commands:
+  check    validate JSON files, or standard input, against a Go type
+
+Run "strictjson check -h" for the flags of check.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "check":
+		os.Exit(check(os.Args[2:], os.Stdin, os.Stdout, os.Stderr))
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(os.Stdout, usage)
+	default:
+		fmt.Fprintf(os.Stderr, "strictjson: unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,12 @@
+// Package api declares the request types the check command tests use.
+package api
+
+type CreateUserRequest struct {
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Address Address `json:"address"`
+}
+
+type Address struct {
+	City string `json:"city"`
+}
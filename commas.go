@@ -0,0 +1,58 @@
+package strictjson
+
+// WithAllowTrailingCommas(true) accepts a comma after the last member of an
+// object or the last element of an array, as in {"a": 1,} and [1, 2,], for
+// hand-edited config files. Only a single comma after a member is accepted:
+// [,] and [1,,] are still rejected. Such commas are blanked out with spaces
+// before decoding, after comments if WithAllowComments is also set, so keys
+// are still checked strictly and error offsets point into the original
+// input.
+//
+// Trailing commas are accepted by every entry point that takes the whole
+// input as bytes, but not by ForEachReader.
+func WithAllowTrailingCommas(allow bool) DecoderOption {
+	return func(d *Decoder) {
+		d.AllowTrailingCommas = allow
+	}
+}
+
+// stripTrailingCommas returns data with each comma outside of strings that
+// follows a member and precedes the end of its object or array replaced by
+// a space, or data itself if it has none.
+func stripTrailingCommas(data []byte) []byte {
+	var out []byte
+	inString := false
+	// prev is the last byte outside of strings and whitespace.
+	var prev byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+				prev = c
+			}
+			continue
+		case isSpace(c):
+			continue
+		case c == '"':
+			inString = true
+		case c == ',' && prev != ',' && prev != '[' && prev != '{' && prev != 0:
+			next := i + 1
+			for next < len(data) && isSpace(data[next]) {
+				next++
+			}
+			if next < len(data) && (data[next] == '}' || data[next] == ']') {
+				if out == nil {
+					out = make([]byte, len(data))
+					copy(out, data)
+				}
+				out[i] = ' '
+			}
+		}
+		prev = c
+	}
+	return orData(out, data)
+}
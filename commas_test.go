@@ -0,0 +1,69 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAllowTrailingCommas(t *testing.T) {
+	type item struct {
+		Name string `json:"name"`
+	}
+	type config struct {
+		Items []item         `json:"items"`
+		Ports []int          `json:"ports"`
+		Tags  map[string]int `json:"tags"`
+		Note  string         `json:"note"`
+	}
+	data := `{
+	"items": [{"name": "a",}, {"name": "b"},],
+	"ports": [80, 443,],
+	"tags": {"x": 1,},
+	"note": "keeps ,] and ,} in strings",
+}`
+	if err := Unmarshal([]byte(data), &config{}); err == nil {
+		t.Error("Expected trailing commas to be rejected by default")
+	}
+
+	d := NewDecoder(WithAllowTrailingCommas(true))
+	var got config
+	if err := d.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := config{
+		Items: []item{{"a"}, {"b"}},
+		Ports: []int{80, 443},
+		Tags:  map[string]int{"x": 1},
+		Note:  "keeps ,] and ,} in strings",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+	if err := d.Validate([]byte(data), &config{}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	// Combined with comments.
+	commented := "{\"note\": \"x\", // last\n}"
+	if err := NewDecoder(WithAllowTrailingCommas(true), WithAllowComments(true)).Unmarshal([]byte(commented), &config{}); err != nil {
+		t.Errorf("Unmarshal() with comments unexpected error: %v", err)
+	}
+
+	// Keys are still checked.
+	var ufe *UnknownFieldError
+	if err := d.Unmarshal([]byte(`{"Note": "x",}`), &config{}); !errors.As(err, &ufe) {
+		t.Errorf("Expected *UnknownFieldError, got %v", err)
+	}
+
+	for _, bad := range []string{`{"ports": [,]}`, `{"ports": [1,,]}`, `{,}`, `{"note": "x"},`} {
+		var se *SyntaxError
+		err := d.Unmarshal([]byte(bad), &config{})
+		if !errors.As(err, &se) {
+			t.Errorf("%s: expected *SyntaxError, got %v", bad, err)
+		} else if want := int64(strings.LastIndex(bad, ",")); se.Offset != want && se.Offset != int64(len(bad)) {
+			t.Errorf("%s: offset %d, want %d", bad, se.Offset, want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package strictjson
+
+// WithAllowComments(true) accepts "//" line comments and "/* */" block
+// comments outside of strings, as commonly found in hand-edited config
+// files (JSONC), while keys are still checked strictly. Comments are blanked
+// out with spaces before decoding, keeping line breaks, so error offsets
+// still point into the original input. An unterminated block comment is a
+// syntax error. A json.RawMessage holding a value that contains comments
+// receives spaces in their place.
+//
+// Comments are accepted by every entry point that takes the whole input as
+// bytes, including LoadFile and the HTTP helpers, but not by ForEachReader.
+func WithAllowComments(allow bool) DecoderOption {
+	return func(d *Decoder) {
+		d.AllowComments = allow
+	}
+}
+
+// stripComments returns data with its comments outside of strings replaced
+// by spaces, keeping line breaks, or data itself if it has none. An
+// unterminated block comment is left in place for the scanner to reject.
+func stripComments(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			end := i
+			for end < len(data) && data[end] != '\n' {
+				end++
+			}
+			out = blank(out, data, i, end)
+			i = end
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := i + 2
+			for end+1 < len(data) && !(data[end] == '*' && data[end+1] == '/') {
+				end++
+			}
+			if end+1 >= len(data) {
+				return orData(out, data)
+			}
+			end += 2
+			out = blank(out, data, i, end)
+			i = end - 1
+		}
+	}
+	return orData(out, data)
+}
+
+// blank replaces data[start:end] in out, a copy of data made on first use,
+// with spaces, keeping line breaks.
+func blank(out, data []byte, start, end int) []byte {
+	if out == nil {
+		out = make([]byte, len(data))
+		copy(out, data)
+	}
+	for i := start; i < end; i++ {
+		if c := out[i]; c != '\n' && c != '\r' {
+			out[i] = ' '
+		}
+	}
+	return out
+}
+
+func orData(out, data []byte) []byte {
+	if out == nil {
+		return data
+	}
+	return out
+}
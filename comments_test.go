@@ -0,0 +1,73 @@
+package strictjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAllowComments(t *testing.T) {
+	type service struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+		Port int    `json:"port"`
+	}
+	data := `/* service definition */
+{
+	// the public name
+	"name": "api /* not a comment */",
+	"url": "http://example.com//path", /* trailing
+	   block */ "port": /* inline */ 8080
+} // done`
+
+	if err := Unmarshal([]byte(data), &service{}); err == nil {
+		t.Error("Expected comments to be rejected by default")
+	}
+
+	d := NewDecoder(WithAllowComments(true))
+	var got service
+	if err := d.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := service{Name: "api /* not a comment */", URL: "http://example.com//path", Port: 8080}
+	if got != want {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+	if err := d.Validate([]byte(data), &service{}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	// Keys are still checked, at their original offsets.
+	bad := "/* x */ {\"nmae\": 1}"
+	var ufe *UnknownFieldError
+	if err := d.Unmarshal([]byte(bad), &service{}); !errors.As(err, &ufe) || ufe.Field() != "nmae" {
+		t.Errorf("Expected *UnknownFieldError, got %v", err)
+	}
+	bad = "{ /* a\nb */ \"port\": ,}"
+	var se *SyntaxError
+	if err := d.Unmarshal([]byte(bad), &service{}); !errors.As(err, &se) || se.Offset != int64(strings.Index(bad, ",")) {
+		t.Errorf("Expected *SyntaxError at the comma, got %v", err)
+	}
+
+	// An unterminated block comment is a syntax error.
+	if err := d.Unmarshal([]byte(`{"port": 1} /* open`), &service{}); !errors.As(err, &se) {
+		t.Errorf("Expected *SyntaxError, got %v", err)
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{"a": 1}`, `{"a": 1}`},
+		{"// x\n1", "    \n1"},
+		{"1 /* a\r\nb */", "1     \r\n    "},
+		{`"\" // "`, `"\" // "`},
+		{"/* open", "/* open"},
+	}
+	for _, tt := range tests {
+		if got := string(stripComments([]byte(tt.in))); got != tt.want {
+			t.Errorf("stripComments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
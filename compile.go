@@ -0,0 +1,67 @@
+package strictjson
+
+import "reflect"
+
+// TypeDecoder decodes values of a single type T with a decode plan compiled
+// up front. Hot paths that decode the same type repeatedly skip all per-call
+// type analysis. A TypeDecoder is safe for concurrent use.
+type TypeDecoder[T any] struct {
+	d    *Decoder
+	plan *typePlan
+}
+
+// CompileFor walks the type graph of T once, building the flattened decode
+// plan (field tables, child plans, unmarshaler flags) for T and every type
+// reachable from it, and returns a decoder bound to that plan.
+func CompileFor[T any](opts ...DecoderOption) *TypeDecoder[T] {
+	d := NewDecoder(opts...)
+	return &TypeDecoder[T]{
+		d:    d,
+		plan: d.planFor(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+}
+
+// Unmarshal decodes data into the value pointed to by v.
+func (td *TypeDecoder[T]) Unmarshal(data []byte, v *T) (err error) {
+	defer func() { err = td.d.formatError(err) }()
+	if v == nil {
+		return newNonPointerError()
+	}
+	s, err := td.d.newState(data)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	defer s.observe(td.plan.typ, &err)
+	dst := reflect.ValueOf(v).Elem()
+	target := td.d.target(dst)
+	err = s.document(func() error {
+		return s.value(target, td.plan)
+	})
+	if err != nil {
+		return err
+	}
+	td.d.commit(dst, target)
+	return nil
+}
+
+// Decode decodes data into a new value of type T and returns it.
+func (td *TypeDecoder[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := td.Unmarshal(data, &v)
+	return v, err
+}
+
+// Validate checks data against T without decoding it. See Validate.
+func (td *TypeDecoder[T]) Validate(data []byte) (err error) {
+	defer func() { err = td.d.formatError(err) }()
+	s, err := td.d.newState(data)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	defer s.observe(td.plan.typ, &err)
+	return s.document(func() error {
+		return s.validateValue(td.plan)
+	})
+}
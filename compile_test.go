@@ -0,0 +1,81 @@
+package strictjson
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestCompileFor(t *testing.T) {
+	type Node struct {
+		Name     string           `json:"name"`
+		Children []Node           `json:"children"`
+		Index    map[string]*Node `json:"index"`
+	}
+
+	td := CompileFor[Node](WithSuggestClosest(true))
+
+	n, err := td.Decode([]byte(`{"name": "root", "children": [{"name": "a"}], "index": {"b": {"name": "b"}}}`))
+	if err != nil {
+		t.Fatalf("Decode() unexpected error: %v", err)
+	}
+	if n.Children[0].Name != "a" || n.Index["b"].Name != "b" {
+		t.Errorf("Unexpected result: %+v", n)
+	}
+
+	_, err = td.Decode([]byte(`{"children": [{"children": [{"Name": "x"}]}]}`))
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "children[0].children[0].Name" || ufe.Suggestion() != "name" {
+		t.Errorf("Expected unknown field with suggestion, got %v", err)
+	}
+
+	if err := td.Validate([]byte(`{"index": {"k": {"NAME": "x"}}}`)); err == nil {
+		t.Error("Validate() expected error")
+	}
+	if err := td.Unmarshal([]byte(`{}`), nil); err == nil {
+		t.Error("Unmarshal(nil) expected error")
+	}
+}
+
+func TestCompileForConcurrent(t *testing.T) {
+	type Item struct {
+		ID int `json:"id"`
+	}
+
+	td := CompileFor[[]Item]()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				items, err := td.Decode([]byte(`[{"id": 1}, {"id": 2}]`))
+				if err != nil || len(items) != 2 {
+					t.Errorf("Decode() = %v, %v", items, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkCompiledDecode(b *testing.B) {
+	type Item struct {
+		Name  string `json:"name"`
+		Price int    `json:"price"`
+	}
+	type Order struct {
+		ID    string `json:"id"`
+		Items []Item `json:"items"`
+	}
+
+	data := []byte(`{"id": "o1", "items": [{"name": "A", "price": 1}, {"name": "B", "price": 2}]}`)
+	td := CompileFor[Order]()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var o Order
+		_ = td.Unmarshal(data, &o)
+	}
+}
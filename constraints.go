@@ -0,0 +1,254 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithConstraints(true) enforces the range and length constraints of strict
+// tags: min=n and max=n on numeric fields, and maxlen=n on string, slice and
+// map fields, as in `strict:"min=0,max=100"`. A value out of range is
+// reported with a *ConstraintError naming its location. The length of a
+// string is counted in characters, not bytes. Without the option these tag
+// options are ignored, so decoders that do not need them pay nothing for
+// them; nonzero and nonempty are enforced either way.
+func WithConstraints(enabled bool) DecoderOption {
+	return func(d *Decoder) {
+		d.Constraints = enabled
+	}
+}
+
+// constraint is a check that a strict tag option places on the value of a
+// field, such as nonzero.
+type constraint struct {
+	option string
+	// optional constraints are enforced only with WithConstraints.
+	optional bool
+	// check returns why v, the decoded value of the field, breaks the
+	// constraint, or "" if it does not.
+	check func(v reflect.Value) string
+}
+
+// isConstraint reports whether opt is a strict tag option that constrains
+// the value of its field.
+func isConstraint(opt string) bool {
+	if opt == "nonzero" || opt == "nonempty" {
+		return true
+	}
+	name, _, ok := strings.Cut(opt, "=")
+	return ok && (name == "min" || name == "max" || name == "maxlen")
+}
+
+// buildConstraints returns the constraints named by opts for the field
+// named name of type t, or an error if one does not apply to t.
+func buildConstraints(name string, t reflect.Type, opts []string) ([]constraint, error) {
+	var cs []constraint
+	for _, opt := range opts {
+		switch opt {
+		case "nonzero":
+			cs = append(cs, constraint{option: opt, check: checkNonzero})
+		case "nonempty":
+			switch indirectType(t).Kind() {
+			case reflect.String, reflect.Slice, reflect.Map:
+			default:
+				return nil, newInvalidTagError(name, `strict:"nonempty" requires a string, slice or map type`)
+			}
+			cs = append(cs, constraint{option: opt, check: checkNonempty})
+		default:
+			c, err := buildBound(name, indirectType(t), opt)
+			if err != nil {
+				return nil, err
+			}
+			cs = append(cs, c)
+		}
+	}
+	return cs, nil
+}
+
+// buildBound returns the constraint of the tag option opt, one of min=n,
+// max=n and maxlen=n, for a field of type t.
+func buildBound(name string, t reflect.Type, opt string) (constraint, error) {
+	kind, arg, _ := strings.Cut(opt, "=")
+	c := constraint{option: opt, optional: true}
+	invalid := func(reason string) (constraint, error) {
+		return c, newInvalidTagError(name, fmt.Sprintf(`strict:"%s" %s`, opt, reason))
+	}
+	if kind == "maxlen" {
+		switch t.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map:
+		default:
+			return invalid("requires a string, slice or map type")
+		}
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return invalid("requires a non-negative integer")
+		}
+		c.check = func(v reflect.Value) string {
+			v, ok := indirectValue(v)
+			if !ok {
+				return ""
+			}
+			length := v.Len()
+			if v.Kind() == reflect.String {
+				length = utf8.RuneCountInString(v.String())
+			}
+			if length > n {
+				return fmt.Sprintf("has length %d, above the maximum of %d", length, n)
+			}
+			return ""
+		}
+		return c, nil
+	}
+
+	var compare func(v reflect.Value) int
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return invalid("requires an integer")
+		}
+		compare = func(v reflect.Value) int { return compareInts(v.Int(), n) }
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(arg, 10, 64)
+		if err != nil {
+			return invalid("requires a non-negative integer")
+		}
+		compare = func(v reflect.Value) int { return compareUints(v.Uint(), n) }
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(arg, 64)
+		if err != nil {
+			return invalid("requires a number")
+		}
+		compare = func(v reflect.Value) int { return compareFloats(v.Float(), n) }
+	default:
+		return invalid("requires a numeric type")
+	}
+	c.check = func(v reflect.Value) string {
+		v, ok := indirectValue(v)
+		if !ok {
+			return ""
+		}
+		switch cmp := compare(v); {
+		case kind == "min" && cmp < 0:
+			return fmt.Sprintf("is %v, below the minimum of %s", v, arg)
+		case kind == "max" && cmp > 0:
+			return fmt.Sprintf("is %v, above the maximum of %s", v, arg)
+		}
+		return ""
+	}
+	return c, nil
+}
+
+func compareInts(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareUints(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// indirectValue follows the pointers of v and reports false if one is nil.
+func indirectValue(v reflect.Value) (reflect.Value, bool) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return v, false
+		}
+		v = v.Elem()
+	}
+	return v, true
+}
+
+func checkNonzero(v reflect.Value) string {
+	v, ok := indirectValue(v)
+	if !ok {
+		return "is null"
+	}
+	if v.IsZero() {
+		return "is zero"
+	}
+	return ""
+}
+
+func checkNonempty(v reflect.Value) string {
+	v, ok := indirectValue(v)
+	if !ok || (v.Kind() != reflect.String && v.IsNil()) {
+		return "is null"
+	}
+	if v.Len() == 0 {
+		return "is empty"
+	}
+	return ""
+}
+
+// checkConstraints checks v, the value just decoded for the field fi,
+// against the constraints of its tag.
+func (s *decodeState) checkConstraints(v reflect.Value, fi *fieldInfo) error {
+	for _, c := range fi.constraints {
+		if c.optional && !s.d.Constraints {
+			continue
+		}
+		if reason := c.check(v); reason != "" {
+			return s.violation(newConstraintError(s.pathString(), v.Type(), c.option, reason))
+		}
+	}
+	return nil
+}
+
+// constrained reports whether the value of fi is checked against
+// constraints.
+func (s *decodeState) constrained(fi *fieldInfo) bool {
+	for _, c := range fi.constraints {
+		if !c.optional || s.d.Constraints {
+			return true
+		}
+	}
+	return false
+}
+
+// validateConstraints decodes the next value into a scratch value of the
+// type of fi and checks it against the constraints of fi, for Validate.
+func (s *decodeState) validateConstraints(fi *fieldInfo) error {
+	v := reflect.New(fi.typ).Elem()
+	var err error
+	if fi.quoted {
+		err = s.quoted(v, fi.plan)
+	} else {
+		err = s.value(v, fi.plan)
+	}
+	if err != nil {
+		return err
+	}
+	return s.checkConstraints(v, fi)
+}
@@ -0,0 +1,254 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type account struct {
+	Name   string            `json:"name" strict:"nonempty"`
+	Limit  int               `json:"limit" strict:"nonzero"`
+	Ratio  *float64          `json:"ratio" strict:"nonzero"`
+	Tags   []string          `json:"tags" strict:"nonempty"`
+	Labels map[string]string `json:"labels" strict:"nonempty"`
+	Code   int               `json:"code,string" strict:"nonzero"`
+	Note   string            `json:"note"`
+}
+
+func TestConstraints(t *testing.T) {
+	var got account
+	data := []byte(`{"name": "ops", "limit": 5, "ratio": 0.5, "tags": ["a"], "labels": {"k": "v"}, "code": "7", "note": ""}`)
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.Name != "ops" || got.Limit != 5 || *got.Ratio != 0.5 || got.Code != 7 {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	// Absent fields are not checked.
+	if err := Unmarshal([]byte(`{"note": "x"}`), &account{}); err != nil {
+		t.Errorf("Unmarshal() of absent fields unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "empty string",
+			data:     `{"name": ""}`,
+			wantPath: "name",
+			wantMsg:  `strictjson: value at "name" is empty (strict:"nonempty")`,
+		},
+		{
+			name:     "zero number",
+			data:     `{"limit": 0}`,
+			wantPath: "limit",
+			wantMsg:  `strictjson: value at "limit" is zero (strict:"nonzero")`,
+		},
+		{
+			name:     "zero behind pointer",
+			data:     `{"ratio": 0}`,
+			wantPath: "ratio",
+			wantMsg:  `strictjson: value at "ratio" is zero (strict:"nonzero")`,
+		},
+		{
+			name:     "null pointer",
+			data:     `{"ratio": null}`,
+			wantPath: "ratio",
+			wantMsg:  `strictjson: value at "ratio" is null (strict:"nonzero")`,
+		},
+		{
+			name:     "empty slice",
+			data:     `{"tags": []}`,
+			wantPath: "tags",
+			wantMsg:  `strictjson: value at "tags" is empty (strict:"nonempty")`,
+		},
+		{
+			name:     "null slice",
+			data:     `{"tags": null}`,
+			wantPath: "tags",
+			wantMsg:  `strictjson: value at "tags" is null (strict:"nonempty")`,
+		},
+		{
+			name:     "empty map",
+			data:     `{"labels": {}}`,
+			wantPath: "labels",
+			wantMsg:  `strictjson: value at "labels" is empty (strict:"nonempty")`,
+		},
+		{
+			name:     "quoted zero",
+			data:     `{"code": "0"}`,
+			wantPath: "code",
+			wantMsg:  `strictjson: value at "code" is zero (strict:"nonzero")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.data), &account{})
+			verr := Validate([]byte(tt.data), &account{})
+			var m map[string]any
+			if jerr := json.Unmarshal([]byte(tt.data), &m); jerr != nil {
+				t.Fatal(jerr)
+			}
+			merr := NewDecoder().DecodeMap(m, &account{})
+			for _, err := range []error{err, verr, merr} {
+				var ce *ConstraintError
+				if !errors.As(err, &ce) || ce.Path() != tt.wantPath {
+					t.Fatalf("Expected *ConstraintError at %q, got %v", tt.wantPath, err)
+				}
+				if err.Error() != tt.wantMsg {
+					t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+				}
+				if ErrorCode(err) != CodeConstraint {
+					t.Errorf("ErrorCode() = %q, want %q", ErrorCode(err), CodeConstraint)
+				}
+			}
+		})
+	}
+
+	// Check reports the values and keeps decoding.
+	var v account
+	violations, err := NewDecoder().Check([]byte(`{"name": "", "limit": 0, "note": "kept"}`), &v)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	var paths []string
+	for _, vi := range violations {
+		paths = append(paths, vi.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"name", "limit"}) || v.Note != "kept" {
+		t.Errorf("Check() = %v, note %q", paths, v.Note)
+	}
+}
+
+func TestNonemptyTagRequiresLength(t *testing.T) {
+	var v struct {
+		N int `json:"n" strict:"nonempty"`
+	}
+	var ite *InvalidTagError
+	if err := Unmarshal([]byte(`{"n": 1}`), &v); !errors.As(err, &ite) {
+		t.Errorf("Expected *InvalidTagError, got %v", err)
+	}
+}
+
+type limits struct {
+	Percent int      `json:"percent" strict:"min=0,max=100"`
+	Port    *uint16  `json:"port" strict:"min=1"`
+	Ratio   float64  `json:"ratio" strict:"max=1.5"`
+	Name    string   `json:"name" strict:"maxlen=3"`
+	Tags    []string `json:"tags" strict:"maxlen=2"`
+}
+
+func TestRangeConstraints(t *testing.T) {
+	d := NewDecoder(WithConstraints(true))
+	var got limits
+	data := []byte(`{"percent": 100, "port": 80, "ratio": 1.5, "name": "héé", "tags": ["a", "b"]}`)
+	if err := d.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.Percent != 100 || *got.Port != 80 || got.Name != "héé" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "above max",
+			data:     `{"percent": 101}`,
+			wantPath: "percent",
+			wantMsg:  `strictjson: value at "percent" is 101, above the maximum of 100 (strict:"max=100")`,
+		},
+		{
+			name:     "below min",
+			data:     `{"percent": -1}`,
+			wantPath: "percent",
+			wantMsg:  `strictjson: value at "percent" is -1, below the minimum of 0 (strict:"min=0")`,
+		},
+		{
+			name:     "unsigned behind pointer",
+			data:     `{"port": 0}`,
+			wantPath: "port",
+			wantMsg:  `strictjson: value at "port" is 0, below the minimum of 1 (strict:"min=1")`,
+		},
+		{
+			name:     "float",
+			data:     `{"ratio": 1.75}`,
+			wantPath: "ratio",
+			wantMsg:  `strictjson: value at "ratio" is 1.75, above the maximum of 1.5 (strict:"max=1.5")`,
+		},
+		{
+			name:     "string length in characters",
+			data:     `{"name": "abcd"}`,
+			wantPath: "name",
+			wantMsg:  `strictjson: value at "name" has length 4, above the maximum of 3 (strict:"maxlen=3")`,
+		},
+		{
+			name:     "slice length",
+			data:     `{"tags": ["a", "b", "c"]}`,
+			wantPath: "tags",
+			wantMsg:  `strictjson: value at "tags" has length 3, above the maximum of 2 (strict:"maxlen=2")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Without the option the constraints are ignored.
+			if err := Unmarshal([]byte(tt.data), &limits{}); err != nil {
+				t.Fatalf("Unmarshal() without WithConstraints unexpected error: %v", err)
+			}
+
+			err := d.Unmarshal([]byte(tt.data), &limits{})
+			verr := d.Validate([]byte(tt.data), &limits{})
+			var m map[string]any
+			if jerr := json.Unmarshal([]byte(tt.data), &m); jerr != nil {
+				t.Fatal(jerr)
+			}
+			merr := d.DecodeMap(m, &limits{})
+			for _, err := range []error{err, verr, merr} {
+				var ce *ConstraintError
+				if !errors.As(err, &ce) || ce.Path() != tt.wantPath {
+					t.Fatalf("Expected *ConstraintError at %q, got %v", tt.wantPath, err)
+				}
+				if err.Error() != tt.wantMsg {
+					t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+				}
+			}
+		})
+	}
+}
+
+func TestRangeConstraintTags(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"min on string", &struct {
+			S string `json:"s" strict:"min=1"`
+		}{}},
+		{"fractional bound on int", &struct {
+			N int `json:"n" strict:"max=1.5"`
+		}{}},
+		{"negative maxlen", &struct {
+			S string `json:"s" strict:"maxlen=-1"`
+		}{}},
+		{"maxlen on number", &struct {
+			N int `json:"n" strict:"maxlen=2"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ite *InvalidTagError
+			if err := Unmarshal([]byte(`{}`), tt.v); !errors.As(err, &ite) {
+				t.Errorf("Expected *InvalidTagError, got %v", err)
+			}
+		})
+	}
+}
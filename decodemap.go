@@ -0,0 +1,398 @@
+package strictjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// DecodeMap decodes an already parsed generic value, such as a message-bus
+// payload or YAML converted to map[string]any, into v with the same field
+// matching and unknown-field rules as Unmarshal. The map is walked directly
+// instead of being re-encoded as JSON; only leaves that cannot be assigned
+// directly, such as values for json.Unmarshaler fields, are round-tripped
+// individually.
+//
+// Keys are visited in sorted order so that the first error reported for a
+// map is deterministic. Values of interface type may share maps and slices
+// with m.
+func (d *Decoder) DecodeMap(m map[string]any, v any) (err error) {
+	defer func() { err = d.formatError(err) }()
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return newNonPointerError()
+	}
+	s, err := d.newState(nil)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	defer s.observe(rv.Elem().Type(), &err)
+	target := d.target(rv.Elem())
+	if err := s.mapValue(target, d.planFor(rv.Elem().Type()), m); err != nil {
+		return err
+	}
+	d.commit(rv.Elem(), target)
+	return nil
+}
+
+// DecodeMap decodes m into v using the default strict decoder.
+func DecodeMap(m map[string]any, v any) error {
+	return NewDecoder().DecodeMap(m, v)
+}
+
+// mapValue mirrors value for a generic source value.
+func (s *decodeState) mapValue(v reflect.Value, p *typePlan, src any) error {
+	if policy, ok := s.scope(p); ok {
+		saved := s.enterPolicy(policy)
+		err := s.mapValue(v, p, src)
+		s.policy = saved
+		return err
+	}
+	if p.kind == planOptional {
+		o := v.Addr().Interface().(optional)
+		o.markPresent(src == nil)
+		if src == nil {
+			return nil
+		}
+		return s.mapValue(reflect.ValueOf(o.valuePtr()).Elem(), p.elem, src)
+	}
+	if src == nil {
+		if s.d.DisallowNullForNonPointer && !p.nullable {
+			return s.violation(newNullValueError(s.pathString(), v.Type()))
+		}
+		return s.mapLeaf(v, src)
+	}
+	if p.indirect {
+		v = allocatePointers(v)
+	}
+	if set := s.enumFor(p); set != nil {
+		if str, ok := src.(string); ok {
+			if err := s.enumValue(set, str); err != nil {
+				return err
+			}
+		}
+	}
+
+	switch p.kind {
+	case planStruct:
+		if m, ok := src.(map[string]any); ok {
+			return s.mapStruct(v, p, m)
+		}
+	case planSlice:
+		if a, ok := src.([]any); ok {
+			return s.mapSlice(v, p.elem, a)
+		}
+	case planMap:
+		if m, ok := src.(map[string]any); ok {
+			return s.mapMap(v, p.elem, m)
+		}
+	case planGenerated:
+		if _, ok := src.(map[string]any); ok {
+			return s.mapGenerated(v, src)
+		}
+	case planFunc:
+		return s.mapDecodeFunc(v, p, src)
+	case planBytes:
+		if str, ok := src.(string); ok {
+			if format := s.base64Format(); format != "" {
+				return s.decodeBase64(v, []byte(strconv.Quote(str)), str, format)
+			}
+		}
+	case planTime:
+		if layout := s.timeLayout(); layout != "" {
+			return s.mapTime(v, src, layout)
+		}
+	case planInterface:
+		if m, ok := src.(map[string]any); ok && p.union != nil {
+			member, err := s.mapUnionMember(p.union, m)
+			if err != nil {
+				return err
+			}
+			return s.inUnion(p.union, func() error { return s.mapConcrete(v, member, src) })
+		}
+		if m, ok := src.(map[string]any); ok && s.d.ValidateInterfaceObjects {
+			return s.mapConcrete(v, s.implFor(p, func() []string { return sortedKeys(m) }), src)
+		}
+	case planLiteral:
+		if ok, err := s.mapContainer(v, src); ok {
+			return err
+		}
+	}
+	return s.mapLeaf(v, src)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *decodeState) mapStruct(v reflect.Value, p *typePlan, m map[string]any) error {
+	if err := s.structErr(p); err != nil {
+		return err
+	}
+	if s.stats != nil {
+		s.stats.Objects++
+	}
+	sf := p.fields
+	saved := s.beginFields(v, sf)
+	err := s.mapMembers(v, sf, m)
+	if err == nil {
+		s.endFields(v, sf)
+	}
+	s.present = saved
+	return err
+}
+
+// mapMembers mirrors members for a generic source object.
+func (s *decodeState) mapMembers(v reflect.Value, sf *structFields, m map[string]any) error {
+	for _, key := range sortedKeys(m) {
+		src := m[key]
+		key = s.transformKey(key)
+		s.pushKey(key)
+		err := s.mapField(v, sf, key, src)
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapField mirrors field for a generic source value.
+func (s *decodeState) mapField(v reflect.Value, sf *structFields, key string, src any) error {
+	fi, exists := s.lookup(sf, key)
+	if !exists {
+		if sf.remain != nil {
+			return s.mapRemain(v, sf, key, src)
+		}
+		fold, err := s.unknown(sf, key)
+		if err != nil || fold == nil {
+			return err
+		}
+		fi = fold
+	}
+
+	if s.report != nil {
+		s.report.add(s.pathString())
+	}
+	s.markPresent(fi)
+	fieldValue := getFieldByIndex(v, fi.fieldIndex)
+	if !fieldValue.IsValid() || !fieldValue.CanSet() {
+		return nil
+	}
+	saved, savedCurrent := s.enterPolicy(fi.policy), s.current
+	s.current = fi
+	var err error
+	if fi.quoted {
+		err = s.mapQuoted(fieldValue, fi.plan, src)
+	} else {
+		err = s.mapValue(fieldValue, fi.plan, src)
+	}
+	if err == nil && s.constrained(fi) {
+		err = s.checkConstraints(fieldValue, fi)
+	}
+	s.policy, s.current = saved, savedCurrent
+	return err
+}
+
+// mapQuoted mirrors quoted for a generic source value.
+func (s *decodeState) mapQuoted(v reflect.Value, p *typePlan, src any) error {
+	str, ok := src.(string)
+	if !ok {
+		c := byte('n')
+		switch src.(type) {
+		case bool:
+			c = 't'
+		case float64, json.Number:
+			c = '0'
+		case map[string]any:
+			c = '{'
+		case []any:
+			c = '['
+		}
+		if err := s.checkQuoted(p, c); err != nil {
+			return err
+		}
+		return s.mapValue(v, p, src)
+	}
+	if err := s.d.backend().Unmarshal([]byte(str), v.Addr().Interface()); err != nil {
+		raw, _ := json.Marshal(str)
+		return s.typeMismatch(&json.UnmarshalTypeError{Value: "string", Type: v.Type()}, raw, 0)
+	}
+	return nil
+}
+
+func (s *decodeState) mapRemain(v reflect.Value, sf *structFields, key string, src any) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return wrapJSONError(err)
+	}
+	m := getFieldByIndex(v, sf.remain)
+	if !m.IsValid() {
+		return nil
+	}
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	m.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(json.RawMessage(raw)))
+	return nil
+}
+
+func (s *decodeState) mapSlice(v reflect.Value, elem *typePlan, a []any) error {
+	var newSlice reflect.Value
+	if v.Kind() == reflect.Array {
+		if err := s.arrayLength(v.Type(), len(a)); err != nil {
+			return err
+		}
+		newSlice = reflect.New(v.Type()).Elem()
+	} else {
+		newSlice = reflect.MakeSlice(v.Type(), len(a), len(a))
+	}
+	if s.d.MergeExisting {
+		reflect.Copy(newSlice, v)
+	}
+	for i, src := range a {
+		var dst reflect.Value
+		if i < newSlice.Len() {
+			dst = newSlice.Index(i)
+		} else {
+			// Checked like the others, then discarded.
+			dst = reflect.New(v.Type().Elem()).Elem()
+		}
+		s.pushIndex(i)
+		err := s.mapValue(dst, elem, src)
+		s.pop()
+		if err != nil {
+			return err
+		}
+	}
+	if v.Kind() == reflect.Array {
+		zeroFrom(newSlice, len(a))
+	}
+	v.Set(newSlice)
+	return nil
+}
+
+func (s *decodeState) mapMap(v reflect.Value, elem *typePlan, m map[string]any) error {
+	s.resetMap(v)
+	keyType := v.Type().Key()
+	for _, key := range sortedKeys(m) {
+		var elemVal reflect.Value
+		s.pushKey(key)
+		keyVal, err := s.mapKey(keyType, key)
+		if err == nil {
+			elemVal = s.mapElem(v, keyVal)
+			err = s.mapValue(elemVal, elem, m[key])
+		}
+		s.pop()
+		if err != nil {
+			return err
+		}
+		v.SetMapIndex(keyVal, elemVal)
+	}
+	return nil
+}
+
+// mapGenerated runs a generated decoder over the JSON encoding of src.
+func (s *decodeState) mapGenerated(v reflect.Value, src any) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return wrapJSONError(err)
+	}
+	saved := s.scan
+	s.scan = scanner{data: data}
+	err = s.generated(v)
+	s.scan = saved
+	return err
+}
+
+// mapLeaf stores a leaf value. Values that convert losslessly are assigned
+// directly; anything else goes through the backend as JSON, so that custom
+// unmarshalers and type errors behave as they do in Unmarshal.
+func (s *decodeState) mapLeaf(v reflect.Value, src any) error {
+	if !hasUnmarshalMethod(v.Type()) {
+		if src == nil {
+			if isNullable(v.Kind()) {
+				v.Set(reflect.Zero(v.Type()))
+			}
+			return nil
+		}
+		if assignLeaf(v, reflect.ValueOf(src)) {
+			return nil
+		}
+	}
+
+	data, err := json.Marshal(src)
+	if err != nil {
+		return wrapJSONError(err)
+	}
+	err = s.d.backend().Unmarshal(data, v.Addr().Interface())
+	if err != nil {
+		// The offset is into data, which is not part of any input.
+		if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+			typeErr.Offset = 0
+		}
+		return s.typeMismatch(err, data, 0)
+	}
+	return nil
+}
+
+// hasUnmarshalMethod reports whether encoding/json would decode into t
+// through a method rather than by kind.
+func hasUnmarshalMethod(t reflect.Type) bool {
+	pt := reflect.PointerTo(t)
+	return pt.Implements(unmarshalerType) || pt.Implements(textUnmarshalerType)
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// assignLeaf assigns sv to v if that loses nothing, reporting whether it did.
+func assignLeaf(v, sv reflect.Value) bool {
+	if sv.Type().AssignableTo(v.Type()) {
+		v.Set(sv)
+		return true
+	}
+	switch sv.Kind() {
+	case reflect.String:
+		if v.Kind() == reflect.String {
+			v.SetString(sv.String())
+			return true
+		}
+	case reflect.Bool:
+		if v.Kind() == reflect.Bool {
+			v.SetBool(sv.Bool())
+			return true
+		}
+	case reflect.Float64:
+		f := sv.Float()
+		switch v.Kind() {
+		case reflect.Float32, reflect.Float64:
+			if v.OverflowFloat(f) {
+				return false
+			}
+			v.SetFloat(f)
+			return true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 || v.OverflowInt(int64(f)) {
+				return false
+			}
+			v.SetInt(int64(f))
+			return true
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			if f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 || v.OverflowUint(uint64(f)) {
+				return false
+			}
+			v.SetUint(uint64(f))
+			return true
+		}
+	}
+	return false
+}
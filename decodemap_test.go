@@ -0,0 +1,134 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type mapEvent struct {
+	ID      int64               `json:"id"`
+	Kind    string              `json:"kind"`
+	Ratio   float32             `json:"ratio"`
+	At      time.Time           `json:"at"`
+	Tags    []string            `json:"tags"`
+	Payload any                 `json:"payload"`
+	Owner   *mapOwner           `json:"owner"`
+	Items   []mapOwner          `json:"items"`
+	Index   map[string]mapOwner `json:"index"`
+	Note    Optional[string]    `json:"note"`
+}
+
+type mapOwner struct {
+	Name string `json:"name"`
+}
+
+func TestDecodeMap(t *testing.T) {
+	src := `{"id": 7, "kind": "created", "ratio": 0.5, "at": "2024-01-02T03:04:05Z",
+		"tags": ["a"], "payload": {"x": [1, true]}, "owner": {"name": "o"},
+		"items": [{"name": "i"}], "index": {"k": {"name": "v"}}, "note": null}`
+	var m map[string]any
+	if err := json.Unmarshal([]byte(src), &m); err != nil {
+		t.Fatal(err)
+	}
+
+	var got, want mapEvent
+	if err := DecodeMap(m, &got); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if err := Unmarshal([]byte(src), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeMap() = %+v, want %+v", got, want)
+	}
+	if !got.Note.Set || !got.Note.Null {
+		t.Errorf("Expected explicit null to be recorded, got %+v", got.Note)
+	}
+}
+
+func TestDecodeMapStrictRules(t *testing.T) {
+	var e mapEvent
+	err := NewDecoder(WithSuggestClosest(true)).DecodeMap(map[string]any{
+		"items": []any{map[string]any{"Name": "x"}},
+	}, &e)
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Path() != "items[0].Name" || unknown.Suggestion() != "name" {
+		t.Errorf("Expected UnknownFieldError at items[0].Name, got %v", err)
+	}
+
+	// Keys are checked in sorted order.
+	err = DecodeMap(map[string]any{"zz": 1, "aa": 1}, &e)
+	if !errors.As(err, &unknown) || unknown.Field() != "aa" {
+		t.Errorf("Expected the first sorted key to be reported, got %v", err)
+	}
+
+	err = DecodeMap(map[string]any{"id": 1.5}, &e)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "id" {
+		t.Errorf("Expected UnmarshalTypeError at 'id', got %v", err)
+	}
+
+	err = DecodeMap(map[string]any{"owner": "nobody"}, &e)
+	if !errors.As(err, &typeErr) || typeErr.Field != "owner" {
+		t.Errorf("Expected UnmarshalTypeError at 'owner', got %v", err)
+	}
+
+	err = NewDecoder(WithDisallowNullForNonPointer(true)).DecodeMap(map[string]any{"kind": nil}, &e)
+	var nullErr *NullValueError
+	if !errors.As(err, &nullErr) || nullErr.Path() != "kind" {
+		t.Errorf("Expected NullValueError at 'kind', got %v", err)
+	}
+
+	if err := DecodeMap(nil, e); err == nil {
+		t.Error("Expected error for non-pointer")
+	}
+}
+
+func TestDecodeMapLeaves(t *testing.T) {
+	var v struct {
+		Small  int8                       `json:"small"`
+		Count  uint                       `json:"count"`
+		Level  time.Duration              `json:"level"`
+		Nested map[string]int             `json:"nested"`
+		Raw    json.RawMessage            `json:"raw"`
+		Ptr    *int                       `json:"ptr"`
+		Extra  map[string]json.RawMessage `strict:",remain"`
+	}
+	v.Ptr = new(int)
+	err := DecodeMap(map[string]any{
+		"small":  float64(-3),
+		"count":  int(4),
+		"level":  int64(5),
+		"nested": map[string]any{"a": float64(1)},
+		"raw":    []any{"x"},
+		"ptr":    nil,
+		"other":  map[string]any{"k": true},
+	}, &v)
+	if err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if v.Small != -3 || v.Count != 4 || v.Level != 5 || v.Nested["a"] != 1 || string(v.Raw) != `["x"]` || v.Ptr != nil {
+		t.Errorf("Unexpected result %+v", v)
+	}
+	if string(v.Extra["other"]) != `{"k":true}` {
+		t.Errorf("Expected remain to collect 'other', got %q", v.Extra["other"])
+	}
+
+	if err := DecodeMap(map[string]any{"small": float64(300)}, &v); err == nil {
+		t.Error("Expected overflow error")
+	}
+}
+
+func TestDecodeMapGenerated(t *testing.T) {
+	var u genUser
+	err := DecodeMap(map[string]any{"name": "Ann", "address": map[string]any{"city": "Oslo"}}, &u)
+	if err != nil || u.Name != "Ann" || u.Address.City != "Oslo" {
+		t.Errorf("Unexpected result %+v (%v)", u, err)
+	}
+	if err := DecodeMap(map[string]any{"address": map[string]any{"City": "Oslo"}}, &u); err == nil {
+		t.Error("Expected generated decoder to reject mis-cased key")
+	}
+}
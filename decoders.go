@@ -0,0 +1,90 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// DecodeFunc decodes data, a single JSON value other than null, into v, an
+// addressable value of the type it was registered for.
+type DecodeFunc func(data []byte, v reflect.Value) error
+
+var decoderRegistry struct {
+	sync.RWMutex
+	byType map[reflect.Type]DecodeFunc
+}
+
+// RegisterDecoder makes fn decode every value of type t, wherever it
+// appears, in place of encoding/json and of any UnmarshalJSON or
+// UnmarshalText method of t. It lets types you do not own, such as
+// time.Time, accept other formats while the structs around them are still
+// validated strictly:
+//
+//	strictjson.RegisterDecoder(reflect.TypeOf(time.Time{}), func(data []byte, v reflect.Value) error {
+//		var s string
+//		if err := json.Unmarshal(data, &s); err != nil {
+//			return err
+//		}
+//		t, err := time.Parse("2006-01-02", s)
+//		v.Set(reflect.ValueOf(t))
+//		return err
+//	})
+//
+// Pointers to t are allocated as usual, and null is handled as for any other
+// type without being passed to fn. An error from fn is reported with a
+// *ValueError naming the location of the value. Registering a nil fn removes
+// the decoder.
+//
+// It is intended to be called during program initialization.
+func RegisterDecoder(t reflect.Type, fn DecodeFunc) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	decoderRegistry.Lock()
+	if decoderRegistry.byType == nil {
+		decoderRegistry.byType = make(map[reflect.Type]DecodeFunc)
+	}
+	if fn == nil {
+		delete(decoderRegistry.byType, t)
+	} else {
+		decoderRegistry.byType[t] = fn
+	}
+	decoderRegistry.Unlock()
+
+	ClearCache()
+}
+
+// registeredDecoder returns the DecodeFunc registered for t, or nil.
+func registeredDecoder(t reflect.Type) DecodeFunc {
+	decoderRegistry.RLock()
+	defer decoderRegistry.RUnlock()
+	return decoderRegistry.byType[t]
+}
+
+// decodeFunc decodes the next value into v with the DecodeFunc of p.
+func (s *decodeState) decodeFunc(v reflect.Value, p *typePlan) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	return s.callDecodeFunc(v, p, raw)
+}
+
+// mapDecodeFunc mirrors decodeFunc for a generic source value.
+func (s *decodeState) mapDecodeFunc(v reflect.Value, p *typePlan, src any) error {
+	raw, err := json.Marshal(src)
+	if err != nil {
+		return wrapJSONError(err)
+	}
+	return s.callDecodeFunc(v, p, raw)
+}
+
+func (s *decodeState) callDecodeFunc(v reflect.Value, p *typePlan, raw []byte) error {
+	if err := p.decode(raw, v); err != nil {
+		return newValueError(s.pathString(), v.Type(), s.snippet(raw), fmt.Sprintf("is not a valid Go %s", v.Type()), err)
+	}
+	return nil
+}
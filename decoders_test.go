@@ -0,0 +1,100 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// flexTime is a type we pretend not to own.
+type flexTime struct{ time.Time }
+
+func decodeFlexTime(data []byte, v reflect.Value) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			v.Set(reflect.ValueOf(flexTime{t}))
+			return nil
+		}
+	}
+	return errors.New("unsupported time format")
+}
+
+func TestRegisterDecoder(t *testing.T) {
+	RegisterDecoder(reflect.TypeOf(flexTime{}), decodeFlexTime)
+	defer RegisterDecoder(reflect.TypeOf(flexTime{}), nil)
+
+	type Event struct {
+		At      flexTime            `json:"at"`
+		Ends    *flexTime           `json:"ends"`
+		History []flexTime          `json:"history"`
+		ByName  map[string]flexTime `json:"byName"`
+	}
+
+	data := []byte(`{
+		"at": "2024-05-01",
+		"ends": "2024-05-02T10:00:00Z",
+		"history": ["2024-01-01"],
+		"byName": {"a": "2024-02-01"}
+	}`)
+	var got Event
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.At.Format("2006-01-02") != "2024-05-01" || got.Ends == nil || got.Ends.Hour() != 10 {
+		t.Errorf("Unexpected times %v, %v", got.At, got.Ends)
+	}
+	if len(got.History) != 1 || got.ByName["a"].Month() != time.February {
+		t.Errorf("Unexpected nested times %v, %v", got.History, got.ByName)
+	}
+
+	var fromMap Event
+	if err := DecodeMap(map[string]any{"at": "2024-05-01"}, &fromMap); err != nil || fromMap.At.Day() != 1 {
+		t.Errorf("DecodeMap() = %v, %v", fromMap.At, err)
+	}
+
+	// The surrounding struct is still strict.
+	var ufe *UnknownFieldError
+	if err := Unmarshal([]byte(`{"At": "2024-05-01"}`), &got); !errors.As(err, &ufe) {
+		t.Errorf("Expected *UnknownFieldError, got %v", err)
+	}
+
+	d := NewDecoder(WithErrorValueSnippets(20))
+	err := d.Unmarshal([]byte(`{"history": ["2024-01-01", "May 1st"]}`), &got)
+	var ve *ValueError
+	if !errors.As(err, &ve) || ve.Path() != "history[1]" || ve.Value() != `"May 1st"` {
+		t.Fatalf("Expected *ValueError at history[1], got %v", err)
+	}
+	const want = `strictjson: value at "history[1]" is not a valid Go strictjson.flexTime: unsupported time format`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if err := d.Validate([]byte(`{"at": 1}`), &got); !errors.As(err, &ve) || ve.Path() != "at" {
+		t.Errorf("Validate() expected *ValueError at at, got %v", err)
+	}
+}
+
+func TestRegisterDecoderOverridesMethods(t *testing.T) {
+	// time.Time implements json.Unmarshaler; a registered decoder wins.
+	RegisterDecoder(reflect.TypeOf(time.Time{}), func(data []byte, v reflect.Value) error {
+		var days int
+		if err := json.Unmarshal(data, &days); err != nil {
+			return err
+		}
+		v.Set(reflect.ValueOf(time.Unix(0, 0).UTC().AddDate(0, 0, days)))
+		return nil
+	})
+	defer RegisterDecoder(reflect.TypeOf(time.Time{}), nil)
+
+	var v struct {
+		At time.Time `json:"at"`
+	}
+	if err := Unmarshal([]byte(`{"at": 2}`), &v); err != nil || v.At.Day() != 3 {
+		t.Errorf("Unmarshal() = %v, %v", v.At, err)
+	}
+}
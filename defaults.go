@@ -0,0 +1,62 @@
+package strictjson
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// fieldDefault is the value the default=value option of a strict tag
+// assigns to its field when the field's key is absent.
+type fieldDefault struct {
+	text  string
+	value reflect.Value
+}
+
+// parseDefault parses the default text of the field named name of type t:
+// string types take text as written, types with an UnmarshalText method
+// decode it as text, and other types decode it as JSON, so that
+// `strict:"default=30"` suits an int and `strict:"default=[1]"` a slice.
+func parseDefault(name string, t reflect.Type, text string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	target := v
+	for target.Kind() == reflect.Ptr {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+	var err error
+	switch {
+	case reflect.PointerTo(target.Type()).Implements(textUnmarshalerType):
+		err = target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	case target.Kind() == reflect.String:
+		target.SetString(text)
+	default:
+		err = json.Unmarshal([]byte(text), target.Addr().Interface())
+	}
+	if err != nil {
+		return reflect.Value{}, newInvalidTagError(name, fmt.Sprintf(`strict:"default=%s" is not a valid %s: %v`, text, t, err))
+	}
+	return v, nil
+}
+
+// applyDefaults assigns their defaults to the fields of v, decoded with sf,
+// that were absent from its object.
+func (s *decodeState) applyDefaults(v reflect.Value, sf *structFields) {
+	for _, fi := range sf.defaults {
+		if s.present[fi.index] {
+			continue
+		}
+		fieldValue := getFieldByIndex(v, fi.fieldIndex)
+		if !fieldValue.IsValid() || !fieldValue.CanSet() {
+			continue
+		}
+		def := fi.def.value
+		switch def.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			// Every value gets its own copy of a reference default.
+			def, _ = parseDefault(fi.goPath, fi.typ, fi.def.text)
+		}
+		fieldValue.Set(def)
+	}
+}
@@ -0,0 +1,109 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type serverConfig struct {
+	Host    string        `json:"host" strict:"default=localhost"`
+	Port    int           `json:"port" strict:"default=8080"`
+	Debug   *bool         `json:"debug" strict:"default=true"`
+	Tags    []string      `json:"tags" strict:"default=[\"web\"]"`
+	Timeout time.Duration `json:"timeout" strict:"default=30"`
+	Start   time.Time     `json:"start" strict:"default=2024-01-01T00:00:00Z"`
+	Name    string        `json:"name"`
+}
+
+func TestDefaults(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var got serverConfig
+	if err := Unmarshal([]byte(`{"name": "api", "port": 9000}`), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.Host != "localhost" || got.Port != 9000 || got.Debug == nil || !*got.Debug ||
+		!reflect.DeepEqual(got.Tags, []string{"web"}) || got.Timeout != 30 || !got.Start.Equal(start) {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	// Each value gets its own copy of a reference default.
+	got.Tags[0] = "changed"
+	*got.Debug = false
+	var other serverConfig
+	if err := Unmarshal([]byte(`{}`), &other); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if other.Tags[0] != "web" || !*other.Debug {
+		t.Errorf("Defaults shared between values: %+v", other)
+	}
+
+	// null is a value, not an absent key.
+	var nulls serverConfig
+	if err := Unmarshal([]byte(`{"debug": null, "tags": null}`), &nulls); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if nulls.Debug != nil || nulls.Tags != nil || nulls.Port != 8080 {
+		t.Errorf("Unexpected result %+v", nulls)
+	}
+
+	// DecodeMap applies the same defaults.
+	var m map[string]any
+	if err := json.Unmarshal([]byte(`{"host": "example.com"}`), &m); err != nil {
+		t.Fatal(err)
+	}
+	var mapped serverConfig
+	if err := NewDecoder().DecodeMap(m, &mapped); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if mapped.Host != "example.com" || mapped.Port != 8080 {
+		t.Errorf("Unexpected result %+v", mapped)
+	}
+}
+
+func TestDefaultsNested(t *testing.T) {
+	type Inner struct {
+		Level int `json:"level" strict:"default=3"`
+	}
+	type Outer struct {
+		Inner  Inner   `json:"inner"`
+		Items  []Inner `json:"items"`
+		Absent *Inner  `json:"absent"`
+	}
+	var got Outer
+	if err := Unmarshal([]byte(`{"inner": {}, "items": [{}, {"level": 1}]}`), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := Outer{Inner: Inner{3}, Items: []Inner{{3}, {1}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDefaultTagErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+	}{
+		{"not a number", &struct {
+			N int `json:"n" strict:"default=many"`
+		}{}},
+		{"not a time", &struct {
+			T time.Time `json:"t" strict:"default=yesterday"`
+		}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ite *InvalidTagError
+			if err := Unmarshal([]byte(`{}`), tt.v); !errors.As(err, &ite) {
+				t.Errorf("Expected *InvalidTagError, got %v", err)
+			}
+			if err := Validate([]byte(`{}`), tt.v); !errors.As(err, &ite) {
+				t.Errorf("Validate() expected *InvalidTagError, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// DiffKind classifies a DiffEntry.
+type DiffKind uint8
+
+const (
+	DiffUnknownKey   DiffKind = iota + 1 // key that matches no struct field
+	DiffMissingField                     // struct field whose key is absent
+	DiffCaseMismatch                     // key matching a field only case-insensitively
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffUnknownKey:
+		return "unknown_key"
+	case DiffMissingField:
+		return "missing_field"
+	case DiffCaseMismatch:
+		return "case_mismatch"
+	}
+	return fmt.Sprintf("DiffKind(%d)", uint8(k))
+}
+
+// MarshalText encodes k as its String form, so that serialized entries
+// read "unknown_key" rather than a number.
+func (k DiffKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// DiffEntry is one structural difference between a JSON document and a
+// struct type.
+type DiffEntry struct {
+	Kind DiffKind `json:"kind"`
+	// Path locates the key or the absent field, e.g. "items[0].sku".
+	Path string `json:"path"`
+	// Key is the key as written in the document. It is empty for
+	// DiffMissingField.
+	Key string `json:"key,omitempty"`
+	// Field is the JSON name of the field that Key matched
+	// case-insensitively, or of the absent field. It is empty for
+	// DiffUnknownKey.
+	Field string `json:"field,omitempty"`
+}
+
+func (e DiffEntry) String() string {
+	switch e.Kind {
+	case DiffUnknownKey:
+		return fmt.Sprintf(`unknown key "%s" at "%s"`, e.Key, e.Path)
+	case DiffMissingField:
+		return fmt.Sprintf(`missing field "%s" at "%s"`, e.Field, e.Path)
+	default:
+		return fmt.Sprintf(`key "%s" at "%s" matches field "%s" only case-insensitively`, e.Key, e.Path, e.Field)
+	}
+}
+
+// Diff compares the structure of data with the struct type of v, which may
+// be a value or a pointer, and lists the keys no field matches, the fields
+// whose keys are absent and the keys that match a field only
+// case-insensitively. It walks the whole document, into nested structs,
+// slices and maps, and does not stop at the first difference, so the result
+// suits contract-drift reports. Keys collected by a strict:",remain" field
+// are listed as unknown, and a field absent from the document is listed
+// without the fields nested within it.
+//
+// Diff returns an error only if data is not valid JSON or the type cannot
+// be decoded into at all, e.g. because of an invalid tag.
+func Diff(data []byte, v any) ([]DiffEntry, error) {
+	return NewDecoder().Diff(data, v)
+}
+
+// Diff is like the package-level Diff but applies d's options, such as
+// TagKeys and MaxDepth. Options that reject documents, such as
+// DisallowUnknownFields, do not apply.
+func (d *Decoder) Diff(data []byte, v any) (_ []DiffEntry, err error) {
+	defer func() { err = d.formatError(err) }()
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, newNonPointerError()
+	}
+	s, err := d.newState(data)
+	if err != nil {
+		return nil, err
+	}
+	defer s.release()
+	var entries []DiffEntry
+	if err := s.diffValue(d.planFor(t), &entries); err != nil {
+		return nil, err
+	}
+	return entries, s.scan.end()
+}
+
+// diffValue mirrors validateValue, appending differences to entries instead
+// of rejecting them.
+func (s *decodeState) diffValue(p *typePlan, entries *[]DiffEntry) error {
+	if p.kind == planOptional {
+		p = p.elem
+	}
+	switch p.kind {
+	case planStruct:
+		if s.scan.peek() == '{' {
+			return s.diffObject(p, entries)
+		}
+	case planSlice:
+		if s.scan.peek() == '[' {
+			return s.diffArray(p.elem, entries)
+		}
+	case planMap:
+		if s.scan.peek() == '{' {
+			return s.diffMap(p.elem, entries)
+		}
+	}
+	_, err := s.scan.skipValue()
+	return err
+}
+
+func (s *decodeState) diffObject(p *typePlan, entries *[]DiffEntry) error {
+	if err := s.structErr(p); err != nil {
+		return err
+	}
+	sf := p.fields
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+
+	present := make([]bool, len(sf.list))
+	s.scan.pos++ // '{'
+	if !s.scan.consume('}') {
+		for {
+			raw, escaped, err := s.scan.skipKey()
+			if err != nil {
+				return err
+			}
+			key := unquote(raw, escaped)
+
+			s.pushKey(key)
+			fi, ok := sf.lookup(key)
+			if !ok {
+				if fi, ok = sf.lookupFold(key); ok {
+					*entries = append(*entries, DiffEntry{Kind: DiffCaseMismatch, Path: s.pathString(), Key: key, Field: fi.jsonName})
+				} else {
+					*entries = append(*entries, DiffEntry{Kind: DiffUnknownKey, Path: s.pathString(), Key: key})
+				}
+			}
+			if fi != nil {
+				present[fi.index] = true
+				err = s.diffValue(fi.plan, entries)
+			} else {
+				_, err = s.scan.skipValue()
+			}
+			s.pop()
+			if err != nil {
+				return err
+			}
+
+			if s.scan.consume(',') {
+				continue
+			}
+			if err := s.scan.expect('}', "after object key:value pair"); err != nil {
+				return err
+			}
+			break
+		}
+	}
+
+	for _, fi := range sf.list {
+		if !present[fi.index] {
+			s.pushKey(fi.jsonName)
+			*entries = append(*entries, DiffEntry{Kind: DiffMissingField, Path: s.pathString(), Field: fi.jsonName})
+			s.pop()
+		}
+	}
+	return nil
+}
+
+func (s *decodeState) diffArray(elem *typePlan, entries *[]DiffEntry) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+
+	s.scan.pos++ // '['
+	if s.scan.consume(']') {
+		return nil
+	}
+	for i := 0; ; i++ {
+		s.pushIndex(i)
+		err := s.diffValue(elem, entries)
+		s.pop()
+		if err != nil {
+			return err
+		}
+
+		if s.scan.consume(',') {
+			continue
+		}
+		return s.scan.expect(']', "after array element")
+	}
+}
+
+func (s *decodeState) diffMap(elem *typePlan, entries *[]DiffEntry) error {
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+
+	s.scan.pos++ // '{'
+	if s.scan.consume('}') {
+		return nil
+	}
+	for {
+		raw, escaped, err := s.scan.skipKey()
+		if err != nil {
+			return err
+		}
+
+		s.pushKey(unquote(raw, escaped))
+		err = s.diffValue(elem, entries)
+		s.pop()
+		if err != nil {
+			return err
+		}
+
+		if s.scan.consume(',') {
+			continue
+		}
+		return s.scan.expect('}', "after object key:value pair")
+	}
+}
@@ -0,0 +1,96 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type diffItem struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+type diffOrder struct {
+	ID     int                 `json:"id"`
+	Items  []diffItem          `json:"items"`
+	ByName map[string]diffItem `json:"byName"`
+	Ship   *struct {
+		City string `json:"city"`
+	} `json:"ship"`
+	Note Optional[diffItem] `json:"note"`
+}
+
+func TestDiff(t *testing.T) {
+	data := []byte(`{
+		"ID": 1,
+		"items": [{"sku": "a", "qty": 1}, {"sku": "b", "quantity": 2}],
+		"byName": {"x": {"SKU": "c", "qty": 3, "extra": null}},
+		"note": {"sku": "d", "qty": 4},
+		"legacy": {"nested": true}
+	}`)
+	got, err := Diff(data, (*diffOrder)(nil))
+	if err != nil {
+		t.Fatalf("Diff() unexpected error: %v", err)
+	}
+	want := []DiffEntry{
+		{Kind: DiffCaseMismatch, Path: "ID", Key: "ID", Field: "id"},
+		{Kind: DiffUnknownKey, Path: "items[1].quantity", Key: "quantity"},
+		{Kind: DiffMissingField, Path: "items[1].qty", Field: "qty"},
+		{Kind: DiffCaseMismatch, Path: "byName.x.SKU", Key: "SKU", Field: "sku"},
+		{Kind: DiffUnknownKey, Path: "byName.x.extra", Key: "extra"},
+		{Kind: DiffUnknownKey, Path: "legacy", Key: "legacy"},
+		{Kind: DiffMissingField, Path: "ship", Field: "ship"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() =\n%v\nwant\n%v", got, want)
+	}
+
+	if want := `key "ID" at "ID" matches field "id" only case-insensitively`; got[0].String() != want {
+		t.Errorf("String() = %q, want %q", got[0].String(), want)
+	}
+	b, err := json.Marshal(got[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"kind":"unknown_key","path":"items[1].quantity","key":"quantity"}`; string(b) != want {
+		t.Errorf("json.Marshal() = %s, want %s", b, want)
+	}
+}
+
+func TestDiffNoDifferences(t *testing.T) {
+	got, err := Diff([]byte(`{"sku": "a", "qty": 1}`), diffItem{})
+	if err != nil || len(got) != 0 {
+		t.Errorf("Diff() = %v, %v; want no entries", got, err)
+	}
+	// Null and non-object values are not descended into.
+	got, err = Diff([]byte(`[null, 1, {"qty": 1}]`), []diffItem{})
+	if err != nil || len(got) != 1 || got[0].Path != "[2].sku" {
+		t.Errorf("Diff() = %v, %v", got, err)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	var se *SyntaxError
+	if _, err := Diff([]byte(`{"sku": }`), diffItem{}); !errors.As(err, &se) {
+		t.Errorf("Expected *SyntaxError, got %v", err)
+	}
+	type A struct{ ID int }
+	type B struct{ ID int }
+	var fce *FieldConflictError
+	if _, err := Diff([]byte(`{"ID": 1}`), struct {
+		A
+		B
+	}{}); !errors.As(err, &fce) {
+		t.Errorf("Expected *FieldConflictError, got %v", err)
+	}
+	if _, err := Diff([]byte(`{}`), nil); err == nil {
+		t.Error("Expected error for nil prototype")
+	}
+	// Options that reject documents do not apply.
+	d := NewDecoder(WithDisallowDuplicateKeys(true))
+	if got, err := d.Diff([]byte(`{"sku": "a", "sku": "b", "qty": 1}`), diffItem{}); err != nil || len(got) != 0 {
+		t.Errorf("Diff() = %v, %v", got, err)
+	}
+}
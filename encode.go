@@ -0,0 +1,507 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"unicode/utf8"
+)
+
+// An Encoder writes JSON values to an output stream byte for byte the same
+// way every time, for golden files and content-addressed storage. Struct
+// fields are written in the order encoding/json writes them, or sorted by
+// name, and map keys are always sorted. Values JSON cannot represent, such as
+// NaN, channels and strings holding invalid UTF-8, fail with an
+// *EncodeError naming their location instead of being written in some
+// approximation. Build one with NewEncoder.
+type Encoder struct {
+	w io.Writer
+	// SortedKeys sorts the members of every object by key, in byte order.
+	// See WithSortedKeys.
+	SortedKeys bool
+	// Prefix and Indent, when either is set, lay out each value over
+	// several lines. See WithIndent.
+	Prefix, Indent string
+	// EscapeHTML escapes <, > and & inside strings. See WithEscapeHTML.
+	EscapeHTML bool
+}
+
+type EncoderOption func(*Encoder)
+
+// NewEncoder returns an Encoder writing to w, configured by opts.
+func NewEncoder(w io.Writer, opts ...EncoderOption) *Encoder {
+	e := &Encoder{w: w}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// WithSortedKeys(true) writes the fields of structs sorted by their JSON
+// names, like map keys, rather than in declaration order. The output then
+// satisfies WithRequireKeyOrder(KeyOrderSorted); without the option it
+// satisfies WithRequireKeyOrder(KeyOrderFields).
+func WithSortedKeys(sorted bool) EncoderOption {
+	return func(e *Encoder) {
+		e.SortedKeys = sorted
+	}
+}
+
+// WithIndent lays out each value over several lines, each beginning with
+// prefix and indented by one copy of indent per level of nesting, as
+// json.MarshalIndent does.
+func WithIndent(prefix, indent string) EncoderOption {
+	return func(e *Encoder) {
+		e.Prefix, e.Indent = prefix, indent
+	}
+}
+
+// WithEscapeHTML(true) escapes <, > and & inside strings as \u003c, \u003e
+// and \u0026, as encoding/json does by default, so that the output can be
+// embedded in HTML. They are written as they are by default.
+func WithEscapeHTML(escape bool) EncoderOption {
+	return func(e *Encoder) {
+		e.EscapeHTML = escape
+	}
+}
+
+// Encode writes the JSON encoding of v to the stream, followed by a newline.
+// Nothing is written if v cannot be encoded.
+//
+// Values are encoded as by encoding/json, with these differences: struct
+// fields are those strictjson decodes, so a struct with an invalid strict
+// tag or a JSON name that several embedded structs provide fails, rather
+// than silently dropping the ambiguous name; the keys collected by a
+// strict:",remain" field are written as members of its object; NaN and
+// infinite floats, invalid UTF-8 and pointer cycles are errors; and
+// strings escape only what JSON requires, plus HTML characters with
+// EscapeHTML.
+func (e *Encoder) Encode(v any) error {
+	out, err := e.marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = e.w.Write(append(out, '\n'))
+	return err
+}
+
+// marshal returns the laid-out encoding of v.
+func (e *Encoder) marshal(v any) ([]byte, error) {
+	es := encodeState{e: e}
+	if err := es.value(reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	out := es.buf
+	if e.EscapeHTML {
+		var b bytes.Buffer
+		json.HTMLEscape(&b, out)
+		out = b.Bytes()
+	}
+	if e.Prefix != "" || e.Indent != "" {
+		var b bytes.Buffer
+		if err := json.Indent(&b, out, e.Prefix, e.Indent); err != nil {
+			return nil, err
+		}
+		out = b.Bytes()
+	}
+	return out, nil
+}
+
+var (
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// encodeState holds the state of one Encode call.
+type encodeState struct {
+	e    *Encoder
+	buf  []byte
+	path []pathSegment
+	// ptrs holds the pointers and maps being encoded, to detect cycles.
+	ptrs map[ptrKey]struct{}
+}
+
+// fail reports that the value of type t at the current path cannot be
+// encoded.
+func (es *encodeState) fail(t reflect.Type, reason string, err error) error {
+	return newEncodeError(formatPath(es.path), t, reason, err)
+}
+
+func (es *encodeState) value(v reflect.Value) error {
+	if !v.IsValid() {
+		es.buf = append(es.buf, "null"...)
+		return nil
+	}
+	t := v.Type()
+	if t.Kind() == reflect.Interface {
+		if v.IsNil() {
+			es.buf = append(es.buf, "null"...)
+			return nil
+		}
+		return es.value(v.Elem())
+	}
+	if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PointerTo(t).Implements(marshalerType) {
+		return es.marshaler(v.Addr())
+	}
+	if t.Implements(marshalerType) {
+		return es.marshaler(v)
+	}
+	if t.Kind() != reflect.Ptr && v.CanAddr() && reflect.PointerTo(t).Implements(textMarshalerType) {
+		return es.textMarshaler(v.Addr())
+	}
+	if t.Implements(textMarshalerType) {
+		return es.textMarshaler(v)
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		es.buf = strconv.AppendBool(es.buf, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		es.buf = strconv.AppendInt(es.buf, v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		es.buf = strconv.AppendUint(es.buf, v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return es.fail(t, fmt.Sprintf("%v is not a JSON number", f), nil)
+		}
+		es.buf = appendFloat(es.buf, f, t.Bits())
+	case reflect.String:
+		if t == numberType {
+			return es.number(v)
+		}
+		return es.string(v)
+	case reflect.Ptr:
+		if v.IsNil() {
+			es.buf = append(es.buf, "null"...)
+			return nil
+		}
+		if err := es.enter(v); err != nil {
+			return err
+		}
+		defer es.leave(v)
+		return es.value(v.Elem())
+	case reflect.Struct:
+		return es.structValue(v)
+	case reflect.Map:
+		if v.IsNil() {
+			es.buf = append(es.buf, "null"...)
+			return nil
+		}
+		if err := es.enter(v); err != nil {
+			return err
+		}
+		defer es.leave(v)
+		return es.mapValue(v)
+	case reflect.Slice:
+		if v.IsNil() {
+			es.buf = append(es.buf, "null"...)
+			return nil
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(marshalerType) &&
+			!reflect.PointerTo(t.Elem()).Implements(textMarshalerType) {
+			es.buf = append(es.buf, '"')
+			es.buf = append(es.buf, base64.StdEncoding.EncodeToString(v.Bytes())...)
+			es.buf = append(es.buf, '"')
+			return nil
+		}
+		return es.array(v)
+	case reflect.Array:
+		return es.array(v)
+	default:
+		return es.fail(t, "unsupported type", nil)
+	}
+	return nil
+}
+
+// enter records that the pointer or map v is being encoded, failing if it
+// already is, further up the path.
+func (es *encodeState) enter(v reflect.Value) error {
+	key := ptrKey{v.Type(), v.Pointer()}
+	if _, cycle := es.ptrs[key]; cycle {
+		return es.fail(v.Type(), "cycle of references", nil)
+	}
+	if es.ptrs == nil {
+		es.ptrs = make(map[ptrKey]struct{})
+	}
+	es.ptrs[key] = struct{}{}
+	return nil
+}
+
+func (es *encodeState) leave(v reflect.Value) {
+	delete(es.ptrs, ptrKey{v.Type(), v.Pointer()})
+}
+
+func (es *encodeState) marshaler(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		es.buf = append(es.buf, "null"...)
+		return nil
+	}
+	out, err := v.Interface().(json.Marshaler).MarshalJSON()
+	if err != nil {
+		return es.fail(v.Type(), "MarshalJSON failed", err)
+	}
+	b := bytes.NewBuffer(es.buf)
+	if err := json.Compact(b, out); err != nil {
+		return es.fail(v.Type(), "MarshalJSON returned invalid JSON", err)
+	}
+	es.buf = b.Bytes()
+	return nil
+}
+
+func (es *encodeState) textMarshaler(v reflect.Value) error {
+	if v.Kind() == reflect.Ptr && v.IsNil() {
+		es.buf = append(es.buf, "null"...)
+		return nil
+	}
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return es.fail(v.Type(), "MarshalText failed", err)
+	}
+	return es.text(v.Type(), string(text))
+}
+
+func (es *encodeState) string(v reflect.Value) error {
+	return es.text(v.Type(), v.String())
+}
+
+// text appends s, the text of a value of type t, as a JSON string.
+func (es *encodeState) text(t reflect.Type, s string) error {
+	if !utf8.ValidString(s) {
+		return es.fail(t, "invalid UTF-8 in string", nil)
+	}
+	es.buf = appendCanonicalString(es.buf, s)
+	return nil
+}
+
+func (es *encodeState) number(v reflect.Value) error {
+	n := v.String()
+	if n == "" {
+		n = "0" // as encoding/json writes an empty Number
+	}
+	s := scanner{data: []byte(n)}
+	if err := s.readNumber(); err != nil || s.pos != len(n) {
+		return es.fail(v.Type(), fmt.Sprintf("%q is not a JSON number", n), nil)
+	}
+	es.buf = append(es.buf, n...)
+	return nil
+}
+
+func (es *encodeState) array(v reflect.Value) error {
+	es.buf = append(es.buf, '[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			es.buf = append(es.buf, ',')
+		}
+		es.path = append(es.path, pathSegment{index: i})
+		if err := es.value(v.Index(i)); err != nil {
+			return err
+		}
+		es.path = es.path[:len(es.path)-1]
+	}
+	es.buf = append(es.buf, ']')
+	return nil
+}
+
+// encodedMember is a member of an object about to be written.
+type encodedMember struct {
+	key string
+	v   reflect.Value
+	fi  *fieldInfo // nil for map entries and remain keys
+	raw json.RawMessage
+}
+
+func (es *encodeState) mapValue(v reflect.Value) error {
+	members := make([]encodedMember, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := es.mapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		members = append(members, encodedMember{key: key, v: iter.Value()})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].key < members[j].key })
+	return es.object(members)
+}
+
+// mapKey returns the object key of the map key k, resolved the way
+// encoding/json resolves it.
+func (es *encodeState) mapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		if !utf8.ValidString(k.String()) {
+			return "", es.fail(k.Type(), "invalid UTF-8 in map key", nil)
+		}
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		if k.Kind() == reflect.Ptr && k.IsNil() {
+			return "", nil
+		}
+		text, err := tm.MarshalText()
+		if err != nil {
+			return "", es.fail(k.Type(), "MarshalText of map key failed", err)
+		}
+		if !utf8.Valid(text) {
+			return "", es.fail(k.Type(), "invalid UTF-8 in map key", nil)
+		}
+		return string(text), nil
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", es.fail(k.Type(), "unsupported map key type", nil)
+}
+
+func (es *encodeState) structValue(v reflect.Value) error {
+	sf, err := getStructFields(v.Type(), fieldConfig{})
+	if err != nil {
+		return err
+	}
+	if len(sf.conflicts) > 0 {
+		return sf.conflicts[0]
+	}
+	fields := encodeOrder(v.Type(), sf)
+	members := make([]encodedMember, 0, len(fields))
+	for _, fi := range fields {
+		fv, err := v.FieldByIndexErr(fi.fieldIndex)
+		if err != nil {
+			continue // behind a nil embedded pointer
+		}
+		if hasTagOption(fi.tagOpts, "omitempty") && isEmptyValue(fv) {
+			continue
+		}
+		members = append(members, encodedMember{key: fi.jsonName, v: fv, fi: fi})
+	}
+	if sf.remain != nil {
+		extra, err := v.FieldByIndexErr(sf.remain)
+		if err == nil && extra.Len() > 0 {
+			rest := make([]encodedMember, 0, extra.Len())
+			for key, raw := range extra.Interface().(map[string]json.RawMessage) {
+				if _, ok := sf.fields[key]; ok {
+					return es.fail(v.Type(), fmt.Sprintf("remain key %q duplicates a field", key), nil)
+				}
+				rest = append(rest, encodedMember{key: key, raw: raw})
+			}
+			sort.Slice(rest, func(i, j int) bool { return rest[i].key < rest[j].key })
+			members = append(members, rest...)
+		}
+	}
+	if es.e.SortedKeys {
+		sort.SliceStable(members, func(i, j int) bool { return members[i].key < members[j].key })
+	}
+	return es.object(members)
+}
+
+func (es *encodeState) object(members []encodedMember) error {
+	es.buf = append(es.buf, '{')
+	for i, m := range members {
+		if i > 0 {
+			es.buf = append(es.buf, ',')
+		}
+		es.path = append(es.path, pathSegment{key: m.key, index: -1})
+		es.buf = appendCanonicalString(es.buf, m.key)
+		es.buf = append(es.buf, ':')
+		var err error
+		switch {
+		case m.raw != nil:
+			b := bytes.NewBuffer(es.buf)
+			if err = json.Compact(b, m.raw); err != nil {
+				err = es.fail(rawMessageMapType.Elem(), "invalid JSON", err)
+			}
+			es.buf = b.Bytes()
+		case m.fi != nil && m.fi.quoted:
+			err = es.quoted(m.v)
+		default:
+			err = es.value(m.v)
+		}
+		if err != nil {
+			return err
+		}
+		es.path = es.path[:len(es.path)-1]
+	}
+	es.buf = append(es.buf, '}')
+	return nil
+}
+
+// quoted appends v, the value of a field with the ,string option, encoded
+// inside a JSON string.
+func (es *encodeState) quoted(v reflect.Value) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			es.buf = append(es.buf, "null"...)
+			return nil
+		}
+		v = v.Elem()
+	}
+	start := len(es.buf)
+	if err := es.value(v); err != nil {
+		return err
+	}
+	inner := string(es.buf[start:])
+	es.buf = appendCanonicalString(es.buf[:start], inner)
+	return nil
+}
+
+// encodeOrders caches the fields of struct types in the order they are
+// encoded.
+var encodeOrders sync.Map // map[reflect.Type][]*fieldInfo
+
+// encodeOrder returns the fields of sf, the field table of t, in the order
+// encoding/json writes them.
+func encodeOrder(t reflect.Type, sf *structFields) []*fieldInfo {
+	if cached, ok := encodeOrders.Load(t); ok {
+		return cached.([]*fieldInfo)
+	}
+	fields := append([]*fieldInfo(nil), sf.list...)
+	sort.Slice(fields, func(i, j int) bool { return compareIndex(fields[i].fieldIndex, fields[j].fieldIndex) < 0 })
+	encodeOrders.Store(t, fields)
+	return fields
+}
+
+// isEmptyValue reports whether v is empty in the sense of the omitempty tag
+// option.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// appendFloat appends f, of the given bit size, as encoding/json writes
+// it, which is also how ECMAScript writes numbers: the shortest digits that
+// round-trip, in plain notation for magnitudes from 1e-6 up to 1e21 and in
+// exponential notation otherwise.
+func appendFloat(dst []byte, f float64, bits int) []byte {
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (bits == 64 && (abs < 1e-6 || abs >= 1e21) ||
+		bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21)) {
+		format = 'e'
+	}
+	start := len(dst)
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		// Go pads the exponent to two digits; ECMAScript does not.
+		if n := len(dst); n-start >= 4 && dst[n-2] == '0' && (dst[n-3] == '-' || dst[n-3] == '+') {
+			dst = append(dst[:n-2], dst[n-1])
+		}
+	}
+	return dst
+}
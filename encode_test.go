@@ -0,0 +1,203 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type encBase struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type encItem struct {
+	Name    string            `json:"name"`
+	encBase                   // promoted fields take the place of the embedding
+	Price   float64           `json:"price"`
+	Count   int64             `json:"count,string"`
+	Tags    []string          `json:"tags,omitempty"`
+	Attrs   map[string]any    `json:"attrs"`
+	IP      net.IP            `json:"ip"`
+	Data    []byte            `json:"data"`
+	Next    *encItem          `json:"next"`
+	Byte    map[int]string    `json:"byId"`
+	Extra   map[string]string `json:"-"`
+	note    string
+}
+
+func TestEncoder(t *testing.T) {
+	item := encItem{
+		Name:    "<widget>",
+		encBase: encBase{ID: 7, Created: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		Price:   1e21,
+		Count:   3,
+		Attrs:   map[string]any{"z": 1, "a": []any{true, nil, 0.5}, "m": map[string]int{"y": 1, "x": 2}},
+		IP:      net.IPv4(10, 0, 0, 1),
+		Data:    []byte("hi"),
+		Byte:    map[int]string{10: "b", 2: "a"},
+		note:    "unexported",
+	}
+
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(item); err != nil {
+		t.Fatalf("Encode() unexpected error: %v", err)
+	}
+	want := `{"name":"<widget>","id":7,"created":"2024-01-02T03:04:05Z","price":1e+21,"count":"3","attrs":{"a":[true,null,0.5],"m":{"x":2,"y":1},"z":1},"ip":"10.0.0.1","data":"aGk=","next":null,"byId":{"10":"b","2":"a"}}` + "\n"
+	if buf.String() != want {
+		t.Errorf("Encode() =\n%s want\n%s", buf.String(), want)
+	}
+
+	// The output decodes strictly, with struct field order required.
+	var back encItem
+	if err := NewDecoder(WithRequireKeyOrder(KeyOrderFields)).Unmarshal(buf.Bytes(), &back); err != nil {
+		t.Errorf("Unmarshal() of encoded output unexpected error: %v", err)
+	}
+
+	// encoding/json agrees, apart from HTML escaping.
+	std, err := json.Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	if err := NewEncoder(&buf, WithEscapeHTML(true)).Encode(item); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSuffix(buf.String(), "\n") != string(std) {
+		t.Errorf("Encode() with WithEscapeHTML =\n%s, encoding/json\n%s", buf.String(), std)
+	}
+
+	buf.Reset()
+	if err := NewEncoder(&buf, WithSortedKeys(true)).Encode(encBase{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"created":"0001-01-01T00:00:00Z","id":1}` + "\n"; buf.String() != want {
+		t.Errorf("Encode() with WithSortedKeys = %s, want %s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := NewEncoder(&buf, WithIndent("", "  ")).Encode(map[string][]int{"b": {1, 2}, "a": nil}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"a\": null,\n  \"b\": [\n    1,\n    2\n  ]\n}\n"; buf.String() != want {
+		t.Errorf("Encode() with WithIndent = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestEncoderDeterministic(t *testing.T) {
+	m := map[string]any{}
+	for _, k := range []string{"q", "w", "e", "r", "t", "y", "u", "i", "o", "p"} {
+		m[k] = map[string]int{k + "1": 1, k + "2": 2, k + "0": 0}
+	}
+	var first bytes.Buffer
+	if err := NewEncoder(&first).Encode(m); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		var again bytes.Buffer
+		if err := NewEncoder(&again).Encode(m); err != nil {
+			t.Fatal(err)
+		}
+		if again.String() != first.String() {
+			t.Fatalf("Encode() output changed:\n%s\n%s", first.String(), again.String())
+		}
+	}
+}
+
+type encRemain struct {
+	Name  string                     `json:"name"`
+	Extra map[string]json.RawMessage `strict:",remain"`
+}
+
+func TestEncoderRemain(t *testing.T) {
+	v := encRemain{Name: "n", Extra: map[string]json.RawMessage{"b": json.RawMessage(`[1, 2]`), "a": json.RawMessage(`{"x": 1}`)}}
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(v); err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"name":"n","a":{"x":1},"b":[1,2]}` + "\n"; buf.String() != want {
+		t.Errorf("Encode() = %s, want %s", buf.String(), want)
+	}
+	var back encRemain
+	if err := Unmarshal(buf.Bytes(), &back); err != nil || len(back.Extra) != 2 {
+		t.Errorf("Unmarshal() = %+v, %v", back, err)
+	}
+}
+
+type failingMarshaler struct{}
+
+func (failingMarshaler) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("boom")
+}
+
+type cyclic struct {
+	Next *cyclic `json:"next"`
+}
+
+func TestEncoderErrors(t *testing.T) {
+	loop := &cyclic{}
+	loop.Next = loop
+	type withChan struct {
+		C chan int `json:"c"`
+	}
+	type Left struct {
+		Name string
+	}
+	type Right struct {
+		Name string
+	}
+	type ambiguous struct {
+		Left
+		Right
+	}
+
+	tests := []struct {
+		name     string
+		v        any
+		wantPath string
+		wantMsg  string
+	}{
+		{"NaN", map[string]any{"x": []float64{1, math.NaN()}}, "x[1]", `strictjson: cannot encode float64 at "x[1]": NaN is not a JSON number`},
+		{"infinity", float32(math.Inf(1)), "", `strictjson: cannot encode float32: +Inf is not a JSON number`},
+		{"channel", withChan{}, "c", `strictjson: cannot encode chan int at "c": unsupported type`},
+		{"invalid UTF-8", []string{"ok", "\xff"}, "[1]", `strictjson: cannot encode string at "[1]": invalid UTF-8 in string`},
+		{"cycle", loop, "next", `strictjson: cannot encode *strictjson.cyclic at "next": cycle of references`},
+		{"marshaler", map[string]any{"m": failingMarshaler{}}, "m", `strictjson: cannot encode strictjson.failingMarshaler at "m": MarshalJSON failed: boom`},
+		{"bad number", json.Number("0x1"), "", `strictjson: cannot encode json.Number: "0x1" is not a JSON number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := NewEncoder(&buf).Encode(tt.v)
+			var ee *EncodeError
+			if !errors.As(err, &ee) || ee.Path() != tt.wantPath {
+				t.Fatalf("Expected *EncodeError at %q, got %v", tt.wantPath, err)
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+			}
+			if !errors.Is(err, ErrEncode) || ErrorCode(err) != CodeEncode {
+				t.Errorf("Expected ErrEncode and code %q, got %q", CodeEncode, ErrorCode(err))
+			}
+			if buf.Len() != 0 {
+				t.Errorf("Encode() wrote %q on failure", buf.String())
+			}
+		})
+	}
+
+	var fce *FieldConflictError
+	if err := NewEncoder(&bytes.Buffer{}).Encode(ambiguous{}); !errors.As(err, &fce) {
+		t.Errorf("Expected *FieldConflictError, got %v", err)
+	}
+	var ite *InvalidTagError
+	if err := NewEncoder(&bytes.Buffer{}).Encode(struct {
+		N int `json:"n" strict:"nonempty"`
+	}{}); !errors.As(err, &ite) {
+		t.Errorf("Expected *InvalidTagError, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package strictjson
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Enumer is implemented by types whose JSON strings are restricted to a
+// fixed set of values. Wherever such a type is decoded, a JSON string that
+// is not exactly one of EnumValues, including one that differs only in
+// case, is rejected with an *EnumError. EnumValues is called once, on the
+// zero value, when the type is first decoded.
+//
+// A string field can be restricted in the same way with the strict tag
+// option oneof, which lists the values separated by "|", as in
+// `strict:"oneof=red|green|blue"`.
+type Enumer interface {
+	EnumValues() []string
+}
+
+var enumerType = reflect.TypeOf((*Enumer)(nil)).Elem()
+
+// enumSet is the set of values of an enum.
+type enumSet struct {
+	values []string
+	// lower holds values lower-cased, built on the first suggestion.
+	lowerOnce sync.Once
+	lower     [][]rune
+}
+
+func newEnumSet(values []string) *enumSet {
+	return &enumSet{values: values}
+}
+
+// typeEnum returns the values of t as an Enumer, or nil if t is not one.
+func typeEnum(t reflect.Type) *enumSet {
+	if !reflect.PointerTo(t).Implements(enumerType) {
+		return nil
+	}
+	return newEnumSet(reflect.New(t).Interface().(Enumer).EnumValues())
+}
+
+// parseOneOf parses the value list of the oneof tag option.
+func parseOneOf(list string) *enumSet {
+	return newEnumSet(strings.Split(list, "|"))
+}
+
+func (e *enumSet) contains(value string) bool {
+	for _, v := range e.values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *enumSet) suggestions(key string, limit, maxDistance int) []string {
+	e.lowerOnce.Do(func() {
+		e.lower = lowerRunes(e.values)
+	})
+	return closestNames(key, e.values, e.lower, limit, maxDistance)
+}
+
+// enumFor returns the enum the value at the current position is restricted
+// to by the oneof option of its field or by its type p, or nil.
+func (s *decodeState) enumFor(p *typePlan) *enumSet {
+	if s.current != nil && s.current.oneof != nil {
+		return s.current.oneof
+	}
+	return p.enum
+}
+
+// checkEnum rejects the JSON string at the scanner, without consuming it,
+// if it is not one of the values of the enum of p. Other values are left to
+// the decode.
+func (s *decodeState) checkEnum(p *typePlan) error {
+	set := s.enumFor(p)
+	if set == nil || s.scan.peek() != '"' {
+		return nil
+	}
+	scan := s.scan
+	raw, escaped, err := scan.readString()
+	if err != nil {
+		return nil
+	}
+	return s.enumValue(set, unquote(raw, escaped))
+}
+
+// enumValue rejects value if it is not in set.
+func (s *decodeState) enumValue(set *enumSet, value string) error {
+	if set.contains(value) {
+		return nil
+	}
+	return s.violation(newEnumError(s.pathString(), value, set.values, s.suggest(set, value)))
+}
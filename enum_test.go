@@ -0,0 +1,113 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type color string
+
+func (color) EnumValues() []string { return []string{"red", "green", "blue"} }
+
+func TestEnumValues(t *testing.T) {
+	type Palette struct {
+		Primary color            `json:"primary"`
+		Accent  *color           `json:"accent"`
+		Others  []color          `json:"others"`
+		ByName  map[string]color `json:"byName"`
+		Size    string           `json:"size" strict:"oneof=S|M|L"`
+		Note    string           `json:"note"`
+	}
+
+	var got Palette
+	data := []byte(`{"primary": "red", "accent": "blue", "others": ["green"], "byName": {"a": "red"}, "size": "M", "note": "free"}`)
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.Primary != "red" || *got.Accent != "blue" || got.Size != "M" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "wrong case",
+			data:     `{"primary": "Red"}`,
+			wantPath: "primary",
+			wantMsg:  `strictjson: value "Red" at "primary" is not "red", "green" or "blue" (did you mean "red"?)`,
+		},
+		{
+			name:     "typo in slice",
+			data:     `{"others": ["green", "bleu"]}`,
+			wantPath: "others[1]",
+			wantMsg:  `strictjson: value "bleu" at "others[1]" is not "red", "green" or "blue" (did you mean "blue"?)`,
+		},
+		{
+			name:     "pointer and map",
+			data:     `{"byName": {"a": "purple"}}`,
+			wantPath: "byName.a",
+			wantMsg:  `strictjson: value "purple" at "byName.a" is not "red", "green" or "blue"`,
+		},
+		{
+			name:     "oneof tag",
+			data:     `{"size": "m"}`,
+			wantPath: "size",
+			wantMsg:  `strictjson: value "m" at "size" is not "S", "M" or "L" (did you mean "M"?)`,
+		},
+	}
+	d := NewDecoder(WithSuggestClosest(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Palette
+			err := d.Unmarshal([]byte(tt.data), &v)
+			verr := d.Validate([]byte(tt.data), &Palette{})
+			var m map[string]any
+			if jerr := json.Unmarshal([]byte(tt.data), &m); jerr != nil {
+				t.Fatal(jerr)
+			}
+			merr := d.DecodeMap(m, &Palette{})
+			for _, err := range []error{err, verr, merr} {
+				var ee *EnumError
+				if !errors.As(err, &ee) || ee.Path() != tt.wantPath {
+					t.Fatalf("Expected *EnumError at %q, got %v", tt.wantPath, err)
+				}
+				if err.Error() != tt.wantMsg {
+					t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+				}
+			}
+		})
+	}
+
+	// Check reports the value and decodes it like encoding/json.
+	var v Palette
+	violations, err := d.Check([]byte(`{"primary": "Red", "size": "XL"}`), &v)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	var paths []string
+	for _, vi := range violations {
+		paths = append(paths, vi.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"primary", "size"}) || v.Primary != "Red" {
+		t.Errorf("Check() = %v, primary %q", paths, v.Primary)
+	}
+	if violations[0].Suggestion != "red" {
+		t.Errorf("Expected suggestion \"red\", got %q", violations[0].Suggestion)
+	}
+}
+
+func TestOneOfTagRequiresString(t *testing.T) {
+	var v struct {
+		N int `json:"n" strict:"oneof=1|2"`
+	}
+	var ite *InvalidTagError
+	if err := Unmarshal([]byte(`{"n": 1}`), &v); !errors.As(err, &ite) {
+		t.Errorf("Expected *InvalidTagError, got %v", err)
+	}
+}
@@ -0,0 +1,440 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// ErrInfo is the structured form of a decoder error. It is what the
+// MarshalJSON methods of this package's errors encode and what an error
+// formatter receives; its JSON names and codes are part of the API.
+type ErrInfo struct {
+	Code string `json:"code"`
+	// Message is the error message. Formatters receive the default English
+	// message.
+	Message     string   `json:"message"`
+	Path        string   `json:"path,omitempty"`
+	Field       string   `json:"field,omitempty"`
+	Suggestion  string   `json:"suggestion,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	Sources     []string `json:"sources,omitempty"`
+	Type        string   `json:"type,omitempty"`
+	Found       string   `json:"found,omitempty"`
+	Value       string   `json:"value,omitempty"`
+	Constraint  string   `json:"constraint,omitempty"`
+	Limit       int      `json:"limit,omitempty"`
+	Offset      int64    `json:"offset,omitempty"`
+	Line        int      `json:"line,omitempty"`
+}
+
+// Error codes reported in the "code" member of serialized errors.
+const (
+	CodeDecode               = "decode_error"
+	CodeEncode               = "encode_error"
+	CodeInvalidInput         = "invalid_input"
+	CodeSyntax               = "syntax_error"
+	CodeNotCanonical         = "not_canonical"
+	CodeTypeMismatch         = "type_mismatch"
+	CodeInvalidNumber        = "invalid_number"
+	CodeUnknownField         = "unknown_field"
+	CodeDuplicateKey         = "duplicate_key"
+	CodeEscapedKey           = "escaped_key"
+	CodeKeyOrder             = "key_order"
+	CodeInvalidKey           = "invalid_key"
+	CodeInvalidValue         = "invalid_value"
+	CodeInvalidEnum          = "invalid_enum"
+	CodeInvalidDiscriminator = "invalid_discriminator"
+	CodeConstraint           = "constraint"
+	CodeSchema               = "schema_violation"
+	CodeNullValue            = "null_value"
+	CodeUnquotedValue        = "unquoted_value"
+	CodeArrayLength          = "array_length"
+	CodeFieldConflict        = "field_conflict"
+	CodeInvalidTag           = "invalid_tag"
+	CodeMaxDepth             = "max_depth"
+	CodeMaxBytes             = "max_bytes"
+	CodeMaxKeys              = "max_keys"
+	CodeMaxElements          = "max_elements"
+)
+
+// infoError is implemented by every error type with a JSON form.
+type infoError interface {
+	error
+	info() ErrInfo
+}
+
+func (e *Error) info() ErrInfo {
+	return ErrInfo{Code: CodeDecode, Message: e.Error()}
+}
+
+func (e *UnmarshalError) info() ErrInfo {
+	return ErrInfo{Code: CodeInvalidInput, Message: e.Error()}
+}
+
+func (e *UnknownFieldError) info() ErrInfo {
+	return ErrInfo{
+		Code:        CodeUnknownField,
+		Message:     e.Error(),
+		Path:        e.path,
+		Field:       e.fieldName,
+		Suggestion:  e.Suggestion(),
+		Suggestions: e.suggestions,
+	}
+}
+
+func (e *FieldConflictError) info() ErrInfo {
+	return ErrInfo{Code: CodeFieldConflict, Message: e.Error(), Field: e.fieldName, Sources: e.sources}
+}
+
+func (e *jsonError) info() ErrInfo {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(e.err, &typeErr):
+		return ErrInfo{
+			Code:    CodeTypeMismatch,
+			Message: e.Error(),
+			Path:    typeErr.Field,
+			Type:    typeErr.Type.String(),
+			Offset:  typeErr.Offset,
+		}
+	case errors.As(e.err, &syntaxErr):
+		return ErrInfo{Code: CodeSyntax, Message: e.Error(), Offset: syntaxErr.Offset}
+	}
+	return ErrInfo{Code: CodeDecode, Message: e.Error()}
+}
+
+func (e *TypeMismatchError) info() ErrInfo {
+	return ErrInfo{
+		Code:    CodeTypeMismatch,
+		Message: e.Error(),
+		Path:    e.Path(),
+		Type:    e.Expected().String(),
+		Found:   e.Found(),
+		Value:   e.value,
+		Offset:  e.Offset(),
+	}
+}
+
+func (e *InvalidKeyError) info() ErrInfo {
+	return ErrInfo{Code: CodeInvalidKey, Message: e.Error(), Path: e.path, Field: e.key, Type: e.typ.String()}
+}
+
+func (e *ValueError) info() ErrInfo {
+	return ErrInfo{Code: CodeInvalidValue, Message: e.Error(), Path: e.path, Type: e.typ.String(), Value: e.value}
+}
+
+func (e *EnumError) info() ErrInfo {
+	return ErrInfo{
+		Code:        CodeInvalidEnum,
+		Message:     e.Error(),
+		Path:        e.path,
+		Value:       e.value,
+		Suggestion:  e.Suggestion(),
+		Suggestions: e.suggestions,
+	}
+}
+
+func (e *DiscriminatorError) info() ErrInfo {
+	return ErrInfo{Code: CodeInvalidDiscriminator, Message: e.Error(), Path: e.path, Value: e.value}
+}
+
+func (e *ConstraintError) info() ErrInfo {
+	return ErrInfo{Code: CodeConstraint, Message: e.Error(), Path: e.path, Type: e.typ.String(), Constraint: e.constraint}
+}
+
+func (e *SchemaError) info() ErrInfo {
+	return ErrInfo{Code: CodeSchema, Message: e.Error(), Path: e.path, Constraint: e.keyword}
+}
+
+func (e *EncodeError) info() ErrInfo {
+	return ErrInfo{Code: CodeEncode, Message: e.Error(), Path: e.path, Type: e.typ.String()}
+}
+
+func (e *NumberError) info() ErrInfo {
+	return ErrInfo{Code: CodeInvalidNumber, Message: e.Error(), Path: e.path, Type: e.typ.String(), Value: e.value}
+}
+
+func (e *SyntaxError) info() ErrInfo {
+	return ErrInfo{Code: CodeSyntax, Message: e.Error(), Offset: e.Offset}
+}
+
+func (e *CanonicalError) info() ErrInfo {
+	return ErrInfo{Code: CodeNotCanonical, Message: e.Error(), Path: e.path, Offset: e.offset}
+}
+
+func (e *DuplicateKeyError) info() ErrInfo {
+	return ErrInfo{Code: CodeDuplicateKey, Message: e.Error(), Path: e.path, Field: e.key}
+}
+
+func (e *EscapedKeyError) info() ErrInfo {
+	return ErrInfo{Code: CodeEscapedKey, Message: e.Error(), Path: e.path, Field: e.key}
+}
+
+func (e *KeyOrderError) info() ErrInfo {
+	return ErrInfo{Code: CodeKeyOrder, Message: e.Error(), Path: e.path, Field: e.key}
+}
+
+func (e *NullValueError) info() ErrInfo {
+	return ErrInfo{Code: CodeNullValue, Message: e.Error(), Path: e.path, Type: e.typ.String()}
+}
+
+func (e *UnquotedValueError) info() ErrInfo {
+	return ErrInfo{Code: CodeUnquotedValue, Message: e.Error(), Path: e.path, Type: e.typ.String(), Found: e.found}
+}
+
+func (e *ArrayLengthError) info() ErrInfo {
+	return ErrInfo{
+		Code:    CodeArrayLength,
+		Message: e.Error(),
+		Path:    e.path,
+		Type:    e.typ.String(),
+		Found:   strconv.Itoa(e.found) + " elements",
+		Limit:   e.typ.Len(),
+	}
+}
+
+func (e *InvalidTagError) info() ErrInfo {
+	return ErrInfo{Code: CodeInvalidTag, Message: e.Error(), Field: e.field}
+}
+
+func (e *MaxDepthError) info() ErrInfo {
+	return ErrInfo{Code: CodeMaxDepth, Message: e.Error(), Path: e.path, Limit: e.limit}
+}
+
+func (e *LimitError) info() ErrInfo {
+	code := CodeMaxElements
+	switch e.kind {
+	case LimitBytes:
+		code = CodeMaxBytes
+	case LimitKeys:
+		code = CodeMaxKeys
+	}
+	return ErrInfo{Code: code, Message: e.Error(), Path: e.path, Limit: e.limit}
+}
+
+func (e *LineError) info() ErrInfo {
+	info := errorInfo(e.err)
+	info.Message = e.Error()
+	info.Line = e.Line
+	return info
+}
+
+func (e *ScanError) info() ErrInfo {
+	info := errorInfo(e.err)
+	info.Message = e.Error()
+	return info
+}
+
+// ErrorCode returns the code of err as reported in its JSON form, such as
+// CodeUnknownField, or "" if err is nil. Errors from outside this package
+// have code CodeDecode.
+func ErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+	return errorInfo(err).Code
+}
+
+// errorInfo returns the JSON form of err, which need not come from this
+// package.
+func errorInfo(err error) ErrInfo {
+	var ie infoError
+	if errors.As(err, &ie) {
+		return ie.info()
+	}
+	return ErrInfo{Code: CodeDecode, Message: err.Error()}
+}
+
+// MarshalJSON encodes the error as an object with a stable "code" member
+// and the location details of the failure. Every error type in this package
+// encodes the same way.
+func (e *Error) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *UnmarshalError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *UnknownFieldError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *FieldConflictError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *jsonError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *TypeMismatchError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *InvalidKeyError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *ValueError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *EnumError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *DiscriminatorError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *ConstraintError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *SchemaError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *EncodeError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *NumberError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *SyntaxError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *CanonicalError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *DuplicateKeyError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *KeyOrderError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *EscapedKeyError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *NullValueError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *UnquotedValueError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *ArrayLengthError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *InvalidTagError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *MaxDepthError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *LimitError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *LineError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+func (e *ScanError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+// MarshalJSON encodes the violation like its Err.
+func (v Violation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(errorInfo(v.Err))
+}
+
+// ErrorsToJSON encodes err as a JSON array with one object per failure, in
+// the form produced by the MarshalJSON methods of this package's errors.
+// Joined errors, such as those from errors.Join, yield one object each;
+// wrapping added by fmt.Errorf is looked through. A nil err encodes as [].
+func ErrorsToJSON(err error) ([]byte, error) {
+	infos := []ErrInfo{}
+	var walk func(error)
+	walk = func(err error) {
+		if ie, ok := err.(infoError); ok {
+			infos = append(infos, ie.info())
+			return
+		}
+		switch u := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range u.Unwrap() {
+				walk(e)
+			}
+		case interface{ Unwrap() error }:
+			if inner := u.Unwrap(); inner != nil {
+				walk(inner)
+				return
+			}
+			infos = append(infos, errorInfo(err))
+		default:
+			infos = append(infos, errorInfo(err))
+		}
+	}
+	if err != nil {
+		walk(err)
+	}
+	return json.Marshal(infos)
+}
+
+// formattedError replaces the message of a decoder error with the one
+// produced by the decoder's ErrorFormatter. The original error remains
+// reachable through errors.As.
+type formattedError struct {
+	err error
+	msg string
+}
+
+func (e *formattedError) Error() string {
+	return e.msg
+}
+
+func (e *formattedError) Unwrap() error {
+	return e.err
+}
+
+func (e *formattedError) info() ErrInfo {
+	info := errorInfo(e.err)
+	info.Message = e.msg
+	return info
+}
+
+func (e *formattedError) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.info())
+}
+
+// formatError applies d's ErrorFormatter to err if err is one of this
+// package's errors. Other errors, such as those returned by an
+// OnUnknownField callback, are returned unchanged.
+func (d *Decoder) formatError(err error) error {
+	if d.ErrorFormatter == nil {
+		return err
+	}
+	ie, ok := err.(infoError)
+	if !ok {
+		return err
+	}
+	if _, done := err.(*formattedError); done {
+		return err
+	}
+	return &formattedError{err: err, msg: d.ErrorFormatter(ie.info())}
+}
@@ -0,0 +1,222 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestErrorMarshalJSON(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Contact struct {
+		Address Address `json:"address"`
+	}
+	type Person struct {
+		Age     int      `json:"age"`
+		Contact Contact  `json:"contact"`
+		Tags    []string `json:"tags"`
+		Pair    [2]int   `json:"pair"`
+		Payment payment  `json:"payment"`
+	}
+
+	tests := []struct {
+		name string
+		opts []DecoderOption
+		data string
+		want string
+	}{
+		{
+			name: "unknown field",
+			opts: []DecoderOption{WithSuggestClosest(true)},
+			data: `{"contact": {"address": {"CITY": "NYC"}}}`,
+			want: `{"code":"unknown_field","message":"strictjson: unknown field \"CITY\" (did you mean \"city\"?)","path":"contact.address.CITY","field":"CITY","suggestion":"city","suggestions":["city"]}`,
+		},
+		{
+			name: "duplicate key",
+			opts: []DecoderOption{WithDisallowDuplicateKeys(true)},
+			data: `{"age": 1, "age": 2}`,
+			want: `{"code":"duplicate_key","message":"strictjson: duplicate key \"age\" at \"age\"","path":"age","field":"age"}`,
+		},
+		{
+			name: "escaped key",
+			opts: []DecoderOption{WithRejectEscapedKeys(true)},
+			data: `{"\u0061ge": 1}`,
+			want: `{"code":"escaped_key","message":"strictjson: key \"age\" at \"age\" is written with escape sequences","path":"age","field":"age"}`,
+		},
+		{
+			name: "discriminator",
+			data: `{"payment": {"type": "cash"}}`,
+			want: `{"code":"invalid_discriminator","message":"strictjson: discriminator at \"payment.type\" is \"cash\", not \"bank\" or \"card\"","path":"payment.type","value":"cash"}`,
+		},
+		{
+			name: "type mismatch",
+			data: `{"age": "thirty"}`,
+			want: `{"code":"type_mismatch","message":"strictjson: cannot decode JSON string into Go int at \"age\"","path":"age","type":"int","found":"string","offset":16}`,
+		},
+		{
+			name: "syntax",
+			data: `{"age": }`,
+			want: `{"code":"syntax_error","message":"strictjson: invalid character '}' looking for beginning of value","offset":8}`,
+		},
+		{
+			name: "element limit",
+			opts: []DecoderOption{WithMaxElements(1)},
+			data: `{"tags": ["a", "b"]}`,
+			want: `{"code":"max_elements","message":"strictjson: array at \"tags\" exceeds max of 1 elements","path":"tags","limit":1}`,
+		},
+		{
+			name: "array length",
+			opts: []DecoderOption{WithStrictArrayLength(true)},
+			data: `{"pair": [1, 2, 3]}`,
+			want: `{"code":"array_length","message":"strictjson: array at \"pair\" has 3 elements, [2]int requires 2","path":"pair","type":"[2]int","found":"3 elements","limit":2}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Person
+			err := NewDecoder(tt.opts...).Unmarshal([]byte(tt.data), &p)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			got, merr := json.Marshal(err)
+			if merr != nil {
+				t.Fatalf("json.Marshal() error = %v", merr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorsToJSON(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+
+	d := NewDecoder(WithSuggestClosest(true))
+	var it Item
+	violations, err := d.Check([]byte(`{"Name": "a", "extra": 1}`), &it)
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	var errs []error
+	for _, v := range violations {
+		errs = append(errs, v.Err)
+	}
+	joined := fmt.Errorf("request: %w", errors.Join(append(errs, errors.New("upstream timeout"))...))
+
+	got, err := ErrorsToJSON(joined)
+	if err != nil {
+		t.Fatalf("ErrorsToJSON() error = %v", err)
+	}
+	var infos []map[string]any
+	if err := json.Unmarshal(got, &infos); err != nil {
+		t.Fatalf("ErrorsToJSON() produced invalid JSON %s: %v", got, err)
+	}
+	var codes []string
+	for _, info := range infos {
+		codes = append(codes, info["code"].(string)+"|"+fmt.Sprint(info["path"]))
+	}
+	if want := "unknown_field|Name unknown_field|extra decode_error|<nil>"; strings.Join(codes, " ") != want {
+		t.Errorf("ErrorsToJSON() codes = %q, want %q", strings.Join(codes, " "), want)
+	}
+
+	if got, _ := ErrorsToJSON(nil); string(got) != "[]" {
+		t.Errorf("ErrorsToJSON(nil) = %s, want []", got)
+	}
+
+	v, _ := json.Marshal(violations[0])
+	if !strings.HasPrefix(string(v), `{"code":"unknown_field"`) {
+		t.Errorf("Violation.MarshalJSON() = %s", v)
+	}
+}
+
+func TestLineErrorMarshalJSON(t *testing.T) {
+	type Rec struct {
+		ID int `json:"id"`
+	}
+	ld := NewLinesDecoder(strings.NewReader("{\"id\": 1}\n{\"ID\": 2}\n"))
+	var r Rec
+	var err error
+	for err == nil {
+		err = ld.Next(&r)
+	}
+	got, _ := json.Marshal(err)
+	want := `{"code":"unknown_field","message":"strictjson: line 2: strictjson: unknown or mis-cased field \"ID\"","path":"ID","field":"ID","line":2}`
+	if string(got) != want {
+		t.Errorf("json.Marshal() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestErrorFormatter(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	german := func(info ErrInfo) string {
+		switch info.Code {
+		case CodeUnknownField:
+			return fmt.Sprintf("E1001: unbekanntes Feld %q (meinten Sie %q?)", info.Field, info.Suggestion)
+		case CodeDuplicateKey:
+			return fmt.Sprintf("E1002: doppelter Schlüssel %q", info.Field)
+		}
+		return info.Message
+	}
+	d := NewDecoder(WithSuggestClosest(true), WithDisallowDuplicateKeys(true), WithErrorFormatter(german))
+
+	var p Person
+	err := d.Unmarshal([]byte(`{"Name": "x"}`), &p)
+	if err == nil || err.Error() != `E1001: unbekanntes Feld "Name" (meinten Sie "name"?)` {
+		t.Fatalf("Unexpected error message: %v", err)
+	}
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Suggestion() != "name" || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected formatted error to wrap *UnknownFieldError, got %#v", err)
+	}
+	got, _ := json.Marshal(err)
+	if !strings.Contains(string(got), `"code":"unknown_field","message":"E1001: unbekanntes Feld`) {
+		t.Errorf("json.Marshal() = %s", got)
+	}
+
+	violations, err := d.Check([]byte(`{"name": "a", "name": "b"}`), &p)
+	if err != nil || len(violations) != 1 || violations[0].Err.Error() != `E1002: doppelter Schlüssel "name"` {
+		t.Errorf("Check() = %v, %v", violations, err)
+	}
+
+	// Errors from callbacks are returned unchanged.
+	errStop := errors.New("stop")
+	d = NewDecoder(WithErrorFormatter(german), WithOnUnknownField(func(path, key, suggestion string) error {
+		return errStop
+	}))
+	if err := d.Unmarshal([]byte(`{"x": 1}`), &p); err != errStop {
+		t.Errorf("Expected callback error unchanged, got %v", err)
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	type Item struct {
+		N int `json:"n"`
+	}
+	var it Item
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{Unmarshal([]byte(`{"N": 1}`), &it), CodeUnknownField},
+		{Unmarshal([]byte(`{"n": "x"}`), &it), CodeTypeMismatch},
+		{fmt.Errorf("wrapped: %w", Unmarshal([]byte(`{`), &it)), CodeSyntax},
+		{errors.New("other"), CodeDecode},
+	}
+	for _, tt := range tests {
+		if got := ErrorCode(tt.err); got != tt.want {
+			t.Errorf("ErrorCode(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -3,12 +3,37 @@
 // implementations
 package strictjson
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
 
 const (
 	errPrefixNonPointer = "strictjson: Unmarshal(non-pointer)"
 )
 
+// Error is the common sentinel wrapped by every error the decoder returns,
+// ErrDecode, and by every error the encoder returns, ErrEncode. Use
+// errors.Is(err, ErrDecode) or errors.As with a *Error target to tell
+// strictjson failures apart from other errors.
+type Error struct {
+	message string
+}
+
+func (e *Error) Error() string {
+	return e.message
+}
+
+// ErrDecode is the *Error wrapped by all decoder errors.
+var ErrDecode = &Error{message: "strictjson: decode error"}
+
+// ErrEncode is the *Error wrapped by all encoder errors.
+var ErrEncode = &Error{message: "strictjson: encode error"}
+
 type UnmarshalError struct {
 	message string
 }
@@ -17,37 +42,895 @@ func (e *UnmarshalError) Error() string {
 	return e.message
 }
 
+func (e *UnmarshalError) Unwrap() error {
+	return ErrDecode
+}
+
 func newNonPointerError() error {
 	return &UnmarshalError{message: errPrefixNonPointer}
 }
 
-type unknownFieldError struct {
-	fieldName  string
-	suggestion string
+// UnknownFieldError reports a JSON key that matches no struct field exactly.
+type UnknownFieldError struct {
+	fieldName   string
+	suggestions []string
+	path        string
 }
 
-func (e *unknownFieldError) Error() string {
-	if e.suggestion != "" {
-		return fmt.Sprintf(`strictjson: unknown field "%s" (did you mean "%s"?)`, e.fieldName, e.suggestion)
+func (e *UnknownFieldError) Error() string {
+	if len(e.suggestions) > 0 {
+		return fmt.Sprintf(`strictjson: unknown field "%s" (did you mean %s?)`, e.fieldName, quoteAlternatives(e.suggestions))
 	}
 	return fmt.Sprintf(`strictjson: unknown or mis-cased field "%s"`, e.fieldName)
 }
 
-func newUnknownFieldError(fieldName, suggestion string) error {
-	return &unknownFieldError{
-		fieldName:  fieldName,
-		suggestion: suggestion,
+// quoteAlternatives formats names as `"a"`, `"a" or "b"` or `"a", "b" or "c"`.
+func quoteAlternatives(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = `"` + name + `"`
 	}
+	last := len(quoted) - 1
+	if last == 0 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:last], ", ") + " or " + quoted[last]
+}
+
+func (e *UnknownFieldError) Unwrap() error {
+	return ErrDecode
+}
+
+// Field returns the offending JSON key as it appeared in the input.
+func (e *UnknownFieldError) Field() string {
+	return e.fieldName
+}
+
+// Suggestion returns the closest known field name, or "" if none was found
+// or suggestions are disabled.
+func (e *UnknownFieldError) Suggestion() string {
+	if len(e.suggestions) == 0 {
+		return ""
+	}
+	return e.suggestions[0]
+}
+
+// Suggestions returns the closest known field names, best first. It holds at
+// most SuggestionLimit names.
+func (e *UnknownFieldError) Suggestions() []string {
+	return e.suggestions
 }
 
-type fieldConflictError struct {
+// Path returns the location of the key in the document, e.g.
+// "contact.address.CITY" or "items[2].Name".
+func (e *UnknownFieldError) Path() string {
+	return e.path
+}
+
+func newUnknownFieldError(fieldName string, suggestions []string, path string) error {
+	return &UnknownFieldError{
+		fieldName:   fieldName,
+		suggestions: suggestions,
+		path:        path,
+	}
+}
+
+// FieldConflictError reports a JSON name provided by more than one embedded
+// struct at the same depth, or by a field and an alias registered for
+// another field with RegisterAlias.
+type FieldConflictError struct {
 	fieldName string
+	sources   []string
+}
+
+func (e *FieldConflictError) Error() string {
+	if len(e.sources) == 2 {
+		return fmt.Sprintf(`strictjson: field conflict: "%s" provided by both %s and %s`, e.fieldName, e.sources[0], e.sources[1])
+	}
+	last := len(e.sources) - 1
+	return fmt.Sprintf(`strictjson: field conflict: "%s" provided by %s and %s`, e.fieldName, strings.Join(e.sources[:last], ", "), e.sources[last])
+}
+
+func (e *FieldConflictError) Unwrap() error {
+	return ErrDecode
+}
+
+// Field returns the conflicting JSON name.
+func (e *FieldConflictError) Field() string {
+	return e.fieldName
+}
+
+// Sources returns the Go paths of the conflicting fields, such as "A.Name"
+// and "B.Name", in declaration order.
+func (e *FieldConflictError) Sources() []string {
+	return e.sources
+}
+
+// jsonError wraps errors reported by encoding/json so they also match
+// ErrDecode while remaining reachable through errors.As.
+type jsonError struct {
+	err error
+}
+
+func (e *jsonError) Error() string {
+	return e.err.Error()
+}
+
+func (e *jsonError) Unwrap() []error {
+	return []error{e.err, ErrDecode}
+}
+
+func wrapJSONError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &jsonError{err: err}
+}
+
+// SyntaxError reports malformed JSON input.
+type SyntaxError struct {
+	msg    string
+	Offset int64 // byte offset at which the error was detected
+}
+
+func (e *SyntaxError) Error() string {
+	return "strictjson: " + e.msg
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return ErrDecode
+}
+
+func newSyntaxError(msg string, offset int64) error {
+	return &SyntaxError{msg: msg, Offset: offset}
+}
+
+// CanonicalError reports input that is not in the canonical form of RFC
+// 8785, with WithRequireCanonical, or a number that Canonicalize cannot
+// write in it.
+type CanonicalError struct {
+	path   string
+	offset int64
+	reason string
+}
+
+func (e *CanonicalError) Error() string {
+	if e.path == "" {
+		return fmt.Sprintf("strictjson: non-canonical JSON at offset %d: %s", e.offset, e.reason)
+	}
+	return fmt.Sprintf(`strictjson: non-canonical JSON at offset %d ("%s"): %s`, e.offset, e.path, e.reason)
+}
+
+func (e *CanonicalError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the offending value.
+func (e *CanonicalError) Path() string {
+	return e.path
+}
+
+// Offset returns the byte offset of the offending value or whitespace.
+func (e *CanonicalError) Offset() int64 {
+	return e.offset
+}
+
+// Reason describes how the input departs from canonical form.
+func (e *CanonicalError) Reason() string {
+	return e.reason
+}
+
+func newCanonicalError(path string, offset int64, reason string) error {
+	return &CanonicalError{path: path, offset: offset, reason: reason}
+}
+
+// DuplicateKeyError reports a key that appears more than once in an object.
+type DuplicateKeyError struct {
+	key  string
+	path string
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf(`strictjson: duplicate key "%s" at "%s"`, e.key, e.path)
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDecode
+}
+
+// Key returns the repeated key.
+func (e *DuplicateKeyError) Key() string {
+	return e.key
+}
+
+// Path returns the location of the second occurrence of the key.
+func (e *DuplicateKeyError) Path() string {
+	return e.path
+}
+
+func newDuplicateKeyError(key, path string) error {
+	return &DuplicateKeyError{key: key, path: path}
+}
+
+// EscapedKeyError reports an object key written with escape sequences, such
+// as "\u0063ity" for "city", when WithRejectEscapedKeys is set.
+type EscapedKeyError struct {
+	key  string
+	path string
+}
+
+func (e *EscapedKeyError) Error() string {
+	return fmt.Sprintf(`strictjson: key "%s" at "%s" is written with escape sequences`, e.key, e.path)
+}
+
+func (e *EscapedKeyError) Unwrap() error {
+	return ErrDecode
+}
+
+// Key returns the key after unescaping.
+func (e *EscapedKeyError) Key() string {
+	return e.key
+}
+
+// Path returns the location of the key.
+func (e *EscapedKeyError) Path() string {
+	return e.path
+}
+
+func newEscapedKeyError(key, path string) error {
+	return &EscapedKeyError{key: key, path: path}
+}
+
+// KeyOrderError reports an object key that breaks the order required by
+// WithRequireKeyOrder.
+type KeyOrderError struct {
+	key  string
+	prev string
+	path string
+}
+
+func (e *KeyOrderError) Error() string {
+	return fmt.Sprintf(`strictjson: key "%s" at "%s" is out of order after "%s"`, e.key, e.path, e.prev)
+}
+
+func (e *KeyOrderError) Unwrap() error {
+	return ErrDecode
+}
+
+// Key returns the key that is out of order.
+func (e *KeyOrderError) Key() string {
+	return e.key
+}
+
+// Previous returns the earlier key that Key should have preceded.
+func (e *KeyOrderError) Previous() string {
+	return e.prev
+}
+
+// Path returns the location of the key.
+func (e *KeyOrderError) Path() string {
+	return e.path
+}
+
+func newKeyOrderError(key, prev, path string) error {
+	return &KeyOrderError{key: key, prev: prev, path: path}
+}
+
+// NullValueError reports a null assigned to a value that cannot represent it.
+type NullValueError struct {
+	path string
+	typ  reflect.Type
+}
+
+func (e *NullValueError) Error() string {
+	if e.path == "" {
+		return fmt.Sprintf("strictjson: null is not allowed at top level for type %s", e.typ)
+	}
+	return fmt.Sprintf(`strictjson: null is not allowed for field "%s" of type %s`, e.path, e.typ)
+}
+
+func (e *NullValueError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the null value.
+func (e *NullValueError) Path() string {
+	return e.path
+}
+
+// Type returns the Go type that received the null.
+func (e *NullValueError) Type() reflect.Type {
+	return e.typ
+}
+
+func newNullValueError(path string, typ reflect.Type) error {
+	return &NullValueError{path: path, typ: typ}
+}
+
+// UnquotedValueError reports a bare value for a field with the ,string tag
+// option when the decoder's StrictStringOption is set.
+type UnquotedValueError struct {
+	path  string
+	typ   reflect.Type
+	found string
+}
+
+func (e *UnquotedValueError) Error() string {
+	return fmt.Sprintf(`strictjson: field "%s" of type %s requires a quoted value, got %s`, e.path, e.typ, e.found)
+}
+
+func (e *UnquotedValueError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the unquoted value.
+func (e *UnquotedValueError) Path() string {
+	return e.path
+}
+
+// Type returns the Go type of the field.
+func (e *UnquotedValueError) Type() reflect.Type {
+	return e.typ
+}
+
+// Found returns the JSON kind of the value, such as "number" or "bool".
+func (e *UnquotedValueError) Found() string {
+	return e.found
+}
+
+func newUnquotedValueError(path string, typ reflect.Type, found string) error {
+	return &UnquotedValueError{path: path, typ: typ, found: found}
+}
+
+// ArrayLengthError reports a JSON array decoded into a Go array of another
+// length when the decoder's StrictArrayLength is set.
+type ArrayLengthError struct {
+	path  string
+	typ   reflect.Type
+	found int
+}
+
+func (e *ArrayLengthError) Error() string {
+	return fmt.Sprintf(`strictjson: array at "%s" has %d elements, %s requires %d`, e.path, e.found, e.typ, e.typ.Len())
+}
+
+func (e *ArrayLengthError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the array.
+func (e *ArrayLengthError) Path() string {
+	return e.path
+}
+
+// Type returns the Go array type.
+func (e *ArrayLengthError) Type() reflect.Type {
+	return e.typ
+}
+
+// Len returns the number of elements the Go array requires.
+func (e *ArrayLengthError) Len() int {
+	return e.typ.Len()
+}
+
+// Found returns the number of elements of the JSON array.
+func (e *ArrayLengthError) Found() int {
+	return e.found
+}
+
+func newArrayLengthError(path string, typ reflect.Type, found int) error {
+	return &ArrayLengthError{path: path, typ: typ, found: found}
+}
+
+// InvalidTagError reports a struct tag that strictjson cannot honor.
+type InvalidTagError struct {
+	field  string
+	reason string
+}
+
+func (e *InvalidTagError) Error() string {
+	return fmt.Sprintf(`strictjson: invalid tag on field "%s": %s`, e.field, e.reason)
+}
+
+func (e *InvalidTagError) Unwrap() error {
+	return ErrDecode
+}
+
+// Field returns the Go name of the offending struct field.
+func (e *InvalidTagError) Field() string {
+	return e.field
+}
+
+func newInvalidTagError(field, reason string) error {
+	return &InvalidTagError{field: field, reason: reason}
+}
+
+// MaxDepthError reports a document nested deeper than the decoder's MaxDepth.
+type MaxDepthError struct {
+	path  string
+	limit int
+}
+
+func (e *MaxDepthError) Error() string {
+	return fmt.Sprintf(`strictjson: exceeded max depth %d at "%s"`, e.limit, e.path)
+}
+
+func (e *MaxDepthError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the value whose nesting exceeds the limit.
+func (e *MaxDepthError) Path() string {
+	return e.path
+}
+
+// Limit returns the configured maximum depth.
+func (e *MaxDepthError) Limit() int {
+	return e.limit
+}
+
+func newMaxDepthError(path string, limit int) error {
+	return &MaxDepthError{path: path, limit: limit}
+}
+
+// LimitKind identifies the resource limit a *LimitError reports.
+type LimitKind int
+
+const (
+	LimitBytes    LimitKind = iota // input length, see WithMaxBytes
+	LimitKeys                      // keys per object, see WithMaxKeysPerObject
+	LimitElements                  // array elements, see WithMaxElements
+)
+
+// LimitError reports input that exceeds one of the decoder's size limits.
+type LimitError struct {
+	kind  LimitKind
+	path  string
+	limit int
+}
+
+func (e *LimitError) Error() string {
+	switch e.kind {
+	case LimitBytes:
+		return fmt.Sprintf("strictjson: input exceeds max size of %d bytes", e.limit)
+	case LimitKeys:
+		return fmt.Sprintf(`strictjson: object at "%s" exceeds max of %d keys`, e.path, e.limit)
+	default:
+		return fmt.Sprintf(`strictjson: array at "%s" exceeds max of %d elements`, e.path, e.limit)
+	}
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrDecode
+}
+
+// Kind returns the limit that was exceeded.
+func (e *LimitError) Kind() LimitKind {
+	return e.kind
+}
+
+// Path returns the location of the object or array that exceeds the limit.
+// It is empty for LimitBytes.
+func (e *LimitError) Path() string {
+	return e.path
+}
+
+// Limit returns the configured maximum.
+func (e *LimitError) Limit() int {
+	return e.limit
+}
+
+func newLimitError(kind LimitKind, path string, limit int) error {
+	return &LimitError{kind: kind, path: path, limit: limit}
+}
+
+// InvalidKeyError reports an object key that cannot be decoded into the key
+// type of the map it belongs to, such as "abc" for a map[int]T or a key
+// rejected by the UnmarshalText method of the key type.
+type InvalidKeyError struct {
+	key  string
+	path string
+	typ  reflect.Type
+	err  error
+}
+
+func (e *InvalidKeyError) Error() string {
+	msg := fmt.Sprintf(`strictjson: key "%s" at "%s" is not a valid Go %s`, e.key, e.path, e.typ)
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+func (e *InvalidKeyError) Unwrap() []error {
+	if e.err != nil {
+		return []error{ErrDecode, e.err}
+	}
+	return []error{ErrDecode}
+}
+
+// Key returns the key.
+func (e *InvalidKeyError) Key() string {
+	return e.key
+}
+
+// Path returns the location of the key.
+func (e *InvalidKeyError) Path() string {
+	return e.path
+}
+
+// Type returns the key type of the map.
+func (e *InvalidKeyError) Type() reflect.Type {
+	return e.typ
+}
+
+func newInvalidKeyError(key, path string, typ reflect.Type, err error) error {
+	return &InvalidKeyError{key: key, path: path, typ: typ, err: err}
+}
+
+// ValueError reports a value that the decoder for the type at its location
+// rejected, such as a DecodeFunc registered with RegisterDecoder. The
+// decoder's error, if any, is reachable with errors.As.
+type ValueError struct {
+	path   string
+	typ    reflect.Type
+	value  string
+	reason string
+	err    error
+}
+
+func (e *ValueError) Error() string {
+	msg := fmt.Sprintf(`strictjson: value at "%s" %s`, e.path, e.reason)
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+func (e *ValueError) Unwrap() []error {
+	if e.err != nil {
+		return []error{ErrDecode, e.err}
+	}
+	return []error{ErrDecode}
+}
+
+// Path returns the location of the value.
+func (e *ValueError) Path() string {
+	return e.path
+}
+
+// Type returns the Go type the value was decoded into.
+func (e *ValueError) Type() reflect.Type {
+	return e.typ
+}
+
+// Value returns an excerpt of the value as written, if ErrorValueSnippets
+// is set, or "".
+func (e *ValueError) Value() string {
+	return e.value
+}
+
+func newValueError(path string, typ reflect.Type, value, reason string, err error) error {
+	return &ValueError{path: path, typ: typ, value: value, reason: reason, err: err}
+}
+
+// EnumError reports a string outside the values allowed by the Enumer type
+// or the oneof tag option at its location. Values must match exactly,
+// including case.
+type EnumError struct {
+	path        string
+	value       string
+	allowed     []string
+	suggestions []string
+}
+
+func (e *EnumError) Error() string {
+	msg := fmt.Sprintf(`strictjson: value "%s" at "%s" is not %s`, e.value, e.path, quoteAlternatives(e.allowed))
+	if len(e.suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", quoteAlternatives(e.suggestions))
+	}
+	return msg
+}
+
+func (e *EnumError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the value.
+func (e *EnumError) Path() string {
+	return e.path
+}
+
+// Value returns the rejected string.
+func (e *EnumError) Value() string {
+	return e.value
+}
+
+// Allowed returns the values allowed at the location.
+func (e *EnumError) Allowed() []string {
+	return e.allowed
+}
+
+// Suggestion returns the closest allowed value, or "" if none was found or
+// suggestions are disabled.
+func (e *EnumError) Suggestion() string {
+	if len(e.suggestions) == 0 {
+		return ""
+	}
+	return e.suggestions[0]
+}
+
+// Suggestions returns the closest allowed values, best first.
+func (e *EnumError) Suggestions() []string {
+	return e.suggestions
+}
+
+func newEnumError(path, value string, allowed, suggestions []string) error {
+	return &EnumError{path: path, value: value, allowed: allowed, suggestions: suggestions}
+}
+
+// DiscriminatorError reports an object decoded into an interface registered
+// with RegisterUnion whose discriminator member is missing, is not a string,
+// or has a value that selects no type.
+type DiscriminatorError struct {
+	path   string
+	value  string
+	reason string
+}
+
+func (e *DiscriminatorError) Error() string {
+	return fmt.Sprintf(`strictjson: discriminator at "%s" %s`, e.path, e.reason)
+}
+
+func (e *DiscriminatorError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the discriminator member, such as
+// "payment.type", also when it is missing.
+func (e *DiscriminatorError) Path() string {
+	return e.path
+}
+
+// Value returns the value of the discriminator: the string if it is one,
+// otherwise the JSON value as written, and "" if it is missing.
+func (e *DiscriminatorError) Value() string {
+	return e.value
+}
+
+func newDiscriminatorError(path, value, reason string) error {
+	return &DiscriminatorError{path: path, value: value, reason: reason}
+}
+
+// ConstraintError reports a field value that breaks a constraint set by
+// its strict tag, such as strict:"nonempty".
+type ConstraintError struct {
+	path       string
+	typ        reflect.Type
+	constraint string
+	reason     string
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf(`strictjson: value at "%s" %s (strict:"%s")`, e.path, e.reason, e.constraint)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the value.
+func (e *ConstraintError) Path() string {
+	return e.path
+}
+
+// Type returns the Go type of the field.
+func (e *ConstraintError) Type() reflect.Type {
+	return e.typ
+}
+
+// Constraint returns the strict tag option the value breaks, such as
+// "nonzero".
+func (e *ConstraintError) Constraint() string {
+	return e.constraint
+}
+
+func newConstraintError(path string, typ reflect.Type, constraint, reason string) error {
+	return &ConstraintError{path: path, typ: typ, constraint: constraint, reason: reason}
+}
+
+// NumberError reports a JSON number that does not fit the numeric Go type
+// at its location, when WithStrictNumbers is set: a fraction or exponent for
+// an integer type, or a value outside the type's range. With
+// WithRejectLossyFloats it also reports a number a float type can only
+// approximate. It also reports a number rejected by the UnmarshalText method
+// of the type it is decoded into, such as *big.Float, whatever the options;
+// that error is then reachable with errors.As.
+type NumberError struct {
+	path   string
+	typ    reflect.Type
+	value  string
+	reason string
+	err    error
+}
+
+func (e *NumberError) Error() string {
+	msg := "strictjson: number " + e.value
+	if e.path != "" {
+		msg += fmt.Sprintf(` at "%s"`, e.path)
+	}
+	return msg + " " + e.reason
+}
+
+func (e *NumberError) Unwrap() []error {
+	if e.err != nil {
+		return []error{ErrDecode, e.err}
+	}
+	return []error{ErrDecode}
+}
+
+// Path returns the location of the number.
+func (e *NumberError) Path() string {
+	return e.path
+}
+
+// Type returns the Go type the number did not fit.
+func (e *NumberError) Type() reflect.Type {
+	return e.typ
+}
+
+// Value returns the number as written in the input.
+func (e *NumberError) Value() string {
+	return e.value
+}
+
+func newNumberError(path string, typ reflect.Type, value string) error {
+	return &NumberError{path: path, typ: typ, value: value, reason: numberReason(value, typ)}
+}
+
+func newInvalidNumberError(path string, typ reflect.Type, value string, err error) error {
+	return &NumberError{path: path, typ: typ, value: value, reason: "is not valid for Go " + typ.String() + ": " + err.Error(), err: err}
+}
+
+func newLossyFloatError(path string, typ reflect.Type, value string) error {
+	return &NumberError{path: path, typ: typ, value: value, reason: "cannot be represented exactly in Go " + typ.String()}
+}
+
+// TypeMismatchError reports a JSON value whose kind cannot be decoded into
+// the Go type at its location, such as a string for an int field. The
+// underlying *json.UnmarshalTypeError remains reachable through errors.As,
+// with its Field and Offset made absolute.
+type TypeMismatchError struct {
+	err   *json.UnmarshalTypeError
+	value string
+}
+
+func (e *TypeMismatchError) Error() string {
+	msg := fmt.Sprintf("strictjson: cannot decode JSON %s into Go %s", e.Found(), e.err.Type)
+	if e.err.Field != "" {
+		msg += fmt.Sprintf(` at "%s"`, e.err.Field)
+	}
+	if e.value != "" {
+		msg += " (got " + e.value + ")"
+	}
+	return msg
+}
+
+func (e *TypeMismatchError) Unwrap() []error {
+	return []error{e.err, ErrDecode}
+}
+
+// Path returns the location of the value, e.g. "items[1].qty".
+func (e *TypeMismatchError) Path() string {
+	return e.err.Field
+}
+
+// Expected returns the Go type the value could not be decoded into.
+func (e *TypeMismatchError) Expected() reflect.Type {
+	return e.err.Type
+}
+
+// Found returns the kind of JSON value found: "string", "number", "bool",
+// "array" or "object".
+func (e *TypeMismatchError) Found() string {
+	found, _, _ := strings.Cut(e.err.Value, " ")
+	return found
+}
+
+// Offset returns the byte offset of the end of the value in the input.
+func (e *TypeMismatchError) Offset() int64 {
+	return e.err.Offset
+}
+
+// Value returns an excerpt of the offending JSON value, or "" unless
+// WithErrorValueSnippets is enabled.
+func (e *TypeMismatchError) Value() string {
+	return e.value
+}
+
+func newTypeMismatchError(err *json.UnmarshalTypeError, value string) error {
+	return &TypeMismatchError{err: err, value: value}
+}
+
+// typeMismatch converts err, returned by the backend for raw, the value at
+// the current path, to a *TypeMismatchError if it is a
+// *json.UnmarshalTypeError. The backend only saw raw, so the location of a
+// type error anywhere in err is made absolute; base is the offset of raw in
+// the input. Other errors are wrapped with wrapJSONError.
+func (s *decodeState) typeMismatch(err error, raw []byte, base int64) error {
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		return wrapJSONError(err)
+	}
+	rel := typeErr.Field
+	typeErr.Field = joinPath(s.pathString(), rel)
+	typeErr.Offset += base
+	if err != error(typeErr) {
+		// Returned by a custom unmarshaler; keep its wrapping.
+		return wrapJSONError(err)
+	}
+	value := valueAt(raw, rel)
+	if s.d.ownsNumbers() && isNumberKind(typeErr.Type.Kind()) && len(value) > 0 && value[0] != '"' {
+		return newNumberError(typeErr.Field, typeErr.Type, string(value))
+	}
+	return newTypeMismatchError(typeErr, s.snippet(value))
+}
+
+// snippet returns raw shortened to ErrorValueSnippets bytes for inclusion
+// in an error, or "" if snippets are disabled.
+func (s *decodeState) snippet(raw []byte) string {
+	n := s.d.ErrorValueSnippets
+	if n <= 0 || len(raw) == 0 {
+		return ""
+	}
+	if len(raw) <= n {
+		return string(raw)
+	}
+	for n > 0 && !utf8.RuneStart(raw[n]) {
+		n--
+	}
+	return string(raw[:n]) + "..."
+}
+
+// EncodeError reports a value that an Encoder cannot write as JSON, such as
+// a NaN float, a channel or a reference cycle. It wraps ErrEncode and the
+// error of a failing MarshalJSON or MarshalText method, if any.
+type EncodeError struct {
+	path   string
+	typ    reflect.Type
+	reason string
+	err    error
+}
+
+func (e *EncodeError) Error() string {
+	msg := "strictjson: cannot encode " + e.typ.String()
+	if e.path != "" {
+		msg += fmt.Sprintf(` at "%s"`, e.path)
+	}
+	msg += ": " + e.reason
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+func (e *EncodeError) Unwrap() []error {
+	if e.err != nil {
+		return []error{ErrEncode, e.err}
+	}
+	return []error{ErrEncode}
+}
+
+// Path returns the location of the value.
+func (e *EncodeError) Path() string {
+	return e.path
 }
 
-func (e *fieldConflictError) Error() string {
-	return fmt.Sprintf(`strictjson: field conflict: "%s" defined in multiple embedded structs`, e.fieldName)
+// Type returns the Go type of the value.
+func (e *EncodeError) Type() reflect.Type {
+	return e.typ
 }
 
-func newFieldConflictError(fieldName string) error {
-	return &fieldConflictError{fieldName: fieldName}
+func newEncodeError(path string, typ reflect.Type, reason string, err error) error {
+	return &EncodeError{path: path, typ: typ, reason: reason, err: err}
 }
@@ -1,45 +1,117 @@
 package strictjson
 
 import (
+	"encoding/json"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"unicode/utf8"
 )
 
 type fieldInfo struct {
 	jsonName   string
+	goPath     string // e.g. "Base.ID" for a promoted field
 	fieldIndex []int
+	typ        reflect.Type
+	plan       *typePlan // set once when the owning struct's plan is built
+	// nocase lets the field match keys that differ from jsonName only in case.
+	nocase bool
+	// policy is the unknown-key policy set by strict:"lenient" or
+	// strict:"strict" for the field's subtree.
+	policy Policy
+	// quoted is set for scalar fields with the ,string tag option, whose
+	// values are encoded inside JSON strings.
+	quoted bool
+	// tagOpts holds the options of the tag that named the field.
+	tagOpts string
+	// index is the position of the field in structFields.list.
+	index int
+	// format is the format of the field's value set by its strict tag: the
+	// layout of a time.Time field or the base64 encoding of a []byte field.
+	format string
+	// oneof restricts the values of a string field, set by
+	// strict:"oneof=a|b".
+	oneof *enumSet
+	// constraints are checked against the decoded value of the field, set
+	// by strict:"nonzero", strict:"max=100" and the like.
+	constraints []constraint
+	// def is the value assigned to the field when its key is absent, set by
+	// strict:"default=value".
+	def *fieldDefault
 }
 
 type structFields struct {
 	fields   map[string]*fieldInfo
 	allNames []string
-	conflict string
+	// nocase lists the fields tagged strict:"nocase", consulted when no field
+	// matches a key exactly.
+	nocase []*fieldInfo
+	// list holds every field once, in the order of their indexes.
+	list []*fieldInfo
+	// defaults lists the fields with a default.
+	defaults []*fieldInfo
+	// remain is the index path of the field tagged strict:",remain", which
+	// collects keys that match no other field.
+	remain []int
+	err    error
+	// conflicts lists names provided by several fields at the same depth.
+	// They match no field; see Decoder.IgnoreConflicts.
+	conflicts []*FieldConflictError
+
+	// lower holds allNames lower-cased, built on the first suggestion.
+	lowerOnce sync.Once
+	lower     [][]rune
 }
 
-// fieldCache caches struct field mappings by type to avoid repeated reflection.
-var fieldCache sync.Map
+// fieldConfig holds the decoder settings that change how the field table of
+// a struct is built. Field tables and plans are cached per type and config.
+type fieldConfig struct {
+	protoNames ProtoNames
+	// tagKeys is the comma-separated list of struct tag keys that name
+	// fields, in order of preference. Empty means "json".
+	tagKeys string
+	// throughUnmarshalers gives plans of json.Unmarshaler types an inner
+	// plan to validate their values with. It does not affect field tables.
+	throughUnmarshalers bool
+}
+
+type fieldKey struct {
+	typ reflect.Type
+	cfg fieldConfig
+}
 
-func getStructFields(t reflect.Type) (*structFields, error) {
-	if cached, ok := fieldCache.Load(t); ok {
+// fieldCache caches struct field mappings by type and config to avoid
+// repeated reflection.
+var fieldCache sync.Map // map[fieldKey]*structFields
+
+func getStructFields(t reflect.Type, cfg fieldConfig) (*structFields, error) {
+	key := fieldKey{t, cfg}
+	if cached, ok := fieldCache.Load(key); ok {
+		cacheTouch(key)
 		sf := cached.(*structFields)
-		if sf.conflict != "" {
-			return nil, newFieldConflictError(sf.conflict)
+		if sf.err != nil {
+			return nil, sf.err
 		}
 		return sf, nil
 	}
 
-	sf := buildStructFields(t)
-	fieldCache.Store(t, sf)
+	sf := buildStructFields(t, cfg)
+	applyAliases(t, sf)
+	cacheStoreFields(key, sf)
 
-	if sf.conflict != "" {
-		return nil, newFieldConflictError(sf.conflict)
+	if sf.err != nil {
+		return nil, sf.err
 	}
 	return sf, nil
 }
 
-// buildStructFields extracts field information using BFS to handle shadowing correctly.
-func buildStructFields(t reflect.Type) *structFields {
+// buildStructFields flattens the fields of t the way encoding/json does:
+// breadth first through untagged embedded structs, visiting each type once
+// at the shallowest depth it is embedded at. Of several fields with the
+// same name, the shallowest wins; at equal depth a single tagged field
+// wins, and otherwise the name is ambiguous and recorded as a conflict.
+func buildStructFields(t reflect.Type, cfg fieldConfig) *structFields {
 	sf := &structFields{
 		fields:   make(map[string]*fieldInfo),
 		allNames: make([]string, 0),
@@ -48,14 +120,28 @@ func buildStructFields(t reflect.Type) *structFields {
 	type fieldScan struct {
 		typ   reflect.Type
 		index []int
+		names []string // Go field names along index
+		// also holds the names of other embeddings of typ at the same
+		// depth, whose fields conflict with those reached through names.
+		also [][]string
+	}
+	type candidate struct {
+		fi      *fieldInfo
+		tagged  bool
+		sources []string // one Go path per embedding
 	}
 	currentLevel := []fieldScan{{typ: t, index: nil}}
 	nextLevel := []fieldScan{}
 
 	visitedTypes := map[reflect.Type]bool{}
+	nextSeen := map[reflect.Type]int{} // position in nextLevel
+	// conflicts maps ambiguous names to their error. An ambiguous name also
+	// hides fields of that name at deeper levels.
+	conflicts := map[string]*FieldConflictError{}
 
 	for len(currentLevel) > 0 {
-		fieldsFoundThisLevel := make(map[string]bool)
+		candidates := map[string][]candidate{}
+		var levelNames []string // in declaration order
 
 		for _, scan := range currentLevel {
 			typ := scan.typ
@@ -74,66 +160,306 @@ func buildStructFields(t reflect.Type) *structFields {
 
 			for i := 0; i < typ.NumField(); i++ {
 				f := typ.Field(i)
-				if f.Anonymous {
-					nextIndex := make([]int, len(scan.index)+1)
-					copy(nextIndex, scan.index)
-					nextIndex[len(scan.index)] = i
-
-					nextLevel = append(nextLevel, fieldScan{
-						typ:   f.Type,
-						index: nextIndex,
-					})
+				ft := f.Type
+				if ft.Name() == "" && ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+				// Embedded structs of unexported types may still promote
+				// exported fields; anything else unexported is ignored.
+				if !f.IsExported() && (!f.Anonymous || ft.Kind() != reflect.Struct) {
 					continue
 				}
 
-				if !f.IsExported() {
+				opts := parseStrictTag(f.Tag.Get("strict"))
+				if opts.remain {
+					if f.Type != rawMessageMapType {
+						sf.err = newInvalidTagError(f.Name, `strict:",remain" requires type map[string]json.RawMessage`)
+						continue
+					}
+					if sf.remain == nil {
+						sf.remain = appendIndex(scan.index, i)
+					}
 					continue
 				}
-
-				tag := f.Tag.Get("json")
-				if tag == "-" {
+				if opts.format != "" {
+					if err := checkFormat(f.Name, ft, opts.format); err != nil {
+						sf.err = err
+						continue
+					}
+				}
+				if opts.oneof != nil && ft.Kind() != reflect.String {
+					sf.err = newInvalidTagError(f.Name, `strict:"oneof=..." requires a string type`)
 					continue
 				}
-				name, _ := parseTag(tag)
-				if name == "" {
-					name = f.Name
+				constraints, err := buildConstraints(f.Name, f.Type, opts.constraints)
+				if err != nil {
+					sf.err = err
+					continue
+				}
+				var def *fieldDefault
+				if opts.hasDefault {
+					value, err := parseDefault(f.Name, f.Type, opts.def)
+					if err != nil {
+						sf.err = err
+						continue
+					}
+					def = &fieldDefault{text: opts.def, value: value}
 				}
 
-				if fieldsFoundThisLevel[name] {
-					delete(sf.fields, name)
-					sf.conflict = name
+				name, tagOpts, ok := cfg.fieldName(f)
+				if !ok {
 					continue
 				}
-
-				if _, exists := sf.fields[name]; exists {
+				names := appendName(scan.names, f.Name)
+				tagged := name != ""
+				if !tagged {
+					// An untagged embedded struct has its fields promoted,
+					// while a tagged one or an embedded non-struct is a field
+					// named after its tag or type.
+					if f.Anonymous && ft.Kind() == reflect.Struct {
+						if pos, ok := nextSeen[ft]; ok {
+							nextLevel[pos].also = append(nextLevel[pos].also, names)
+							continue
+						}
+						nextSeen[ft] = len(nextLevel)
+						nextLevel = append(nextLevel, fieldScan{
+							typ:   f.Type,
+							index: appendIndex(scan.index, i),
+							names: names,
+						})
+						continue
+					}
+					name = f.Name
+				}
+				if !f.IsExported() {
 					continue
 				}
+				if _, exists := sf.fields[name]; exists || conflicts[name] != nil {
+					continue // shadowed by a shallower field
+				}
 
-				indexPath := make([]int, len(scan.index)+1)
-				copy(indexPath, scan.index)
-				indexPath[len(scan.index)] = i
-
-				sf.fields[name] = &fieldInfo{
-					jsonName:   name,
-					fieldIndex: indexPath,
+				sources := []string{strings.Join(names, ".")}
+				for _, other := range scan.also {
+					sources = append(sources, strings.Join(appendName(other, f.Name), "."))
+				}
+				if candidates[name] == nil {
+					levelNames = append(levelNames, name)
 				}
-				fieldsFoundThisLevel[name] = true
+				candidates[name] = append(candidates[name], candidate{
+					fi: &fieldInfo{
+						jsonName:    name,
+						goPath:      sources[0],
+						fieldIndex:  appendIndex(scan.index, i),
+						typ:         f.Type,
+						nocase:      opts.nocase,
+						policy:      opts.policy,
+						quoted:      hasTagOption(tagOpts, "string") && isQuotable(f.Type),
+						tagOpts:     tagOpts,
+						format:      opts.format,
+						oneof:       opts.oneof,
+						constraints: constraints,
+						def:         def,
+					},
+					tagged:  tagged,
+					sources: sources,
+				})
 			}
 		}
 
-		for name := range fieldsFoundThisLevel {
-			if _, ok := sf.fields[name]; ok {
-				sf.allNames = append(sf.allNames, name)
+		for _, name := range levelNames {
+			// Tagged fields dominate untagged ones at the same depth.
+			all := candidates[name]
+			var pool []candidate
+			for _, c := range all {
+				if c.tagged {
+					pool = append(pool, c)
+				}
+			}
+			if len(pool) == 0 {
+				pool = all
+			}
+			if len(pool) > 1 || len(pool[0].sources) > 1 {
+				c := &FieldConflictError{fieldName: name}
+				for _, p := range pool {
+					c.sources = append(c.sources, p.sources...)
+				}
+				conflicts[name] = c
+				sf.conflicts = append(sf.conflicts, c)
+				continue
+			}
+
+			fi := pool[0].fi
+			fi.index = len(sf.list)
+			sf.fields[name] = fi
+			sf.list = append(sf.list, fi)
+			sf.allNames = append(sf.allNames, name)
+			if fi.nocase {
+				sf.nocase = append(sf.nocase, fi)
+			}
+			if fi.def != nil {
+				sf.defaults = append(sf.defaults, fi)
 			}
 		}
 
 		currentLevel = nextLevel
 		nextLevel = []fieldScan{}
+		nextSeen = map[reflect.Type]int{}
 	}
 
 	return sf
 }
 
+// fieldName returns the JSON name given to f by its tags, or "" if no tag
+// names it, along with the tag options, or false if f is not decoded.
+func (cfg fieldConfig) fieldName(f reflect.StructField) (name, opts string, ok bool) {
+	if cfg.protoNames != ProtoNamesOff {
+		if name, ok := protoFieldName(f, cfg.protoNames); ok {
+			return name, "", true
+		}
+	}
+	keys := cfg.tagKeys
+	if keys == "" {
+		keys = "json"
+	}
+	for keys != "" {
+		var key string
+		key, keys, _ = strings.Cut(keys, ",")
+		tag, present := f.Tag.Lookup(key)
+		if tag == "-" {
+			return "", "", false
+		}
+		tagName, tagOpts := parseTag(tag)
+		if tagName != "" {
+			return tagName, tagOpts, true
+		}
+		if present && opts == "" {
+			opts = tagOpts // e.g. json:",string" keeps the Go name
+		}
+	}
+	return "", opts, true
+}
+
+// lookup finds the field for a JSON key: an exact match first, then a
+// case-insensitive match among fields that opted out of strict casing.
+func (sf *structFields) lookup(key string) (*fieldInfo, bool) {
+	if fi, ok := sf.fields[key]; ok {
+		return fi, true
+	}
+	for _, fi := range sf.nocase {
+		if strings.EqualFold(fi.jsonName, key) {
+			return fi, true
+		}
+	}
+	return nil, false
+}
+
+// lookupFold finds a field whose name matches key case-insensitively, the
+// way encoding/json would.
+func (sf *structFields) lookupFold(key string) (*fieldInfo, bool) {
+	for _, name := range sf.allNames {
+		if strings.EqualFold(name, key) {
+			return sf.fields[name], true
+		}
+	}
+	return nil, false
+}
+
+// appendName returns a copy of names extended with name.
+func appendName(names []string, name string) []string {
+	return append(names[:len(names):len(names)], name)
+}
+
+// appendIndex returns a copy of index extended with i.
+func appendIndex(index []int, i int) []int {
+	out := make([]int, len(index)+1)
+	copy(out, index)
+	out[len(index)] = i
+	return out
+}
+
+var rawMessageMapType = reflect.TypeOf(map[string]json.RawMessage(nil))
+
+// strictOptions holds the options of a field's strict struct tag. The tag is
+// a comma-separated list; empty entries are ignored, so strict:",remain" and
+// strict:"remain" are equivalent.
+type strictOptions struct {
+	remain bool
+	nocase bool
+	policy Policy
+	// format is the time layout of the time=layout option or the base64
+	// encoding named by the b64 options.
+	format string
+	// oneof holds the values of the oneof=a|b option.
+	oneof *enumSet
+	// constraints lists the options that constrain the field's value.
+	constraints []string
+	// def holds the text of the default=value option, and hasDefault
+	// whether the option is set.
+	def        string
+	hasDefault bool
+}
+
+func parseStrictTag(tag string) strictOptions {
+	var opts strictOptions
+	for tag != "" {
+		var opt string
+		opt, tag, _ = strings.Cut(tag, ",")
+		switch opt {
+		case "remain":
+			opts.remain = true
+		case "nocase":
+			opts.nocase = true
+		case "lenient":
+			opts.policy = PolicyLenient
+		case "strict":
+			opts.policy = PolicyStrict
+		default:
+			if isConstraint(opt) {
+				opts.constraints = append(opts.constraints, opt)
+			} else if def, ok := strings.CutPrefix(opt, "default="); ok {
+				opts.def, opts.hasDefault = def, true
+			} else if list, ok := strings.CutPrefix(opt, "oneof="); ok {
+				opts.oneof = parseOneOf(list)
+			} else if layout, ok := strings.CutPrefix(opt, "time="); ok {
+				opts.format = layout
+			} else if base64Encodings[opt] != nil {
+				opts.format = opt
+			}
+		}
+	}
+	return opts
+}
+
+// hasTagOption reports whether the comma-separated tag options contain opt.
+func hasTagOption(opts, opt string) bool {
+	for opts != "" {
+		var o string
+		o, opts, _ = strings.Cut(opts, ",")
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+// isQuotable reports whether the ,string option applies to fields of type
+// t, following encoding/json: booleans, numbers and strings, or a pointer
+// to one.
+func isQuotable(t reflect.Type) bool {
+	if t.Name() == "" && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.String:
+		return true
+	}
+	return false
+}
+
 func parseTag(tag string) (name, opts string) {
 	if idx := strings.Index(tag, ","); idx != -1 {
 		return tag[:idx], tag[idx+1:]
@@ -141,45 +467,129 @@ func parseTag(tag string) (name, opts string) {
 	return tag, ""
 }
 
-func findSuggestion(unknown string, knownNames []string) string {
-	unknownLower := strings.ToLower(unknown)
+// Bounds on suggestion work, so that unknown keys cannot be used to make
+// the decoder do quadratic amounts of work.
+const (
+	// maxSuggestKeyLen is the longest key, in runes, compared by edit
+	// distance. Longer keys only get case-insensitive matches.
+	maxSuggestKeyLen = 64
+	// defaultSuggestionBudget is the number of unknown keys per decode that
+	// get suggestions when SuggestionBudget is zero.
+	defaultSuggestionBudget = 100
+)
 
-	for _, name := range knownNames {
-		if strings.ToLower(name) == unknownLower {
-			return name
+// suggestions returns up to limit field names within maxDistance edits of
+// key, closest first. A match under Unicode case folding ranks ahead of
+// every edit-distance match; ties are broken alphabetically.
+func (sf *structFields) suggestions(key string, limit, maxDistance int) []string {
+	sf.lowerOnce.Do(func() {
+		sf.lower = lowerRunes(sf.allNames)
+	})
+	return closestNames(key, sf.allNames, sf.lower, limit, maxDistance)
+}
+
+// lowerRunes returns names lower-cased, as runes, for closestNames.
+func lowerRunes(names []string) [][]rune {
+	lower := make([][]rune, len(names))
+	for i, name := range names {
+		lower[i] = []rune(strings.ToLower(name))
+	}
+	return lower
+}
+
+// closestNames implements suggestions for any list of names, given them
+// lower-cased as runes in lower.
+func closestNames(key string, names []string, lower [][]rune, limit, maxDistance int) []string {
+	var keyRunes []rune
+	if len(key) <= maxSuggestKeyLen*utf8.UTFMax {
+		if r := []rune(strings.ToLower(key)); len(r) <= maxSuggestKeyLen {
+			keyRunes = r
 		}
 	}
 
-	for _, name := range knownNames {
-		if levenshteinDistance(unknown, name) <= 2 {
-			return name
+	scratch := suggestPool.Get().(*suggestScratch)
+	defer suggestPool.Put(scratch)
+	candidates := scratch.candidates[:0]
+	for i, name := range names {
+		if strings.EqualFold(name, key) {
+			candidates = append(candidates, suggestCandidate{name, -1})
+			continue
+		}
+		if keyRunes == nil {
+			continue
+		}
+		if n := 3 * (len(lower[i]) + 1); cap(scratch.rows) < n {
+			scratch.rows = make([]int, n)
+		}
+		if d := editDistance(keyRunes, lower[i], maxDistance, scratch.rows); d <= maxDistance {
+			candidates = append(candidates, suggestCandidate{name, d})
 		}
 	}
+	scratch.candidates = candidates
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].dist != candidates[j].dist {
+			return candidates[i].dist < candidates[j].dist
+		}
+		return candidates[i].name < candidates[j].name
+	})
 
-	return ""
+	if len(candidates) == 0 {
+		return nil
+	}
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	closest := make([]string, len(candidates))
+	for i, c := range candidates {
+		closest[i] = c.name
+	}
+	return closest
+}
+
+type suggestCandidate struct {
+	name string
+	dist int
+}
+
+// suggestScratch holds the buffers of a suggestions call, recycled through
+// suggestPool.
+type suggestScratch struct {
+	candidates []suggestCandidate
+	rows       []int
 }
 
-// levenshteinDistance distance between two strings.
-func levenshteinDistance(s1, s2 string) int {
-	if len(s1) == 0 {
-		return len(s2)
+var suggestPool = sync.Pool{New: func() any { return new(suggestScratch) }}
+
+// editDistance returns the Damerau-Levenshtein distance between a and b
+// (optimal string alignment): the number of rune insertions, deletions,
+// substitutions and adjacent transpositions turning one into the other.
+// It stops early and returns maxDist+1 once the distance must exceed
+// maxDist. rows is scratch space of at least 3*(len(b)+1) ints.
+func editDistance(a, b []rune, maxDist int, rows []int) int {
+	if diff := len(a) - len(b); diff > maxDist || -diff > maxDist {
+		return maxDist + 1
+	}
+	if len(a) == 0 {
+		return len(b)
 	}
-	if len(s2) == 0 {
-		return len(s1)
+	if len(b) == 0 {
+		return len(a)
 	}
 
-	prev := make([]int, len(s2)+1)
-	curr := make([]int, len(s2)+1)
+	// Three rows suffice: a transposition looks back two rows.
+	n := len(b) + 1
+	prev2, prev, curr := rows[:n], rows[n:2*n], rows[2*n:3*n]
 
-	for j := 0; j <= len(s2); j++ {
+	for j := 0; j <= len(b); j++ {
 		prev[j] = j
 	}
 
-	for i := 1; i <= len(s1); i++ {
+	for i := 1; i <= len(a); i++ {
 		curr[0] = i
-		for j := 1; j <= len(s2); j++ {
+		rowMin := i
+		for j := 1; j <= len(b); j++ {
 			cost := 0
-			if s1[i-1] != s2[j-1] {
+			if a[i-1] != b[j-1] {
 				cost = 1
 			}
 			curr[j] = minOfThree(
@@ -187,11 +597,22 @@ func levenshteinDistance(s1, s2 string) int {
 				curr[j-1]+1,
 				prev[j-1]+cost,
 			)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < curr[j] {
+				curr[j] = prev2[j-2] + 1
+			}
+			if curr[j] < rowMin {
+				rowMin = curr[j]
+			}
+		}
+		// Each row's minimum is at most one below the next row's, so once
+		// a whole row exceeds maxDist the result will too.
+		if rowMin > maxDist {
+			return maxDist + 1
 		}
-		prev, curr = curr, prev
+		prev2, prev, curr = prev, curr, prev2
 	}
 
-	return prev[len(s2)]
+	return prev[len(b)]
 }
 
 func minOfThree(a, b, c int) int {
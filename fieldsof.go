@@ -0,0 +1,93 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FieldSpec describes how a struct field is matched by the decoder.
+type FieldSpec struct {
+	// Name is the JSON name the field is matched by.
+	Name string
+	// Aliases lists the names registered for the field with RegisterAlias,
+	// sorted.
+	Aliases []string
+	// GoPath is the dotted path of Go field names to the field, e.g.
+	// "Base.ID" for a field promoted from an embedded Base.
+	GoPath string
+	// Index is the index sequence for reflect.Value.FieldByIndex.
+	Index []int
+	Type  reflect.Type
+	// Options lists the options of the tag that named the field, such as
+	// "omitempty" or "string".
+	Options []string
+	// NoCase is set for fields tagged strict:"nocase".
+	NoCase bool
+	// Policy is the unknown-key policy applied to the field's value: the
+	// policy registered for its type if any, else that of its strict tag.
+	Policy Policy
+}
+
+// FieldsOf returns the fields of struct type t, or of the struct t points
+// to, in the order the decoder lists them: shallowest first, then in
+// declaration order. The field set is resolved with the same flattening,
+// shadowing and conflict rules as decoding, so tools built on it agree with
+// the decoder.
+func FieldsOf(t reflect.Type) ([]FieldSpec, error) {
+	return NewDecoder().FieldsOf(t)
+}
+
+// FieldsOf is like the package-level FieldsOf but applies d's options, such
+// as TagKeys, ProtoNames and IgnoreConflicts.
+func (d *Decoder) FieldsOf(t reflect.Type) ([]FieldSpec, error) {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, &UnmarshalError{message: fmt.Sprintf("strictjson: FieldsOf(%v): not a struct type", t)}
+	}
+	sf, err := getStructFields(t, d.fieldConfig())
+	if err != nil {
+		return nil, err
+	}
+	if len(sf.conflicts) > 0 && !d.IgnoreConflicts {
+		return nil, sf.conflicts[0]
+	}
+
+	aliases := make(map[*fieldInfo][]string)
+	for name, fi := range sf.fields {
+		if name != fi.jsonName {
+			aliases[fi] = append(aliases[fi], name)
+		}
+	}
+	specs := make([]FieldSpec, 0, len(sf.allNames))
+	for _, name := range sf.allNames {
+		fi := sf.fields[name]
+		spec := FieldSpec{
+			Name:    name,
+			Aliases: aliases[fi],
+			GoPath:  fi.goPath,
+			Index:   append([]int(nil), fi.fieldIndex...),
+			Type:    fi.typ,
+			NoCase:  fi.nocase,
+			Policy:  fi.policy,
+		}
+		sort.Strings(spec.Aliases)
+		for _, opt := range strings.Split(fi.tagOpts, ",") {
+			if opt != "" {
+				spec.Options = append(spec.Options, opt)
+			}
+		}
+		base := fi.typ
+		for base.Kind() == reflect.Ptr {
+			base = base.Elem()
+		}
+		if p := typePolicy(base); p != PolicyDefault {
+			spec.Policy = p
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
@@ -0,0 +1,78 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type fieldsOfVendor struct {
+	ID string `json:"id"`
+}
+
+func TestFieldsOf(t *testing.T) {
+	type Base struct {
+		ID      int    `json:"id"`
+		Created string `json:"created,omitempty"`
+	}
+	type Order struct {
+		Base
+		Count  int            `json:"count,string"`
+		Name   string         `json:"name" strict:"nocase"`
+		Vendor fieldsOfVendor `json:"vendor" strict:"strict"`
+		Meta   map[string]any `json:"meta" strict:"lenient"`
+		Note   string
+		Hidden string `json:"-"`
+	}
+
+	RegisterLenientType(reflect.TypeOf(fieldsOfVendor{}))
+	defer RegisterTypePolicy(reflect.TypeOf(fieldsOfVendor{}), PolicyDefault)
+
+	specs, err := FieldsOf(reflect.TypeOf(&Order{}))
+	if err != nil {
+		t.Fatalf("FieldsOf() error = %v", err)
+	}
+	want := []FieldSpec{
+		{Name: "count", GoPath: "Count", Index: []int{1}, Type: reflect.TypeOf(0), Options: []string{"string"}},
+		{Name: "name", GoPath: "Name", Index: []int{2}, Type: reflect.TypeOf(""), NoCase: true},
+		{Name: "vendor", GoPath: "Vendor", Index: []int{3}, Type: reflect.TypeOf(fieldsOfVendor{}), Policy: PolicyLenient},
+		{Name: "meta", GoPath: "Meta", Index: []int{4}, Type: reflect.TypeOf(map[string]any(nil)), Policy: PolicyLenient},
+		{Name: "Note", GoPath: "Note", Index: []int{5}, Type: reflect.TypeOf("")},
+		{Name: "id", GoPath: "Base.ID", Index: []int{0, 0}, Type: reflect.TypeOf(0)},
+		{Name: "created", GoPath: "Base.Created", Index: []int{0, 1}, Type: reflect.TypeOf(""), Options: []string{"omitempty"}},
+	}
+	if !reflect.DeepEqual(specs, want) {
+		t.Errorf("FieldsOf() =\n%+v\nwant\n%+v", specs, want)
+	}
+
+	specs, err = NewDecoder(WithTagKey("api")).FieldsOf(reflect.TypeOf(struct {
+		UserID string `api:"userId" json:"user_id"`
+	}{}))
+	if err != nil || len(specs) != 1 || specs[0].Name != "userId" {
+		t.Errorf("FieldsOf() with tag key = %+v, %v", specs, err)
+	}
+}
+
+func TestFieldsOfErrors(t *testing.T) {
+	type A struct{ Name string }
+	type B struct{ Name string }
+	type Conflict struct {
+		A
+		B
+		ID int
+	}
+
+	_, err := FieldsOf(reflect.TypeOf(Conflict{}))
+	var fce *FieldConflictError
+	if !errors.As(err, &fce) {
+		t.Errorf("Expected *FieldConflictError, got %v", err)
+	}
+	specs, err := NewDecoder(WithIgnoreConflicts(true)).FieldsOf(reflect.TypeOf(Conflict{}))
+	if err != nil || len(specs) != 1 || specs[0].Name != "ID" {
+		t.Errorf("FieldsOf() ignoring conflicts = %+v, %v", specs, err)
+	}
+
+	if _, err := FieldsOf(reflect.TypeOf(0)); !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected error for non-struct type, got %v", err)
+	}
+}
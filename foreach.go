@@ -0,0 +1,151 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+// ForEach strictly decodes the elements of the top-level JSON array in data
+// one at a time, calling fn with each element's index and a pointer to a new
+// value of elemPrototype's type. elemPrototype may be a value or a pointer,
+// e.g. ForEach(data, (*Order)(nil), fn). Elements are never collected, so
+// memory use is bounded by the largest element. A non-nil error from fn
+// stops the iteration and is returned unchanged.
+func ForEach(data []byte, elemPrototype any, fn func(i int, elem any) error) error {
+	return NewDecoder().ForEach(data, elemPrototype, fn)
+}
+
+// ForEach is like the package-level ForEach but applies d's options.
+func (d *Decoder) ForEach(data []byte, elemPrototype any, fn func(i int, elem any) error) (err error) {
+	defer func() { err = d.formatError(err) }()
+	t := reflect.TypeOf(elemPrototype)
+	if t == nil {
+		return newNonPointerError()
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	p := d.planFor(t)
+
+	s, err := d.newState(data)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	defer s.observe(t, &err)
+	if err := s.scan.expect('[', "looking for beginning of array"); err != nil {
+		return err
+	}
+	if !s.scan.consume(']') {
+		for i := 0; ; i++ {
+			if err := s.countElement(i + 1); err != nil {
+				return err
+			}
+			elem := reflect.New(t)
+			s.pushIndex(i)
+			err := s.value(elem.Elem(), p)
+			s.pop()
+			if err != nil {
+				return err
+			}
+			if err := fn(i, elem.Interface()); err != nil {
+				return err
+			}
+
+			if s.scan.consume(',') {
+				continue
+			}
+			if err := s.scan.expect(']', "after array element"); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return s.scan.end()
+}
+
+// ForEachReader is like ForEach but reads the array from r, holding only
+// one element in memory at a time. Use it for arrays too large to buffer.
+// MaxBytes applies to each element rather than to the whole stream.
+func (d *Decoder) ForEachReader(r io.Reader, elemPrototype any, fn func(i int, elem any) error) (err error) {
+	defer func() { err = d.formatError(err) }()
+	t := reflect.TypeOf(elemPrototype)
+	if t == nil {
+		return newNonPointerError()
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	p := d.planFor(t)
+
+	r, bom, err := d.bomReader(r)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return wrapJSONError(err)
+	}
+	if tok != json.Delim('[') {
+		return newSyntaxError("top-level value is not an array", 0)
+	}
+	for i := 0; dec.More(); i++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return wrapJSONError(err)
+		}
+		start := int64(bom) + dec.InputOffset() - int64(len(raw))
+
+		s, err := d.newState(raw)
+		if err == nil {
+			err = s.countElement(i + 1)
+		}
+		if err != nil {
+			return err
+		}
+
+		elem := reflect.New(t)
+		s.pushIndex(i)
+		err = s.value(elem.Elem(), p)
+		if err == nil {
+			err = s.scan.end()
+		}
+		s.observe(t, &err)
+		s.release()
+		if err != nil {
+			return offsetError(err, start)
+		}
+		if err := fn(i, elem.Interface()); err != nil {
+			return err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return wrapJSONError(err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return newSyntaxError("invalid character after top-level value", int64(bom)+dec.InputOffset())
+	}
+	return nil
+}
+
+// ForEachReader reads the array from r using the default strict decoder.
+func ForEachReader(r io.Reader, elemPrototype any, fn func(i int, elem any) error) error {
+	return NewDecoder().ForEachReader(r, elemPrototype, fn)
+}
+
+// offsetError shifts the offset of a syntax or type error by base, for
+// errors found in a value sliced out of a larger input.
+func offsetError(err error, base int64) error {
+	var syntaxErr *SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		syntaxErr.Offset += base
+	case errors.As(err, &typeErr):
+		typeErr.Offset += base
+	}
+	return err
+}
@@ -0,0 +1,99 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type feOrder struct {
+	ID    int    `json:"id"`
+	Owner string `json:"owner"`
+}
+
+func TestForEach(t *testing.T) {
+	data := `[{"id": 1, "owner": "a"}, {"id": 2, "owner": "b"}, {"id": 3}]`
+
+	run := func(name string, iterate func(fn func(i int, elem any) error) error) {
+		t.Run(name, func(t *testing.T) {
+			var ids []int
+			err := iterate(func(i int, elem any) error {
+				o := elem.(*feOrder)
+				if o.ID != i+1 {
+					t.Errorf("element %d has id %d", i, o.ID)
+				}
+				ids = append(ids, o.ID)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ids) != 3 {
+				t.Errorf("Expected 3 elements, got %v", ids)
+			}
+		})
+	}
+	run("bytes", func(fn func(int, any) error) error {
+		return ForEach([]byte(data), feOrder{}, fn)
+	})
+	run("reader", func(fn func(int, any) error) error {
+		return ForEachReader(strings.NewReader(data), (*feOrder)(nil), fn)
+	})
+}
+
+func TestForEachErrors(t *testing.T) {
+	data := `[{"id": 1}, {"id": 2, "Owner": "b"}]`
+	noop := func(int, any) error { return nil }
+
+	for name, err := range map[string]error{
+		"bytes":  ForEach([]byte(data), feOrder{}, noop),
+		"reader": ForEachReader(strings.NewReader(data), feOrder{}, noop),
+	} {
+		var unknown *UnknownFieldError
+		if !errors.As(err, &unknown) || unknown.Path() != "[1].Owner" {
+			t.Errorf("%s: expected UnknownFieldError at [1].Owner, got %v", name, err)
+		}
+	}
+
+	typeData := `[{"id": 1},  {"id": "x"}]`
+	for name, err := range map[string]error{
+		"bytes":  ForEach([]byte(typeData), feOrder{}, noop),
+		"reader": ForEachReader(strings.NewReader(typeData), feOrder{}, noop),
+	} {
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) || typeErr.Field != "[1].id" || typeErr.Offset != int64(strings.Index(typeData, `"x"`)+3) {
+			t.Errorf("%s: expected UnmarshalTypeError at [1].id, got %v", name, err)
+		}
+	}
+
+	stop := errors.New("stop")
+	calls := 0
+	err := ForEach([]byte(data), feOrder{}, func(int, any) error {
+		calls++
+		return stop
+	})
+	if err != stop || calls != 1 {
+		t.Errorf("Expected callback error after one call, got %v after %d", err, calls)
+	}
+
+	for _, input := range []string{`{"id": 1}`, `[{"id": 1}`, `[{"id": 1}] x`, `[{"id": 1},]`} {
+		if err := ForEach([]byte(input), feOrder{}, noop); !errors.Is(err, ErrDecode) {
+			t.Errorf("ForEach(%s): expected decode error, got %v", input, err)
+		}
+		if err := ForEachReader(strings.NewReader(input), feOrder{}, noop); !errors.Is(err, ErrDecode) {
+			t.Errorf("ForEachReader(%s): expected decode error, got %v", input, err)
+		}
+	}
+}
+
+func TestForEachEmpty(t *testing.T) {
+	called := false
+	fn := func(int, any) error { called = true; return nil }
+	if err := ForEach([]byte(` [ ] `), feOrder{}, fn); err != nil || called {
+		t.Errorf("Expected no elements, got called=%v err=%v", called, err)
+	}
+	if err := ForEachReader(strings.NewReader(`[]`), feOrder{}, fn); err != nil || called {
+		t.Errorf("Expected no elements, got called=%v err=%v", called, err)
+	}
+}
@@ -0,0 +1,372 @@
+package strictjson
+
+import "reflect"
+
+// frameKind is the kind of value a frame decodes.
+type frameKind uint8
+
+const (
+	frameObject frameKind = iota // a struct
+	frameArray                   // a slice
+	frameMap                     // a map
+)
+
+// frame is an object or array being decoded member by member. Frames take
+// the place of the call stack of a recursive decoder: each holds the state
+// its object or array needs between members, and what it must restore when
+// it is closed.
+type frame struct {
+	// The small fields come first so that they share a word: a document
+	// has as many frames as levels of nesting.
+	kind frameKind
+	// validate is set for the frames of Validate, which have no v: their
+	// members are checked and discarded.
+	validate bool
+	// inMember reports whether the last member begun is still to be ended.
+	inMember bool
+	// policy is the policy to restore on closing if scoped is set, because
+	// the type of v has one of its own.
+	scoped bool
+	policy Policy
+	// fieldPolicy is the policy to restore when the struct field being
+	// decoded is done.
+	fieldPolicy Policy
+
+	v reflect.Value
+	// elem is the plan of the elements of an array or the values of a map.
+	elem *typePlan
+	// fields is the field table of a struct.
+	fields *structFields
+	// n is the number of members begun so far.
+	n     int
+	seen  map[string]struct{}
+	order keyOrder
+	// slice collects the elements of an array, stored in v when it closes.
+	// For a Go array it is a new array; elements beyond its length are
+	// decoded into scratch values and discarded.
+	slice reflect.Value
+	// array is the type of a Go array being decoded or validated, and nil
+	// for a slice.
+	array reflect.Type
+	// key is the key type of a map being validated whose keys need parsing.
+	key reflect.Type
+	// field is the struct field being decoded, if any, and fieldCurrent the
+	// current field to restore when it is done. member is the value of that
+	// field or of the map entry being decoded, and keyVal the key of the
+	// entry.
+	field          *fieldInfo
+	fieldCurrent   *fieldInfo
+	member, keyVal reflect.Value
+	// present is the presence tracking of the enclosing struct object.
+	present []bool
+	// pathLen is the length of the path when the frame was opened.
+	pathLen int
+}
+
+// closer returns the byte that closes the frame's object or array.
+func (f *frame) closer() byte {
+	if f.kind == frameArray {
+		return ']'
+	}
+	return '}'
+}
+
+// frameChunk is the number of frames a frameStack allocates at a time.
+const frameChunk = 256
+
+// frameStack is a stack of frames stored in chunks, so that growing it
+// neither copies the frames of a deep document nor moves them: a pointer to
+// a frame stays valid until the frame is popped.
+type frameStack struct {
+	chunks []*[frameChunk]frame
+	n      int
+}
+
+func (st *frameStack) len() int { return st.n }
+
+func (st *frameStack) at(i int) *frame {
+	return &st.chunks[i/frameChunk][i%frameChunk]
+}
+
+func (st *frameStack) push(f frame) {
+	if st.n == len(st.chunks)*frameChunk {
+		st.chunks = append(st.chunks, new([frameChunk]frame))
+	}
+	st.n++
+	*st.at(st.n - 1) = f
+}
+
+func (st *frameStack) pop() {
+	st.n--
+	*st.at(st.n) = frame{}
+}
+
+// reset empties the stack, keeping its first chunk for reuse.
+func (st *frameStack) reset() {
+	for st.n > 0 {
+		st.pop()
+	}
+	for i := 1; i < len(st.chunks); i++ {
+		st.chunks[i] = nil
+	}
+	if len(st.chunks) > 1 {
+		st.chunks = st.chunks[:1]
+	}
+}
+
+func (s *decodeState) push(f frame) {
+	f.pathLen = len(s.path)
+	s.stack.push(f)
+	if s.tree != nil {
+		s.tree.open(f.kind, s.scan.pos-1)
+	}
+}
+
+// top returns the innermost frame.
+func (s *decodeState) top() *frame {
+	return s.stack.at(s.stack.len() - 1)
+}
+
+func (s *decodeState) popFrame() {
+	s.stack.pop()
+	if s.tree != nil {
+		s.tree.close(s.scan.pos)
+	}
+}
+
+// run decodes the frame at index base of s.stack, and the frames its members
+// open, until it is closed. On error it discards the frames from base up,
+// restoring what they changed, and returns the error.
+func (s *decodeState) run(base int) error {
+	for s.stack.len() > base {
+		if err := s.step(s.top()); err != nil {
+			s.unwind(base)
+			return err
+		}
+	}
+	return nil
+}
+
+// step advances f, the innermost frame, by one member: it ends the member
+// decoded last, if any, and then closes f or begins its next member, which
+// may push a frame of its own.
+func (s *decodeState) step(f *frame) error {
+	if f.inMember {
+		f.inMember = false
+		if err := s.endMember(f); err != nil {
+			return err
+		}
+		if !s.scan.consume(',') {
+			what := "after object key:value pair"
+			if f.kind == frameArray {
+				what = "after array element"
+			}
+			if err := s.scan.expect(f.closer(), what); err != nil {
+				return err
+			}
+			return s.close(f)
+		}
+	} else if f.n == 0 && s.scan.consume(f.closer()) {
+		return s.close(f)
+	}
+
+	f.n++
+	f.inMember = true
+	if s.tree != nil {
+		s.tree.beginMember(s.scan.pos)
+	}
+	switch f.kind {
+	case frameObject:
+		return s.beginField(f)
+	case frameArray:
+		return s.beginElement(f)
+	default:
+		return s.beginEntry(f)
+	}
+}
+
+// beginField reads the next key of f's object and begins decoding its value
+// into the matching field. Its path segment is left for endMember to pop.
+func (s *decodeState) beginField(f *frame) error {
+	if err := s.countKey(f.n); err != nil {
+		return err
+	}
+	key, fi, err := s.readFieldKey(f.fields)
+	if err != nil {
+		return err
+	}
+	s.pushKey(key)
+	if err := s.seenKey(f.seen, key); err != nil {
+		return err
+	}
+	if err := s.orderedKey(&f.order, f.fields, key, fi); err != nil {
+		return err
+	}
+
+	if f.validate {
+		return s.beginValidateField(f, key, fi)
+	}
+	if fi == nil {
+		fi, _ = s.lookup(f.fields, key)
+	}
+	if fi == nil {
+		if f.fields.remain != nil {
+			return s.remain(f.v, f.fields, key)
+		}
+		fold, err := s.unknown(f.fields, key)
+		if err != nil || fold == nil {
+			if err == nil {
+				_, err = s.skip()
+			}
+			return err
+		}
+		fi = fold
+	}
+
+	if s.report != nil {
+		s.report.add(s.pathString())
+	}
+	s.markPresent(fi)
+	fieldValue := getFieldByIndex(f.v, fi.fieldIndex)
+	if !fieldValue.IsValid() || !fieldValue.CanSet() {
+		_, err := s.skip()
+		return err
+	}
+	f.field, f.member = fi, fieldValue
+	f.fieldPolicy, f.fieldCurrent = s.enterPolicy(fi.policy), s.current
+	s.current = fi
+	if fi.quoted {
+		return s.quoted(fieldValue, fi.plan)
+	}
+	_, err = s.begin(fieldValue, fi.plan)
+	return err
+}
+
+// beginElement begins decoding the next element of f's array.
+func (s *decodeState) beginElement(f *frame) error {
+	if err := s.countElement(f.n); err != nil {
+		return err
+	}
+	i := f.n - 1
+	s.pushIndex(i)
+	switch {
+	case f.validate:
+		_, err := s.beginValidate(f.elem)
+		return err
+	case f.array == nil:
+		f.slice = reflect.Append(f.slice, s.sliceElem(f.v, i))
+	case i >= f.array.Len():
+		// The element does not fit and is discarded, but only after the
+		// same checks as the others.
+		_, err := s.begin(reflect.New(f.array.Elem()).Elem(), f.elem)
+		return err
+	}
+	_, err := s.begin(f.slice.Index(i), f.elem)
+	return err
+}
+
+// beginEntry reads the next key of f's map object and begins decoding its
+// value.
+func (s *decodeState) beginEntry(f *frame) error {
+	if err := s.countKey(f.n); err != nil {
+		return err
+	}
+	key, err := s.readKey()
+	if err != nil {
+		return err
+	}
+	s.pushKey(key)
+	if err := s.seenKey(f.seen, key); err != nil {
+		return err
+	}
+	if err := s.orderedKey(&f.order, nil, key, nil); err != nil {
+		return err
+	}
+	if f.validate {
+		if f.key != nil {
+			if _, err := s.mapKey(f.key, key); err != nil {
+				return err
+			}
+		}
+		_, err := s.beginValidate(f.elem)
+		return err
+	}
+	keyVal, err := s.mapKey(f.v.Type().Key(), key)
+	if err != nil {
+		return err
+	}
+	f.keyVal, f.member = keyVal, s.mapElem(f.v, keyVal)
+	_, err = s.begin(f.member, f.elem)
+	return err
+}
+
+// endMember completes the member of f decoded last: it checks the
+// constraints of a struct field and stores a map entry.
+func (s *decodeState) endMember(f *frame) error {
+	var err error
+	if fi := f.field; fi != nil {
+		if s.constrained(fi) {
+			err = s.checkConstraints(f.member, fi)
+		}
+		s.policy, s.current = f.fieldPolicy, f.fieldCurrent
+		f.field, f.member = nil, reflect.Value{}
+	}
+	if f.kind == frameMap && !f.validate {
+		f.v.SetMapIndex(f.keyVal, f.member)
+		f.keyVal, f.member = reflect.Value{}, reflect.Value{}
+	}
+	if s.tree != nil {
+		s.tree.endMember(s.scan.data, s.scan.pos)
+	}
+	s.pop()
+	return err
+}
+
+// close pops f, the innermost frame, whose closing bracket has been read,
+// completing its value. It fails, leaving f for unwind, if a Go array has
+// the wrong number of elements.
+func (s *decodeState) close(f *frame) error {
+	if f.array != nil {
+		if err := s.arrayLength(f.array, f.n); err != nil {
+			return err
+		}
+	}
+	switch {
+	case f.validate:
+	case f.kind == frameObject:
+		s.endFields(f.v, f.fields)
+		s.present = f.present
+	case f.kind == frameArray:
+		if f.array != nil {
+			zeroFrom(f.slice, f.n)
+		}
+		f.v.Set(f.slice)
+	}
+	s.leave()
+	if f.scoped {
+		s.policy = f.policy
+	}
+	s.popFrame()
+	return nil
+}
+
+// unwind pops the frames from base up without completing them, restoring
+// the state each changed, as returning an error from a recursive decoder
+// would.
+func (s *decodeState) unwind(base int) {
+	for s.stack.len() > base {
+		f := s.top()
+		if f.field != nil {
+			s.policy, s.current = f.fieldPolicy, f.fieldCurrent
+		}
+		if f.kind == frameObject && !f.validate {
+			s.present = f.present
+		}
+		s.leave()
+		if f.scoped {
+			s.policy = f.policy
+		}
+		s.path = s.path[:f.pathLen]
+		s.popFrame()
+	}
+}
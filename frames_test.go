@@ -0,0 +1,153 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"testing"
+)
+
+func TestDeepNesting(t *testing.T) {
+	const depth = 1000000
+
+	type node struct {
+		Next *node `json:"next"`
+		N    int   `json:"n"`
+	}
+	data := strings.Repeat(`{"next": `, depth) + `{"n": 1}` + strings.Repeat("}", depth)
+	var n node
+	if err := Unmarshal([]byte(data), &n); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	levels, last := 0, &n
+	for ; last.Next != nil; last = last.Next {
+		levels++
+	}
+	if levels != depth || last.N != 1 {
+		t.Errorf("Expected %d levels ending in n=1, got %d ending in n=%d", depth, levels, last.N)
+	}
+
+	type tree struct {
+		List []tree          `json:"list"`
+		Kids map[string]tree `json:"kids"`
+	}
+	var tr tree
+	data = strings.Repeat(`{"list": [{"kids": {"a": `, depth/2) + "{}" + strings.Repeat("}}]}", depth/2)
+	if err := Unmarshal([]byte(data), &tr); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if len(tr.List) != 1 || len(tr.List[0].Kids) != 1 {
+		t.Errorf("Unexpected result at the top level: %+v", tr)
+	}
+
+	// Recursive types without structs are decoded by the backend.
+	type list []list
+	var l list
+	if err := Unmarshal([]byte(`[[], [[]]]`), &l); err != nil || len(l) != 2 || len(l[1]) != 1 {
+		t.Errorf("Unmarshal() = %v, %v", l, err)
+	}
+}
+
+// TestDeepNestingStack checks that the entry points sharing the frame
+// engine decode a million levels of nesting within a goroutine stack far too
+// small for a recursive decoder, which would crash the test binary.
+func TestDeepNestingStack(t *testing.T) {
+	const depth = 1000000
+	defer debug.SetMaxStack(debug.SetMaxStack(8 << 20))
+
+	type node struct {
+		Next *node `json:"next"`
+	}
+	data := []byte(strings.Repeat(`{"next": `, depth) + "{}" + strings.Repeat("}", depth))
+
+	var n node
+	if err := Unmarshal(data, &n); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+	if err := Validate(data, (*node)(nil)); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+	if violations, err := NewDecoder().Check(data, &node{}); err != nil || len(violations) != 0 {
+		t.Errorf("Check() = %v, %v", violations, err)
+	}
+	if _, err := CompileFor[node]().Decode(data); err != nil {
+		t.Errorf("TypeDecoder.Decode() unexpected error: %v", err)
+	}
+	if err := CompileFor[node]().Validate(data); err != nil {
+		t.Errorf("TypeDecoder.Validate() unexpected error: %v", err)
+	}
+}
+
+func TestDeepNestingErrorPath(t *testing.T) {
+	type node struct {
+		Next []*node `json:"next"`
+	}
+	const depth = 10000
+	data := strings.Repeat(`{"next": [`, depth) + `{"nxet": 1}` + strings.Repeat("]}", depth)
+
+	var n node
+	err := Unmarshal([]byte(data), &n)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) {
+		t.Fatalf("Expected *UnknownFieldError, got %v", err)
+	}
+	want := strings.Repeat("next[0].", depth) + "nxet"
+	if ufe.Path() != want {
+		t.Errorf("Expected the error at a path of %d bytes, got %d bytes", len(want), len(ufe.Path()))
+	}
+
+	err = NewDecoder(WithMaxDepth(depth)).Unmarshal([]byte(data), &n)
+	var mde *MaxDepthError
+	if !errors.As(err, &mde) {
+		t.Fatalf("Expected *MaxDepthError, got %v", err)
+	}
+	if want := strings.TrimSuffix(strings.Repeat("next[0].", depth/2), "."); mde.Path() != want {
+		t.Errorf("Expected the error at a path of %d bytes, got %d bytes", len(want), len(mde.Path()))
+	}
+}
+
+// TestFrameState checks that closing or abandoning a frame restores the
+// state it changed: the unknown-key policy, the present tracking of the
+// enclosing struct and the path.
+func TestFrameState(t *testing.T) {
+	type Vendor struct {
+		ID   string            `json:"id"`
+		Tags map[string]string `json:"tags"`
+	}
+	type Payload struct {
+		Vendor  Vendor   `json:"vendor" strict:"lenient"`
+		Vendors []Vendor `json:"vendors" strict:"lenient"`
+		Name    string   `json:"name" strict:"default=anon"`
+		Count   int      `json:"count"`
+	}
+
+	violations, err := NewDecoder().Check([]byte(`{"vendor": {"id": "v", "x": 1}, "vendors": [{"y": 2}, {}], "extra": 3, "vendor": {"z": 4}, "more": 5}`), &Payload{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Path)
+	}
+	if want := []string{"extra", "more"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected violations at %q, got %q", want, got)
+	}
+
+	var p Payload
+	if err := Unmarshal([]byte(`{"vendor": {"id": "v", "tags": {"a": "b"}}, "count": 1}`), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "anon" || p.Vendor.Tags["a"] != "b" || p.Count != 1 {
+		t.Errorf("Unexpected result %+v", p)
+	}
+
+	s := &decodeState{d: NewDecoder(), scan: scanner{data: []byte(`{"vendor": {"tags": {"a": 1}}}`)}}
+	err = s.value(reflect.ValueOf(&p).Elem(), s.d.planFor(reflect.TypeOf(p)))
+	if err == nil {
+		t.Fatal("Expected a type error")
+	}
+	if s.stack.len() != 0 || len(s.path) != 0 || s.depth != 0 || s.policy != PolicyDefault || s.present != nil {
+		t.Errorf("State not restored after an error: %d frames, path %q, depth %d, policy %v", s.stack.len(), s.pathString(), s.depth, s.policy)
+	}
+}
@@ -0,0 +1,150 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// Fuzz is a fuzzing harness for the decoder, in the form go-fuzz and the
+// libFuzzer drivers of OSS-Fuzz expect. Wire it into native Go fuzzing with
+//
+//	func FuzzStrictJSON(f *testing.F) {
+//		for _, seed := range strictjson.FuzzCorpus() {
+//			f.Add(seed)
+//		}
+//		f.Fuzz(func(t *testing.T, data []byte) { strictjson.Fuzz(data) })
+//	}
+//
+// Besides looking for crashes, Fuzz checks the decoder differentially
+// against encoding/json and panics on a mismatch:
+//
+//   - decoding into an any must accept exactly the documents encoding/json
+//     accepts, a leading byte order mark aside, and produce the same value;
+//   - a document that decodes strictly into a struct must also decode with
+//     encoding/json, to the same value, since strict decoding only ever
+//     rejects more;
+//   - Validate must accept every document Unmarshal accepts;
+//   - a decoded value must survive a Marshal and Unmarshal round trip.
+//
+// It returns 1 for documents that decode into an any and 0 otherwise, so
+// that fuzzers favour valid JSON.
+func Fuzz(data []byte) int {
+	var got any
+	err := fuzzDecoder.Unmarshal(data, &got)
+	var want any
+	wantErr := json.Unmarshal(bytes.TrimPrefix(data, utf8BOM), &want)
+	if (err == nil) != (wantErr == nil) {
+		panic(fmt.Sprintf("strictjson: decoding %q into any: got error %v, encoding/json got %v", data, err, wantErr))
+	}
+	if err == nil && !reflect.DeepEqual(got, want) {
+		panic(fmt.Sprintf("strictjson: decoding %q into any: got %#v, encoding/json got %#v", data, got, want))
+	}
+
+	var target fuzzTarget
+	if targetErr := fuzzDecoder.Unmarshal(data, &target); targetErr == nil {
+		var lenient fuzzTarget
+		if err := json.Unmarshal(bytes.TrimPrefix(data, utf8BOM), &lenient); err != nil {
+			panic(fmt.Sprintf("strictjson: %q decodes strictly but encoding/json rejects it: %v", data, err))
+		}
+		if !reflect.DeepEqual(target, lenient) {
+			panic(fmt.Sprintf("strictjson: decoding %q: got %#v, encoding/json got %#v", data, target, lenient))
+		}
+		if err := fuzzDecoder.Validate(data, (*fuzzTarget)(nil)); err != nil {
+			panic(fmt.Sprintf("strictjson: %q decodes but Validate rejects it: %v", data, err))
+		}
+		fuzzRoundTrip(data, &target)
+	}
+
+	if err != nil {
+		return 0
+	}
+	fuzzRoundTrip(data, &got)
+	return 1
+}
+
+// fuzzDecoder matches the depth limit of encoding/json and its handling of
+// the ,string option, so that both reject the same documents for those.
+var fuzzDecoder = NewDecoder(WithMaxDepth(10000), WithStrictStringOption(true))
+
+// fuzzTarget is the struct type Fuzz decodes into, covering the kinds of
+// fields and the strict tag options with the most decoding logic.
+type fuzzTarget struct {
+	Name    string             `json:"name"`
+	Count   int64              `json:"count"`
+	Ratio   float64            `json:"ratio"`
+	Quoted  uint16             `json:"quoted,string"`
+	OK      bool               `json:"ok"`
+	Ptr     *string            `json:"ptr"`
+	Tags    []string           `json:"tags"`
+	Pair    [2]int8            `json:"pair"`
+	Attrs   map[string]float64 `json:"attrs"`
+	Any     any                `json:"any"`
+	Raw     json.RawMessage    `json:"raw"`
+	Bytes   []byte             `json:"bytes"`
+	Child   *fuzzTarget        `json:"child"`
+	Nodes   []fuzzTarget       `json:"nodes,omitempty"`
+	Skipped string             `json:"-"`
+}
+
+// fuzzRoundTrip panics unless v, decoded from data, encodes to a document
+// that decodes back to a value with the same encoding. Encodings are
+// compared rather than values since a json.RawMessage holds its document
+// as written, spacing and all.
+func fuzzRoundTrip(data []byte, v any) {
+	encoded, err := Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("strictjson: cannot encode the value decoded from %q: %v", data, err))
+	}
+	again := reflect.New(reflect.TypeOf(v).Elem())
+	if err := fuzzDecoder.Unmarshal(encoded, again.Interface()); err != nil {
+		panic(fmt.Sprintf("strictjson: cannot decode %s, the encoding of %q: %v", encoded, data, err))
+	}
+	reencoded, err := Marshal(again.Interface())
+	if err != nil || !bytes.Equal(reencoded, encoded) {
+		panic(fmt.Sprintf("strictjson: %q does not survive a round trip through %s: got %s, %v", data, encoded, reencoded, err))
+	}
+}
+
+// FuzzCorpus returns seed inputs for Fuzz: valid documents for its struct
+// target and for an any, and near misses around the decoder's strict rules
+// and JSON syntax, such as mis-cased and unknown keys, trailing commas,
+// escapes, byte order marks, invalid UTF-8 and deep nesting. Each call
+// returns a fresh copy.
+func FuzzCorpus() [][]byte {
+	seeds := make([][]byte, len(fuzzSeeds))
+	for i, seed := range fuzzSeeds {
+		seeds[i] = []byte(seed)
+	}
+	return seeds
+}
+
+var fuzzSeeds = []string{
+	`{"name": "a", "count": 1, "ratio": 0.5, "quoted": "7", "ok": true, "ptr": "p", "tags": ["x"], "pair": [1, 2], ` +
+		`"attrs": {"k": 1e3}, "any": [null, {}], "raw": {"r": 1}, "bytes": "AQID", "child": {"name": "c"}, "nodes": [{}]}`,
+	`{"name": null, "child": null, "tags": [], "attrs": {}}`,
+	`{"raw": null, "bytes": null}`,
+	`{"Name": "a"}`,
+	`{"nmae": "a"}`,
+	`{"name": "a", "name": "b"}`,
+	`{"name": "😀"}`,
+	`{"quoted": 7}`,
+	`{"count": 1.5}`,
+	`{"count": 9223372036854775808}`,
+	`{"pair": [1, 2, 3]}`,
+	`{"bytes": "not base64"}`,
+	`{"name": "a",}`,
+	`{"name": nnull}`,
+	`[1, 2,]`,
+	`{"a": [1, "two", 3.0, -0, 1e-7, true, false, null]}`,
+	"\xef\xbb\xbf{}",
+	"\"\xff\"",
+	`"\ud800"`,
+	`"\u0000"`,
+	`1e400`,
+	`{} {}`,
+	` 1 `,
+	`[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[[]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]]`,
+	`{"child": {"child": {"child": {"nodes": [{"child": {}}]}}}}`,
+}
@@ -0,0 +1,43 @@
+package strictjson
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzUnmarshal(f *testing.F) {
+	for _, seed := range FuzzCorpus() {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		Fuzz(data)
+	})
+}
+
+func TestFuzzCorpus(t *testing.T) {
+	valid := 0
+	for _, seed := range FuzzCorpus() {
+		valid += Fuzz(seed)
+	}
+	if want := 20; valid != want {
+		t.Errorf("Fuzz() accepted %d seeds, want %d", valid, want)
+	}
+
+	seeds := FuzzCorpus()
+	seeds[0][0] = 'x'
+	if FuzzCorpus()[0][0] != '{' {
+		t.Error("FuzzCorpus() returned shared storage")
+	}
+}
+
+func TestFuzzDetectsDifferences(t *testing.T) {
+	defer func(d *Decoder) { fuzzDecoder = d }(fuzzDecoder)
+	fuzzDecoder = NewDecoder(WithMaxDepth(2))
+
+	defer func() {
+		if p := recover(); p == nil || !strings.Contains(p.(string), "encoding/json got <nil>") {
+			t.Errorf("Expected a panic for a depth mismatch, got %v", p)
+		}
+	}()
+	Fuzz([]byte(`[[[1]]]`))
+}
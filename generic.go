@@ -0,0 +1,14 @@
+package strictjson
+
+// UnmarshalAs decodes data into a new value of type T and returns it.
+func UnmarshalAs[T any](data []byte) (T, error) {
+	return DecodeAs[T](NewDecoder(), data)
+}
+
+// DecodeAs is UnmarshalAs with d's options. Go does not allow type
+// parameters on methods, so this stands in for a Decoder.As[T] method.
+func DecodeAs[T any](d *Decoder, data []byte) (T, error) {
+	var v T
+	err := d.Unmarshal(data, &v)
+	return v, err
+}
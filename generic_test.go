@@ -0,0 +1,35 @@
+package strictjson
+
+import "testing"
+
+func TestUnmarshalAs(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	p, err := UnmarshalAs[Person]([]byte(`{"name": "John"}`))
+	if err != nil || p.Name != "John" {
+		t.Errorf("UnmarshalAs() = %+v, %v", p, err)
+	}
+
+	if _, err := UnmarshalAs[Person]([]byte(`{"Name": "John"}`)); err == nil {
+		t.Error("Expected error for mis-cased key")
+	}
+
+	ptr, err := UnmarshalAs[*Person]([]byte(`{"name": "Jane"}`))
+	if err != nil || ptr == nil || ptr.Name != "Jane" {
+		t.Errorf("UnmarshalAs[*Person]() = %+v, %v", ptr, err)
+	}
+}
+
+func TestDecodeAs(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	d := NewDecoder(WithDisallowUnknownFields(false))
+	p, err := DecodeAs[Person](d, []byte(`{"name": "John", "extra": 1}`))
+	if err != nil || p.Name != "John" {
+		t.Errorf("DecodeAs() = %+v, %v", p, err)
+	}
+}
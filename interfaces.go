@@ -0,0 +1,178 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var implRegistry struct {
+	sync.RWMutex
+	byType map[reflect.Type][]reflect.Type // interface -> implementations
+}
+
+// RegisterInterfaceImpl registers the struct type T, or *T if only the
+// pointer has the methods, as an implementation of the interface type I.
+// With WithValidateInterfaceObjects, a JSON object decoded into a value of
+// type I is decoded into a new T with every strict check, instead of being
+// handed to encoding/json, which cannot decode into interfaces with methods
+// and turns objects in an any into map[string]any unchecked.
+//
+// When several implementations are registered for I, the keys of the object
+// discriminate between them: it is decoded into the first registered type
+// whose fields match all of its keys, or, if none does, into the one with the
+// fewest unknown keys, which is then reported as usual.
+//
+// RegisterInterfaceImpl panics if I is not an interface type or T is not a
+// struct type implementing it. It is intended to be called during program
+// initialization.
+func RegisterInterfaceImpl[I, T any]() {
+	it := reflect.TypeOf((*I)(nil)).Elem()
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if it.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("strictjson: RegisterInterfaceImpl of non-interface type %s", it))
+	}
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("strictjson: RegisterInterfaceImpl of non-struct type %s", t))
+	}
+	if !t.Implements(it) {
+		if !reflect.PointerTo(t).Implements(it) {
+			panic(fmt.Sprintf("strictjson: RegisterInterfaceImpl: %s does not implement %s", t, it))
+		}
+		t = reflect.PointerTo(t)
+	}
+
+	implRegistry.Lock()
+	if implRegistry.byType == nil {
+		implRegistry.byType = make(map[reflect.Type][]reflect.Type)
+	}
+	registered := false
+	for _, impl := range implRegistry.byType[it] {
+		registered = registered || impl == t
+	}
+	if !registered {
+		implRegistry.byType[it] = append(implRegistry.byType[it], t)
+	}
+	implRegistry.Unlock()
+
+	// Plans of types with fields of type I must now decode them.
+	ClearCache()
+}
+
+// WithValidateInterfaceObjects(true) decodes JSON objects into interface
+// values through the implementations registered with RegisterInterfaceImpl,
+// so that they are validated strictly. Interfaces without registered
+// implementations, and values other than objects, are still decoded by the
+// backend.
+func WithValidateInterfaceObjects(validate bool) DecoderOption {
+	return func(d *Decoder) {
+		d.ValidateInterfaceObjects = validate
+	}
+}
+
+// interfaceImpls returns the implementations registered for the interface
+// type t.
+func interfaceImpls(t reflect.Type) []reflect.Type {
+	implRegistry.RLock()
+	defer implRegistry.RUnlock()
+	return implRegistry.byType[t]
+}
+
+// iface decodes the object at the scanner into v, an interface value, as
+// the member of its union selected by its discriminator or else as the
+// registered implementation chosen by its keys.
+func (s *decodeState) iface(v reflect.Value, p *typePlan) error {
+	if p.union != nil {
+		member, err := s.unionMember(p.union)
+		if err != nil {
+			return err
+		}
+		return s.inUnion(p.union, func() error { return s.concrete(v, member) })
+	}
+	return s.concrete(v, s.implFor(p, s.objectKeys))
+}
+
+// concrete decodes the next value into a new value of the type of p, which
+// it stores in v, an interface value, on success.
+func (s *decodeState) concrete(v reflect.Value, p *typePlan) error {
+	nv := reflect.New(p.typ).Elem()
+	if err := s.value(nv, p); err != nil {
+		return err
+	}
+	v.Set(nv)
+	return nil
+}
+
+// mapConcrete mirrors concrete for a generic source value.
+func (s *decodeState) mapConcrete(v reflect.Value, p *typePlan, src any) error {
+	nv := reflect.New(p.typ).Elem()
+	if err := s.mapValue(nv, p, src); err != nil {
+		return err
+	}
+	v.Set(nv)
+	return nil
+}
+
+// implFor returns the plan of the implementation of the interface of p to
+// decode an object into. keys lists the keys of the object; it is only
+// called when several implementations are registered.
+func (s *decodeState) implFor(p *typePlan, keys func() []string) *typePlan {
+	if len(p.impls) == 1 {
+		return p.impls[0]
+	}
+	// Pick the implementation with the fewest unknown keys, preferring
+	// earlier ones on a tie.
+	names := keys()
+	best, fewest := p.impls[0], len(names)+1
+	for _, impl := range p.impls {
+		sf := implFields(impl)
+		unknown := 0
+		for _, key := range names {
+			if sf == nil {
+				unknown++
+			} else if _, ok := s.lookup(sf, key); !ok {
+				unknown++
+			}
+		}
+		if unknown < fewest {
+			best, fewest = impl, unknown
+		}
+	}
+	return best
+}
+
+// objectKeys returns the keys of the object at the scanner without
+// consuming it. A malformed object yields the keys before the error, which
+// the decode then reports.
+func (s *decodeState) objectKeys() []string {
+	var keys []string
+	scan := s.scan
+	scan.pos++ // '{'
+	if scan.consume('}') {
+		return nil
+	}
+	for {
+		key, err := scan.readKey()
+		if err != nil {
+			return keys
+		}
+		keys = append(keys, key)
+		if _, err := scan.skipValue(); err != nil || !scan.consume(',') {
+			return keys
+		}
+	}
+}
+
+// implFields returns the field table of the struct decoded by the plan of an
+// implementation, or nil if it has none.
+func implFields(p *typePlan) *structFields {
+	if p.fields != nil {
+		return p.fields
+	}
+	base := p.typ
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	sf, _ := getStructFields(base, fieldConfig{})
+	return sf
+}
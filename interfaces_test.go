@@ -0,0 +1,165 @@
+package strictjson
+
+import (
+	"errors"
+	"testing"
+)
+
+type shape interface{ area() float64 }
+
+type circle struct {
+	Radius float64 `json:"radius"`
+}
+
+func (c circle) area() float64 { return 3 * c.Radius * c.Radius }
+
+type rect struct {
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+func (r *rect) area() float64 { return r.Width * r.Height }
+
+type drawing struct {
+	Name   string  `json:"name"`
+	Main   shape   `json:"main"`
+	Shapes []shape `json:"shapes"`
+}
+
+func init() {
+	RegisterInterfaceImpl[shape, circle]()
+	RegisterInterfaceImpl[shape, rect]()
+}
+
+func TestValidateInterfaceObjects(t *testing.T) {
+	d := NewDecoder(WithValidateInterfaceObjects(true))
+	data := []byte(`{
+		"name": "d",
+		"main": {"radius": 2},
+		"shapes": [{"width": 2, "height": 3}, {"radius": 1}, null]
+	}`)
+
+	var got drawing
+	if err := d.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if c, ok := got.Main.(circle); !ok || c.Radius != 2 {
+		t.Errorf("Expected main to be circle{2}, got %#v", got.Main)
+	}
+	if len(got.Shapes) != 3 {
+		t.Fatalf("Expected 3 shapes, got %d", len(got.Shapes))
+	}
+	if r, ok := got.Shapes[0].(*rect); !ok || r.area() != 6 {
+		t.Errorf("Expected shapes[0] to be &rect{2, 3}, got %#v", got.Shapes[0])
+	}
+	if _, ok := got.Shapes[1].(circle); !ok {
+		t.Errorf("Expected shapes[1] to be circle, got %#v", got.Shapes[1])
+	}
+	if got.Shapes[2] != nil {
+		t.Errorf("Expected shapes[2] to be nil, got %#v", got.Shapes[2])
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantKey  string
+		wantPath string
+	}{
+		{name: "miscased key", data: `{"main": {"Radius": 2}}`, wantKey: "Radius", wantPath: "main.Radius"},
+		{name: "closest implementation", data: `{"shapes": [{"width": 1, "hieght": 2}]}`, wantKey: "hieght", wantPath: "shapes[0].hieght"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v drawing
+			err := d.Unmarshal([]byte(tt.data), &v)
+			var ufe *UnknownFieldError
+			if !errors.As(err, &ufe) || ufe.Field() != tt.wantKey || ufe.Path() != tt.wantPath {
+				t.Fatalf("Expected unknown field %q at %q, got %v", tt.wantKey, tt.wantPath, err)
+			}
+			if err := d.Validate([]byte(tt.data), &v); !errors.As(err, &ufe) || ufe.Path() != tt.wantPath {
+				t.Errorf("Validate() expected unknown field at %q, got %v", tt.wantPath, err)
+			}
+		})
+	}
+
+	// DecodeMap chooses implementations the same way.
+	var fromMap drawing
+	m := map[string]any{"main": map[string]any{"width": 1.0, "height": 1.0}}
+	if err := d.DecodeMap(m, &fromMap); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if _, ok := fromMap.Main.(*rect); !ok {
+		t.Errorf("Expected DecodeMap to decode main as *rect, got %#v", fromMap.Main)
+	}
+}
+
+func TestValidateInterfaceObjectsDisabled(t *testing.T) {
+	// Without the option, interfaces are left to encoding/json, which
+	// cannot decode objects into interfaces with methods.
+	var v drawing
+	var tme *TypeMismatchError
+	if err := Unmarshal([]byte(`{"main": {"radius": 2}}`), &v); !errors.As(err, &tme) {
+		t.Errorf("Expected *TypeMismatchError, got %v", err)
+	}
+}
+
+type anyHolder struct {
+	Payload any `json:"payload"`
+}
+
+type anyPayload struct {
+	ID int `json:"id"`
+}
+
+func TestRegisterInterfaceImplEmptyInterface(t *testing.T) {
+	RegisterInterfaceImpl[any, anyPayload]()
+	defer func() {
+		implRegistry.Lock()
+		delete(implRegistry.byType, anyType)
+		implRegistry.Unlock()
+		ClearCache()
+	}()
+
+	d := NewDecoder(WithValidateInterfaceObjects(true))
+	var v anyHolder
+	if err := d.Unmarshal([]byte(`{"payload": {"id": 7}}`), &v); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if p, ok := v.Payload.(anyPayload); !ok || p.ID != 7 {
+		t.Errorf("Expected anyPayload{7}, got %#v", v.Payload)
+	}
+
+	// Values other than objects are still decoded by the backend.
+	if err := d.Unmarshal([]byte(`{"payload": [1, "a"]}`), &v); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if _, ok := v.Payload.([]any); !ok {
+		t.Errorf("Expected []any, got %#v", v.Payload)
+	}
+
+	var ufe *UnknownFieldError
+	if err := d.Unmarshal([]byte(`{"payload": {"ID": 7}}`), &v); !errors.As(err, &ufe) || ufe.Path() != "payload.ID" {
+		t.Errorf("Expected unknown field at payload.ID, got %v", err)
+	}
+}
+
+func TestRegisterInterfaceImplPanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{name: "non-interface", register: RegisterInterfaceImpl[circle, circle]},
+		{name: "non-struct", register: RegisterInterfaceImpl[any, int]},
+		{name: "not implemented", register: RegisterInterfaceImpl[shape, anyPayload]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.register()
+		})
+	}
+}
@@ -0,0 +1,49 @@
+//go:build goexperiment.jsonv2
+
+package strictjson
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+)
+
+// UnmarshalValue decodes a jsontext.Value with d's strict rules.
+func (d *Decoder) UnmarshalValue(val jsontext.Value, v any) error {
+	return d.Unmarshal([]byte(val), v)
+}
+
+// V2Options maps d's options onto their encoding/json/v2 equivalents, for
+// code that decodes some values with json/v2 directly during a migration.
+// Member names are matched case-insensitively only if CaseInsensitive is
+// set. Options without a v2 counterpart, such as IgnorePaths or
+// suggestions, are not represented.
+func (d *Decoder) V2Options() jsonv2.Options {
+	return jsonv2.JoinOptions(
+		jsonv2.RejectUnknownMembers(d.DisallowUnknownFields),
+		jsonv2.MatchCaseInsensitiveNames(d.CaseInsensitive),
+		jsontext.AllowDuplicateNames(!d.DisallowDuplicateKeys),
+	)
+}
+
+// Strict wraps a value so that json/v2 decodes it with strictjson's default
+// rules:
+//
+//	var req strictjson.Strict[Request]
+//	err := jsonv2.Unmarshal(data, &req)
+type Strict[T any] struct {
+	Value T
+}
+
+// UnmarshalJSONFrom implements json/v2's UnmarshalerFrom.
+func (s *Strict[T]) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	val, err := dec.ReadValue()
+	if err != nil {
+		return err
+	}
+	return Unmarshal(val, &s.Value)
+}
+
+// MarshalJSONTo implements json/v2's MarshalerTo.
+func (s Strict[T]) MarshalJSONTo(enc *jsontext.Encoder) error {
+	return jsonv2.MarshalEncode(enc, s.Value)
+}
@@ -0,0 +1,74 @@
+//go:build goexperiment.jsonv2
+
+package strictjson
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"errors"
+	"testing"
+)
+
+func TestStrictV2Wrapper(t *testing.T) {
+	type Request struct {
+		Name string `json:"name"`
+	}
+
+	var req struct {
+		Body Strict[Request] `json:"body"`
+	}
+	if err := jsonv2.Unmarshal([]byte(`{"body": {"name": "a"}}`), &req); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if req.Body.Value.Name != "a" {
+		t.Errorf("Expected name 'a', got %q", req.Body.Value.Name)
+	}
+
+	err := jsonv2.Unmarshal([]byte(`{"body": {"NAME": "a"}}`), &req)
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) {
+		t.Errorf("Expected UnknownFieldError, got %v", err)
+	}
+
+	out, err := jsonv2.Marshal(req.Body)
+	if err != nil || string(out) != `{"name":"a"}` {
+		t.Errorf("Expected wrapped value to marshal transparently, got %s (%v)", out, err)
+	}
+}
+
+func TestUnmarshalValue(t *testing.T) {
+	var v struct {
+		ID int `json:"id"`
+	}
+	if err := NewDecoder().UnmarshalValue(jsontext.Value(`{"id": 3}`), &v); err != nil || v.ID != 3 {
+		t.Errorf("Expected id 3, got %+v (%v)", v, err)
+	}
+}
+
+func TestV2Options(t *testing.T) {
+	type T struct {
+		Name string `json:"name"`
+	}
+	opts := NewDecoder(WithDisallowDuplicateKeys(true)).V2Options()
+
+	var v T
+	if err := jsonv2.Unmarshal([]byte(`{"NAME": "a"}`), &v, opts); err == nil {
+		t.Error("Expected mis-cased key to be rejected")
+	}
+	if err := jsonv2.Unmarshal([]byte(`{"name": "a", "name": "b"}`), &v, opts); err == nil {
+		t.Error("Expected duplicate key to be rejected")
+	}
+	if err := jsonv2.Unmarshal([]byte(`{"name": "a"}`), &v, opts); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	folding := NewDecoder(WithCaseSensitive(false)).V2Options()
+	if err := jsonv2.Unmarshal([]byte(`{"NAME": "a"}`), &v, folding); err != nil || v.Name != "a" {
+		t.Errorf("Expected mis-cased key to match with CaseInsensitive set, got %+v (%v)", v, err)
+	}
+
+	lenient := NewDecoder(WithDisallowUnknownFields(false)).V2Options()
+	if err := jsonv2.Unmarshal([]byte(`{"extra": 1}`), &v, lenient); err != nil {
+		t.Errorf("Expected unknown key to be tolerated, got %v", err)
+	}
+}
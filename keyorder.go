@@ -0,0 +1,77 @@
+package strictjson
+
+// KeyOrder selects the order in which the keys of objects must appear.
+type KeyOrder uint8
+
+const (
+	// KeyOrderAny accepts keys in any order, the default.
+	KeyOrderAny KeyOrder = iota
+	// KeyOrderFields requires the keys of objects decoded into structs to
+	// name their fields in the order the fields are declared, the order in
+	// which encoding/json writes them: the fields of an embedded struct take
+	// its place. Keys that match no field, and objects decoded into maps,
+	// are not checked.
+	KeyOrderFields
+	// KeyOrderSorted requires the keys of every object to be sorted in
+	// ascending byte order, after unescaping, including objects decoded
+	// into maps and interface values or skipped.
+	KeyOrderSorted
+)
+
+// WithRequireKeyOrder rejects objects whose keys are not in the given order
+// with a *KeyOrderError naming the first key out of order, for protocols
+// whose producers must emit keys in a fixed order. DecodeMap, whose input
+// has no order, does not check it.
+func WithRequireKeyOrder(order KeyOrder) DecoderOption {
+	return func(d *Decoder) {
+		d.KeyOrder = order
+	}
+}
+
+// keyOrder holds what the order of the next key of an object is checked
+// against: the previous key and, for KeyOrderFields, the field it named.
+type keyOrder struct {
+	prev  string
+	field *fieldInfo
+	begun bool
+}
+
+// orderedKey records key, read from an object decoded with the field table
+// sf, or from a map's object if sf is nil, and reports it if it is out of
+// order. fi is the field readFieldKey matched key to, if any.
+func (s *decodeState) orderedKey(o *keyOrder, sf *structFields, key string, fi *fieldInfo) error {
+	switch s.d.KeyOrder {
+	case KeyOrderFields:
+		if sf == nil {
+			return nil
+		}
+		if fi == nil {
+			fi, _ = s.lookup(sf, key)
+		}
+		if fi == nil {
+			return nil
+		}
+		if o.field != nil && compareIndex(fi.fieldIndex, o.field.fieldIndex) < 0 {
+			return s.violation(newKeyOrderError(key, o.prev, s.pathString()))
+		}
+		o.prev, o.field = key, fi
+	case KeyOrderSorted:
+		if o.begun && key < o.prev {
+			return s.violation(newKeyOrderError(key, o.prev, s.pathString()))
+		}
+		o.prev, o.begun = key, true
+	}
+	return nil
+}
+
+// compareIndex orders the index sequences of two fields the way
+// encoding/json orders fields, so that a field promoted from an embedded
+// struct takes the place of the embedded struct.
+func compareIndex(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return compareInts(int64(a[i]), int64(b[i]))
+		}
+	}
+	return compareInts(int64(len(a)), int64(len(b)))
+}
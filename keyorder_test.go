@@ -0,0 +1,119 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type header struct {
+	Version int            `json:"version"`
+	Kind    string         `json:"kind"`
+	Body    map[string]any `json:"body"`
+	Trailer *header        `json:"trailer"`
+}
+
+func TestRequireKeyOrderFields(t *testing.T) {
+	d := NewDecoder(WithRequireKeyOrder(KeyOrderFields), WithAllowedExtraFields("$schema"))
+	var got header
+	// Absent fields may be skipped, and extra keys and map keys are free.
+	data := []byte(`{"$schema": "x", "version": 1, "body": {"z": 1, "a": 2}, "trailer": {"kind": "t"}}`)
+	if err := d.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.Version != 1 || got.Trailer.Kind != "t" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+
+	tests := []struct {
+		data     string
+		wantKey  string
+		wantPath string
+		wantMsg  string
+	}{
+		{`{"kind": "a", "version": 1}`, "version", "version", `strictjson: key "version" at "version" is out of order after "kind"`},
+		{`{"version": 1, "trailer": {"body": {}, "kind": "t"}}`, "kind", "trailer.kind", `strictjson: key "kind" at "trailer.kind" is out of order after "body"`},
+	}
+	for _, tt := range tests {
+		err := d.Unmarshal([]byte(tt.data), &header{})
+		verr := d.Validate([]byte(tt.data), &header{})
+		for _, err := range []error{err, verr} {
+			var koe *KeyOrderError
+			if !errors.As(err, &koe) || koe.Key() != tt.wantKey || koe.Path() != tt.wantPath {
+				t.Errorf("%s: expected *KeyOrderError for %q at %q, got %v", tt.data, tt.wantKey, tt.wantPath, err)
+				continue
+			}
+			if err.Error() != tt.wantMsg {
+				t.Errorf("%s: Error() = %q, want %q", tt.data, err.Error(), tt.wantMsg)
+			}
+			if ErrorCode(err) != CodeKeyOrder {
+				t.Errorf("%s: ErrorCode() = %q, want %q", tt.data, ErrorCode(err), CodeKeyOrder)
+			}
+		}
+	}
+}
+
+func TestRequireKeyOrderSorted(t *testing.T) {
+	d := NewDecoder(WithRequireKeyOrder(KeyOrderSorted))
+	var got header
+	if err := d.Unmarshal([]byte(`{"body": {"a": {"x": 1, "y": [{"m": 1, "n": 2}]}, "b": 2}, "kind": "k", "version": 1}`), &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		data     string
+		wantPath string
+		wantPrev string
+	}{
+		{`{"version": 1, "kind": "k"}`, "kind", "version"},
+		{`{"body": {"b": 1, "a": 2}}`, "body.a", "b"},
+		// Inside values delegated to the backend.
+		{`{"body": {"a": {"y": [{"n": 2, "m": 1}]}}}`, "body.a.y[0].m", "n"},
+		// Escaped keys are compared unescaped.
+		{`{"kind": "k", "\u0062ody": {}}`, "body", "kind"},
+	}
+	for _, tt := range tests {
+		err := d.Unmarshal([]byte(tt.data), &header{})
+		verr := d.Validate([]byte(tt.data), &header{})
+		for _, err := range []error{err, verr} {
+			var koe *KeyOrderError
+			if !errors.As(err, &koe) || koe.Path() != tt.wantPath || koe.Previous() != tt.wantPrev {
+				t.Errorf("%s: expected *KeyOrderError at %q after %q, got %v", tt.data, tt.wantPath, tt.wantPrev, err)
+			}
+		}
+	}
+
+	// Check reports every key out of order.
+	violations, err := d.Check([]byte(`{"kind": "k", "body": {"b": 1, "a": 2}}`), &header{})
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path)
+	}
+	if !reflect.DeepEqual(paths, []string{"body", "body.a"}) {
+		t.Errorf("Check() paths = %v", paths)
+	}
+}
+
+func TestRequireKeyOrderEmbedded(t *testing.T) {
+	type Meta struct {
+		Created string `json:"created"`
+	}
+	type record struct {
+		ID string `json:"id"`
+		Meta
+		Name string `json:"name"`
+	}
+	d := NewDecoder(WithRequireKeyOrder(KeyOrderFields))
+	// Promoted fields are ordered where their struct is embedded, as
+	// encoding/json writes them.
+	if err := d.Unmarshal([]byte(`{"id": "1", "created": "today", "name": "n"}`), &record{}); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+	var koe *KeyOrderError
+	if err := d.Unmarshal([]byte(`{"id": "1", "name": "n", "created": "today"}`), &record{}); !errors.As(err, &koe) || koe.Key() != "created" {
+		t.Errorf("Expected *KeyOrderError for created, got %v", err)
+	}
+}
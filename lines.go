@@ -0,0 +1,108 @@
+package strictjson
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// LineError reports a record of a JSON Lines stream that failed to decode.
+type LineError struct {
+	Line int // 1-based line number
+	err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("strictjson: line %d: %v", e.Line, e.err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.err
+}
+
+// LinesDecoder strictly decodes a stream of newline-delimited JSON records
+// (NDJSON, JSON Lines) one record at a time. Blank lines are skipped.
+type LinesDecoder struct {
+	d    *Decoder
+	r    *bufio.Reader
+	line int
+
+	// OnError, when set, is called for every record that fails to decode.
+	// Returning nil skips the record and Next moves on to the following
+	// line; a non-nil error is returned by Next. Read errors from the
+	// underlying reader are never passed to OnError.
+	OnError func(line int, err error) error
+}
+
+// NewLinesDecoder returns a LinesDecoder reading from r with a decoder
+// configured by opts.
+func NewLinesDecoder(r io.Reader, opts ...DecoderOption) *LinesDecoder {
+	return &LinesDecoder{d: NewDecoder(opts...), r: bufio.NewReader(r)}
+}
+
+// Next decodes the next record into v. It returns io.EOF once the stream is
+// exhausted. Decode failures are returned as *LineError. With MaxBytes set,
+// a line longer than MaxBytes fails with a *LimitError without being held
+// in memory whole.
+func (ld *LinesDecoder) Next(v any) error {
+	for {
+		data, tooLong, readErr := ld.readLine()
+		if readErr != nil && readErr != io.EOF {
+			return readErr
+		}
+		if len(data) == 0 && !tooLong && readErr == io.EOF {
+			return io.EOF
+		}
+		ld.line++
+
+		var err error
+		if tooLong {
+			err = ld.d.formatError(newLimitError(LimitBytes, "", ld.d.MaxBytes))
+		} else {
+			data = bytes.TrimSpace(data)
+			if len(data) == 0 {
+				if readErr == io.EOF {
+					return io.EOF
+				}
+				continue
+			}
+			if err = ld.d.Unmarshal(data, v); err == nil {
+				return nil
+			}
+		}
+		err = &LineError{Line: ld.line, err: err}
+		if ld.OnError == nil {
+			return err
+		}
+		if err := ld.OnError(ld.line, err); err != nil {
+			return err
+		}
+		if readErr == io.EOF {
+			return io.EOF
+		}
+	}
+}
+
+// readLine reads the next line, including its newline if any. Once the line
+// is longer than MaxBytes, not counting surrounding whitespace, the rest of
+// it is discarded and tooLong is set.
+func (ld *LinesDecoder) readLine() (line []byte, tooLong bool, err error) {
+	for {
+		chunk, err := ld.r.ReadSlice('\n')
+		if !tooLong {
+			line = append(line, chunk...)
+			if ld.d.MaxBytes > 0 && len(bytes.TrimSpace(line)) > ld.d.MaxBytes {
+				line, tooLong = nil, true
+			}
+		}
+		if err != bufio.ErrBufferFull {
+			return line, tooLong, err
+		}
+	}
+}
+
+// Line returns the number of the line most recently read.
+func (ld *LinesDecoder) Line() int {
+	return ld.line
+}
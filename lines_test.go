@@ -0,0 +1,134 @@
+package strictjson
+
+import (
+	"errors"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type logRecord struct {
+	Level string `json:"level"`
+	Msg   string `json:"msg"`
+}
+
+func TestLinesDecoder(t *testing.T) {
+	input := "{\"level\": \"info\", \"msg\": \"a\"}\n\n  {\"level\": \"warn\", \"msg\": \"b\"}\r\n{\"level\": \"error\", \"msg\": \"c\"}"
+	ld := NewLinesDecoder(strings.NewReader(input))
+
+	var got []string
+	for {
+		var rec logRecord
+		err := ld.Next(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() unexpected error: %v", err)
+		}
+		got = append(got, rec.Msg)
+	}
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("Expected records a,b,c, got %v", got)
+	}
+	if ld.Line() != 4 {
+		t.Errorf("Line() = %d, want 4", ld.Line())
+	}
+}
+
+func TestLinesDecoderErrors(t *testing.T) {
+	input := "{\"level\": \"info\"}\n{\"Level\": \"warn\"}\n{\"level\": \n"
+	ld := NewLinesDecoder(strings.NewReader(input), WithSuggestClosest(true))
+
+	var rec logRecord
+	if err := ld.Next(&rec); err != nil {
+		t.Fatalf("Next() unexpected error: %v", err)
+	}
+	err := ld.Next(&rec)
+	var lineErr *LineError
+	var unknown *UnknownFieldError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.As(err, &unknown) {
+		t.Fatalf("Expected LineError at line 2 wrapping UnknownFieldError, got %v", err)
+	}
+	want := `strictjson: line 2: strictjson: unknown field "Level" (did you mean "level"?)`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	// Decoding continues after an error.
+	err = ld.Next(&rec)
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &lineErr) || lineErr.Line != 3 || !errors.As(err, &syntaxErr) {
+		t.Errorf("Expected LineError at line 3 wrapping SyntaxError, got %v", err)
+	}
+	if err := ld.Next(&rec); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
+
+func TestLinesDecoderOnError(t *testing.T) {
+	input := "{\"msg\": \"a\"}\n{\"MSG\": \"b\"}\n{\"msg\": \"c\"}\n{\"bad\": 1}"
+	ld := NewLinesDecoder(strings.NewReader(input))
+	var failed []int
+	ld.OnError = func(line int, err error) error {
+		failed = append(failed, line)
+		return nil
+	}
+
+	var msgs []string
+	for {
+		var rec logRecord
+		err := ld.Next(&rec)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() unexpected error: %v", err)
+		}
+		msgs = append(msgs, rec.Msg)
+	}
+	if strings.Join(msgs, ",") != "a,c" || len(failed) != 2 || failed[0] != 2 || failed[1] != 4 {
+		t.Errorf("Unexpected records %v and failures %v", msgs, failed)
+	}
+
+	stop := errors.New("stop")
+	ld = NewLinesDecoder(strings.NewReader("{\"x\": 1}\n"))
+	ld.OnError = func(int, error) error { return stop }
+	var rec logRecord
+	if err := ld.Next(&rec); err != stop {
+		t.Errorf("Expected callback error to be returned, got %v", err)
+	}
+}
+
+func TestLinesDecoderMaxBytes(t *testing.T) {
+	long := `{"msg": "` + strings.Repeat("a", 8<<20) + `"}`
+	input := "  {\"msg\": \"a\"}  \n" + long + "\n{\"msg\": \"b\"}"
+	ld := NewLinesDecoder(strings.NewReader(input), WithMaxBytes(16))
+
+	var rec logRecord
+	if err := ld.Next(&rec); err != nil || rec.Msg != "a" {
+		t.Fatalf("Next() = %+v, %v", rec, err)
+	}
+
+	// The long line is rejected without being read into memory whole.
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	err := ld.Next(&rec)
+	runtime.ReadMemStats(&after)
+	var lineErr *LineError
+	var le *LimitError
+	if !errors.As(err, &lineErr) || lineErr.Line != 2 || !errors.As(err, &le) || le.Kind() != LimitBytes {
+		t.Fatalf("Expected LineError at line 2 wrapping a bytes LimitError, got %v", err)
+	}
+	if n := after.TotalAlloc - before.TotalAlloc; n > 1<<20 {
+		t.Errorf("Next() allocated %d bytes for an over-long line", n)
+	}
+
+	if err := ld.Next(&rec); err != nil || rec.Msg != "b" {
+		t.Errorf("Next() after the long line = %+v, %v", rec, err)
+	}
+	if err := ld.Next(&rec); err != io.EOF {
+		t.Errorf("Expected io.EOF, got %v", err)
+	}
+}
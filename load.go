@@ -0,0 +1,110 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// WithExpandEnv makes LoadFile replace ${NAME} references inside string
+// values with the value of the environment variable NAME. A reference to an
+// unset variable is an error. Object keys are left as written, so a
+// reference in a key is matched against the fields as is.
+func WithExpandEnv(expand bool) DecoderOption {
+	return func(d *Decoder) {
+		d.ExpandEnv = expand
+	}
+}
+
+// LoadFile strictly decodes the JSON file at path into v. It is meant for
+// application config structs, where a mis-cased key would otherwise fall
+// back to a default without notice. Comments and environment references are
+// handled only when enabled with WithAllowComments and WithExpandEnv.
+// Decode errors are prefixed with path.
+func LoadFile(path string, v any, opts ...DecoderOption) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	d := NewDecoder(opts...)
+	if d.ExpandEnv {
+		// Comments go first, so that references in them are not expanded.
+		if d.AllowComments {
+			data = stripComments(data)
+		}
+		if data, err = expandEnv(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if err := d.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// expandEnv substitutes ${NAME} references inside string values. Values
+// are escaped so that they cannot break out of the string. Object keys are
+// left as written, so that the environment cannot choose which field a
+// value goes to.
+func expandEnv(data []byte) ([]byte, error) {
+	var out []byte
+	inString := false
+	last := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case !inString:
+			if c == '"' {
+				end, key := keyString(data, i)
+				if key {
+					i = end
+				} else {
+					inString = true
+				}
+			}
+		case c == '\\':
+			i++
+		case c == '"':
+			inString = false
+		case c == '$' && i+1 < len(data) && data[i+1] == '{':
+			end := i + 2
+			for end < len(data) && data[end] != '}' && data[end] != '"' {
+				end++
+			}
+			if end >= len(data) || data[end] != '}' {
+				continue
+			}
+			name := string(data[i+2 : end])
+			val, ok := os.LookupEnv(name)
+			if !ok {
+				return nil, &UnmarshalError{message: fmt.Sprintf("strictjson: environment variable %q is not set", name)}
+			}
+			quoted, _ := json.Marshal(val)
+			out = append(out, data[last:i]...)
+			out = append(out, quoted[1:len(quoted)-1]...)
+			last = end + 1
+			i = end
+		}
+	}
+	if out == nil {
+		return data, nil
+	}
+	return append(out, data[last:]...), nil
+}
+
+// keyString finds the end of the string literal starting at data[start] and
+// reports whether it is an object key, one followed by a colon.
+func keyString(data []byte, start int) (int, bool) {
+	end := start + 1
+	for ; end < len(data) && data[end] != '"'; end++ {
+		if data[end] == '\\' {
+			end++
+		}
+	}
+	for i := end + 1; i < len(data); i++ {
+		if c := data[i]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return end, c == ':'
+		}
+	}
+	return end, false
+}
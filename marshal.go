@@ -1,7 +1,64 @@
 package strictjson
 
-import "encoding/json"
+import (
+	"bytes"
+	"encoding/json"
+)
 
+// Marshal returns the JSON encoding of v. It is json.Marshal, kept for
+// compatibility: it does not apply the strict checks of Encoder and
+// MarshalIndent.
 func Marshal(v any) ([]byte, error) {
 	return json.Marshal(v)
 }
+
+// MarshalIndent returns the JSON encoding of v laid out over several lines,
+// each beginning with prefix and indented by one copy of indent per level of
+// nesting. It is encoded by an Encoder with WithEscapeHTML(true), so its
+// output matches that of json.MarshalIndent, except that it fails where
+// encoding/json would silently drop or approximate something: a struct with
+// an invalid strict tag or a JSON name that several embedded structs
+// provide, NaN and infinite floats, invalid UTF-8 and pointer cycles. See
+// Encoder.Encode.
+func MarshalIndent(v any, prefix, indent string) ([]byte, error) {
+	return NewEncoder(nil, WithEscapeHTML(true), WithIndent(prefix, indent)).marshal(v)
+}
+
+// Compact appends to dst the JSON document src with insignificant
+// whitespace removed, as json.Compact does. Unlike json.Compact, it rejects
+// what strictjson's decoder can be set to reject, rather than passing it on:
+// an object that repeats a key fails with a *DuplicateKeyError, and invalid
+// UTF-8 or an unpaired surrogate escape with a *SyntaxError. Nothing is
+// appended on failure.
+func Compact(dst *bytes.Buffer, src []byte) error {
+	if err := checkOutput(src); err != nil {
+		return err
+	}
+	return json.Compact(dst, src)
+}
+
+// Indent appends to dst the JSON document src laid out as by MarshalIndent,
+// as json.Indent does, after the checks of Compact.
+func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
+	if err := checkOutput(src); err != nil {
+		return err
+	}
+	return json.Indent(dst, src, prefix, indent)
+}
+
+// checkOutput checks that src holds exactly one JSON value, with valid
+// strings and no repeated keys.
+func checkOutput(src []byte) error {
+	s := scanner{data: src, strictUTF8: true}
+	raw, err := s.skipValue()
+	if err != nil {
+		return err
+	}
+	if err := s.end(); err != nil {
+		return err
+	}
+	if rel, key, _, problem := findKey(raw, true, false, false); problem != keyOK {
+		return newDuplicateKeyError(key, formatPath(rel))
+	}
+	return nil
+}
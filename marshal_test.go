@@ -0,0 +1,95 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMarshal(t *testing.T) {
+	type Inner struct {
+		Note string `json:"note,omitempty"`
+	}
+	type doc struct {
+		Title string         `json:"title"`
+		Tags  []string       `json:"tags"`
+		Meta  map[string]int `json:"meta"`
+		Inner
+	}
+	v := doc{Title: "a<b> &  ", Tags: []string{"x"}, Meta: map[string]int{"b": 2, "a": 1}, Inner: Inner{Note: "n"}}
+
+	got, err := Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	want, _ := json.Marshal(v)
+	if !bytes.Equal(got, want) {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+
+	got, err = MarshalIndent(v, ">", "\t")
+	if err != nil {
+		t.Fatalf("MarshalIndent() unexpected error: %v", err)
+	}
+	want, _ = json.MarshalIndent(v, ">", "\t")
+	if !bytes.Equal(got, want) {
+		t.Errorf("MarshalIndent() = %s, want %s", got, want)
+	}
+
+	var ee *EncodeError
+	if _, err := MarshalIndent(map[string]float64{"x": math.Inf(-1)}, "", " "); !errors.As(err, &ee) || ee.Path() != "x" {
+		t.Errorf("Expected *EncodeError at x, got %v", err)
+	}
+	type A struct{ ID int }
+	type B struct{ ID int }
+	type ambiguous struct {
+		A
+		B
+	}
+	var fce *FieldConflictError
+	if _, err := MarshalIndent(ambiguous{}, "", " "); !errors.As(err, &fce) || fce.Field() != "ID" {
+		t.Errorf("Expected *FieldConflictError for ID, got %v", err)
+	}
+
+	// Marshal is json.Marshal: it drops the ambiguous field.
+	if got, err := Marshal(ambiguous{}); err != nil || string(got) != "{}" {
+		t.Errorf("Marshal() = %s, %v, want {}", got, err)
+	}
+}
+
+func TestCompactAndIndent(t *testing.T) {
+	src := []byte(` { "a" : [ 1 , 2 ] , "b" : { "c" : "é" } } `)
+	var buf bytes.Buffer
+	if err := Compact(&buf, src); err != nil {
+		t.Fatalf("Compact() unexpected error: %v", err)
+	}
+	if want := `{"a":[1,2],"b":{"c":"é"}}`; buf.String() != want {
+		t.Errorf("Compact() = %s, want %s", buf.String(), want)
+	}
+	buf.Reset()
+	if err := Indent(&buf, src, "", "  "); err != nil {
+		t.Fatalf("Indent() unexpected error: %v", err)
+	}
+	var want bytes.Buffer
+	_ = json.Indent(&want, src, "", "  ")
+	if buf.String() != want.String() {
+		t.Errorf("Indent() = %s, want %s", buf.String(), want.String())
+	}
+
+	var dke *DuplicateKeyError
+	buf.Reset()
+	if err := Compact(&buf, []byte(`{"a": [{"b": 1, "b": 2}]}`)); !errors.As(err, &dke) || dke.Path() != "a[0].b" {
+		t.Errorf("Expected *DuplicateKeyError at a[0].b, got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Compact() appended %q on failure", buf.String())
+	}
+	for _, bad := range []string{"\"\xff\"", `"\udc00"`, `{"a": 1} {}`, `{"a": }`} {
+		var se *SyntaxError
+		if err := Indent(&buf, []byte(bad), "", " "); !errors.As(err, &se) {
+			t.Errorf("Indent(%q): expected *SyntaxError, got %v", bad, err)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package strictjson
+
+import "reflect"
+
+// WithMergeExisting sets how input is combined with a destination that
+// already holds data. Either way, struct fields whose keys are absent keep
+// their values, fields of structs, including structs behind non-nil
+// pointers, are decoded into in place, and a present key overwrites scalar
+// fields.
+//
+// With merge set, maps, slices and arrays are merged too. A map keeps its
+// entries, and each key of the input is decoded into a copy of the existing
+// entry for that key, if any, so that nested structs and maps are merged
+// rather than replaced. A slice takes the length of the input array, and
+// each element is decoded into the existing element at its index, if any.
+// So is each element of a Go array, whose elements beyond the input's are
+// zeroed. Values of interface type are replaced, as with encoding/json.
+//
+// Without it, the default, every map, slice and array that a present key
+// decodes into is replaced by a new one holding only the input's entries or
+// elements, so nothing of its previous contents, or its backing array,
+// survives.
+func WithMergeExisting(merge bool) DecoderOption {
+	return func(d *Decoder) {
+		d.MergeExisting = merge
+	}
+}
+
+// resetMap prepares v, a map a JSON object is about to be decoded into: a
+// new map, or with MergeExisting the existing one, allocated if nil.
+func (s *decodeState) resetMap(v reflect.Value) {
+	if v.IsNil() || !s.d.MergeExisting {
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+}
+
+// mapElem returns the value the entry key of the map v is decoded into: a
+// new value or, with MergeExisting, a copy of the existing entry.
+func (s *decodeState) mapElem(v, key reflect.Value) reflect.Value {
+	elem := reflect.New(v.Type().Elem()).Elem()
+	if s.d.MergeExisting {
+		if old := v.MapIndex(key); old.IsValid() {
+			elem.Set(old)
+		}
+	}
+	return elem
+}
+
+// sliceElem returns the initial value of element i of a slice replacing v:
+// the zero value or, with MergeExisting, the existing element at i.
+func (s *decodeState) sliceElem(v reflect.Value, i int) reflect.Value {
+	if s.d.MergeExisting && i < v.Len() {
+		return v.Index(i)
+	}
+	return reflect.Zero(v.Type().Elem())
+}
+
+// zeroFrom zeroes the elements of the Go array v from index i on, which the
+// input did not reach, as encoding/json does.
+func zeroFrom(v reflect.Value, i int) {
+	for ; i < v.Len(); i++ {
+		v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+	}
+}
+
+// container decodes the next value into v if v is a map, slice or array
+// left to the backend by its plan and the value is an object or array, in
+// the way MergeExisting, StrictArrayLength or DisallowNullForNonPointer
+// requires: the backend would store a null member as a zero value. It
+// reports whether it decoded the value.
+func (s *decodeState) container(v reflect.Value) (bool, error) {
+	switch c := s.scan.peek(); {
+	case v.Kind() == reflect.Map && c == '{':
+		if s.d.MergeExisting || s.d.DisallowNullForNonPointer {
+			return true, s.complete(s.mapObject(v, s.d.planFor(v.Type().Elem())))
+		}
+	case v.Kind() == reflect.Slice && c == '[':
+		if s.d.MergeExisting || s.d.DisallowNullForNonPointer {
+			return true, s.complete(s.array(v, s.d.planFor(v.Type().Elem())))
+		}
+	case v.Kind() == reflect.Array && c == '[':
+		if s.d.StrictArrayLength || s.d.DisallowNullForNonPointer {
+			return true, s.complete(s.array(v, s.d.planFor(v.Type().Elem())))
+		}
+		return false, nil
+	default:
+		return false, nil
+	}
+	// The backend merges into maps and reuses the backing arrays of slices.
+	v.Set(reflect.Zero(v.Type()))
+	return false, nil
+}
+
+// mapContainer mirrors container for a generic source value.
+func (s *decodeState) mapContainer(v reflect.Value, src any) (bool, error) {
+	switch src := src.(type) {
+	case map[string]any:
+		if v.Kind() != reflect.Map {
+			return false, nil
+		}
+		if s.d.MergeExisting || s.d.DisallowNullForNonPointer {
+			return true, s.mapMap(v, s.d.planFor(v.Type().Elem()), src)
+		}
+	case []any:
+		if v.Kind() == reflect.Array && (s.d.StrictArrayLength || s.d.DisallowNullForNonPointer) {
+			return true, s.mapSlice(v, s.d.planFor(v.Type().Elem()), src)
+		}
+		if v.Kind() != reflect.Slice {
+			return false, nil
+		}
+		if s.d.MergeExisting || s.d.DisallowNullForNonPointer {
+			return true, s.mapSlice(v, s.d.planFor(v.Type().Elem()), src)
+		}
+	default:
+		return false, nil
+	}
+	v.Set(reflect.Zero(v.Type()))
+	return false, nil
+}
@@ -0,0 +1,132 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type mergeEndpoint struct {
+	Host string `json:"host"`
+	Port int    `json:"port"`
+}
+
+type mergeConfig struct {
+	Name      string                      `json:"name"`
+	Limits    map[string]int              `json:"limits"`
+	Nested    map[string]map[string]int   `json:"nested"`
+	Endpoints map[string]mergeEndpoint    `json:"endpoints"`
+	Ports     []int                       `json:"ports"`
+	Servers   []mergeEndpoint             `json:"servers"`
+	Pair      [3]mergeEndpoint            `json:"pair"`
+	Groups    map[string][]*mergeEndpoint `json:"groups"`
+	Owner     *mergeEndpoint              `json:"owner"`
+}
+
+func newMergeConfig() *mergeConfig {
+	return &mergeConfig{
+		Name:      "prod",
+		Limits:    map[string]int{"cpu": 2, "mem": 1},
+		Nested:    map[string]map[string]int{"a": {"x": 1, "y": 2}},
+		Endpoints: map[string]mergeEndpoint{"api": {Host: "api.local", Port: 80}},
+		Ports:     []int{1, 2, 3},
+		Servers:   []mergeEndpoint{{Host: "a", Port: 1}, {Host: "b", Port: 2}},
+		Pair:      [3]mergeEndpoint{{Host: "a", Port: 1}, {Host: "b", Port: 2}, {Host: "c", Port: 3}},
+		Owner:     &mergeEndpoint{Host: "owner", Port: 1},
+	}
+}
+
+const mergeInput = `{
+	"limits": {"mem": 4},
+	"nested": {"a": {"y": 3}},
+	"endpoints": {"api": {"port": 8080}, "web": {"host": "web.local"}},
+	"ports": [9],
+	"servers": [{"port": 10}, {"port": 20}, {"host": "c"}],
+	"pair": [{"port": 10}, {"host": "x"}],
+	"owner": {"port": 2}
+}`
+
+func TestMergeExisting(t *testing.T) {
+	want := &mergeConfig{
+		Name:      "prod",
+		Limits:    map[string]int{"cpu": 2, "mem": 4},
+		Nested:    map[string]map[string]int{"a": {"x": 1, "y": 3}},
+		Endpoints: map[string]mergeEndpoint{"api": {Host: "api.local", Port: 8080}, "web": {Host: "web.local"}},
+		Ports:     []int{9},
+		Servers:   []mergeEndpoint{{Host: "a", Port: 10}, {Host: "b", Port: 20}, {Host: "c"}},
+		Pair:      [3]mergeEndpoint{{Host: "a", Port: 10}, {Host: "x", Port: 2}},
+		Owner:     &mergeEndpoint{Host: "owner", Port: 2},
+	}
+	d := NewDecoder(WithMergeExisting(true))
+
+	got := newMergeConfig()
+	if err := d.Unmarshal([]byte(mergeInput), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() =\n%+v, want\n%+v", got, want)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(mergeInput), &m); err != nil {
+		t.Fatal(err)
+	}
+	got = newMergeConfig()
+	if err := d.DecodeMap(m, got); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeMap() =\n%+v, want\n%+v", got, want)
+	}
+}
+
+func TestReplaceExisting(t *testing.T) {
+	want := &mergeConfig{
+		Name:      "prod",
+		Limits:    map[string]int{"mem": 4},
+		Nested:    map[string]map[string]int{"a": {"y": 3}},
+		Endpoints: map[string]mergeEndpoint{"api": {Port: 8080}, "web": {Host: "web.local"}},
+		Ports:     []int{9},
+		Servers:   []mergeEndpoint{{Port: 10}, {Port: 20}, {Host: "c"}},
+		Pair:      [3]mergeEndpoint{{Port: 10}, {Host: "x"}},
+		Owner:     &mergeEndpoint{Host: "owner", Port: 2},
+	}
+
+	got := newMergeConfig()
+	ports := got.Ports
+	if err := Unmarshal([]byte(mergeInput), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() =\n%+v, want\n%+v", got, want)
+	}
+	if ports[0] != 1 {
+		t.Errorf("Backing array of the previous slice reused: %v", ports)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(mergeInput), &m); err != nil {
+		t.Fatal(err)
+	}
+	got = newMergeConfig()
+	if err := NewDecoder().DecodeMap(m, got); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeMap() =\n%+v, want\n%+v", got, want)
+	}
+}
+
+func TestMergeExistingPointerElements(t *testing.T) {
+	got := newMergeConfig()
+	shared := &mergeEndpoint{Host: "g", Port: 1}
+	got.Groups = map[string][]*mergeEndpoint{"g": {shared}}
+	d := NewDecoder(WithMergeExisting(true))
+	if err := d.Unmarshal([]byte(`{"groups": {"g": [{"port": 5}, {"host": "h"}]}}`), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	g := got.Groups["g"]
+	if len(g) != 2 || g[0] != shared || *g[0] != (mergeEndpoint{Host: "g", Port: 5}) || *g[1] != (mergeEndpoint{Host: "h"}) {
+		t.Errorf("Unexpected groups %+v %+v", g[0], g[1])
+	}
+}
@@ -0,0 +1,62 @@
+package strictjson
+
+import (
+	"reflect"
+	"time"
+)
+
+// DecodeStats summarizes one decode call for a MetricsHook.
+type DecodeStats struct {
+	// Type is the Go type decoded into or validated against.
+	Type reflect.Type
+	// Bytes is the length of the input; zero for DecodeMap.
+	Bytes int
+	// Objects counts the JSON objects checked against the fields of a
+	// struct.
+	Objects int
+	// UnknownFields counts keys that matched no field where unknown keys
+	// are not tolerated, whether they failed the call, were reported by
+	// Check or were passed to OnUnknownField.
+	UnknownFields int
+	// MaxDepth is the deepest nesting of objects and arrays walked by the
+	// decoder. Values delegated to encoding/json whole are not counted.
+	MaxDepth int
+	// Duration is the time the call took.
+	Duration time.Duration
+	// Violations holds what Check reported.
+	Violations []Violation
+	// Err is the error the call returned, if any.
+	Err error
+}
+
+// MetricsHook receives the statistics of every decode call.
+type MetricsHook func(DecodeStats)
+
+// WithMetricsHook calls h at the end of every Unmarshal, Check, Validate,
+// DecodeMap and ForEach call made through the decoder, including failed
+// ones, e.g. to count strict violations per endpoint. ForEachReader reports
+// each element separately. Calls rejected before decoding starts, for a
+// non-pointer target or input over MaxBytes, are not reported. h runs on
+// the decoding goroutine and must be safe for concurrent use.
+func WithMetricsHook(h MetricsHook) DecoderOption {
+	return func(d *Decoder) {
+		d.MetricsHook = h
+	}
+}
+
+// observe reports the statistics of the call decoding into t to the
+// decoder's MetricsHook. It is deferred by the entry points with a pointer
+// to their error result.
+func (s *decodeState) observe(t reflect.Type, err *error) {
+	if s.stats == nil {
+		return
+	}
+	stats := *s.stats
+	stats.Type = t
+	stats.Duration = time.Since(s.start)
+	if s.violations != nil {
+		stats.Violations = *s.violations
+	}
+	stats.Err = *err
+	s.d.MetricsHook(stats)
+}
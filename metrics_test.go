@@ -0,0 +1,59 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMetricsHook(t *testing.T) {
+	type Item struct {
+		SKU string `json:"sku"`
+	}
+	type Order struct {
+		ID    string `json:"id"`
+		Items []Item `json:"items"`
+	}
+
+	var got []DecodeStats
+	d := NewDecoder(WithMetricsHook(func(s DecodeStats) { got = append(got, s) }))
+
+	data := []byte(`{"id": "o1", "items": [{"sku": "a"}, {"sku": "b"}]}`)
+	var o Order
+	if err := d.Unmarshal(data, &o); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Expected 1 report, got %d", len(got))
+	}
+	s := got[0]
+	if s.Type != reflect.TypeOf(o) || s.Bytes != len(data) || s.Objects != 3 || s.MaxDepth != 3 || s.UnknownFields != 0 || s.Err != nil {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	got = nil
+	err := d.Unmarshal([]byte(`{"id": "o1", "items": [{"SKU": "a"}]}`), &o)
+	var ufe *UnknownFieldError
+	if len(got) != 1 || got[0].UnknownFields != 1 || !errors.As(got[0].Err, &ufe) || got[0].Err.Error() != err.Error() {
+		t.Errorf("Unexpected stats for failed call %+v", got)
+	}
+
+	got = nil
+	violations, err := d.Check([]byte(`{"ID": "o1", "extra": 1}`), &o)
+	if err != nil || len(got) != 1 || got[0].UnknownFields != 2 || len(got[0].Violations) != len(violations) {
+		t.Errorf("Unexpected stats for Check %+v", got)
+	}
+
+	got = nil
+	_ = d.Validate(data, o)
+	_ = d.DecodeMap(map[string]any{"id": "o1"}, &o)
+	_ = d.ForEach([]byte(`[{"sku": "a"}, {"sku": "b"}]`), Item{}, func(int, any) error { return nil })
+	_ = d.ForEachReader(strings.NewReader(`[{"sku": "a"}, {"sku": "b"}]`), Item{}, func(int, any) error { return nil })
+	if len(got) != 5 {
+		t.Fatalf("Expected 5 reports, got %d", len(got))
+	}
+	if got[2].Objects != 2 || got[2].Type != reflect.TypeOf(Item{}) {
+		t.Errorf("Unexpected ForEach stats %+v", got[2])
+	}
+}
@@ -0,0 +1,67 @@
+package strictjson
+
+import "reflect"
+
+// WithZeroMissing(true) resets the fields of a struct whose keys are absent
+// from its JSON object to their zero values, instead of leaving them as they
+// were. Decoding into a reused value, such as a request struct taken from a
+// sync.Pool, then gives the same result as decoding into a new one, with no
+// data left over from its previous use. Fields with a strict:"default=..."
+// tag take their default instead, and the field tagged strict:",remain"
+// holds only the extra keys of the input.
+//
+// Only fields the decoder can set are reset: unexported fields, fields
+// tagged json:"-" and structs whose keys are absent altogether keep their
+// values.
+func WithZeroMissing(zero bool) DecoderOption {
+	return func(d *Decoder) {
+		d.ZeroMissing = zero
+	}
+}
+
+// beginFields prepares v for the object of sf about to be decoded into it
+// and starts tracking which of its fields are present, when ZeroMissing or a
+// default needs to know. It returns the tracking of the enclosing object, to
+// be restored once the object is decoded.
+func (s *decodeState) beginFields(v reflect.Value, sf *structFields) []bool {
+	saved := s.present
+	s.present = nil
+	if sf.defaults != nil || s.d.ZeroMissing {
+		s.present = make([]bool, len(sf.list))
+	}
+	if s.d.ZeroMissing && sf.remain != nil {
+		if remain, err := v.FieldByIndexErr(sf.remain); err == nil && remain.CanSet() {
+			remain.Set(reflect.Zero(remain.Type()))
+		}
+	}
+	return saved
+}
+
+// markPresent records that the field fi is present in the object being
+// decoded.
+func (s *decodeState) markPresent(fi *fieldInfo) {
+	if s.present != nil {
+		s.present[fi.index] = true
+	}
+}
+
+// endFields resets or defaults the fields of v, decoded with sf, that were
+// absent from its object.
+func (s *decodeState) endFields(v reflect.Value, sf *structFields) {
+	if s.present == nil {
+		return
+	}
+	if s.d.ZeroMissing {
+		for _, fi := range sf.list {
+			if s.present[fi.index] || fi.def != nil {
+				continue
+			}
+			// A field inside a nil embedded pointer is zero already.
+			f, err := v.FieldByIndexErr(fi.fieldIndex)
+			if err == nil && f.CanSet() {
+				f.Set(reflect.Zero(f.Type()))
+			}
+		}
+	}
+	s.applyDefaults(v, sf)
+}
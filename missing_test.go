@@ -0,0 +1,96 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type pooledBase struct {
+	ID string `json:"id"`
+}
+
+type pooledRequest struct {
+	*pooledBase
+	Name    string                     `json:"name"`
+	Tags    []string                   `json:"tags"`
+	Retries int                        `json:"retries" strict:"default=3"`
+	Owner   *mergeEndpoint             `json:"owner"`
+	Extra   map[string]json.RawMessage `json:"-" strict:",remain"`
+	Skipped string                     `json:"-"`
+	hidden  string
+}
+
+func TestZeroMissing(t *testing.T) {
+	reused := func() *pooledRequest {
+		return &pooledRequest{
+			Name:    "old",
+			Tags:    []string{"a"},
+			Retries: 9,
+			Owner:   &mergeEndpoint{Host: "h", Port: 1},
+			Extra:   map[string]json.RawMessage{"stale": json.RawMessage(`1`)},
+			Skipped: "kept",
+			hidden:  "kept",
+		}
+	}
+	want := &pooledRequest{
+		Name:    "new",
+		Retries: 3,
+		Owner:   &mergeEndpoint{Port: 2},
+		Extra:   map[string]json.RawMessage{"fresh": json.RawMessage(`true`)},
+		Skipped: "kept",
+		hidden:  "kept",
+	}
+	data := `{"name": "new", "owner": {"port": 2}, "fresh": true}`
+	d := NewDecoder(WithZeroMissing(true))
+
+	got := reused()
+	if err := d.Unmarshal([]byte(data), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %+v, want %+v", got, want)
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+	got = reused()
+	if err := d.DecodeMap(m, got); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DecodeMap() = %+v, want %+v", got, want)
+	}
+
+	// Without the option absent fields keep their values.
+	got = reused()
+	if err := NewDecoder().Unmarshal([]byte(`{"name": "new"}`), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"a"}) || got.Owner.Host != "h" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+}
+
+func TestZeroMissingEmbeddedPointer(t *testing.T) {
+	d := NewDecoder(WithZeroMissing(true))
+
+	// A nil embedded pointer is not allocated to reset its fields.
+	got := &pooledRequest{}
+	if err := d.Unmarshal([]byte(`{"name": "x"}`), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.pooledBase != nil {
+		t.Errorf("Embedded pointer allocated: %+v", got.pooledBase)
+	}
+
+	got = &pooledRequest{pooledBase: &pooledBase{ID: "old"}}
+	if err := d.Unmarshal([]byte(`{"name": "x"}`), got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.ID != "" {
+		t.Errorf("Embedded field not reset: %q", got.ID)
+	}
+}
@@ -0,0 +1,67 @@
+package strictjson
+
+// KeyNormalizer converts object keys to a Unicode normalization form. The
+// forms of golang.org/x/text/unicode/norm, such as norm.NFC, implement it.
+type KeyNormalizer interface {
+	// IsNormalString reports whether s is already in normal form.
+	IsNormalString(s string) bool
+	// String returns s in normal form.
+	String(s string) string
+}
+
+// WithNormalizeKeys normalizes object keys with n before they are matched
+// against struct fields, so that keys which look identical but differ in
+// composition, such as "café" written with a precomposed "é" or with "e"
+// followed by a combining accent, name the same field:
+//
+//	d := strictjson.NewDecoder(strictjson.WithNormalizeKeys(norm.NFC))
+//
+// Field names are compared as written in their tags and should be in the
+// same form; Go source is normally NFC. Keys of maps are kept as written.
+func WithNormalizeKeys(n KeyNormalizer) DecoderOption {
+	return func(d *Decoder) {
+		d.KeyNormalizer = n
+	}
+}
+
+// WithKeyTransform rewrites each key of an object decoded into a struct
+// with fn before it is matched against the struct's fields. fn receives the
+// path of the object, as in error messages, and the key as written, and
+// returns the key to match; returning key unchanged keeps it. This allows
+// controlled migrations without loosening strictness elsewhere:
+//
+//	strictjson.WithKeyTransform(func(path, key string) string {
+//		if path == "user" && key == "user_id" {
+//			return "userId"
+//		}
+//		return key
+//	})
+//
+// The transformed key is the one checked for duplicates and reported in
+// errors. Keys of maps are not transformed. fn is called for every key of
+// every struct object, so it should be cheap; it may be called concurrently.
+func WithKeyTransform(fn func(path, key string) string) DecoderOption {
+	return func(d *Decoder) {
+		d.KeyTransform = fn
+	}
+}
+
+// transformKey applies KeyTransform, if set, to key, a key of the object at
+// the current path.
+func (s *decodeState) transformKey(key string) string {
+	if s.d.KeyTransform == nil {
+		return key
+	}
+	return s.d.KeyTransform(s.pathString(), key)
+}
+
+// normalizeKey returns key in the normal form of KeyNormalizer. raw holds
+// the string contents the key was read from; keys of plain ASCII, which
+// every form leaves unchanged, are returned as they are.
+func (s *decodeState) normalizeKey(raw []byte, key string) string {
+	n := s.d.KeyNormalizer
+	if n == nil || !needsUnquote(raw) || n.IsNormalString(key) {
+		return key
+	}
+	return n.String(key)
+}
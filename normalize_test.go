@@ -0,0 +1,130 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// composeAcute is a minimal stand-in for norm.NFC that composes "e" followed
+// by U+0301 COMBINING ACUTE ACCENT into U+00E9.
+type composeAcute struct{}
+
+func (composeAcute) IsNormalString(s string) bool { return !strings.Contains(s, "é") }
+func (composeAcute) String(s string) string       { return strings.ReplaceAll(s, "é", "é") }
+
+func TestNormalizeKeys(t *testing.T) {
+	type Venue struct {
+		Cafe  string            `json:"café"`
+		Notes map[string]string `json:"notes"`
+	}
+
+	const composed, decomposed = "café", "café"
+	data := []byte(`{"` + decomposed + `": "a", "notes": {"` + decomposed + `": "b"}}`)
+
+	var v Venue
+	var ufe *UnknownFieldError
+	if err := Unmarshal(data, &v); !errors.As(err, &ufe) {
+		t.Errorf("Expected *UnknownFieldError without normalization, got %v", err)
+	}
+
+	d := NewDecoder(WithNormalizeKeys(composeAcute{}))
+	v = Venue{}
+	if err := d.Unmarshal(data, &v); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if v.Cafe != "a" {
+		t.Errorf("Expected Cafe='a', got %q", v.Cafe)
+	}
+	// Map keys are kept as written.
+	if v.Notes[decomposed] != "b" {
+		t.Errorf("Expected the map key as written, got %q", v.Notes)
+	}
+
+	v = Venue{}
+	if err := d.Unmarshal([]byte(`{"café": "a"}`), &v); err != nil || v.Cafe != "a" {
+		t.Errorf("Expected escaped decomposed key to match, got %+v (%v)", v, err)
+	}
+	if err := d.Validate(data, &v); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+
+	// Both forms of a key are the same key.
+	dup := []byte(`{"` + composed + `": "a", "` + decomposed + `": "b"}`)
+	var dke *DuplicateKeyError
+	err := NewDecoder(WithNormalizeKeys(composeAcute{}), WithDisallowDuplicateKeys(true)).Unmarshal(dup, &v)
+	if !errors.As(err, &dke) || dke.Key() != composed {
+		t.Errorf("Expected duplicate key %q, got %v", composed, err)
+	}
+}
+
+func TestKeyTransform(t *testing.T) {
+	type User struct {
+		UserID string `json:"userId"`
+		Name   string `json:"name"`
+	}
+	type Event struct {
+		User    User              `json:"user"`
+		Actor   User              `json:"actor"`
+		Labels  map[string]string `json:"labels"`
+		Comment string            `json:"comment"`
+	}
+
+	var paths []string
+	d := NewDecoder(WithDisallowDuplicateKeys(true), WithKeyTransform(func(path, key string) string {
+		paths = append(paths, path+"|"+key)
+		if path == "user" && key == "user_id" {
+			return "userId"
+		}
+		return key
+	}))
+
+	var got Event
+	data := []byte(`{"user": {"user_id": "u1", "name": "a"}, "labels": {"user_id": "x"}}`)
+	if err := d.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if got.User.UserID != "u1" || got.Labels["user_id"] != "x" {
+		t.Errorf("Unexpected result %+v", got)
+	}
+	want := []string{"|user", "user|user_id", "user|name", "|labels"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Transform calls = %q, want %q", paths, want)
+	}
+
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+	}{
+		// The migration applies only at the chosen path.
+		{name: "other path", data: `{"actor": {"user_id": "u1"}}`, wantPath: "actor.user_id"},
+		{name: "still case sensitive", data: `{"user": {"User_id": "u1"}}`, wantPath: "user.User_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v Event
+			var ufe *UnknownFieldError
+			if err := d.Unmarshal([]byte(tt.data), &v); !errors.As(err, &ufe) || ufe.Path() != tt.wantPath {
+				t.Errorf("Expected unknown field at %q, got %v", tt.wantPath, err)
+			}
+		})
+	}
+
+	// Both spellings name the same field.
+	var dke *DuplicateKeyError
+	err := d.Unmarshal([]byte(`{"user": {"userId": "a", "user_id": "b"}}`), &got)
+	if !errors.As(err, &dke) || dke.Path() != "user.userId" {
+		t.Errorf("Expected duplicate key at user.userId, got %v", err)
+	}
+
+	var fromMap Event
+	m := map[string]any{"user": map[string]any{"user_id": "u2"}}
+	if err := d.DecodeMap(m, &fromMap); err != nil || fromMap.User.UserID != "u2" {
+		t.Errorf("DecodeMap() = %+v, %v", fromMap, err)
+	}
+	if err := d.Validate(data, &Event{}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// WithStrictNumbers(true) makes strictjson decode numbers into numeric
+// fields itself rather than through the backend, and report a number that
+// does not fit its type, such as 3.5 or 1e2 for an int or 300 for an int8,
+// with a *NumberError naming the value and why it was rejected. Numbers in
+// subtrees delegated to the backend, such as the values of a map[string]int,
+// are reported the same way. By default such numbers fail with a
+// *TypeMismatchError, after encoding/json has stored a float overflowing
+// float32 as an infinity.
+func WithStrictNumbers(strict bool) DecoderOption {
+	return func(d *Decoder) {
+		d.StrictNumbers = strict
+	}
+}
+
+// WithRejectLossyFloats(true) rejects, with a *NumberError, a JSON number
+// that cannot be represented exactly by the float type it is decoded into,
+// such as 0.1 or 9007199254740993 for a float64, instead of rounding it. This
+// covers numbers decoded into any as float64 unless WithUseNumber is set.
+// Numbers out of range for their type are rejected too; the option implies
+// WithStrictNumbers.
+func WithRejectLossyFloats(reject bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RejectLossyFloats = reject
+	}
+}
+
+// ownsNumbers reports whether strictjson decodes numbers into numeric types
+// itself instead of delegating them to the backend.
+func (d *Decoder) ownsNumbers() bool {
+	return d.StrictNumbers || d.RejectLossyFloats
+}
+
+// number decodes raw into v if raw is a JSON number and v is of a numeric
+// kind without an UnmarshalJSON method, reporting whether it did so.
+func (s *decodeState) number(v reflect.Value, raw []byte) (bool, error) {
+	t := v.Type()
+	if !isNumberKind(t.Kind()) || implementsUnmarshaler(reflect.PointerTo(t)) {
+		return false, nil
+	}
+	if c := raw[0]; c != '-' && (c < '0' || c > '9') {
+		return false, nil
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(string(raw), 10, t.Bits())
+		if err != nil {
+			return true, newNumberError(s.pathString(), t, string(raw))
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(string(raw), 10, t.Bits())
+		if err != nil {
+			return true, newNumberError(s.pathString(), t, string(raw))
+		}
+		v.SetUint(n)
+	default:
+		num := string(raw)
+		f, err := strconv.ParseFloat(num, t.Bits())
+		if err != nil {
+			return true, newNumberError(s.pathString(), t, num)
+		}
+		if s.d.RejectLossyFloats && !isExactFloat(num, f) {
+			return true, newLossyFloatError(s.pathString(), t, num)
+		}
+		v.SetFloat(f)
+	}
+	return true, nil
+}
+
+// isExactFloat reports whether f, parsed from the JSON number num, equals
+// it exactly.
+func isExactFloat(num string, f float64) bool {
+	if f == 0 {
+		// Zero, or a number too small for the type.
+		mantissa, _, _ := strings.Cut(strings.ToLower(num), "e")
+		return !strings.ContainsAny(mantissa, "123456789")
+	}
+	r, ok := new(big.Rat).SetString(num)
+	return ok && r.Cmp(new(big.Rat).SetFloat64(f)) == 0
+}
+
+// lossyFloats checks the numbers of raw, a value the backend decoded into
+// type t, that were decoded into floats, including float64s held by
+// interfaces, and reports the first one not represented exactly.
+func (s *decodeState) lossyFloats(raw []byte, t reflect.Type) error {
+	if !holdsFloats(t, nil) {
+		return nil
+	}
+	scan := scanner{data: raw}
+	rel, num, ft, found := s.findLossyFloat(&scan, t, nil)
+	if !found {
+		return nil
+	}
+	s.path = append(s.path, rel...)
+	err := newLossyFloatError(s.pathString(), ft, num)
+	if _, perr := strconv.ParseFloat(num, ft.Bits()); perr != nil {
+		err = newNumberError(s.pathString(), ft, num)
+	}
+	s.path = s.path[:len(s.path)-len(rel)]
+	return err
+}
+
+// findLossyFloat walks the value at scan, of type t, for a number that the
+// backend rounded when decoding it into a float. It returns the path of the
+// number relative to the value, the number, and the float type.
+func (s *decodeState) findLossyFloat(scan *scanner, t reflect.Type, rel []pathSegment) ([]pathSegment, string, reflect.Type, bool) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if implementsUnmarshaler(reflect.PointerTo(t)) {
+		_, _ = scan.skipValue()
+		return nil, "", nil, false
+	}
+	k := t.Kind()
+	if k == reflect.Interface {
+		if t.NumMethod() > 0 {
+			_, _ = scan.skipValue()
+			return nil, "", nil, false
+		}
+		if s.d.UseNumber {
+			t = numberType
+		} else {
+			t = float64Type
+		}
+	}
+
+	switch c := scan.peek(); {
+	case c == '-' || '0' <= c && c <= '9':
+		raw, _ := scan.skipValue()
+		if k := t.Kind(); k == reflect.Float32 || k == reflect.Float64 {
+			num := string(raw)
+			f, err := strconv.ParseFloat(num, t.Bits())
+			if err != nil || !isExactFloat(num, f) {
+				return rel, num, t, true
+			}
+		}
+	case c == '[' && (k == reflect.Slice || k == reflect.Array || k == reflect.Interface):
+		elem := anyType
+		if k != reflect.Interface {
+			elem = t.Elem()
+		}
+		scan.pos++
+		if scan.consume(']') {
+			return nil, "", nil, false
+		}
+		for i := 0; ; i++ {
+			if r, num, ft, found := s.findLossyFloat(scan, elem, append(rel, pathSegment{index: i})); found {
+				return r, num, ft, true
+			}
+			if !scan.consume(',') {
+				scan.pos++ // ']'
+				return nil, "", nil, false
+			}
+		}
+	case c == '{' && (k == reflect.Map || k == reflect.Interface):
+		elem := anyType
+		if k == reflect.Map {
+			elem = t.Elem()
+		}
+		scan.pos++
+		if scan.consume('}') {
+			return nil, "", nil, false
+		}
+		for {
+			key, _ := scan.readKey()
+			if r, num, ft, found := s.findLossyFloat(scan, elem, append(rel, pathSegment{key: key, index: -1})); found {
+				return r, num, ft, true
+			}
+			if !scan.consume(',') {
+				scan.pos++ // '}'
+				return nil, "", nil, false
+			}
+		}
+	default:
+		_, _ = scan.skipValue()
+	}
+	return nil, "", nil, false
+}
+
+// holdsFloats reports whether values of type t can hold floats, directly or
+// in interfaces, outside of custom unmarshalers.
+func holdsFloats(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if seen[t] || implementsUnmarshaler(reflect.PointerTo(t)) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Interface:
+		return t.NumMethod() == 0
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if seen == nil {
+			seen = make(map[reflect.Type]bool)
+		}
+		seen[t] = true
+		return holdsFloats(t.Elem(), seen)
+	}
+	return false
+}
+
+var (
+	anyType    = reflect.TypeOf((*any)(nil)).Elem()
+	numberType = reflect.TypeOf(json.Number(""))
+)
+
+func isNumberKind(k reflect.Kind) bool {
+	return reflect.Int <= k && k <= reflect.Float64
+}
+
+// numberReason explains why the JSON number num does not fit t.
+func numberReason(num string, t reflect.Type) string {
+	k := t.Kind()
+	if k == reflect.Float32 || k == reflect.Float64 || !strings.ContainsAny(num, ".eE") {
+		return "is out of range for Go " + t.String()
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	switch {
+	case err != nil || f != math.Trunc(f):
+		return "is not an integer, as Go " + t.String() + " requires"
+	case !fitsInteger(f, t):
+		return "is out of range for Go " + t.String()
+	}
+	return "is not written as an integer, as Go " + t.String() + " requires"
+}
+
+// fitsInteger reports whether the integral value f is within the range of
+// the integer type t.
+func fitsInteger(f float64, t reflect.Type) bool {
+	bits := t.Bits()
+	if reflect.Uint <= t.Kind() && t.Kind() <= reflect.Uintptr {
+		return f >= 0 && f < math.Ldexp(1, bits)
+	}
+	limit := math.Ldexp(1, bits-1)
+	return f >= -limit && f < limit
+}
@@ -0,0 +1,218 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStrictNumbers(t *testing.T) {
+	type Reading struct {
+		Count   int            `json:"count"`
+		Total   int64          `json:"total"`
+		Level   int8           `json:"level"`
+		Size    uint           `json:"size"`
+		Ratio   float32        `json:"ratio"`
+		Timeout time.Duration  `json:"timeout"`
+		Counts  map[string]int `json:"counts"`
+		Levels  []*int8        `json:"levels"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		path    string
+		wantMsg string
+	}{
+		{name: "fraction", json: `{"count": 3.5}`, path: "count", wantMsg: `strictjson: number 3.5 at "count" is not an integer, as Go int requires`},
+		{name: "exponent", json: `{"count": 1e2}`, path: "count", wantMsg: `strictjson: number 1e2 at "count" is not written as an integer, as Go int requires`},
+		{name: "exponent overflow", json: `{"total": 1e20}`, path: "total", wantMsg: `strictjson: number 1e20 at "total" is out of range for Go int64`},
+		{name: "overflow", json: `{"level": 300}`, path: "level", wantMsg: `strictjson: number 300 at "level" is out of range for Go int8`},
+		{name: "negative unsigned", json: `{"size": -1}`, path: "size", wantMsg: `strictjson: number -1 at "size" is out of range for Go uint`},
+		{name: "float overflow", json: `{"ratio": 1e39}`, path: "ratio", wantMsg: `strictjson: number 1e39 at "ratio" is out of range for Go float32`},
+		{name: "delegated map", json: `{"counts": {"a": 1, "b": 2.5}}`, path: "counts.b", wantMsg: `strictjson: number 2.5 at "counts.b" is not an integer, as Go int requires`},
+		{name: "delegated pointer slice", json: `{"levels": [1, 128]}`, path: "levels[1]", wantMsg: `strictjson: number 128 at "levels[1]" is out of range for Go int8`},
+	}
+
+	d := NewDecoder(WithStrictNumbers(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Reading
+			err := d.Unmarshal([]byte(tt.json), &r)
+			var ne *NumberError
+			if !errors.As(err, &ne) {
+				t.Fatalf("Expected *NumberError, got %T (%v)", err, err)
+			}
+			if ne.Path() != tt.path || err.Error() != tt.wantMsg {
+				t.Errorf("Got %q at %q, want %q at %q", err, ne.Path(), tt.wantMsg, tt.path)
+			}
+			if !errors.Is(err, ErrDecode) {
+				t.Error("Expected error to wrap ErrDecode")
+			}
+
+			// Without the option encoding/json rejects the same numbers.
+			var tme *TypeMismatchError
+			if err := Unmarshal([]byte(tt.json), &r); !errors.As(err, &tme) {
+				t.Errorf("Expected *TypeMismatchError by default, got %v", err)
+			}
+		})
+	}
+
+	var r Reading
+	ok := `{"count": -7, "total": 9007199254740993, "level": -128, "size": 0, "ratio": 0.5, "timeout": 1000}`
+	if err := d.Unmarshal([]byte(ok), &r); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if r.Count != -7 || r.Total != 9007199254740993 || r.Level != math.MinInt8 || r.Ratio != 0.5 || r.Timeout != time.Microsecond {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+
+	// A rejected number is not stored.
+	r = Reading{}
+	_ = d.Unmarshal([]byte(`{"ratio": 1e39}`), &r)
+	if r.Ratio != 0 {
+		t.Errorf("Expected Ratio to stay zero, got %v", r.Ratio)
+	}
+
+	b, _ := json.Marshal(d.Unmarshal([]byte(`{"level": 300}`), &r))
+	want := `{"code":"invalid_number","message":"strictjson: number 300 at \"level\" is out of range for Go int8","path":"level","type":"int8","value":"300"}`
+	if string(b) != want {
+		t.Errorf("json.Marshal() =\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestRejectLossyFloats(t *testing.T) {
+	type Sample struct {
+		Value  float64            `json:"value"`
+		Small  float32            `json:"small"`
+		Quoted float64            `json:"quoted,string"`
+		Series []float64          `json:"series"`
+		Named  map[string]float32 `json:"named"`
+		Extra  any                `json:"extra"`
+		Count  int                `json:"count"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		path    string
+		wantMsg string
+	}{
+		{name: "decimal", json: `{"value": 0.1}`, path: "value", wantMsg: `strictjson: number 0.1 at "value" cannot be represented exactly in Go float64`},
+		{name: "large integer", json: `{"value": 9007199254740993}`, path: "value"},
+		{name: "float32", json: `{"small": 16777217}`, path: "small"},
+		{name: "underflow", json: `{"value": 1e-400}`, path: "value"},
+		{name: "overflow", json: `{"small": 1e39}`, path: "small", wantMsg: `strictjson: number 1e39 at "small" is out of range for Go float32`},
+		{name: "quoted", json: `{"quoted": "0.3"}`, path: "quoted"},
+		{name: "delegated slice", json: `{"series": [0.5, 0.25, 0.2]}`, path: "series[2]"},
+		{name: "delegated map", json: `{"named": {"a": 1.5, "b": 3.3}}`, path: "named.b"},
+		{name: "interface", json: `{"extra": {"list": [1, {"x": 0.7}]}}`, path: "extra.list[1].x"},
+		{name: "integer field", json: `{"count": 1.5}`, path: "count"},
+	}
+
+	d := NewDecoder(WithRejectLossyFloats(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s Sample
+			err := d.Unmarshal([]byte(tt.json), &s)
+			var ne *NumberError
+			if !errors.As(err, &ne) {
+				t.Fatalf("Expected *NumberError, got %T (%v)", err, err)
+			}
+			if ne.Path() != tt.path {
+				t.Errorf("Expected path %q, got %q", tt.path, ne.Path())
+			}
+			if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+				t.Errorf("Expected %q, got %q", tt.wantMsg, err)
+			}
+		})
+	}
+
+	var s Sample
+	ok := `{"value": 0.5, "small": 16777216, "series": [1, 2.5e3, -0.0, 0e999], "named": {"a": 0.125}, "extra": [1, 2.75], "quoted": "0.5"}`
+	if err := d.Unmarshal([]byte(ok), &s); err != nil {
+		t.Fatalf("Unmarshal() unexpected error for exact floats: %v", err)
+	}
+	if s.Value != 0.5 || s.Small != 16777216 || s.Series[1] != 2500 || s.Quoted != 0.5 {
+		t.Errorf("Unexpected result: %+v", s)
+	}
+
+	// With UseNumber, interface values keep the exact text.
+	un := NewDecoder(WithRejectLossyFloats(true), WithUseNumber(true))
+	if err := un.Unmarshal([]byte(`{"extra": 0.1}`), &s); err != nil || s.Extra != json.Number("0.1") {
+		t.Errorf("Expected json.Number(0.1), got %#v (%v)", s.Extra, err)
+	}
+}
+
+// cents is a decimal-style type that accepts numbers with at most two
+// fractional digits.
+type cents int64
+
+var errTooPrecise = errors.New("more than two decimal places")
+
+func (c *cents) UnmarshalText(text []byte) error {
+	whole, frac, _ := strings.Cut(string(text), ".")
+	if len(frac) > 2 {
+		return errTooPrecise
+	}
+	n, err := strconv.ParseInt(whole+(frac + "00")[:2], 10, 64)
+	*c = cents(n)
+	return err
+}
+
+func TestTextUnmarshalerNumbers(t *testing.T) {
+	type Ledger struct {
+		Supply  *big.Int            `json:"supply"`
+		Rate    *big.Float          `json:"rate"`
+		Ratio   big.Rat             `json:"ratio"`
+		Balance cents               `json:"balance"`
+		History []cents             `json:"history"`
+		Parts   map[string]*big.Rat `json:"parts"`
+		Label   cents               `json:"label"`
+	}
+
+	data := []byte(`{
+		"supply": 123456789012345678901234567890,
+		"rate": 1e400,
+		"ratio": 0.1,
+		"balance": 12.34,
+		"history": [1, 2.5],
+		"parts": {"a": 1.25},
+		"label": "7.5"
+	}`)
+	var l Ledger
+	if err := Unmarshal(data, &l); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if l.Supply.String() != "123456789012345678901234567890" {
+		t.Errorf("Expected exact Supply, got %v", l.Supply)
+	}
+	if l.Rate.MantExp(nil) != 1329 {
+		t.Errorf("Expected Rate=1e400, got %v", l.Rate)
+	}
+	if l.Ratio.String() != "1/10" {
+		t.Errorf("Expected Ratio=1/10, got %v", l.Ratio.String())
+	}
+	if l.Balance != 1234 || !reflect.DeepEqual(l.History, []cents{100, 250}) || l.Label != 750 {
+		t.Errorf("Unexpected cents: %v %v %v", l.Balance, l.History, l.Label)
+	}
+	if l.Parts["a"].String() != "5/4" {
+		t.Errorf("Expected Parts[a]=5/4, got %v", l.Parts["a"])
+	}
+
+	err := Unmarshal([]byte(`{"history": [1, 0.125]}`), &l)
+	var ne *NumberError
+	if !errors.As(err, &ne) || ne.Path() != "history[1]" || !errors.Is(err, errTooPrecise) {
+		t.Fatalf("Expected *NumberError at history[1] wrapping errTooPrecise, got %v", err)
+	}
+	want := `strictjson: number 0.125 at "history[1]" is not valid for Go strictjson.cents: more than two decimal places`
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err)
+	}
+}
@@ -0,0 +1,349 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DriftKind classifies a Drift.
+type DriftKind uint8
+
+const (
+	DriftMissingSchema       DriftKind = iota + 1 // no component schema for the type
+	DriftFieldNotInSpec                           // struct field the schema does not declare
+	DriftPropertyNotInStruct                      // schema property no struct field matches
+	DriftCaseMismatch                             // property matching a field only case-insensitively
+	DriftRequiredMismatch                         // required property whose field may be omitted
+)
+
+func (k DriftKind) String() string {
+	switch k {
+	case DriftMissingSchema:
+		return "missing_schema"
+	case DriftFieldNotInSpec:
+		return "field_not_in_spec"
+	case DriftPropertyNotInStruct:
+		return "property_not_in_struct"
+	case DriftCaseMismatch:
+		return "case_mismatch"
+	case DriftRequiredMismatch:
+		return "required_mismatch"
+	}
+	return fmt.Sprintf("DriftKind(%d)", uint8(k))
+}
+
+// MarshalText encodes k as its String form.
+func (k DriftKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Drift is one mismatch between a Go type and its OpenAPI component schema.
+type Drift struct {
+	Kind DriftKind `json:"kind"`
+	// Schema is the name of the component schema, the key of the type in
+	// the map passed to CompareWithOpenAPI.
+	Schema string `json:"schema"`
+	// Path locates the property within the schema, e.g. "items.*.sku",
+	// where "*" stands for any array element or map value.
+	Path string `json:"path,omitempty"`
+	// Property is the property name as the spec declares it.
+	Property string `json:"property,omitempty"`
+	// Field is the JSON name of the struct field.
+	Field string `json:"field,omitempty"`
+}
+
+func (d Drift) String() string {
+	switch d.Kind {
+	case DriftMissingSchema:
+		return fmt.Sprintf(`%s: no component schema`, d.Schema)
+	case DriftFieldNotInSpec:
+		return fmt.Sprintf(`%s: field "%s" is not in the spec`, d.Schema, d.Path)
+	case DriftPropertyNotInStruct:
+		return fmt.Sprintf(`%s: property "%s" matches no struct field`, d.Schema, d.Path)
+	case DriftCaseMismatch:
+		return fmt.Sprintf(`%s: property "%s" matches field "%s" only case-insensitively`, d.Schema, d.Path, d.Field)
+	default:
+		return fmt.Sprintf(`%s: property "%s" is required but field "%s" may be omitted`, d.Schema, d.Path, d.Field)
+	}
+}
+
+// CompareWithOpenAPI compares Go types with the component schemas of an
+// OpenAPI document. types maps component names, the keys of
+// components.schemas (or definitions, in Swagger 2.0), to values or
+// pointers of the struct types that decode them. spec must be JSON; convert
+// YAML specs first.
+//
+// Each struct's field table, as the decoder resolves it, is compared with
+// the schema's properties, including those merged in through allOf: fields
+// the schema does not declare, properties no field matches, which the
+// decoder would reject as unknown keys, properties that match a field only
+// case-insensitively, and required properties whose fields are omitempty,
+// have a default or are an Optional, so that Marshal may leave them out.
+// Nested structs are compared with the schemas of their properties, through
+// $ref, arrays and maps. The result is sorted by schema name; an empty
+// result means no drift.
+func CompareWithOpenAPI(spec []byte, types map[string]any) ([]Drift, error) {
+	return NewDecoder().CompareWithOpenAPI(spec, types)
+}
+
+// CompareWithOpenAPI is like the package-level CompareWithOpenAPI but
+// resolves fields with d's options, such as WithTagKey.
+func (d *Decoder) CompareWithOpenAPI(spec []byte, types map[string]any) ([]Drift, error) {
+	var doc any
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, &UnmarshalError{message: "strictjson: CompareWithOpenAPI: invalid spec: " + err.Error()}
+	}
+	components, _ := lookupPointer(doc, "/components/schemas").(map[string]any)
+	if components == nil {
+		components, _ = lookupPointer(doc, "/definitions").(map[string]any)
+	}
+
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	c := &driftChecker{d: d, doc: doc, visited: make(map[driftVisit]bool)}
+	for _, name := range names {
+		t := reflect.TypeOf(types[name])
+		if t == nil {
+			return nil, &UnmarshalError{message: fmt.Sprintf("strictjson: CompareWithOpenAPI: nil value for %q", name)}
+		}
+		schema, ok := components[name]
+		if !ok {
+			c.drifts = append(c.drifts, Drift{Kind: DriftMissingSchema, Schema: name})
+			continue
+		}
+		c.schema = name
+		if err := c.compare(t, schema, ""); err != nil {
+			return nil, err
+		}
+	}
+	return c.drifts, nil
+}
+
+type driftChecker struct {
+	d      *Decoder
+	doc    any
+	schema string // component being compared
+	drifts []Drift
+	// visited cuts recursion through types that refer to themselves.
+	visited map[driftVisit]bool
+}
+
+type driftVisit struct {
+	typ    reflect.Type
+	schema string
+	path   string
+}
+
+// compare compares values of type t, at path, with schema.
+func (c *driftChecker) compare(t reflect.Type, schema any, path string) error {
+	schema, err := c.deref(schema)
+	if err != nil {
+		return err
+	}
+	for {
+		if isOptional(t) {
+			t = t.Field(0).Type
+		}
+		if t.Kind() != reflect.Ptr {
+			break
+		}
+		t = t.Elem()
+	}
+	if !describesFields(t) {
+		return nil
+	}
+
+	m, _ := schema.(map[string]any)
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if isBytes(t) || m["items"] == nil {
+			return nil
+		}
+		return c.compare(t.Elem(), m["items"], joinDriftPath(path, "*"))
+	case reflect.Map:
+		if _, ok := m["additionalProperties"].(map[string]any); !ok {
+			return nil
+		}
+		return c.compare(t.Elem(), m["additionalProperties"], joinDriftPath(path, "*"))
+	case reflect.Struct:
+	default:
+		return nil
+	}
+
+	visit := driftVisit{t, c.schema, path}
+	if c.visited[visit] {
+		return nil
+	}
+	c.visited[visit] = true
+	props, required, ok, err := c.properties(m)
+	if err != nil || !ok {
+		return err // a schema without properties leaves nothing to compare
+	}
+
+	sf, err := getStructFields(t, c.d.fieldConfig())
+	if err != nil {
+		return err
+	}
+	if len(sf.conflicts) > 0 && !c.d.IgnoreConflicts {
+		return sf.conflicts[0]
+	}
+	fields := append([]*fieldInfo(nil), sf.list...)
+	sort.Slice(fields, func(i, j int) bool { return compareIndex(fields[i].fieldIndex, fields[j].fieldIndex) < 0 })
+
+	matched := make(map[string]bool)
+	for _, fi := range fields {
+		name := fi.jsonName
+		prop, ok := props[name]
+		if !ok {
+			for _, other := range sortedKeys(props) {
+				if strings.EqualFold(other, name) && !matched[other] {
+					c.drifts = append(c.drifts, Drift{Kind: DriftCaseMismatch, Schema: c.schema, Path: joinDriftPath(path, other), Property: other, Field: name})
+					name, prop, ok = other, props[other], true
+					break
+				}
+			}
+		}
+		if !ok {
+			c.drifts = append(c.drifts, Drift{Kind: DriftFieldNotInSpec, Schema: c.schema, Path: joinDriftPath(path, fi.jsonName), Field: fi.jsonName})
+			continue
+		}
+		matched[name] = true
+		if required[name] && (hasTagOption(fi.tagOpts, "omitempty") || fi.def != nil || isOptional(fi.typ)) {
+			c.drifts = append(c.drifts, Drift{Kind: DriftRequiredMismatch, Schema: c.schema, Path: joinDriftPath(path, name), Property: name, Field: fi.jsonName})
+		}
+		if err := c.compare(fi.typ, prop, joinDriftPath(path, name)); err != nil {
+			return err
+		}
+	}
+	if sf.remain == nil {
+		for _, name := range sortedKeys(props) {
+			if !matched[name] {
+				c.drifts = append(c.drifts, Drift{Kind: DriftPropertyNotInStruct, Schema: c.schema, Path: joinDriftPath(path, name), Property: name})
+			}
+		}
+	}
+	return nil
+}
+
+// properties collects the properties and required names of the object
+// schema m and of the schemas it combines with allOf. ok is false if m
+// declares no properties at all.
+func (c *driftChecker) properties(m map[string]any) (props map[string]any, required map[string]bool, ok bool, err error) {
+	props, required = make(map[string]any), make(map[string]bool)
+	var collect func(m map[string]any) error
+	collect = func(m map[string]any) error {
+		if p, isMap := m["properties"].(map[string]any); isMap {
+			ok = true
+			for name, schema := range p {
+				props[name] = schema
+			}
+		}
+		list, _ := m["required"].([]any)
+		for _, name := range list {
+			if name, isString := name.(string); isString {
+				required[name] = true
+			}
+		}
+		all, _ := m["allOf"].([]any)
+		for _, sub := range all {
+			sub, err := c.deref(sub)
+			if err != nil {
+				return err
+			}
+			if sub, isMap := sub.(map[string]any); isMap {
+				if err := collect(sub); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	err = collect(m)
+	return props, required, ok, err
+}
+
+// deref follows the $ref of schema, and unwraps an anyOf or oneOf whose
+// only alternative to null is a single schema, as OpenAPI 3.1 writes
+// nullable values.
+func (c *driftChecker) deref(schema any) (any, error) {
+	for hops := 0; hops <= 64; hops++ {
+		m, ok := schema.(map[string]any)
+		if !ok {
+			return schema, nil
+		}
+		if ref, ok := m["$ref"].(string); ok {
+			target, ok := strings.CutPrefix(ref, "#")
+			if schema = lookupPointer(c.doc, target); !ok || schema == nil {
+				return nil, &UnmarshalError{message: fmt.Sprintf("strictjson: CompareWithOpenAPI: cannot resolve $ref %q in %s", ref, c.schema)}
+			}
+			continue
+		}
+		if schema = nonNullAlternative(m); schema == nil {
+			return m, nil
+		}
+	}
+	return nil, &UnmarshalError{message: fmt.Sprintf("strictjson: CompareWithOpenAPI: $ref cycle in %s", c.schema)}
+}
+
+// nonNullAlternative returns the one alternative to null of the anyOf or
+// oneOf of m, or nil if there is no such alternative or m has properties of
+// its own.
+func nonNullAlternative(m map[string]any) any {
+	if m["properties"] != nil {
+		return nil
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		list, _ := m[key].([]any)
+		var only any
+		count := 0
+		for _, alt := range list {
+			if alt, ok := alt.(map[string]any); ok && alt["type"] == "null" {
+				continue
+			}
+			only, count = alt, count+1
+		}
+		if count == 1 {
+			return only
+		}
+	}
+	return nil
+}
+
+// lookupPointer returns the value at the JSON pointer ptr in doc, or nil.
+func lookupPointer(doc any, ptr string) any {
+	if ptr == "" {
+		return doc
+	}
+	v := doc
+	for _, tok := range strings.Split(ptr, "/")[1:] {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch node := v.(type) {
+		case map[string]any:
+			v = node[tok]
+		case []any:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil
+			}
+			v = node[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+func joinDriftPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,138 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const petstoreSpec = `{
+	"openapi": "3.1.0",
+	"components": {
+		"schemas": {
+			"Order": {
+				"type": "object",
+				"properties": {
+					"id": {"type": "string"},
+					"customerId": {"type": "string"},
+					"items": {"type": "array", "items": {"$ref": "#/components/schemas/LineItem"}},
+					"shipTo": {"anyOf": [{"$ref": "#/components/schemas/Address"}, {"type": "null"}]},
+					"tags": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/Tag"}},
+					"coupon": {"type": "string"},
+					"note": {"type": "string"}
+				},
+				"required": ["id", "note"]
+			},
+			"LineItem": {
+				"allOf": [
+					{"$ref": "#/components/schemas/Base"},
+					{"type": "object", "properties": {"sku": {"type": "string"}, "qty": {"type": "integer"}}, "required": ["qty"]}
+				]
+			},
+			"Base": {"type": "object", "properties": {"createdAt": {"type": "string"}}},
+			"Address": {"type": "object", "properties": {"city": {"type": "string"}}},
+			"Tag": {"type": "object", "properties": {"label": {"type": "string"}}}
+		}
+	}
+}`
+
+type driftOrder struct {
+	ID         string              `json:"id"`
+	CustomerID string              `json:"customer_id"`
+	Items      []driftLineItem     `json:"items"`
+	ShipTo     *driftAddress       `json:"shipTo"`
+	Tags       map[string]driftTag `json:"tags"`
+	Note       string              `json:"note,omitempty"`
+}
+
+type driftLineItem struct {
+	SKU       string          `json:"sku"`
+	Qty       Optional[int]   `json:"qty"`
+	CreatedAt string          `json:"createdAt"`
+	Extra     json.RawMessage `json:"extra"`
+}
+
+type driftAddress struct {
+	City string `json:"city"`
+}
+
+type driftTag struct {
+	Label string `json:"Label"`
+}
+
+func TestCompareWithOpenAPI(t *testing.T) {
+	drifts, err := CompareWithOpenAPI([]byte(petstoreSpec), map[string]any{
+		"Order":   (*driftOrder)(nil),
+		"Address": driftAddress{},
+		"Invoice": struct{}{},
+	})
+	if err != nil {
+		t.Fatalf("CompareWithOpenAPI() unexpected error: %v", err)
+	}
+	want := []string{
+		`Invoice: no component schema`,
+		`Order: field "customer_id" is not in the spec`,
+		`Order: property "items.*.qty" is required but field "qty" may be omitted`,
+		`Order: field "items.*.extra" is not in the spec`,
+		`Order: property "tags.*.label" matches field "Label" only case-insensitively`,
+		`Order: property "note" is required but field "note" may be omitted`,
+		`Order: property "coupon" matches no struct field`,
+		`Order: property "customerId" matches no struct field`,
+	}
+	var got []string
+	for _, d := range drifts {
+		got = append(got, d.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("CompareWithOpenAPI() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	data, err := json.Marshal(drifts[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"kind":"case_mismatch","schema":"Order","path":"tags.*.label","property":"label","field":"Label"}`; string(data) != want {
+		t.Errorf("json.Marshal(Drift) = %s, want %s", data, want)
+	}
+}
+
+func TestCompareWithOpenAPIOptions(t *testing.T) {
+	spec := `{"definitions": {"Tag": {"properties": {"label": {}, "color": {}}}}}`
+	drifts, err := CompareWithOpenAPI([]byte(spec), map[string]any{"Tag": struct {
+		Label string                     `json:"label"`
+		Rest  map[string]json.RawMessage `strict:",remain"`
+	}{}})
+	if err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift with a remain field, got %v, %v", drifts, err)
+	}
+
+	d := NewDecoder(WithTagKey("api"))
+	drifts, err = d.CompareWithOpenAPI([]byte(spec), map[string]any{"Tag": struct {
+		Label string `api:"label"`
+		Color string `api:"color"`
+	}{}})
+	if err != nil || len(drifts) != 0 {
+		t.Errorf("Expected no drift with api tags, got %v, %v", drifts, err)
+	}
+}
+
+func TestCompareWithOpenAPIErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{`openapi: 3.1.0`, `strictjson: CompareWithOpenAPI: invalid spec: invalid character 'o' looking for beginning of value`},
+		{`{"components": {"schemas": {"Tag": {"$ref": "#/components/schemas/Label"}}}}`, `strictjson: CompareWithOpenAPI: cannot resolve $ref "#/components/schemas/Label" in Tag`},
+		{`{"components": {"schemas": {"Tag": {"$ref": "#/components/schemas/Tag"}}}}`, `strictjson: CompareWithOpenAPI: $ref cycle in Tag`},
+	}
+	for _, tt := range tests {
+		_, err := CompareWithOpenAPI([]byte(tt.spec), map[string]any{"Tag": driftTag{}})
+		if err == nil || err.Error() != tt.want {
+			t.Errorf("CompareWithOpenAPI(%s) error = %v, want %s", tt.spec, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrDecode) {
+			t.Errorf("CompareWithOpenAPI(%s) error does not wrap ErrDecode", tt.spec)
+		}
+	}
+}
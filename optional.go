@@ -0,0 +1,87 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Optional holds a value together with whether its key was present in the
+// JSON and whether it was explicitly null, so absent, null and zero can be
+// told apart.
+//
+// The strict decoder recognizes Optional and decodes the wrapped value with
+// the same options and validation as the surrounding document. When decoded
+// through encoding/json instead, UnmarshalJSON applies strictjson's default
+// validation to the wrapped value.
+type Optional[T any] struct {
+	Value T
+	Set   bool // the key was present, including as null
+	Null  bool // the value was an explicit null
+}
+
+// Some returns an Optional holding v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Set: true}
+}
+
+// Get returns the value and whether a non-null value was present.
+func (o Optional[T]) Get() (T, bool) {
+	return o.Value, o.Set && !o.Null
+}
+
+// IsZero reports whether the value was absent, so that fields tagged
+// omitzero are omitted when marshaled.
+func (o Optional[T]) IsZero() bool {
+	return !o.Set
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		o.markPresent(true)
+		return nil
+	}
+	o.markPresent(false)
+	return Unmarshal(data, &o.Value)
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.Set || o.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.Value)
+}
+
+func (o *Optional[T]) markPresent(null bool) {
+	var zero T
+	o.Set = true
+	o.Null = null
+	if null {
+		o.Value = zero
+	}
+}
+
+func (o *Optional[T]) valuePtr() any {
+	return &o.Value
+}
+
+// optional is implemented by *Optional[T] so the decoder can record presence
+// and decode the wrapped value itself.
+type optional interface {
+	markPresent(null bool)
+	valuePtr() any
+}
+
+var optionalType = reflect.TypeOf((*optional)(nil)).Elem()
+
+func isOptional(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(optionalType)
+}
+
+func (s *decodeState) optional(o optional, elem *typePlan) (bool, error) {
+	if s.scan.consumeNull() {
+		o.markPresent(true)
+		return false, nil
+	}
+	o.markPresent(false)
+	return s.begin(reflect.ValueOf(o.valuePtr()).Elem(), elem)
+}
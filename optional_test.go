@@ -0,0 +1,105 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestOptionalPresence(t *testing.T) {
+	type Patch struct {
+		Name Optional[string] `json:"name"`
+		Age  Optional[int]    `json:"age"`
+		Nick Optional[string] `json:"nick"`
+	}
+
+	var p Patch
+	if err := Unmarshal([]byte(`{"age": 0, "nick": null}`), &p); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	if p.Name.Set {
+		t.Error("Expected absent name to be unset")
+	}
+	if v, ok := p.Age.Get(); !ok || v != 0 {
+		t.Errorf("Age.Get() = %v, %v; want 0, true", v, ok)
+	}
+	if !p.Nick.Set || !p.Nick.Null {
+		t.Errorf("Expected explicit null nick, got %+v", p.Nick)
+	}
+}
+
+func TestOptionalStrictInnerStruct(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Person struct {
+		Address Optional[Address]   `json:"address"`
+		History []Optional[Address] `json:"history"`
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		wantPath string
+	}{
+		{name: "field", json: `{"address": {"City": "NYC"}}`, wantPath: "address.City"},
+		{name: "slice element", json: `{"history": [null, {"CITY": "LA"}]}`, wantPath: "history[1].CITY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Person
+			err := Unmarshal([]byte(tt.json), &p)
+
+			var ufe *UnknownFieldError
+			if !errors.As(err, &ufe) {
+				t.Fatalf("Expected *UnknownFieldError, got %T (%v)", err, err)
+			}
+			if ufe.Path() != tt.wantPath {
+				t.Errorf("Path() = %q, want %q", ufe.Path(), tt.wantPath)
+			}
+		})
+	}
+
+	// Decoding through encoding/json still applies strict validation.
+	var p Person
+	if err := json.Unmarshal([]byte(`{"address": {"City": "NYC"}}`), &p); err == nil {
+		t.Error("Expected strict error through encoding/json")
+	}
+}
+
+func TestOptionalIgnoresDisallowNull(t *testing.T) {
+	type Patch struct {
+		Age Optional[int] `json:"age"`
+	}
+
+	var p Patch
+	d := NewDecoder(WithDisallowNullForNonPointer(true))
+	if err := d.Unmarshal([]byte(`{"age": null}`), &p); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+}
+
+func TestOptionalMarshal(t *testing.T) {
+	type Patch struct {
+		Name Optional[string] `json:"name"`
+		Age  Optional[int]    `json:"age"`
+	}
+
+	out, err := json.Marshal(Patch{Name: Some("John")})
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	if string(out) != `{"name":"John","age":null}` {
+		t.Errorf("Marshal() = %s", out)
+	}
+	if !(Optional[int]{}).IsZero() || Some(0).IsZero() {
+		t.Error("IsZero() should report absence only")
+	}
+
+	out, _ = json.Marshal(Patch{Age: Optional[int]{Set: true, Null: true}})
+	if string(out) != `{"name":null,"age":null}` {
+		t.Errorf("Marshal() = %s", out)
+	}
+}
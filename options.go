@@ -1,8 +1,147 @@
 package strictjson
 
+// A Decoder holds the options of strict decoding. Build one with NewDecoder
+// and share it: a Decoder is safe for concurrent use by multiple goroutines.
+// Decoding never modifies it; the state of each call, such as the current
+// path and duplicate-key sets, lives in a separate value that is pooled and
+// reused across calls. Its fields must not be changed while it is in use.
 type Decoder struct {
 	DisallowUnknownFields bool
 	SuggestClosest        bool
+	DisallowDuplicateKeys bool
+	// RejectEscapedKeys rejects object keys written with escape sequences.
+	// See WithRejectEscapedKeys.
+	RejectEscapedKeys bool
+	// KeyOrder is the order in which object keys must appear. See
+	// WithRequireKeyOrder.
+	KeyOrder KeyOrder
+	// DisallowNullForNonPointer rejects null for values that cannot hold it:
+	// anything other than pointers, interfaces, maps and slices.
+	DisallowNullForNonPointer bool
+	// AllowedExtraFields lists keys that are tolerated at any nesting level
+	// even though no field matches them.
+	AllowedExtraFields []string
+	// IgnorePaths holds dotted glob patterns naming subtrees in which unknown
+	// keys are tolerated. See WithIgnorePaths.
+	IgnorePaths []string
+	// OnUnknownField, when set, is called for each unknown key instead of
+	// failing. See WithOnUnknownField.
+	OnUnknownField func(path, key, suggestion string) error
+	// StrictNumbers makes strictjson decode numbers into numeric types
+	// itself. See WithStrictNumbers.
+	StrictNumbers bool
+	// RejectLossyFloats rejects numbers that float types can only
+	// approximate. See WithRejectLossyFloats.
+	RejectLossyFloats bool
+	// UseNumber decodes numbers into interface values as json.Number. See
+	// WithUseNumber.
+	UseNumber bool
+	// ValidateInterfaceObjects decodes objects into interface values
+	// through their registered implementations. See
+	// WithValidateInterfaceObjects.
+	ValidateInterfaceObjects bool
+	// ValidateThroughUnmarshalers checks values of types with an
+	// UnmarshalJSON method against their own fields before calling it. See
+	// WithValidateThroughUnmarshalers.
+	ValidateThroughUnmarshalers bool
+	// TimeFormat, when set, is the layout time.Time values must be written
+	// in, and StrictTimeFormat requires them to be written exactly as it
+	// formats them. See WithTimeFormat.
+	TimeFormat       string
+	StrictTimeFormat bool
+	// StrictBase64 requires []byte values in canonical padded standard
+	// base64. See WithStrictBase64.
+	StrictBase64 bool
+	// Constraints enforces the min, max and maxlen options of strict tags.
+	// See WithConstraints.
+	Constraints bool
+	// Atomic leaves the destination untouched when decoding fails. See
+	// WithAtomic.
+	Atomic bool
+	// MergeExisting merges input into existing maps and slices instead of
+	// replacing them. See WithMergeExisting.
+	MergeExisting bool
+	// ZeroMissing resets struct fields whose keys are absent to their zero
+	// values. See WithZeroMissing.
+	ZeroMissing bool
+	// RejectLeadingBOM rejects input starting with a UTF-8 byte order mark
+	// instead of skipping it. See WithRejectLeadingBOM.
+	RejectLeadingBOM bool
+	// StrictUTF8 rejects strings holding invalid UTF-8 or unpaired
+	// surrogate escapes. See WithStrictUTF8.
+	StrictUTF8 bool
+	// RequireCanonical rejects input not in the canonical form of RFC 8785.
+	// See WithRequireCanonical.
+	RequireCanonical bool
+	// Backend decodes values that need no key validation. nil means
+	// StandardBackend. See WithBackend.
+	Backend Backend
+	// Parallelism is the number of goroutines that decode the elements of a
+	// top-level array. Zero or one means sequential. See WithParallelism.
+	Parallelism int
+	// KeyNormalizer, when set, converts keys to a Unicode normalization
+	// form before they are matched against struct fields. See
+	// WithNormalizeKeys.
+	KeyNormalizer KeyNormalizer
+	// KeyTransform, when set, rewrites object keys before they are matched
+	// against struct fields. See WithKeyTransform.
+	KeyTransform func(path, key string) string
+	// ProtoNames selects the protobuf JSON naming convention accepted for
+	// fields of protoc-generated structs. See WithProtoNames.
+	ProtoNames ProtoNames
+	// TagKeys lists the struct tag keys that name fields, in order of
+	// preference. nil means the json tag alone. See WithTagKey.
+	TagKeys []string
+	// AllowComments accepts // and /* */ comments in the input. See
+	// WithAllowComments.
+	AllowComments bool
+	// ExpandEnv makes LoadFile replace ${NAME} references inside string
+	// values with environment variables. See WithExpandEnv.
+	ExpandEnv bool
+	// AllowTrailingCommas accepts a comma after the last member of an
+	// object or array. See WithAllowTrailingCommas.
+	AllowTrailingCommas bool
+	// MaxDepth limits how deeply objects and arrays may nest. Zero means no
+	// limit. See WithMaxDepth.
+	MaxDepth int
+	// MaxBytes, MaxKeysPerObject and MaxElements bound the size of the input
+	// and of each object and array in it. Zero means no limit. See
+	// WithMaxBytes, WithMaxKeysPerObject and WithMaxElements.
+	MaxBytes         int
+	MaxKeysPerObject int
+	MaxElements      int
+	// SuggestionLimit and SuggestionMaxDistance tune SuggestClosest. Zero
+	// means the defaults of 1 suggestion within 2 edits. See
+	// WithSuggestionLimit and WithSuggestionMaxDistance.
+	SuggestionLimit       int
+	SuggestionMaxDistance int
+	// SuggestionBudget caps the unknown keys per decode that suggestions
+	// are computed for. Zero means 100. See WithSuggestionBudget.
+	SuggestionBudget int
+	// ErrorFormatter, when set, produces the message of every error the
+	// decoder returns. See WithErrorFormatter.
+	ErrorFormatter func(ErrInfo) string
+	// ErrorValueSnippets, when positive, is the length at which excerpts of
+	// offending values in errors are cut off. See WithErrorValueSnippets.
+	ErrorValueSnippets int
+	// CaseInsensitive matches keys to fields case-insensitively while still
+	// rejecting unknown keys. See WithCaseSensitive.
+	CaseInsensitive bool
+	// StrictStringOption rejects bare values for fields with the ,string tag
+	// option. See WithStrictStringOption.
+	StrictStringOption bool
+	// StrictArrayLength requires JSON arrays decoded into Go arrays to have
+	// exactly as many elements. See WithStrictArrayLength.
+	StrictArrayLength bool
+	// IgnoreConflicts drops JSON names provided by several embedded structs
+	// at the same depth instead of failing. See WithIgnoreConflicts.
+	IgnoreConflicts bool
+	// MetricsHook, when set, receives the statistics of every decode call.
+	// See WithMetricsHook.
+	MetricsHook MetricsHook
+
+	// schema is the JSON Schema set by WithSchema.
+	schema *compiledSchema
 }
 
 type DecoderOption func(*Decoder)
@@ -29,3 +168,225 @@ func WithSuggestClosest(suggest bool) DecoderOption {
 		d.SuggestClosest = suggest
 	}
 }
+
+// WithSuggestionLimit makes unknown-field errors list up to n ranked
+// suggestions, e.g. did you mean "zipCode" or "zip"?
+func WithSuggestionLimit(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.SuggestionLimit = n
+	}
+}
+
+// WithSuggestionMaxDistance sets how many single-character edits a known
+// field name may be from an unknown key and still be suggested.
+func WithSuggestionMaxDistance(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.SuggestionMaxDistance = n
+	}
+}
+
+// WithSuggestionBudget computes suggestions for at most n unknown keys per
+// decode; later unknown keys are reported without one. This bounds the work
+// an input full of unknown keys can cause in Check mode or with
+// WithOnUnknownField.
+func WithSuggestionBudget(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.SuggestionBudget = n
+	}
+}
+
+// WithTagKey reads field names from the given struct tag keys instead of
+// json, e.g. WithTagKey("api") for fields tagged `api:"userId"`. The keys
+// are tried in order and the first tag that names the field, or skips it
+// with "-", wins; WithTagKey("api", "json") thus falls back to json tags.
+// Fields that no tag names use the Go field name.
+func WithTagKey(keys ...string) DecoderOption {
+	return func(d *Decoder) {
+		d.TagKeys = keys
+	}
+}
+
+// WithCaseSensitive(false) matches keys to field names case-insensitively,
+// like encoding/json, while unknown keys are still rejected at every level.
+// An exact match is preferred when several fields differ only in case.
+func WithCaseSensitive(sensitive bool) DecoderOption {
+	return func(d *Decoder) {
+		d.CaseInsensitive = !sensitive
+	}
+}
+
+// WithStrictStringOption requires the values of fields tagged with the
+// ,string option, such as `json:"count,string"`, to be quoted, as
+// encoding/json does. By default a bare value such as {"count": 12} is
+// accepted too. null is allowed either way.
+func WithStrictStringOption(strict bool) DecoderOption {
+	return func(d *Decoder) {
+		d.StrictStringOption = strict
+	}
+}
+
+// WithStrictArrayLength(true) requires a JSON array decoded into a Go
+// array, such as a [3]float64 coordinate, to have exactly as many elements
+// as the Go array, and reports any other length with a *ArrayLengthError at
+// the array's path. By default arrays are decoded like encoding/json does:
+// surplus elements are dropped, after the same strict checks as the others,
+// and missing ones left zero.
+func WithStrictArrayLength(strict bool) DecoderOption {
+	return func(d *Decoder) {
+		d.StrictArrayLength = strict
+	}
+}
+
+// WithIgnoreConflicts(true) mimics encoding/json for JSON names provided
+// by several embedded structs at the same depth: the ambiguous name matches
+// no field, so it is treated as unknown, instead of every decode into the
+// struct failing with a FieldConflictError.
+func WithIgnoreConflicts(ignore bool) DecoderOption {
+	return func(d *Decoder) {
+		d.IgnoreConflicts = ignore
+	}
+}
+
+// WithDisallowDuplicateKeys rejects objects that repeat a key anywhere in the
+// document, including inside values delegated to encoding/json.
+func WithDisallowDuplicateKeys(disallow bool) DecoderOption {
+	return func(d *Decoder) {
+		d.DisallowDuplicateKeys = disallow
+	}
+}
+
+// WithRejectEscapedKeys rejects object keys written with escape sequences,
+// anywhere in the document, with an EscapedKeyError. Escaped keys are
+// otherwise unescaped before matching, so "\u0063ity" names the same field
+// as "city"; rejecting them outright suits callers that filter or log raw
+// documents by key and must not be bypassed by an alternative spelling.
+func WithRejectEscapedKeys(reject bool) DecoderOption {
+	return func(d *Decoder) {
+		d.RejectEscapedKeys = reject
+	}
+}
+
+// WithUseNumber(true) decodes numbers held in interface values, such as any
+// fields and map[string]any, as json.Number rather than float64, like
+// json.Decoder.UseNumber, so large integers and exact decimals keep their
+// precision. A custom Backend must be configured to do the same itself.
+func WithUseNumber(use bool) DecoderOption {
+	return func(d *Decoder) {
+		d.UseNumber = use
+	}
+}
+
+// WithDisallowNullForNonPointer rejects null for non-pointer fields instead of
+// silently leaving their zero value. Elements of slices and arrays and
+// values of maps are held to the same rule.
+func WithDisallowNullForNonPointer(disallow bool) DecoderOption {
+	return func(d *Decoder) {
+		d.DisallowNullForNonPointer = disallow
+	}
+}
+
+// WithAllowedExtraFields tolerates the given keys (e.g. "$schema", "_links")
+// in every object without weakening unknown-field checks for other keys.
+func WithAllowedExtraFields(keys ...string) DecoderOption {
+	return func(d *Decoder) {
+		d.AllowedExtraFields = append(d.AllowedExtraFields, keys...)
+	}
+}
+
+func (d *Decoder) isAllowedExtra(key string) bool {
+	for _, k := range d.AllowedExtraFields {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// WithIgnorePaths decodes the subtrees matched by the given path patterns
+// leniently, tolerating unknown keys inside them. Patterns are dotted paths
+// in which "*" matches any single key or array index and "**" matches any
+// number of segments, e.g. "data.*.internal" or "meta.**".
+func WithIgnorePaths(patterns ...string) DecoderOption {
+	return func(d *Decoder) {
+		for _, p := range patterns {
+			d.IgnorePaths = append(d.IgnorePaths, normalizePathPattern(p))
+		}
+	}
+}
+
+func (d *Decoder) ignoresPath(path []pathSegment) bool {
+	for _, p := range d.IgnorePaths {
+		if matchPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithOnUnknownField calls fn for every key that would otherwise be rejected
+// as unknown. path is the location of the enclosing object ("" at the top
+// level) and suggestion is filled in when SuggestClosest is enabled.
+// Returning nil skips the key and continues decoding; a non-nil error aborts
+// the decode and is returned unchanged.
+func WithOnUnknownField(fn func(path, key, suggestion string) error) DecoderOption {
+	return func(d *Decoder) {
+		d.OnUnknownField = fn
+	}
+}
+
+// WithMaxDepth rejects documents whose objects and arrays nest more than n
+// levels deep with a *MaxDepthError, including inside values that are
+// skipped or delegated to the backend. A top-level object is at depth 1.
+func WithMaxDepth(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.MaxDepth = n
+	}
+}
+
+// WithMaxBytes rejects input longer than n bytes with a *LimitError before
+// any of it is decoded.
+func WithMaxBytes(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.MaxBytes = n
+	}
+}
+
+// WithMaxKeysPerObject rejects objects with more than n keys with a
+// *LimitError, including objects decoded into maps and those that are
+// skipped. Duplicate keys count once per occurrence.
+func WithMaxKeysPerObject(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.MaxKeysPerObject = n
+	}
+}
+
+// WithMaxElements rejects arrays with more than n elements with a
+// *LimitError, including arrays that are skipped or delegated to the
+// backend.
+func WithMaxElements(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.MaxElements = n
+	}
+}
+
+// WithErrorFormatter replaces the messages of decoder errors with fn's
+// result, e.g. to translate them or add internal error codes. The errors
+// keep their types and accessors, so errors.As and the JSON encoding of
+// errors still report the structured details alongside the new message.
+func WithErrorFormatter(fn func(ErrInfo) string) DecoderOption {
+	return func(d *Decoder) {
+		d.ErrorFormatter = fn
+	}
+}
+
+// WithErrorValueSnippets includes an excerpt of the offending value, cut
+// off after maxLen bytes, in type mismatch errors:
+//
+//	strictjson: cannot decode JSON string into Go int at "age" (got "thirty")
+//
+// Leave it off when errors may be logged and payloads hold secrets.
+func WithErrorValueSnippets(maxLen int) DecoderOption {
+	return func(d *Decoder) {
+		d.ErrorValueSnippets = maxLen
+	}
+}
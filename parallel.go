@@ -0,0 +1,202 @@
+package strictjson
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// WithParallelism decodes the elements of a top-level JSON array across up to
+// n goroutines. Elements are still decoded with every strict check, and the
+// result is the same as a sequential decode: on failure the error of the
+// lowest-indexed failing element is returned, and Check reports violations
+// in document order.
+//
+// Elements are decoded out of order, so custom UnmarshalJSON methods and the
+// OnUnknownField callback may run concurrently and must be safe for that.
+// SuggestionBudget applies to each goroutine separately. UnmarshalWithReport
+// always decodes sequentially. n of zero or one disables parallel decoding.
+func WithParallelism(n int) DecoderOption {
+	return func(d *Decoder) {
+		d.Parallelism = n
+	}
+}
+
+// minParallelChunk is the fewest elements a goroutine is handed at a time;
+// smaller arrays are not worth splitting.
+const minParallelChunk = 16
+
+// parallelChunk is a run of consecutive elements decoded by one goroutine.
+type parallelChunk struct {
+	lo, hi int
+	state  *decodeState
+	err    error
+}
+
+// parallelArray decodes the array at the scanner into v across Parallelism
+// goroutines. It reports false, consuming nothing, if the array is too small
+// to split or cannot be split cleanly; the caller then decodes it
+// sequentially, which also reports any error in it the usual way.
+func (s *decodeState) parallelArray(v reflect.Value, elem *typePlan) (bool, error) {
+	starts, end, ok := s.splitArray()
+	if !ok || len(starts) < 2*minParallelChunk {
+		return false, nil
+	}
+
+	n := len(starts)
+	size := n / (s.d.Parallelism * 4)
+	if size < minParallelChunk {
+		size = minParallelChunk
+	}
+	chunks := make([]parallelChunk, 0, (n+size-1)/size)
+	for lo := 0; lo < n; lo += size {
+		hi := lo + size
+		if hi > n {
+			hi = n
+		}
+		chunks = append(chunks, parallelChunk{lo: lo, hi: hi})
+	}
+
+	newSlice := reflect.MakeSlice(v.Type(), n, n)
+	if s.d.MergeExisting {
+		reflect.Copy(newSlice, v)
+	}
+	// failed is the lowest index of a chunk that failed; later chunks are
+	// skipped, as a sequential decode would never reach them.
+	next, failed := int64(0), int64(len(chunks))
+	var wg sync.WaitGroup
+	workers := s.d.Parallelism
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				c := atomic.AddInt64(&next, 1) - 1
+				if c >= int64(len(chunks)) {
+					return
+				}
+				if c > atomic.LoadInt64(&failed) {
+					continue
+				}
+				if s.decodeChunk(&chunks[c], newSlice, starts, elem) != nil {
+					for f := atomic.LoadInt64(&failed); c < f; f = atomic.LoadInt64(&failed) {
+						if atomic.CompareAndSwapInt64(&failed, f, c) {
+							break
+						}
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	var err error
+	for i := range chunks {
+		c := &chunks[i]
+		if c.state == nil {
+			continue // skipped after an earlier chunk failed
+		}
+		if err == nil {
+			s.merge(c.state)
+			err = c.err
+		}
+		c.state.release()
+	}
+	if err != nil {
+		return true, err
+	}
+	s.scan.pos = end
+	v.Set(newSlice)
+	return true, nil
+}
+
+// splitArray scans the array at the scanner, without consuming it, for the
+// offsets at which its elements start and the offset just past its closing
+// bracket. It reports false if the array is malformed or exceeds a limit.
+func (s *decodeState) splitArray() (starts []int, end int, ok bool) {
+	scan := s.scan
+	scan.depthLimit = 0
+	if s.d.MaxDepth > 0 {
+		scan.depthLimit = s.d.MaxDepth - s.depth + 1
+	}
+	scan.pos++ // '['
+	if scan.consume(']') {
+		return nil, 0, false
+	}
+	for {
+		scan.skipWhitespace()
+		starts = append(starts, scan.pos)
+		if s.d.MaxElements > 0 && len(starts) > s.d.MaxElements {
+			return nil, 0, false
+		}
+		if _, err := scan.skipValue(); err != nil {
+			return nil, 0, false
+		}
+		if scan.consume(',') {
+			continue
+		}
+		if !scan.consume(']') {
+			return nil, 0, false
+		}
+		return starts, scan.pos, true
+	}
+}
+
+// decodeChunk decodes the elements of c into newSlice with a state of its
+// own, stopping at the first error, which it records in c and returns.
+func (s *decodeState) decodeChunk(c *parallelChunk, newSlice reflect.Value, starts []int, elem *typePlan) error {
+	w := s.fork()
+	c.state = w
+	for i := c.lo; i < c.hi; i++ {
+		w.scan.pos = starts[i]
+		w.pushIndex(i)
+		err := w.value(newSlice.Index(i), elem)
+		w.pop()
+		if err != nil {
+			c.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+// fork returns a state that decodes values inside the current one from
+// another goroutine. Its findings are folded back in with merge.
+func (s *decodeState) fork() *decodeState {
+	w := statePool.Get().(*decodeState)
+	w.d = s.d
+	w.scan = scanner{
+		data:        s.scan.data,
+		maxKeys:     s.scan.maxKeys,
+		maxElements: s.scan.maxElements,
+		strictUTF8:  s.scan.strictUTF8,
+	}
+	w.path = append(w.path, s.path...)
+	w.policy = s.policy
+	w.depth = s.depth
+	w.suggested = s.suggested
+	if s.violations != nil {
+		w.violations, w.collect = new([]Violation), s.collect
+	}
+	if s.stats != nil {
+		w.stats = &DecodeStats{}
+	}
+	return w
+}
+
+// merge adds the violations and statistics collected by w, a fork of s.
+func (s *decodeState) merge(w *decodeState) {
+	if s.violations != nil {
+		*s.violations = append(*s.violations, *w.violations...)
+	}
+	if s.stats != nil {
+		s.stats.Objects += w.stats.Objects
+		s.stats.UnknownFields += w.stats.UnknownFields
+		if w.stats.MaxDepth > s.stats.MaxDepth {
+			s.stats.MaxDepth = w.stats.MaxDepth
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package strictjson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type parallelItem struct {
+	ID   int               `json:"id"`
+	Name string            `json:"name"`
+	Tags map[string]string `json:"tags"`
+}
+
+// parallelArrayJSON returns a JSON array of n items, with item i written by
+// item(i).
+func parallelArrayJSON(n int, item func(i int) string) []byte {
+	elems := make([]string, n)
+	for i := range elems {
+		elems[i] = item(i)
+	}
+	return []byte("[\n" + strings.Join(elems, ",\n") + "\n]")
+}
+
+func TestParallelismMatchesSequential(t *testing.T) {
+	data := parallelArrayJSON(1000, func(i int) string {
+		return fmt.Sprintf(`{"id": %d, "name": "item-%d", "tags": {"n": "%d"}}`, i, i, i)
+	})
+
+	var want, got []parallelItem
+	if err := Unmarshal(data, &want); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if err := NewDecoder(WithParallelism(4)).Unmarshal(data, &got); err != nil {
+		t.Fatalf("parallel Unmarshal() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Error("Parallel result differs from sequential result")
+	}
+
+	// Small arrays are decoded sequentially.
+	var small []parallelItem
+	if err := NewDecoder(WithParallelism(4)).Unmarshal([]byte(`[{"id": 1}]`), &small); err != nil || len(small) != 1 {
+		t.Errorf("Expected one item, got %v (%v)", small, err)
+	}
+}
+
+func TestParallelismErrors(t *testing.T) {
+	bad := func(i int) string {
+		switch i {
+		case 300:
+			return `{"id": 1, "bogus": 1}`
+		case 700:
+			return `{"Name": "x"}`
+		}
+		return `{"id": 1}`
+	}
+
+	tests := []struct {
+		name     string
+		data     []byte
+		wantPath string
+	}{
+		{name: "unknown fields", data: parallelArrayJSON(1000, bad), wantPath: "[300].bogus"},
+		{
+			name: "element after a late failure",
+			data: parallelArrayJSON(1000, func(i int) string {
+				if i == 999 {
+					return `{"bogus": 1}`
+				}
+				return `{"id": 1}`
+			}),
+			wantPath: "[999].bogus",
+		},
+	}
+
+	d := NewDecoder(WithParallelism(8))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for run := 0; run < 20; run++ {
+				var items []parallelItem
+				err := d.Unmarshal(tt.data, &items)
+				var ufe *UnknownFieldError
+				if !errors.As(err, &ufe) || ufe.Path() != tt.wantPath {
+					t.Fatalf("Expected unknown field at %q, got %v", tt.wantPath, err)
+				}
+				if items != nil {
+					t.Fatalf("Expected no result on error, got %d items", len(items))
+				}
+			}
+		})
+	}
+
+	// Check collects violations from every element in document order.
+	violations, err := d.Check(parallelArrayJSON(1000, bad), new([]parallelItem))
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path)
+	}
+	if want := []string{"[300].bogus", "[700].Name"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Violations = %v, want %v", paths, want)
+	}
+}
+
+func TestParallelismFallsBackOnMalformedInput(t *testing.T) {
+	data := parallelArrayJSON(100, func(i int) string { return `{"id": 1}` })
+	data = append(data[:len(data)-1], '}')
+
+	var items []parallelItem
+	seqErr := Unmarshal(data, &items)
+	parErr := NewDecoder(WithParallelism(4)).Unmarshal(data, &items)
+	if seqErr == nil || parErr == nil || seqErr.Error() != parErr.Error() {
+		t.Errorf("Expected the sequential error %v, got %v", seqErr, parErr)
+	}
+
+	tooMany := NewDecoder(WithParallelism(4), WithMaxElements(50)).Unmarshal(data, &items)
+	var le *LimitError
+	if !errors.As(tooMany, &le) {
+		t.Errorf("Expected *LimitError, got %v", tooMany)
+	}
+}
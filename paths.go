@@ -0,0 +1,49 @@
+package strictjson
+
+import "strings"
+
+// normalizePathPattern rewrites bracketed indexes into dotted segments so
+// "items[0].name" and "items.0.name" are equivalent patterns.
+func normalizePathPattern(pattern string) string {
+	pattern = strings.ReplaceAll(pattern, "[", ".")
+	pattern = strings.ReplaceAll(pattern, "]", "")
+	return strings.TrimPrefix(pattern, ".")
+}
+
+// matchPath reports whether the dotted glob pattern matches path. A "*"
+// segment matches exactly one key or index, and "**" matches zero or more.
+func matchPath(pattern string, path []pathSegment) bool {
+	if pattern == "" {
+		return len(path) == 0
+	}
+	tok, rest, _ := strings.Cut(pattern, ".")
+	if tok == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchPath(rest, path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || !matchSegment(tok, path[0]) {
+		return false
+	}
+	return matchPath(rest, path[1:])
+}
+
+func matchSegment(tok string, seg pathSegment) bool {
+	if tok == "*" {
+		return true
+	}
+	if seg.index < 0 {
+		return tok == seg.key
+	}
+	n := 0
+	for i := 0; i < len(tok); i++ {
+		if !isDigit(tok[i]) {
+			return false
+		}
+		n = n*10 + int(tok[i]-'0')
+	}
+	return tok != "" && n == seg.index
+}
@@ -0,0 +1,201 @@
+package strictjson
+
+import (
+	"reflect"
+	"sync"
+)
+
+// planKind selects how the decoder handles values of a type.
+type planKind uint8
+
+const (
+	planLiteral     planKind = iota // delegated to encoding/json as-is
+	planUnmarshaler                 // custom json.Unmarshaler, delegated
+	planOptional                    // Optional[T], decoded via elem
+	planStruct                      // object validated against fields
+	planSlice                       // array whose elements need validation, into a slice or Go array
+	planMap                         // object whose values need validation or keys parsing
+	planGenerated                   // object decoded by generated code
+	planText                        // encoding.TextUnmarshaler, also for numbers
+	planInterface                   // interface decoded via registered impls
+	planFunc                        // decoded by a registered DecodeFunc
+	planTime                        // time.Time, checked against a layout
+	planBytes                       // []byte, checked against a base64 encoding
+	planRaw                         // json.RawMessage, stored as written
+)
+
+// typePlan is the precomputed decode strategy for a type. Plans are built
+// once per type by walking the whole reachable type graph, so decoding never
+// repeats interface checks, containsStruct walks or field-table lookups.
+type typePlan struct {
+	typ      reflect.Type
+	kind     planKind
+	indirect bool // typ is a pointer; pointers are allocated before decoding
+	nullable bool // typ has a nil state that null maps onto
+	fields   *structFields
+	err      error // field-table error, reported when an object is decoded
+	elem     *typePlan
+	impls    []*typePlan // registered implementations of an interface
+	union    *unionPlan  // registered union of an interface
+	decode   DecodeFunc  // registered decoder of the base type
+	enum     *enumSet    // values of an Enumer base type
+	policy   Policy      // registered unknown-key policy of the base type
+	// inner is the plan of a json.Unmarshaler type as if it had no
+	// UnmarshalJSON method, with ValidateThroughUnmarshalers.
+	inner *typePlan
+}
+
+var (
+	planCache sync.Map // map[fieldKey]*typePlan
+	planMu    sync.Mutex
+)
+
+// planFor returns the cached plan for t under cfg, building plans for t and
+// every type reachable from it on first use.
+func planFor(t reflect.Type, cfg fieldConfig) *typePlan {
+	key := fieldKey{t, cfg}
+	if p, ok := planCache.Load(key); ok {
+		cacheHit(key)
+		return p.(*typePlan)
+	}
+	cacheMisses.Add(1)
+
+	planMu.Lock()
+	defer planMu.Unlock()
+
+	building := make(map[reflect.Type]*typePlan)
+	p := buildPlan(t, cfg, building)
+	for t, p := range building {
+		cacheStore(fieldKey{t, cfg}, p)
+	}
+	return p
+}
+
+// planFor returns the plan for t under d's field settings.
+func (d *Decoder) planFor(t reflect.Type) *typePlan {
+	return planFor(t, d.fieldConfig())
+}
+
+func buildPlan(t reflect.Type, cfg fieldConfig, building map[reflect.Type]*typePlan) *typePlan {
+	if p, ok := planCache.Load(fieldKey{t, cfg}); ok {
+		return p.(*typePlan)
+	}
+	if p, ok := building[t]; ok {
+		return p // recursive type; filled in further up the stack
+	}
+
+	p := &typePlan{typ: t, nullable: isNullable(t.Kind())}
+	building[t] = p
+
+	if t.Kind() != reflect.Ptr && isOptional(t) {
+		p.kind = planOptional
+		p.elem = buildPlan(t.Field(0).Type, cfg, building)
+		return p
+	}
+
+	base := t
+	for base.Kind() == reflect.Ptr {
+		base = base.Elem()
+		p.indirect = true
+	}
+	p.policy = typePolicy(base)
+	p.enum = typeEnum(base)
+	if fn := registeredDecoder(base); fn != nil {
+		p.kind = planFunc
+		p.decode = fn
+		return p
+	}
+	if base == timeType {
+		p.kind = planTime
+		return p
+	}
+	if base == rawMessageType {
+		p.kind = planRaw
+		return p
+	}
+	if isBytes(base) {
+		p.kind = planBytes
+		return p
+	}
+	if implementsUnmarshaler(reflect.PointerTo(base)) {
+		p.kind = planUnmarshaler
+		if cfg.throughUnmarshalers {
+			p.inner = buildInnerPlan(base, cfg, building)
+		}
+		return p
+	}
+	if implementsTextUnmarshaler(reflect.PointerTo(base)) {
+		p.kind = planText
+		return p
+	}
+	// Generated decoders know only the default field names.
+	if reflect.PointerTo(base).Implements(strictUnmarshalerType) && cfg.protoNames == ProtoNamesOff && cfg.tagKeys == "" && !hasAliases(base) {
+		p.kind = planGenerated
+		return p
+	}
+	buildShape(p, base, cfg, building)
+	return p
+}
+
+// buildInnerPlan returns the plan for base, a json.Unmarshaler type, that
+// ignores its UnmarshalJSON method, or nil if that plan would leave the
+// whole value to the backend anyway.
+func buildInnerPlan(base reflect.Type, cfg fieldConfig, building map[reflect.Type]*typePlan) *typePlan {
+	p := &typePlan{typ: base, nullable: isNullable(base.Kind())}
+	buildShape(p, base, cfg, building)
+	if p.kind == planLiteral {
+		return nil
+	}
+	return p
+}
+
+// buildShape fills in p for base by its kind: the field table of a struct,
+// the element plan of a slice or map that needs validating or whose keys
+// need parsing, or the
+// implementations of an interface.
+func buildShape(p *typePlan, base reflect.Type, cfg fieldConfig, building map[reflect.Type]*typePlan) {
+	switch base.Kind() {
+	case reflect.Struct:
+		p.kind = planStruct
+		p.fields, p.err = getStructFields(base, cfg)
+		if p.fields != nil {
+			for _, fi := range p.fields.fields {
+				if fi.plan == nil {
+					fi.plan = buildPlan(fi.typ, cfg, building)
+				}
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if containsStruct(base.Elem(), cfg.throughUnmarshalers) {
+			p.kind = planSlice
+			p.elem = buildPlan(base.Elem(), cfg, building)
+		}
+	case reflect.Map:
+		if parsesKeys(base) || containsStruct(base.Elem(), cfg.throughUnmarshalers) {
+			p.kind = planMap
+			p.elem = buildPlan(base.Elem(), cfg, building)
+		}
+	case reflect.Interface:
+		if u := registeredUnion(base); u != nil {
+			p.kind = planInterface
+			p.union = buildUnionPlan(u, cfg, building)
+		} else if impls := interfaceImpls(base); len(impls) > 0 {
+			p.kind = planInterface
+			for _, impl := range impls {
+				p.impls = append(p.impls, buildPlan(impl, cfg, building))
+			}
+		}
+	}
+}
+
+// resetPlans discards all cached plans, e.g. after the field table of a type
+// changes.
+func resetPlans() {
+	planMu.Lock()
+	defer planMu.Unlock()
+	planCache.Range(func(k, _ any) bool {
+		planCache.Delete(k)
+		return true
+	})
+	resetCacheOrder()
+}
@@ -0,0 +1,97 @@
+package strictjson
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Policy selects how keys that match no field are treated inside a value.
+type Policy uint8
+
+const (
+	// PolicyDefault inherits the policy of the enclosing value, and at the
+	// top level follows the decoder's DisallowUnknownFields and IgnorePaths.
+	PolicyDefault Policy = iota
+	// PolicyLenient tolerates unknown keys, like encoding/json.
+	PolicyLenient
+	// PolicyStrict rejects unknown keys, even where the decoder or an
+	// enclosing value would tolerate them.
+	PolicyStrict
+)
+
+var policyRegistry struct {
+	sync.RWMutex
+	byType map[reflect.Type]Policy
+}
+
+// RegisterTypePolicy sets the unknown-key policy for every value of type t,
+// wherever it is decoded. The policy covers the whole subtree below the
+// value unless a nested type or field sets its own. Registering
+// PolicyDefault removes an earlier registration.
+//
+// It is intended to be called during program initialization.
+func RegisterTypePolicy(t reflect.Type, p Policy) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	policyRegistry.Lock()
+	if policyRegistry.byType == nil {
+		policyRegistry.byType = make(map[reflect.Type]Policy)
+	}
+	if p == PolicyDefault {
+		delete(policyRegistry.byType, t)
+	} else {
+		policyRegistry.byType[t] = p
+	}
+	policyRegistry.Unlock()
+
+	// Field tables hold the plans of their fields, so drop them all.
+	ClearCache()
+}
+
+// RegisterLenientType excludes values of type t from unknown-field checks
+// wherever they appear, e.g. third-party types such as webhook payloads
+// whose vendors add fields without notice. It is shorthand for
+// RegisterTypePolicy(t, PolicyLenient).
+func RegisterLenientType(t reflect.Type) {
+	RegisterTypePolicy(t, PolicyLenient)
+}
+
+// RegisterStrictType makes values of type t reject unknown keys wherever
+// they appear, including inside lenient subtrees and with decoders that
+// allow unknown fields. It is shorthand for
+// RegisterTypePolicy(t, PolicyStrict).
+func RegisterStrictType(t reflect.Type) {
+	RegisterTypePolicy(t, PolicyStrict)
+}
+
+func typePolicy(t reflect.Type) Policy {
+	policyRegistry.RLock()
+	defer policyRegistry.RUnlock()
+	return policyRegistry.byType[t]
+}
+
+// scope returns the policy to switch to before decoding a value with plan
+// p at the current path, and false if the current policy stays in effect.
+// A type's registered policy wins; otherwise IgnorePaths make a subtree
+// lenient unless an explicit policy is already in effect.
+func (s *decodeState) scope(p *typePlan) (Policy, bool) {
+	if p.policy != PolicyDefault {
+		return p.policy, p.policy != s.policy
+	}
+	if s.policy == PolicyDefault && len(s.d.IgnorePaths) > 0 && s.d.ignoresPath(s.path) {
+		return PolicyLenient, true
+	}
+	return PolicyDefault, false
+}
+
+// enterPolicy switches to p unless it is PolicyDefault and returns the
+// policy to restore afterwards.
+func (s *decodeState) enterPolicy(p Policy) Policy {
+	saved := s.policy
+	if p != PolicyDefault {
+		s.policy = p
+	}
+	return saved
+}
@@ -0,0 +1,156 @@
+package strictjson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestLenientFieldTag(t *testing.T) {
+	type Vendor struct {
+		ID string `json:"id"`
+	}
+	type Payload struct {
+		Name   string `json:"name"`
+		Vendor Vendor `json:"vendor" strict:"lenient"`
+		Items  []struct {
+			SKU string `json:"sku"`
+		} `json:"items"`
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"unknown inside lenient field", `{"name": "a", "vendor": {"id": "v", "extra": {"deep": 1}}}`, ""},
+		{"unknown at top level", `{"name": "a", "extra": 1}`, "extra"},
+		{"unknown in strict sibling", `{"items": [{"sku": "x", "qty": 1}]}`, "items[0].qty"},
+		{"mis-cased inside lenient field", `{"vendor": {"ID": "v"}}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+			check := func(api string, err error) {
+				var ufe *UnknownFieldError
+				switch {
+				case tt.wantErr == "" && err != nil:
+					t.Errorf("%s: unexpected error: %v", api, err)
+				case tt.wantErr != "" && (!errors.As(err, &ufe) || ufe.Path() != tt.wantErr):
+					t.Errorf("%s: expected unknown field at %q, got %v", api, tt.wantErr, err)
+				}
+			}
+			check("Unmarshal", Unmarshal([]byte(tt.data), &p))
+			check("Validate", Validate([]byte(tt.data), p))
+
+			var m map[string]any
+			if err := Unmarshal([]byte(tt.data), &m); err != nil {
+				t.Fatal(err)
+			}
+			check("DecodeMap", DecodeMap(m, &p))
+		})
+	}
+}
+
+func TestStrictFieldTag(t *testing.T) {
+	type Billing struct {
+		Plan string `json:"plan"`
+	}
+	type Account struct {
+		Billing Billing        `json:"billing" strict:"strict"`
+		Meta    map[string]any `json:"meta"`
+	}
+
+	d := NewDecoder(WithDisallowUnknownFields(false))
+	var a Account
+	if err := d.Unmarshal([]byte(`{"billing": {"plan": "pro"}, "other": 1}`), &a); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+	err := d.Unmarshal([]byte(`{"billing": {"plan": "pro", "seats": 3}}`), &a)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "billing.seats" {
+		t.Errorf("Expected unknown field at billing.seats, got %v", err)
+	}
+}
+
+type policyVendorEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		Object policyVendorObject `json:"object"`
+	} `json:"data"`
+}
+
+type policyVendorObject struct {
+	ID string `json:"id"`
+}
+
+func TestRegisterTypePolicy(t *testing.T) {
+	type Webhook struct {
+		Event  policyVendorEvent `json:"event"`
+		Source string            `json:"source"`
+	}
+	data := []byte(`{"event": {"type": "x", "api_version": "1", "data": {"object": {"id": "o", "livemode": true}}}}`)
+
+	var w Webhook
+	if err := Unmarshal(data, &w); err == nil {
+		t.Fatal("Expected unknown field error before registration")
+	}
+
+	RegisterTypePolicy(reflect.TypeOf(policyVendorEvent{}), PolicyLenient)
+	defer RegisterTypePolicy(reflect.TypeOf(policyVendorEvent{}), PolicyDefault)
+
+	if err := Unmarshal(data, &w); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+	if w.Event.Data.Object.ID != "o" {
+		t.Errorf("Expected nested value decoded, got %+v", w.Event)
+	}
+	if err := Unmarshal([]byte(`{"source": "s", "extra": 1}`), &w); err == nil {
+		t.Error("Expected siblings of the lenient type to stay strict")
+	}
+
+	// A strict type nested in a lenient one is strict again.
+	RegisterTypePolicy(reflect.TypeOf(policyVendorObject{}), PolicyStrict)
+	defer RegisterTypePolicy(reflect.TypeOf(policyVendorObject{}), PolicyDefault)
+	err := Unmarshal(data, &w)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "event.data.object.livemode" {
+		t.Errorf("Expected unknown field at event.data.object.livemode, got %v", err)
+	}
+}
+
+type policyThirdParty struct {
+	ID string `json:"id"`
+}
+
+type policyInternal struct {
+	Name string `json:"name"`
+}
+
+func TestRegisterLenientAndStrictType(t *testing.T) {
+	type Envelope struct {
+		External policyThirdParty `json:"external"`
+		Internal *policyInternal  `json:"internal"`
+	}
+
+	RegisterLenientType(reflect.TypeOf(policyThirdParty{}))
+	defer RegisterTypePolicy(reflect.TypeOf(policyThirdParty{}), PolicyDefault)
+	RegisterStrictType(reflect.TypeOf((*policyInternal)(nil)))
+	defer RegisterTypePolicy(reflect.TypeOf(policyInternal{}), PolicyDefault)
+
+	var e Envelope
+	if err := Unmarshal([]byte(`{"external": {"id": "x", "added_later": true}}`), &e); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+
+	lax := NewDecoder(WithDisallowUnknownFields(false))
+	if err := lax.Unmarshal([]byte(`{"other": 1, "internal": {"name": "n"}}`), &e); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+	err := lax.Unmarshal([]byte(`{"internal": {"name": "n", "nmae": "typo"}}`), &e)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "internal.nmae" {
+		t.Errorf("Expected strict type to reject internal.nmae, got %v", err)
+	}
+}
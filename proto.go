@@ -0,0 +1,95 @@
+package strictjson
+
+import (
+	"reflect"
+	"strings"
+)
+
+// ProtoNames selects which naming convention is accepted for structs
+// generated by protoc-gen-go. protojson itself accepts both the original
+// .proto field name ("user_name") and its lowerCamelCase JSON name
+// ("userName"), which lets clients drift between the two unnoticed.
+type ProtoNames uint8
+
+const (
+	// ProtoNamesOff ignores protobuf struct tags and uses json tags.
+	ProtoNamesOff ProtoNames = iota
+	// ProtoNamesOriginal accepts only original .proto field names, as
+	// produced by protojson with UseProtoNames.
+	ProtoNamesOriginal
+	// ProtoNamesJSON accepts only lowerCamelCase JSON names, protojson's
+	// default output.
+	ProtoNamesJSON
+)
+
+// WithProtoNames decodes fields carrying a protobuf struct tag by their
+// protobuf name in the given convention, rejecting keys in the other one.
+// Fields without a protobuf tag keep their json tag name. Oneof wrappers are
+// not flattened the way protojson does.
+func WithProtoNames(mode ProtoNames) DecoderOption {
+	return func(d *Decoder) {
+		d.ProtoNames = mode
+	}
+}
+
+func (d *Decoder) fieldConfig() fieldConfig {
+	cfg := fieldConfig{
+		protoNames:          d.ProtoNames,
+		tagKeys:             strings.Join(d.TagKeys, ","),
+		throughUnmarshalers: d.ValidateThroughUnmarshalers,
+	}
+	if cfg.tagKeys == "json" {
+		cfg.tagKeys = "" // the default, so generated decoders still apply
+	}
+	return cfg
+}
+
+// protoFieldName returns the name of f in the given convention, read from a
+// protoc-gen-go tag such as `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3"`.
+func protoFieldName(f reflect.StructField, mode ProtoNames) (string, bool) {
+	tag, ok := f.Tag.Lookup("protobuf")
+	if !ok {
+		return "", false
+	}
+	var name, jsonName string
+	for _, opt := range strings.Split(tag, ",") {
+		switch {
+		case strings.HasPrefix(opt, "name="):
+			name = opt[len("name="):]
+		case strings.HasPrefix(opt, "json="):
+			jsonName = opt[len("json="):]
+		}
+	}
+	if name == "" {
+		return "", false
+	}
+	if mode == ProtoNamesOriginal {
+		return name, true
+	}
+	if jsonName == "" {
+		jsonName = protoJSONName(name)
+	}
+	return jsonName, true
+}
+
+// protoJSONName derives the JSON name of a .proto field the way protoc does
+// when the tag carries no json= entry: underscores are dropped and the
+// letter after each one is upper-cased.
+func protoJSONName(name string) string {
+	var b strings.Builder
+	upper := false
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '_':
+			upper = true
+		case upper && 'a' <= c && c <= 'z':
+			b.WriteByte(c - 'a' + 'A')
+			upper = false
+		default:
+			b.WriteByte(c)
+			upper = false
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,82 @@
+package strictjson
+
+import (
+	"errors"
+	"testing"
+)
+
+// userProto mirrors the shape of a protoc-gen-go message.
+type userProto struct {
+	UserName  string            `protobuf:"bytes,1,opt,name=user_name,json=userName,proto3" json:"user_name,omitempty"`
+	Id        int64             `protobuf:"varint,2,opt,name=id,proto3" json:"id,omitempty"`
+	HomeAddr  *addressProto     `protobuf:"bytes,3,opt,name=home_addr,proto3" json:"home_addr,omitempty"`
+	Labels    map[string]string `protobuf:"bytes,4,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	LocalOnly string            `json:"local_only"`
+}
+
+type addressProto struct {
+	ZipCode string `protobuf:"bytes,1,opt,name=zip_code,json=zipCode,proto3" json:"zip_code,omitempty"`
+}
+
+func TestProtoNames(t *testing.T) {
+	original := []byte(`{"user_name": "a", "id": 1, "home_addr": {"zip_code": "9"}, "labels": {"k": "v"}, "local_only": "x"}`)
+	camel := []byte(`{"userName": "a", "id": 1, "homeAddr": {"zipCode": "9"}, "labels": {"k": "v"}, "local_only": "x"}`)
+
+	tests := []struct {
+		name    string
+		mode    ProtoNames
+		data    []byte
+		wantErr bool
+	}{
+		{"original accepts original", ProtoNamesOriginal, original, false},
+		{"original rejects camel", ProtoNamesOriginal, camel, true},
+		{"json accepts camel", ProtoNamesJSON, camel, false},
+		{"json rejects original", ProtoNamesJSON, original, true},
+		{"off uses json tags", ProtoNamesOff, original, false},
+		{"off rejects camel", ProtoNamesOff, camel, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var u userProto
+			err := NewDecoder(WithProtoNames(tt.mode)).Unmarshal(tt.data, &u)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() unexpected error: %v", err)
+			}
+			if u.UserName != "a" || u.Id != 1 || u.HomeAddr.ZipCode != "9" || u.Labels["k"] != "v" || u.LocalOnly != "x" {
+				t.Errorf("Unexpected result %+v", u)
+			}
+		})
+	}
+}
+
+func TestProtoNamesSuggestion(t *testing.T) {
+	var u userProto
+	d := NewDecoder(WithProtoNames(ProtoNamesJSON), WithSuggestClosest(true))
+	err := d.Unmarshal([]byte(`{"home_addr": {}}`), &u)
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Suggestion() != "homeAddr" {
+		t.Errorf("Expected suggestion 'homeAddr', got %v", err)
+	}
+}
+
+func TestProtoJSONName(t *testing.T) {
+	tests := map[string]string{
+		"home_addr":   "homeAddr",
+		"id":          "id",
+		"a_b_c":       "aBC",
+		"field_2_x":   "field2X",
+		"trailing_":   "trailing",
+		"already_Cap": "alreadyCap",
+	}
+	for in, want := range tests {
+		if got := protoJSONName(in); got != want {
+			t.Errorf("protoJSONName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+
+// raw stores the next value into v, a json.RawMessage, as written, reusing
+// its storage as encoding/json does. Like encoding/json, it stores a null as
+// the bytes null; only a *json.RawMessage is decoded from null as nil.
+func (s *decodeState) raw(v reflect.Value) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	v.SetBytes(append(v.Bytes()[:0], raw...))
+	return nil
+}
+
+// ValidateRaw validates raw, a value captured as a json.RawMessage field for
+// auditing or later dispatch, against the type of prototype once that type
+// is known, as Validate does: prototype may be a value or a pointer, such as
+// (*CreateUserRequest)(nil). Errors are located relative to raw.
+func ValidateRaw(raw json.RawMessage, prototype any) error {
+	return NewDecoder().ValidateRaw(raw, prototype)
+}
+
+// ValidateRaw is like the package-level ValidateRaw but applies d's
+// options, so a payload is checked by the same rules as the document it
+// came from when d is the decoder that decoded that document.
+func (d *Decoder) ValidateRaw(raw json.RawMessage, prototype any) error {
+	return d.Validate(raw, prototype)
+}
@@ -0,0 +1,94 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRawMessageFields(t *testing.T) {
+	type Envelope struct {
+		Kind    string            `json:"kind"`
+		Payload json.RawMessage   `json:"payload"`
+		Ptr     *json.RawMessage  `json:"ptr"`
+		Parts   []json.RawMessage `json:"parts"`
+	}
+
+	tests := []struct {
+		name string
+		data string
+		opts []DecoderOption
+	}{
+		{"object", `{"payload": {"Any": ["keys", 1]}, "ptr": [true]}`, nil},
+		{"null", `{"payload": null, "ptr": null, "parts": [null, "x"]}`, nil},
+		{"string with strict base64", `{"payload": "not base64", "parts": ["%%"]}`, []DecoderOption{WithStrictBase64(true)}},
+		{"spacing kept", `{"payload": { "a" :1 } }`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got, want Envelope
+			if err := NewDecoder(tt.opts...).Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() unexpected error: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.data), &want); err != nil {
+				t.Fatal(err)
+			}
+			if string(got.Payload) != string(want.Payload) || (got.Ptr == nil) != (want.Ptr == nil) || len(got.Parts) != len(want.Parts) {
+				t.Errorf("Got %s %v %q, encoding/json got %s %v %q", got.Payload, got.Ptr, got.Parts, want.Payload, want.Ptr, want.Parts)
+			}
+			for i := range got.Parts {
+				if string(got.Parts[i]) != string(want.Parts[i]) {
+					t.Errorf("parts[%d] = %s, encoding/json got %s", i, got.Parts[i], want.Parts[i])
+				}
+			}
+		})
+	}
+
+	// The payload is a copy, not a view of the input.
+	data := []byte(`{"payload": [1]}`)
+	var e Envelope
+	if err := Unmarshal(data, &e); err != nil {
+		t.Fatal(err)
+	}
+	data[13] = '2'
+	if string(e.Payload) != "[1]" {
+		t.Errorf("Payload changed with the input: %s", e.Payload)
+	}
+}
+
+func TestValidateRaw(t *testing.T) {
+	type CreateUser struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	type Event struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+
+	var e Event
+	if err := Unmarshal([]byte(`{"type": "create_user", "payload": {"name": "a", "name": "c", "Email": "b"}}`), &e); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+
+	err := ValidateRaw(e.Payload, (*CreateUser)(nil))
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "Email" {
+		t.Errorf("ValidateRaw() = %v, want an unknown field at \"Email\"", err)
+	}
+
+	// The decoder's options apply: the duplicate is reported first.
+	d := NewDecoder(WithDisallowDuplicateKeys(true))
+	err = d.ValidateRaw(e.Payload, CreateUser{})
+	var dke *DuplicateKeyError
+	if !errors.As(err, &dke) {
+		t.Errorf("Decoder.ValidateRaw() = %v, want a *DuplicateKeyError", err)
+	}
+
+	if err := ValidateRaw(json.RawMessage(`{"name": "a"}`), CreateUser{}); err != nil {
+		t.Errorf("ValidateRaw() unexpected error: %v", err)
+	}
+	if err := ValidateRaw(nil, CreateUser{}); err == nil {
+		t.Error("ValidateRaw(nil) succeeded, want a syntax error")
+	}
+}
@@ -0,0 +1,62 @@
+package strictjson
+
+import "reflect"
+
+// DecodeReport records which struct fields were present in a decoded
+// document. Paths use the same notation as error paths, e.g. "name" or
+// "items[0].price".
+type DecodeReport struct {
+	fields  []string
+	present map[string]struct{}
+}
+
+// Present reports whether the field at path appeared in the JSON, including
+// fields explicitly set to null.
+func (r *DecodeReport) Present(path string) bool {
+	_, ok := r.present[path]
+	return ok
+}
+
+// Fields returns the paths of all fields present in the JSON, in document
+// order.
+func (r *DecodeReport) Fields() []string {
+	return r.fields
+}
+
+func (r *DecodeReport) add(path string) {
+	if _, ok := r.present[path]; ok {
+		return
+	}
+	r.present[path] = struct{}{}
+	r.fields = append(r.fields, path)
+}
+
+// UnmarshalWithReport behaves like Unmarshal and additionally reports which
+// struct fields were present in the input. This allows PATCH-style handling
+// without making every field a pointer. On error the report covers the
+// fields decoded before the failure.
+func (d *Decoder) UnmarshalWithReport(data []byte, v any) (_ *DecodeReport, err error) {
+	defer func() { err = d.formatError(err) }()
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, newNonPointerError()
+	}
+
+	report := &DecodeReport{present: make(map[string]struct{})}
+	s, err := d.newState(data)
+	if err != nil {
+		return report, err
+	}
+	defer s.release()
+	defer s.observe(rv.Elem().Type(), &err)
+	s.report = report
+	target := d.target(rv.Elem())
+	err = s.document(func() error {
+		return s.value(target, s.d.planFor(rv.Elem().Type()))
+	})
+	if err != nil {
+		return report, err
+	}
+	d.commit(rv.Elem(), target)
+	return report, nil
+}
@@ -0,0 +1,57 @@
+package strictjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnmarshalWithReport(t *testing.T) {
+	type Item struct {
+		Name  string `json:"name"`
+		Price int    `json:"price"`
+	}
+	type Patch struct {
+		Name  string  `json:"name"`
+		Age   int     `json:"age"`
+		Nick  *string `json:"nick"`
+		Items []Item  `json:"items"`
+	}
+
+	d := NewDecoder()
+	var p Patch
+	report, err := d.UnmarshalWithReport([]byte(`{"age": 0, "nick": null, "items": [{"price": 5}]}`), &p)
+	if err != nil {
+		t.Fatalf("UnmarshalWithReport() unexpected error: %v", err)
+	}
+
+	want := []string{"age", "nick", "items", "items[0].price"}
+	if !reflect.DeepEqual(report.Fields(), want) {
+		t.Errorf("Fields() = %v, want %v", report.Fields(), want)
+	}
+	for _, path := range want {
+		if !report.Present(path) {
+			t.Errorf("Present(%q) = false, want true", path)
+		}
+	}
+	for _, path := range []string{"name", "items[0].name"} {
+		if report.Present(path) {
+			t.Errorf("Present(%q) = true, want false", path)
+		}
+	}
+}
+
+func TestUnmarshalWithReportPartialOnError(t *testing.T) {
+	type Patch struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	var p Patch
+	report, err := NewDecoder().UnmarshalWithReport([]byte(`{"name": "a", "AGE": 1}`), &p)
+	if err == nil {
+		t.Fatal("Expected error for mis-cased key")
+	}
+	if !report.Present("name") || report.Present("age") {
+		t.Errorf("Unexpected partial report: %v", report.Fields())
+	}
+}
@@ -0,0 +1,615 @@
+package strictjson
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// scanner walks a JSON document in a single forward pass. The decoder uses it
+// to read object keys and structural tokens directly, while scalar values and
+// subtrees that need no validation are sliced out raw with skipValue and handed
+// to encoding/json untouched.
+type scanner struct {
+	data []byte
+	pos  int
+	// depthLimit, when positive, is the container nesting at which
+	// skipValue fails with errDepthLimit.
+	depthLimit int
+	// maxKeys and maxElements, when positive, bound the members of each
+	// object and array skipValue passes over.
+	maxKeys     int
+	maxElements int
+	// strictUTF8 rejects strings holding invalid UTF-8 or escapes of
+	// unpaired UTF-16 surrogates.
+	strictUTF8 bool
+}
+
+// Errors returned by skipValue when a value exceeds a limit. The decoder
+// turns them into *MaxDepthError and *LimitError.
+var (
+	errDepthLimit   = errors.New("strictjson: nesting depth limit exceeded")
+	errKeyLimit     = errors.New("strictjson: object key limit exceeded")
+	errElementLimit = errors.New("strictjson: array element limit exceeded")
+)
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (s *scanner) skipWhitespace() {
+	for s.pos < len(s.data) && isSpace(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+// peek returns the next non-whitespace byte without consuming it, or 0 at the
+// end of input.
+func (s *scanner) peek() byte {
+	s.skipWhitespace()
+	if s.pos >= len(s.data) {
+		return 0
+	}
+	return s.data[s.pos]
+}
+
+// consume advances past c if it is the next non-whitespace byte.
+func (s *scanner) consume(c byte) bool {
+	if s.peek() == c {
+		s.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes c or reports a syntax error describing what was expected.
+func (s *scanner) expect(c byte, context string) error {
+	if s.consume(c) {
+		return nil
+	}
+	return s.errorf(context)
+}
+
+// end verifies that only whitespace remains after the top-level value.
+func (s *scanner) end() error {
+	s.skipWhitespace()
+	if s.pos < len(s.data) {
+		return s.errorf("after top-level value")
+	}
+	return nil
+}
+
+// errorf builds a syntax error for the byte at the current position.
+func (s *scanner) errorf(context string) error {
+	if s.pos >= len(s.data) {
+		return newSyntaxError("unexpected end of JSON input", int64(s.pos))
+	}
+	return newSyntaxError("invalid character "+quoteChar(s.data[s.pos])+" "+context, int64(s.pos))
+}
+
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	q := strconv.Quote(string(c))
+	return "'" + q[1:len(q)-1] + "'"
+}
+
+// readKey reads an object key and its trailing colon, returning the unquoted
+// key.
+func (s *scanner) readKey() (string, error) {
+	if s.peek() != '"' {
+		return "", s.errorf("looking for beginning of object key string")
+	}
+	raw, escaped, err := s.readString()
+	if err != nil {
+		return "", err
+	}
+	if err := s.expect(':', "after object key"); err != nil {
+		return "", err
+	}
+	return unquote(raw, escaped), nil
+}
+
+// readString consumes a string literal and returns its contents without the
+// surrounding quotes. escaped reports whether the contents need unquoting.
+func (s *scanner) readString() (raw []byte, escaped bool, err error) {
+	s.pos++ // opening quote
+	start := s.pos
+	for s.pos < len(s.data) {
+		c := s.data[s.pos]
+		switch {
+		case c == '"':
+			raw = s.data[start:s.pos]
+			s.pos++
+			return raw, escaped, nil
+		case c == '\\':
+			escaped = true
+			s.pos++
+			if s.pos >= len(s.data) {
+				return nil, false, s.errorf("")
+			}
+			switch s.data[s.pos] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+				s.pos++
+			case 'u':
+				s.pos++
+				for i := 0; i < 4; i++ {
+					if s.pos >= len(s.data) {
+						return nil, false, s.errorf("")
+					}
+					if !isHex(s.data[s.pos]) {
+						return nil, false, s.errorf("in \\u hexadecimal character escape")
+					}
+					s.pos++
+				}
+				if s.strictUTF8 {
+					if err := s.checkSurrogate(); err != nil {
+						return nil, false, err
+					}
+				}
+			default:
+				return nil, false, s.errorf("in string escape code")
+			}
+		case c < 0x20:
+			return nil, false, s.errorf("in string literal")
+		default:
+			if c < utf8.RuneSelf {
+				s.pos++
+				continue
+			}
+			escaped = true
+			if !s.strictUTF8 {
+				s.pos++
+				continue
+			}
+			r, size := utf8.DecodeRune(s.data[s.pos:])
+			if r == utf8.RuneError && size == 1 {
+				return nil, false, newSyntaxError("invalid UTF-8 in string literal", int64(s.pos))
+			}
+			s.pos += size
+		}
+	}
+	return nil, false, s.errorf("")
+}
+
+// checkSurrogate rejects the \u escape just read if it is half of a UTF-16
+// surrogate pair that is not followed, or preceded, by the other half. A
+// complete pair is consumed whole.
+func (s *scanner) checkSurrogate() error {
+	start := s.pos - 6
+	r := getu4(s.data[s.pos-4:])
+	if !utf16.IsSurrogate(r) {
+		return nil
+	}
+	rest := s.data[s.pos:]
+	if r < 0xdc00 && len(rest) >= 6 && rest[0] == '\\' && rest[1] == 'u' &&
+		isHex(rest[2]) && isHex(rest[3]) && isHex(rest[4]) && isHex(rest[5]) {
+		if r2 := getu4(rest[2:]); 0xdc00 <= r2 && r2 <= 0xdfff {
+			s.pos += 6
+			return nil
+		}
+	}
+	return newSyntaxError("unpaired UTF-16 surrogate in \\u escape", int64(start))
+}
+
+// hasEscape reports whether string contents contain an escape sequence.
+// Unlike the escaped result of readString, it ignores non-ASCII bytes.
+func hasEscape(raw []byte) bool {
+	return bytes.IndexByte(raw, '\\') >= 0
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func (s *scanner) readLiteral(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		if s.pos >= len(s.data) {
+			return s.errorf("")
+		}
+		if s.data[s.pos] != lit[i] {
+			return s.errorf("in literal " + lit + " (expecting " + quoteChar(lit[i]) + ")")
+		}
+		s.pos++
+	}
+	return nil
+}
+
+// consumeNull consumes a null literal if one is next. Anything else,
+// including a malformed literal such as nnull, is left for the caller to
+// read and report.
+func (s *scanner) consumeNull() bool {
+	if s.peek() != 'n' {
+		return false
+	}
+	start := s.pos
+	if s.readLiteral("null") != nil {
+		s.pos = start
+		return false
+	}
+	return true
+}
+
+func (s *scanner) readNumber() error {
+	if s.pos < len(s.data) && s.data[s.pos] == '-' {
+		s.pos++
+	}
+	if s.pos >= len(s.data) {
+		return s.errorf("")
+	}
+	switch c := s.data[s.pos]; {
+	case c == '0':
+		s.pos++
+	case '1' <= c && c <= '9':
+		s.skipDigits()
+	default:
+		return s.errorf("in numeric literal")
+	}
+	if s.pos < len(s.data) && s.data[s.pos] == '.' {
+		s.pos++
+		if s.pos >= len(s.data) || !isDigit(s.data[s.pos]) {
+			return s.errorf("after decimal point in numeric literal")
+		}
+		s.skipDigits()
+	}
+	if s.pos < len(s.data) && (s.data[s.pos] == 'e' || s.data[s.pos] == 'E') {
+		s.pos++
+		if s.pos < len(s.data) && (s.data[s.pos] == '+' || s.data[s.pos] == '-') {
+			s.pos++
+		}
+		if s.pos >= len(s.data) || !isDigit(s.data[s.pos]) {
+			return s.errorf("in exponent of numeric literal")
+		}
+		s.skipDigits()
+	}
+	return nil
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func (s *scanner) skipDigits() {
+	for s.pos < len(s.data) && isDigit(s.data[s.pos]) {
+		s.pos++
+	}
+}
+
+// readScalar consumes a string, number or literal.
+func (s *scanner) readScalar() error {
+	switch c := s.data[s.pos]; {
+	case c == '"':
+		_, _, err := s.readString()
+		return err
+	case c == 't':
+		return s.readLiteral("true")
+	case c == 'f':
+		return s.readLiteral("false")
+	case c == 'n':
+		return s.readLiteral("null")
+	case c == '-' || isDigit(c):
+		return s.readNumber()
+	default:
+		return s.errorf("looking for beginning of value")
+	}
+}
+
+// skipValue consumes the next value, validating its syntax, and returns the
+// raw bytes it spans. Nesting is tracked with an explicit stack so arbitrarily
+// deep input cannot exhaust the goroutine stack.
+func (s *scanner) skipValue() ([]byte, error) {
+	s.skipWhitespace()
+	start := s.pos
+	var stackBuf [16]byte
+	stack := stackBuf[:0]
+	// counts holds the number of members seen in each open container.
+	var countBuf [16]int
+	counts := countBuf[:0]
+
+	for {
+		// Parse the start of a value.
+		s.skipWhitespace()
+		if s.pos >= len(s.data) {
+			return nil, s.errorf("")
+		}
+		switch s.data[s.pos] {
+		case '{':
+			s.pos++
+			if !s.consume('}') {
+				stack = append(stack, '{')
+				counts = append(counts, 1)
+				if len(stack) == s.depthLimit {
+					return nil, errDepthLimit
+				}
+				if _, _, err := s.skipKey(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+		case '[':
+			s.pos++
+			if !s.consume(']') {
+				stack = append(stack, '[')
+				counts = append(counts, 1)
+				if len(stack) == s.depthLimit {
+					return nil, errDepthLimit
+				}
+				continue
+			}
+		default:
+			if err := s.readScalar(); err != nil {
+				return nil, err
+			}
+		}
+
+		// A value is complete; close any containers it finishes.
+		for {
+			if len(stack) == 0 {
+				return s.data[start:s.pos], nil
+			}
+			top := stack[len(stack)-1]
+			if s.consume(',') {
+				n := &counts[len(counts)-1]
+				*n++
+				if top == '{' {
+					if s.maxKeys > 0 && *n > s.maxKeys {
+						return nil, errKeyLimit
+					}
+					if _, _, err := s.skipKey(); err != nil {
+						return nil, err
+					}
+				} else if s.maxElements > 0 && *n > s.maxElements {
+					return nil, errElementLimit
+				}
+				break
+			}
+			if top == '{' && s.consume('}') || top == '[' && s.consume(']') {
+				stack = stack[:len(stack)-1]
+				counts = counts[:len(counts)-1]
+				continue
+			}
+			if top == '{' {
+				return nil, s.errorf("after object key:value pair")
+			}
+			return nil, s.errorf("after array element")
+		}
+	}
+}
+
+// skipKey consumes an object key and its colon without unquoting it.
+func (s *scanner) skipKey() ([]byte, bool, error) {
+	if s.peek() != '"' {
+		return nil, false, s.errorf("looking for beginning of object key string")
+	}
+	raw, escaped, err := s.readString()
+	if err != nil {
+		return nil, false, err
+	}
+	return raw, escaped, s.expect(':', "after object key")
+}
+
+// unquote decodes the contents of a string literal. Input without escapes or
+// non-ASCII bytes is converted directly; otherwise escapes are resolved and
+// invalid UTF-8 or unpaired surrogates become U+FFFD, as in encoding/json.
+func unquote(raw []byte, escaped bool) string {
+	if !escaped {
+		return string(raw)
+	}
+	buf := make([]byte, 0, len(raw)+utf8.UTFMax)
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		switch {
+		case c == '\\':
+			i++
+			switch raw[i] {
+			case 'b':
+				buf = append(buf, '\b')
+			case 'f':
+				buf = append(buf, '\f')
+			case 'n':
+				buf = append(buf, '\n')
+			case 'r':
+				buf = append(buf, '\r')
+			case 't':
+				buf = append(buf, '\t')
+			case 'u':
+				r := getu4(raw[i+1:])
+				i += 4
+				if utf16.IsSurrogate(r) {
+					r2 := rune(-1)
+					if i+6 < len(raw) && raw[i+1] == '\\' && raw[i+2] == 'u' {
+						r2 = getu4(raw[i+3:])
+					}
+					if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+						r = dec
+						i += 6
+					} else {
+						r = utf8.RuneError
+					}
+				}
+				buf = utf8.AppendRune(buf, r)
+			default: // '"', '\\', '/'
+				buf = append(buf, raw[i])
+			}
+			i++
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			i++
+		default:
+			r, size := utf8.DecodeRune(raw[i:])
+			buf = utf8.AppendRune(buf, r)
+			i += size
+		}
+	}
+	return string(buf)
+}
+
+// getu4 decodes the four hex digits at the start of b.
+func getu4(b []byte) rune {
+	var r rune
+	for _, c := range b[:4] {
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		}
+		r = r<<4 | rune(c)
+	}
+	return r
+}
+
+// Problems reported by findKey.
+const (
+	keyOK = iota
+	keyDuplicate
+	keyEscaped
+	keyUnsorted
+)
+
+// findKey scans an already validated value for the first object key that
+// repeats an earlier key of its object, if dups is set, that is written
+// with escapes, if escapes is set, or that sorts before the previous key of
+// its object, if sorted is set. It returns the path of the key relative to
+// raw, which of the problems it has and the previous key of its object.
+func findKey(raw []byte, dups, escapes, sorted bool) (rel []pathSegment, key, prev string, problem int) {
+	type frame struct {
+		object bool
+		keys   map[string]struct{}
+		prev   *string
+	}
+	var stack []frame
+	s := scanner{data: raw}
+
+	// check reads the next key of the object on top of the stack.
+	check := func() (string, int) {
+		b, escaped, _ := s.skipKey()
+		k := unquote(b, escaped)
+		if escapes && escaped && hasEscape(b) {
+			return k, keyEscaped
+		}
+		top := &stack[len(stack)-1]
+		if dups {
+			if _, dup := top.keys[k]; dup {
+				return k, keyDuplicate
+			}
+			top.keys[k] = struct{}{}
+		}
+		if sorted {
+			if top.prev != nil && k < *top.prev {
+				prev = *top.prev
+				return k, keyUnsorted
+			}
+			top.prev = &k
+		}
+		return k, keyOK
+	}
+
+	for {
+		// Parse the start of a value.
+		switch s.peek() {
+		case '{':
+			s.pos++
+			if !s.consume('}') {
+				f := frame{object: true}
+				if dups {
+					f.keys = make(map[string]struct{})
+				}
+				stack = append(stack, f)
+				k, problem := check()
+				rel = append(rel, pathSegment{key: k, index: -1})
+				if problem != keyOK {
+					return rel, k, prev, problem
+				}
+				continue
+			}
+		case '[':
+			s.pos++
+			if !s.consume(']') {
+				stack = append(stack, frame{})
+				rel = append(rel, pathSegment{index: 0})
+				continue
+			}
+		default:
+			_ = s.readScalar()
+		}
+
+		// A value is complete; close any containers it finishes.
+		for {
+			if len(stack) == 0 {
+				return nil, "", "", keyOK
+			}
+			top := &stack[len(stack)-1]
+			last := &rel[len(rel)-1]
+			if s.consume(',') {
+				if !top.object {
+					last.index++
+					break
+				}
+				k, problem := check()
+				last.key = k
+				if problem != keyOK {
+					return rel, k, prev, problem
+				}
+				break
+			}
+			s.pos++ // '}' or ']'
+			stack = stack[:len(stack)-1]
+			rel = rel[:len(rel)-1]
+		}
+	}
+}
+
+// valueAt returns the raw bytes of the value at rel inside data, where rel
+// is a dotted path of keys and array indices as reported by encoding/json.
+// It returns nil if data holds no such value.
+func valueAt(data []byte, rel string) []byte {
+	s := scanner{data: data}
+	for rel != "" {
+		var seg string
+		seg, rel, _ = strings.Cut(rel, ".")
+		switch s.peek() {
+		case '{':
+			s.pos++
+			for {
+				key, err := s.readKey()
+				if err != nil {
+					return nil
+				}
+				if key == seg {
+					break
+				}
+				if _, err := s.skipValue(); err != nil || !s.consume(',') {
+					return nil
+				}
+			}
+		case '[':
+			s.pos++
+			n, err := strconv.Atoi(seg)
+			if err != nil {
+				return nil
+			}
+			for ; n > 0; n-- {
+				if _, err := s.skipValue(); err != nil || !s.consume(',') {
+					return nil
+				}
+			}
+		default:
+			return nil
+		}
+	}
+	raw, err := s.skipValue()
+	if err != nil {
+		return nil
+	}
+	return raw
+}
@@ -0,0 +1,434 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaDialect is the $schema of documents produced by SchemaFor.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaFor returns a JSON Schema (draft 2020-12) describing the documents
+// strictjson decodes into the type of v, which may be a value or a pointer.
+// Objects are described from the same field tables the decoder uses: their
+// properties carry the decoded JSON names in field order, and they set
+// additionalProperties to false, so a client validating against the schema
+// rejects the unknown keys the decoder would. Named struct types are placed
+// in $defs and referenced, so recursive types are described too.
+//
+// A property is required unless its field has the omitempty option or a
+// default, or is an Optional; these are the keys Marshal always writes.
+// Strict tag options are carried over where JSON Schema can express them:
+// oneof and Enumer values become an enum, default a default, b64 formats a
+// contentEncoding, and nonzero and nonempty a minimum length or size. The
+// min, max and maxlen options are described only by a Decoder with
+// Constraints set, since only such a decoder enforces them. Aliases and
+// strict:"nocase" matching are not described.
+func SchemaFor(v any) ([]byte, error) {
+	return NewDecoder().SchemaFor(v)
+}
+
+// SchemaFor is like the package-level SchemaFor but describes what d
+// accepts: d's TagKeys and ProtoNames name the properties, its
+// AllowedExtraFields are listed as properties of every object, and without
+// DisallowUnknownFields objects admit additional properties.
+func (d *Decoder) SchemaFor(v any) ([]byte, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, newNonPointerError()
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	g := &schemaGen{d: d, cfg: d.fieldConfig(), names: make(map[reflect.Type]string), taken: make(map[string]bool), inline: make(map[reflect.Type]bool)}
+
+	var root *jsonSchema
+	var err error
+	if t.Kind() == reflect.Struct && t.Name() != "" && describesFields(t) {
+		// The root schema is the struct's own, referenced as "#".
+		g.root = t
+		root, err = g.object(t, typePolicy(t) == PolicyLenient)
+	} else {
+		root, err = g.schema(t, nil, false)
+	}
+	if err != nil {
+		return nil, err
+	}
+	root.Schema = schemaDialect
+	if len(g.defs) > 0 {
+		sort.Slice(g.defs, func(i, j int) bool { return g.defs[i].name < g.defs[j].name })
+		root.Defs = g.defs
+	}
+	return MarshalIndent(root, "", "  ")
+}
+
+// jsonSchema is a JSON Schema object. Its fields are in the order they are
+// written.
+type jsonSchema struct {
+	Schema               string          `json:"$schema,omitempty"`
+	Ref                  string          `json:"$ref,omitempty"`
+	Type                 any             `json:"type,omitempty"` // a string, or []string with "null"
+	Format               string          `json:"format,omitempty"`
+	ContentEncoding      string          `json:"contentEncoding,omitempty"`
+	Enum                 []any           `json:"enum,omitempty"`
+	Const                any             `json:"const,omitempty"`
+	Properties           schemaMap       `json:"properties,omitempty"`
+	Required             []string        `json:"required,omitempty"`
+	AdditionalProperties any             `json:"additionalProperties,omitempty"` // false or a *jsonSchema
+	Items                *jsonSchema     `json:"items,omitempty"`
+	MinItems             *int            `json:"minItems,omitempty"`
+	MaxItems             *int            `json:"maxItems,omitempty"`
+	MinLength            *int            `json:"minLength,omitempty"`
+	MaxLength            *int            `json:"maxLength,omitempty"`
+	MinProperties        *int            `json:"minProperties,omitempty"`
+	MaxProperties        *int            `json:"maxProperties,omitempty"`
+	Minimum              json.Number     `json:"minimum,omitempty"`
+	Maximum              json.Number     `json:"maximum,omitempty"`
+	Not                  *jsonSchema     `json:"not,omitempty"`
+	Default              json.RawMessage `json:"default,omitempty"`
+	AnyOf                []*jsonSchema   `json:"anyOf,omitempty"`
+	Defs                 schemaMap       `json:"$defs,omitempty"`
+}
+
+// schemaMap is a JSON object of schemas that keeps its members in order.
+type schemaMap []schemaMember
+
+type schemaMember struct {
+	name   string
+	schema *jsonSchema
+}
+
+func (m schemaMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range m {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(member.name)
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := Marshal(member.schema)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type schemaGen struct {
+	d    *Decoder
+	cfg  fieldConfig
+	root reflect.Type
+	defs schemaMap
+	// names holds the $defs names of named struct types, assigned before
+	// their schema is built so recursive references resolve.
+	names map[reflect.Type]string
+	taken map[string]bool
+	// inline holds the struct types being described inline, under a
+	// lenient policy, to cut cycles.
+	inline map[reflect.Type]bool
+}
+
+// describesFields reports whether the struct type t is described by its
+// fields rather than decoded by a method or a registered DecodeFunc.
+func describesFields(t reflect.Type) bool {
+	return registeredDecoder(t) == nil && t != timeType && !isOptional(t) && typeEnum(t) == nil &&
+		!implementsUnmarshaler(reflect.PointerTo(t)) && !implementsTextUnmarshaler(reflect.PointerTo(t))
+}
+
+// schema describes values of type t. fi is the field holding them, if the
+// field's tag options apply, and lenient is set where unknown keys are
+// tolerated.
+func (g *schemaGen) schema(t reflect.Type, fi *fieldInfo, lenient bool) (*jsonSchema, error) {
+	nullable := false
+	if isOptional(t) {
+		t, nullable = t.Field(0).Type, true
+	}
+	for t.Kind() == reflect.Ptr {
+		t, nullable = t.Elem(), true
+	}
+	// Marshal writes nil slices and maps as null.
+	nullable = nullable || t.Kind() == reflect.Slice || t.Kind() == reflect.Map
+	if fi != nil && fi.policy != PolicyDefault {
+		lenient = fi.policy == PolicyLenient
+	}
+	if policy := typePolicy(t); policy != PolicyDefault {
+		lenient = policy == PolicyLenient
+	}
+
+	s, err := g.base(t, fi, lenient)
+	if err != nil {
+		return nil, err
+	}
+	if fi != nil {
+		if fi.quoted {
+			s = &jsonSchema{Type: "string"}
+		}
+		if fi.oneof != nil {
+			s.Enum = stringEnum(fi.oneof.values)
+		}
+		for _, c := range fi.constraints {
+			if c.optional && !g.d.Constraints {
+				continue
+			}
+			describeConstraint(s, t, c.option)
+			if !c.optional {
+				nullable = false // null fails nonzero and nonempty
+			}
+		}
+		if fi.def != nil {
+			def, err := Marshal(fi.def.value.Interface())
+			if err != nil {
+				return nil, err
+			}
+			s.Default = def
+		}
+	}
+	if nullable {
+		s = nullableSchema(s)
+	}
+	return s, nil
+}
+
+// base describes values of type t, which is not a pointer, apart from the
+// options of the field holding them.
+func (g *schemaGen) base(t reflect.Type, fi *fieldInfo, lenient bool) (*jsonSchema, error) {
+	switch {
+	case registeredDecoder(t) != nil:
+		return &jsonSchema{}, nil
+	case t == timeType:
+		layout := g.d.TimeFormat
+		if fi != nil && fi.format != "" {
+			layout = fi.format
+		}
+		s := &jsonSchema{Type: "string"}
+		switch layout {
+		case "", time.RFC3339, time.RFC3339Nano:
+			s.Format = "date-time"
+		case time.DateOnly:
+			s.Format = "date"
+		}
+		return s, nil
+	case implementsUnmarshaler(reflect.PointerTo(t)):
+		return &jsonSchema{}, nil
+	case isBytes(t):
+		s := &jsonSchema{Type: "string", ContentEncoding: "base64"}
+		if fi != nil && strings.HasSuffix(fi.format, "url") {
+			s.ContentEncoding = "base64url"
+		}
+		return s, nil
+	case t == numberType:
+		return &jsonSchema{Type: "number"}, nil
+	}
+	if enum := typeEnum(t); enum != nil {
+		return &jsonSchema{Type: "string", Enum: stringEnum(enum.values)}, nil
+	}
+	if implementsTextUnmarshaler(reflect.PointerTo(t)) {
+		return &jsonSchema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &jsonSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &jsonSchema{Type: "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &jsonSchema{Type: "integer", Minimum: "0"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &jsonSchema{Type: "number"}, nil
+	case reflect.String:
+		return &jsonSchema{Type: "string"}, nil
+	case reflect.Interface:
+		return &jsonSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.schema(t.Elem(), nil, lenient)
+		if err != nil {
+			return nil, err
+		}
+		s := &jsonSchema{Type: "array", Items: items}
+		if t.Kind() == reflect.Array {
+			n := t.Len()
+			s.MinItems, s.MaxItems = &n, &n
+		}
+		return s, nil
+	case reflect.Map:
+		values, err := g.schema(t.Elem(), nil, lenient)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		return g.structRef(t, lenient)
+	}
+	return nil, &UnmarshalError{message: fmt.Sprintf("strictjson: SchemaFor: unsupported type %v", t)}
+}
+
+// structRef describes the struct type t: by reference to its entry in
+// $defs, or inline for anonymous structs and under a lenient policy, which
+// the shared entry would not reflect.
+func (g *schemaGen) structRef(t reflect.Type, lenient bool) (*jsonSchema, error) {
+	if t.Name() == "" || lenient {
+		if g.inline[t] {
+			return &jsonSchema{}, nil // a cycle; the outer schema describes it
+		}
+		g.inline[t] = true
+		defer delete(g.inline, t)
+		return g.object(t, lenient)
+	}
+	if t == g.root {
+		return &jsonSchema{Ref: "#"}, nil
+	}
+	if name, ok := g.names[t]; ok {
+		return &jsonSchema{Ref: "#/$defs/" + name}, nil
+	}
+
+	name := g.defName(t)
+	g.names[t] = name
+	s, err := g.object(t, false)
+	if err != nil {
+		return nil, err
+	}
+	g.defs = append(g.defs, schemaMember{name, s})
+	return &jsonSchema{Ref: "#/$defs/" + name}, nil
+}
+
+// object describes the fields of the struct type t.
+func (g *schemaGen) object(t reflect.Type, lenient bool) (*jsonSchema, error) {
+	sf, err := getStructFields(t, g.cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(sf.conflicts) > 0 && !g.d.IgnoreConflicts {
+		return nil, sf.conflicts[0]
+	}
+	fields := append([]*fieldInfo(nil), sf.list...)
+	sort.Slice(fields, func(i, j int) bool { return compareIndex(fields[i].fieldIndex, fields[j].fieldIndex) < 0 })
+
+	s := &jsonSchema{Type: "object", Properties: schemaMap{}}
+	for _, fi := range fields {
+		prop, err := g.schema(fi.typ, fi, lenient)
+		if err != nil {
+			return nil, err
+		}
+		s.Properties = append(s.Properties, schemaMember{fi.jsonName, prop})
+		if !hasTagOption(fi.tagOpts, "omitempty") && fi.def == nil && !isOptional(fi.typ) {
+			s.Required = append(s.Required, fi.jsonName)
+		}
+	}
+
+	switch {
+	case sf.remain != nil:
+		values, err := g.schema(t.FieldByIndex(sf.remain).Type.Elem(), nil, lenient)
+		if err != nil {
+			return nil, err
+		}
+		s.AdditionalProperties = values
+	case !lenient && g.d.DisallowUnknownFields:
+		for _, name := range g.d.AllowedExtraFields {
+			if _, ok := sf.fields[name]; !ok {
+				s.Properties = append(s.Properties, schemaMember{name, &jsonSchema{}})
+			}
+		}
+		s.AdditionalProperties = false
+	}
+	return s, nil
+}
+
+// defName returns a $defs name for the named type t that no other type
+// has: its name, qualified by its package if that is taken.
+func (g *schemaGen) defName(t reflect.Type) string {
+	clean := func(name string) string {
+		return strings.Map(func(r rune) rune {
+			if r == '_' || r == '.' || r == '-' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+				return r
+			}
+			return '_'
+		}, name)
+	}
+	candidates := []string{clean(t.Name())}
+	if pkg := t.PkgPath(); pkg != "" {
+		candidates = append(candidates, clean(path.Base(pkg)+"."+t.Name()))
+	}
+	for i := 2; ; i++ {
+		for _, name := range candidates {
+			if !g.taken[name] {
+				g.taken[name] = true
+				return name
+			}
+		}
+		candidates = []string{candidates[len(candidates)-1] + strconv.Itoa(i)}
+	}
+}
+
+// nullableSchema returns s extended to admit null.
+func nullableSchema(s *jsonSchema) *jsonSchema {
+	switch typ := s.Type.(type) {
+	case string:
+		s.Type = []string{typ, "null"}
+		if s.Enum != nil {
+			s.Enum = append(s.Enum, nil)
+		}
+		return s
+	case nil:
+		if s.Ref == "" {
+			return s // any value, null included
+		}
+	}
+	return &jsonSchema{AnyOf: []*jsonSchema{s, {Type: "null"}}}
+}
+
+func stringEnum(values []string) []any {
+	enum := make([]any, len(values))
+	for i, v := range values {
+		enum[i] = v
+	}
+	return enum
+}
+
+// describeConstraint adds the constraint of the strict tag option opt on
+// values of type t to s.
+func describeConstraint(s *jsonSchema, t reflect.Type, opt string) {
+	one := 1
+	kind, arg, _ := strings.Cut(opt, "=")
+	switch kind {
+	case "nonzero", "nonempty":
+		switch t.Kind() {
+		case reflect.String:
+			s.MinLength = &one
+		case reflect.Slice:
+			s.MinItems = &one
+		case reflect.Map:
+			s.MinProperties = &one
+		case reflect.Bool:
+			s.Const = true
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64:
+			s.Not = &jsonSchema{Const: 0}
+		}
+	case "min":
+		s.Minimum = json.Number(arg)
+	case "max":
+		s.Maximum = json.Number(arg)
+	case "maxlen":
+		n, _ := strconv.Atoi(arg)
+		switch t.Kind() {
+		case reflect.String:
+			s.MaxLength = &n
+		case reflect.Slice:
+			s.MaxItems = &n
+		case reflect.Map:
+			s.MaxProperties = &n
+		}
+	}
+}
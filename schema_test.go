@@ -0,0 +1,163 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type schemaAddress struct {
+	City string `json:"city" strict:"nonempty"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type schemaUser struct {
+	Name    string           `json:"name"`
+	Age     uint8            `json:"age" strict:"max=150"`
+	Role    string           `json:"role" strict:"oneof=admin|user,default=user"`
+	Born    time.Time        `json:"born"`
+	Avatar  []byte           `json:"avatar,omitempty" strict:"b64url"`
+	Home    *schemaAddress   `json:"home"`
+	Friends []*schemaUser    `json:"friends"`
+	Nick    Optional[string] `json:"nick"`
+	Count   int64            `json:"count,string"`
+	Raw     json.RawMessage  `json:"raw"`
+}
+
+func compactJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return buf.String()
+}
+
+func TestSchemaFor(t *testing.T) {
+	got, err := SchemaFor((*schemaUser)(nil))
+	if err != nil {
+		t.Fatalf("SchemaFor() unexpected error: %v", err)
+	}
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+		`"name":{"type":"string"},` +
+		`"age":{"type":"integer","minimum":0},` +
+		`"role":{"type":"string","enum":["admin","user"],"default":"user"},` +
+		`"born":{"type":"string","format":"date-time"},` +
+		`"avatar":{"type":["string","null"],"contentEncoding":"base64url"},` +
+		`"home":{"anyOf":[{"$ref":"#/$defs/schemaAddress"},{"type":"null"}]},` +
+		`"friends":{"type":["array","null"],"items":{"anyOf":[{"$ref":"#"},{"type":"null"}]}},` +
+		`"nick":{"type":["string","null"]},` +
+		`"count":{"type":"string"},` +
+		`"raw":{}},` +
+		`"required":["name","age","born","home","friends","count","raw"],` +
+		`"additionalProperties":false,` +
+		`"$defs":{"schemaAddress":{"type":"object","properties":{"city":{"type":"string","minLength":1},"zip":{"type":"string"}},"required":["city"],"additionalProperties":false}}}`
+	if compactJSON(t, got) != want {
+		t.Errorf("SchemaFor() =\n%s\nwant\n%s", compactJSON(t, got), want)
+	}
+
+	// Range constraints are described when the decoder enforces them.
+	got, err = NewDecoder(WithConstraints(true)).SchemaFor(schemaUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(got, []byte(`"minimum": 0,
+      "maximum": 150`)) {
+		t.Errorf("SchemaFor() with constraints lacks the maximum:\n%s", got)
+	}
+
+	got, err = SchemaFor([]schemaAddress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":["array","null"],"items":{"$ref":"#/$defs/schemaAddress"},"$defs":{"schemaAddress":` +
+		`{"type":"object","properties":{"city":{"type":"string","minLength":1},"zip":{"type":"string"}},"required":["city"],"additionalProperties":false}}}`; compactJSON(t, got) != want {
+		t.Errorf("SchemaFor() =\n%s\nwant\n%s", compactJSON(t, got), want)
+	}
+}
+
+type schemaParent struct {
+	Children []schemaChild `json:"children"`
+}
+
+type schemaChild struct {
+	Parent *schemaParent `json:"parent"`
+}
+
+type schemaEnvelope struct {
+	Kind    string                     `json:"kind"`
+	Loose   schemaAddress              `json:"loose" strict:"lenient"`
+	Extra   map[string]json.RawMessage `strict:",remain"`
+	Counter struct {
+		N int `json:"n" strict:"nonzero"`
+	} `json:"counter"`
+}
+
+func TestSchemaForObjects(t *testing.T) {
+	got, err := SchemaFor(schemaChild{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"parent":{"anyOf":[{"$ref":"#/$defs/schemaParent"},{"type":"null"}]}},"required":["parent"],"additionalProperties":false,` +
+		`"$defs":{"schemaParent":{"type":"object","properties":{"children":{"type":["array","null"],"items":{"$ref":"#"}}},"required":["children"],"additionalProperties":false}}}`; compactJSON(t, got) != want {
+		t.Errorf("SchemaFor() =\n%s\nwant\n%s", compactJSON(t, got), want)
+	}
+
+	got, err = SchemaFor(schemaEnvelope{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+		`"kind":{"type":"string"},` +
+		`"loose":{"type":"object","properties":{"city":{"type":"string","minLength":1},"zip":{"type":"string"}},"required":["city"]},` +
+		`"counter":{"type":"object","properties":{"n":{"type":"integer","not":{"const":0}}},"required":["n"],"additionalProperties":false}},` +
+		`"required":["kind","loose","counter"],"additionalProperties":{}}`
+	if compactJSON(t, got) != want {
+		t.Errorf("SchemaFor() =\n%s\nwant\n%s", compactJSON(t, got), want)
+	}
+
+	d := NewDecoder(WithAllowedExtraFields("$schema"))
+	got, err = d.SchemaFor(schemaAddress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"city":{"type":"string","minLength":1},"zip":{"type":"string"},"$schema":{}},"required":["city"],"additionalProperties":false}`; compactJSON(t, got) != want {
+		t.Errorf("SchemaFor() =\n%s\nwant\n%s", compactJSON(t, got), want)
+	}
+
+	got, err = NewDecoder(WithDisallowUnknownFields(false)).SchemaFor(schemaAddress{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(got, []byte("additionalProperties")) {
+		t.Errorf("SchemaFor() of lenient decoder =\n%s", got)
+	}
+}
+
+func TestSchemaForErrors(t *testing.T) {
+	type A struct{ ID int }
+	type B struct{ ID int }
+	var fce *FieldConflictError
+	if _, err := SchemaFor(struct {
+		A
+		B
+	}{}); !errors.As(err, &fce) {
+		t.Errorf("Expected *FieldConflictError, got %v", err)
+	}
+	var ite *InvalidTagError
+	if _, err := SchemaFor(struct {
+		N int `json:"n" strict:"nonempty"`
+	}{}); !errors.As(err, &ite) {
+		t.Errorf("Expected *InvalidTagError, got %v", err)
+	}
+	if _, err := SchemaFor(struct {
+		C chan int `json:"c"`
+	}{}); err == nil || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected error for chan field, got %v", err)
+	}
+	if _, err := SchemaFor(nil); err == nil {
+		t.Error("Expected error for nil")
+	}
+}
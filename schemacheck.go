@@ -0,0 +1,931 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// WithSchema makes the decoder also validate documents against the JSON
+// Schema schema, such as one published by SchemaFor or taken from an API
+// spec. The schema is checked in the same pass over the document as the
+// strict rules. Strict-rule violations then no longer stop the decode:
+// Unmarshal, Validate and the other entry points fail with every strict
+// violation and *SchemaError found, joined with errors.Join if there are
+// several, and Check adds the schema errors to its violations, so both
+// kinds of failure arrive as one path-aware list. A failure that leaves the
+// document unreadable, such as a syntax error or a value of the wrong type,
+// still stops the decode, and the schema is then not checked.
+//
+// The validation keywords of draft 2020-12 are supported: type, enum, const,
+// the numeric and length bounds, pattern, properties, patternProperties,
+// additionalProperties, required, items, prefixItems, uniqueItems, allOf,
+// anyOf, oneOf, not and $ref to a JSON pointer within schema, such as
+// "#/$defs/Address". format is checked for date-time, date, time, email,
+// uri, uuid, hostname, ipv4 and ipv6. Other keywords are ignored. A schema
+// that cannot be parsed or refers outside itself makes every call fail with
+// the reason.
+func WithSchema(schema []byte) DecoderOption {
+	return func(d *Decoder) {
+		d.schema = compileSchema(schema)
+	}
+}
+
+// WithSchema returns a copy of d that also validates documents against the
+// JSON Schema schema, as the WithSchema option does. d is not changed.
+func (d *Decoder) WithSchema(schema []byte) *Decoder {
+	nd := *d
+	WithSchema(schema)(&nd)
+	return &nd
+}
+
+// SchemaError reports a value that breaks a keyword of the schema set with
+// WithSchema.
+type SchemaError struct {
+	path    string
+	keyword string
+	reason  string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf(`strictjson: value at "%s" %s (schema %s)`, e.path, e.reason, e.keyword)
+}
+
+func (e *SchemaError) Unwrap() error {
+	return ErrDecode
+}
+
+// Path returns the location of the value. For a missing required key it is
+// the location the key would have.
+func (e *SchemaError) Path() string {
+	return e.path
+}
+
+// Keyword returns the schema keyword the value breaks, such as "required"
+// or "maximum".
+func (e *SchemaError) Keyword() string {
+	return e.keyword
+}
+
+// Reason describes how the value breaks the keyword.
+func (e *SchemaError) Reason() string {
+	return e.reason
+}
+
+func newSchemaError(path []pathSegment, keyword, reason string) error {
+	return &SchemaError{path: formatPath(path), keyword: keyword, reason: reason}
+}
+
+// document runs decode, which reads the value of the whole document, and
+// checks that nothing but whitespace follows it. With a schema, the value is
+// also checked against the schema, as the generic value built while decode
+// reads it; strict violations then no longer stop decode but are returned
+// along with the schema errors, or recorded with them in Check mode.
+func (s *decodeState) document(decode func() error) error {
+	if s.d.schema == nil {
+		if err := decode(); err != nil {
+			return err
+		}
+		return s.scan.end()
+	}
+	if s.violations == nil {
+		s.violations, s.collect = new([]Violation), true
+	}
+	s.tree = &docTree{}
+	err := decode()
+	if err == nil {
+		err = s.scan.end()
+	}
+	var errs []error
+	if err == nil {
+		s.d.schema.root.validate(s.tree.root(s.scan.data), nil, &errs)
+	}
+	if !s.collect {
+		for _, err := range errs {
+			s.violation(err)
+		}
+		return err
+	}
+
+	all := make([]error, 0, len(*s.violations)+len(errs)+1)
+	for _, v := range *s.violations {
+		all = append(all, v.Err)
+	}
+	for _, err := range errs {
+		all = append(all, s.d.formatError(err))
+	}
+	if err != nil {
+		all = append(all, s.d.formatError(err))
+	}
+	switch len(all) {
+	case 0:
+		return nil
+	case 1:
+		return all[0]
+	}
+	return errors.Join(all...)
+}
+
+// docTree builds the generic value of the document being decoded, for the
+// schema check, without parsing the document again: objects and arrays
+// decoded member by member are assembled as their frames close, and other
+// values are converted from the bytes the decoder read them from.
+type docTree struct {
+	// nodes holds the value of each open frame, innermost last.
+	nodes []treeNode
+	// last is the value of the frame closed last, which spans
+	// data[lastStart:lastEnd].
+	last               any
+	lastStart, lastEnd int
+}
+
+type treeNode struct {
+	obj map[string]any
+	arr []any
+	// start is the offset of the node's opening bracket, and member that
+	// of the member being decoded.
+	start, member int
+}
+
+// open starts the node of a frame of kind k opened at offset start.
+func (t *docTree) open(k frameKind, start int) {
+	n := treeNode{start: start}
+	if k == frameArray {
+		n.arr = []any{}
+	} else {
+		n.obj = map[string]any{}
+	}
+	t.nodes = append(t.nodes, n)
+}
+
+// close completes the innermost node, whose frame ends at offset end.
+func (t *docTree) close(end int) {
+	n := t.nodes[len(t.nodes)-1]
+	t.nodes = t.nodes[:len(t.nodes)-1]
+	t.last, t.lastStart, t.lastEnd = n.value(), n.start, end
+}
+
+func (n *treeNode) value() any {
+	if n.obj != nil {
+		return n.obj
+	}
+	return n.arr
+}
+
+// beginMember records that the next member of the innermost node, with its
+// key if it is an object, starts at offset pos.
+func (t *docTree) beginMember(pos int) {
+	t.nodes[len(t.nodes)-1].member = pos
+}
+
+// endMember adds the member of the innermost node that ends at offset end
+// of data.
+func (t *docTree) endMember(data []byte, end int) {
+	n := &t.nodes[len(t.nodes)-1]
+	sc := scanner{data: data[:end], pos: n.member}
+	var key string
+	if n.obj != nil {
+		raw, escaped, _ := sc.skipKey()
+		key = unquote(raw, escaped)
+	}
+	v := t.valueAt(data, sc.pos, end)
+	if n.obj != nil {
+		n.obj[key] = v
+	} else {
+		n.arr = append(n.arr, v)
+	}
+}
+
+// root returns the value of the whole document.
+func (t *docTree) root(data []byte) any {
+	start := 0
+	if bytes.HasPrefix(data, utf8BOM) {
+		start = len(utf8BOM)
+	}
+	return t.valueAt(data, start, len(data))
+}
+
+// valueAt returns the value data[start:end] holds, give or take
+// whitespace: the node that spans it if one just closed, or else the value
+// converted from its bytes.
+func (t *docTree) valueAt(data []byte, start, end int) any {
+	for start < end && isSpace(data[start]) {
+		start++
+	}
+	for end > start && isSpace(data[end-1]) {
+		end--
+	}
+	v := t.last
+	t.last = nil
+	if v != nil && t.lastStart == start && t.lastEnd == end {
+		return v
+	}
+	return genericValue(data[start:end])
+}
+
+// genericValue converts raw, a JSON value the scanner has already checked,
+// to the form schemas are checked against: map[string]any, []any, string,
+// json.Number, bool or nil. Nesting is tracked with an explicit stack, as in
+// skipValue.
+func genericValue(raw []byte) any {
+	sc := scanner{data: raw}
+	var stack []treeNode
+	var keys []string
+	for {
+		var v any
+		switch sc.peek() {
+		case '{':
+			sc.pos++
+			if !sc.consume('}') {
+				stack = append(stack, treeNode{obj: map[string]any{}})
+				keys = append(keys, genericKey(&sc))
+				continue
+			}
+			v = map[string]any{}
+		case '[':
+			sc.pos++
+			if !sc.consume(']') {
+				stack = append(stack, treeNode{arr: []any{}})
+				keys = append(keys, "")
+				continue
+			}
+			v = []any{}
+		default:
+			v = genericScalar(&sc)
+		}
+
+		// A value is complete; add it to its container, closing any
+		// containers it finishes.
+		for {
+			if len(stack) == 0 {
+				return v
+			}
+			n := &stack[len(stack)-1]
+			if n.obj != nil {
+				n.obj[keys[len(keys)-1]] = v
+			} else {
+				n.arr = append(n.arr, v)
+			}
+			if sc.consume(',') {
+				if n.obj != nil {
+					keys[len(keys)-1] = genericKey(&sc)
+				}
+				break
+			}
+			sc.peek()
+			sc.pos++ // '}' or ']'
+			v = n.value()
+			stack, keys = stack[:len(stack)-1], keys[:len(keys)-1]
+		}
+	}
+}
+
+func genericKey(sc *scanner) string {
+	raw, escaped, _ := sc.skipKey()
+	return unquote(raw, escaped)
+}
+
+func genericScalar(sc *scanner) any {
+	start := sc.pos
+	switch sc.data[start] {
+	case '"':
+		raw, escaped, _ := sc.readString()
+		return unquote(raw, escaped)
+	case 't':
+		sc.pos += len("true")
+		return true
+	case 'f':
+		sc.pos += len("false")
+		return false
+	case 'n':
+		sc.pos += len("null")
+		return nil
+	}
+	sc.readNumber()
+	return json.Number(sc.data[start:sc.pos])
+}
+
+// compiledSchema is a schema parsed by WithSchema, or the reason it could
+// not be.
+type compiledSchema struct {
+	root *schemaNode
+	err  error
+}
+
+// schemaNode is one compiled schema. Unset bounds are nil or negative.
+type schemaNode struct {
+	always *bool // set for the boolean schemas true and false
+	ref    *schemaNode
+
+	types    []string
+	enum     []any
+	konst    any
+	hasConst bool
+
+	properties   map[string]*schemaNode
+	patternProps []patternSchema
+	additional   *schemaNode
+	required     []string
+	minProps     int
+	maxProps     int
+
+	items       *schemaNode
+	prefixItems []*schemaNode
+	minItems    int
+	maxItems    int
+	uniqueItems bool
+
+	minLength int
+	maxLength int
+	pattern   *regexp.Regexp
+	format    string
+
+	minimum, maximum                   *big.Rat
+	exclusiveMinimum, exclusiveMaximum *big.Rat
+	multipleOf                         *big.Rat
+
+	allOf, anyOf, oneOf []*schemaNode
+	not                 *schemaNode
+}
+
+type patternSchema struct {
+	re     *regexp.Regexp
+	schema *schemaNode
+}
+
+func compileSchema(data []byte) *compiledSchema {
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return &compiledSchema{err: schemaCompileError("", err.Error())}
+	}
+	c := &schemaCompiler{doc: doc, nodes: make(map[string]*schemaNode)}
+	root, err := c.compile(doc, "")
+	return &compiledSchema{root: root, err: err}
+}
+
+func schemaCompileError(ptr, reason string) error {
+	if ptr == "" {
+		return &UnmarshalError{message: "strictjson: invalid schema: " + reason}
+	}
+	return &UnmarshalError{message: fmt.Sprintf(`strictjson: invalid schema at "#%s": %s`, ptr, reason)}
+}
+
+// schemaCompiler compiles the schemas of a document, keyed by JSON pointer
+// so that references to a schema share its node.
+type schemaCompiler struct {
+	doc   any
+	nodes map[string]*schemaNode
+}
+
+func (c *schemaCompiler) compile(v any, ptr string) (*schemaNode, error) {
+	if n, ok := c.nodes[ptr]; ok {
+		return n, nil
+	}
+	n := &schemaNode{minProps: -1, maxProps: -1, minItems: -1, maxItems: -1, minLength: -1, maxLength: -1}
+	c.nodes[ptr] = n
+	switch v := v.(type) {
+	case bool:
+		n.always = &v
+		return n, nil
+	case map[string]any:
+		return n, c.keywords(n, v, ptr)
+	}
+	return nil, schemaCompileError(ptr, "not an object or boolean")
+}
+
+func (c *schemaCompiler) keywords(n *schemaNode, m map[string]any, ptr string) error {
+	sub := func(key string) (*schemaNode, error) {
+		return c.compile(m[key], ptr+"/"+escapePointer(key))
+	}
+	subs := func(key string) ([]*schemaNode, error) {
+		list, ok := m[key].([]any)
+		if !ok {
+			return nil, schemaCompileError(ptr+"/"+key, "not an array")
+		}
+		nodes := make([]*schemaNode, len(list))
+		for i, v := range list {
+			var err error
+			if nodes[i], err = c.compile(v, ptr+"/"+key+"/"+strconv.Itoa(i)); err != nil {
+				return nil, err
+			}
+		}
+		return nodes, nil
+	}
+	count := func(key string) (int, error) {
+		num, ok := m[key].(json.Number)
+		if !ok {
+			return 0, schemaCompileError(ptr+"/"+key, "not a number")
+		}
+		n, err := strconv.Atoi(strings.TrimSuffix(num.String(), ".0"))
+		if err != nil || n < 0 {
+			return 0, schemaCompileError(ptr+"/"+key, "not a non-negative integer")
+		}
+		return n, nil
+	}
+	number := func(key string) (*big.Rat, error) {
+		num, ok := m[key].(json.Number)
+		if !ok {
+			return nil, schemaCompileError(ptr+"/"+key, "not a number")
+		}
+		r, _ := new(big.Rat).SetString(num.String())
+		return r, nil
+	}
+
+	var err error
+	for _, key := range sortedKeys(m) {
+		v := m[key]
+		switch key {
+		case "$ref":
+			ref, _ := v.(string)
+			n.ref, err = c.resolve(ref, ptr)
+		case "type":
+			switch t := v.(type) {
+			case string:
+				n.types = []string{t}
+			case []any:
+				for _, e := range t {
+					name, _ := e.(string)
+					n.types = append(n.types, name)
+				}
+			}
+			for _, t := range n.types {
+				switch t {
+				case "null", "boolean", "object", "array", "number", "integer", "string":
+				default:
+					err = schemaCompileError(ptr+"/type", fmt.Sprintf("unknown type %q", t))
+				}
+			}
+		case "enum":
+			list, ok := v.([]any)
+			if !ok {
+				err = schemaCompileError(ptr+"/enum", "not an array")
+			}
+			n.enum = list
+		case "const":
+			n.konst, n.hasConst = v, true
+		case "properties":
+			props, ok := v.(map[string]any)
+			if !ok {
+				err = schemaCompileError(ptr+"/properties", "not an object")
+				break
+			}
+			n.properties = make(map[string]*schemaNode, len(props))
+			for name, p := range props {
+				if n.properties[name], err = c.compile(p, ptr+"/properties/"+escapePointer(name)); err != nil {
+					break
+				}
+			}
+		case "patternProperties":
+			props, ok := v.(map[string]any)
+			if !ok {
+				err = schemaCompileError(ptr+"/patternProperties", "not an object")
+				break
+			}
+			patterns := make([]string, 0, len(props))
+			for pattern := range props {
+				patterns = append(patterns, pattern)
+			}
+			sort.Strings(patterns)
+			for _, pattern := range patterns {
+				re, rerr := regexp.Compile(pattern)
+				if rerr != nil {
+					err = schemaCompileError(ptr+"/patternProperties", rerr.Error())
+					break
+				}
+				node, cerr := c.compile(props[pattern], ptr+"/patternProperties/"+escapePointer(pattern))
+				if cerr != nil {
+					err = cerr
+					break
+				}
+				n.patternProps = append(n.patternProps, patternSchema{re, node})
+			}
+		case "additionalProperties":
+			n.additional, err = sub(key)
+		case "required":
+			list, ok := v.([]any)
+			if !ok {
+				err = schemaCompileError(ptr+"/required", "not an array")
+			}
+			for _, e := range list {
+				name, ok := e.(string)
+				if !ok {
+					err = schemaCompileError(ptr+"/required", "not an array of strings")
+				}
+				n.required = append(n.required, name)
+			}
+		case "minProperties":
+			n.minProps, err = count(key)
+		case "maxProperties":
+			n.maxProps, err = count(key)
+		case "items":
+			n.items, err = sub(key)
+		case "prefixItems":
+			n.prefixItems, err = subs(key)
+		case "minItems":
+			n.minItems, err = count(key)
+		case "maxItems":
+			n.maxItems, err = count(key)
+		case "uniqueItems":
+			n.uniqueItems = v == true
+		case "minLength":
+			n.minLength, err = count(key)
+		case "maxLength":
+			n.maxLength, err = count(key)
+		case "pattern":
+			pattern, _ := v.(string)
+			if n.pattern, err = regexp.Compile(pattern); err != nil {
+				err = schemaCompileError(ptr+"/pattern", err.Error())
+			}
+		case "format":
+			n.format, _ = v.(string)
+		case "minimum":
+			n.minimum, err = number(key)
+		case "maximum":
+			n.maximum, err = number(key)
+		case "exclusiveMinimum":
+			n.exclusiveMinimum, err = number(key)
+		case "exclusiveMaximum":
+			n.exclusiveMaximum, err = number(key)
+		case "multipleOf":
+			if n.multipleOf, err = number(key); err == nil && n.multipleOf.Sign() <= 0 {
+				err = schemaCompileError(ptr+"/multipleOf", "not a positive number")
+			}
+		case "allOf":
+			n.allOf, err = subs(key)
+		case "anyOf":
+			n.anyOf, err = subs(key)
+		case "oneOf":
+			n.oneOf, err = subs(key)
+		case "not":
+			n.not, err = sub(key)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolve compiles the schema that the $ref ref, found at ptr, refers to.
+// Only JSON pointers within the document are supported.
+func (c *schemaCompiler) resolve(ref, ptr string) (*schemaNode, error) {
+	target, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, schemaCompileError(ptr+"/$ref", fmt.Sprintf("unsupported reference %q", ref))
+	}
+	if unescaped, err := url.PathUnescape(target); err == nil {
+		target = unescaped
+	}
+	v := c.doc
+	for _, tok := range strings.Split(target, "/")[1:] {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		switch node := v.(type) {
+		case map[string]any:
+			v, ok = node[tok]
+		case []any:
+			i, err := strconv.Atoi(tok)
+			ok = err == nil && i >= 0 && i < len(node)
+			if ok {
+				v = node[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, schemaCompileError(ptr+"/$ref", fmt.Sprintf("reference %q not found", ref))
+		}
+	}
+	return c.compile(v, target)
+}
+
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// validate appends to errs an error for each keyword of n that v, found at
+// path, breaks.
+func (n *schemaNode) validate(v any, path []pathSegment, errs *[]error) {
+	fail := func(keyword, format string, args ...any) {
+		*errs = append(*errs, newSchemaError(path, keyword, fmt.Sprintf(format, args...)))
+	}
+	if n.always != nil {
+		if !*n.always {
+			fail("false", "is not allowed")
+		}
+		return
+	}
+	if n.ref != nil {
+		n.ref.validate(v, path, errs)
+	}
+	if len(n.types) > 0 && !hasSchemaType(n.types, v) {
+		fail("type", "is %s, not %s", describeJSON(v), strings.Join(n.types, " or "))
+		return // the other keywords would only repeat the mismatch
+	}
+	if n.enum != nil && !containsJSON(n.enum, v) {
+		fail("enum", "is %s, not one of the allowed values", showJSON(v))
+	}
+	if n.hasConst && !equalJSON(n.konst, v) {
+		fail("const", "is %s, not %s", showJSON(v), showJSON(n.konst))
+	}
+
+	switch v := v.(type) {
+	case map[string]any:
+		n.validateObject(v, path, errs)
+	case []any:
+		n.validateArray(v, path, errs)
+	case string:
+		length := utf8.RuneCountInString(v)
+		if n.minLength >= 0 && length < n.minLength {
+			fail("minLength", "has length %d, below the minimum of %d", length, n.minLength)
+		}
+		if n.maxLength >= 0 && length > n.maxLength {
+			fail("maxLength", "has length %d, above the maximum of %d", length, n.maxLength)
+		}
+		if n.pattern != nil && !n.pattern.MatchString(v) {
+			fail("pattern", "does not match the pattern %q", n.pattern.String())
+		}
+		if n.format != "" && !validFormat(n.format, v) {
+			fail("format", "is not a valid %s", n.format)
+		}
+	case json.Number:
+		r, _ := new(big.Rat).SetString(v.String())
+		switch {
+		case n.minimum != nil && r.Cmp(n.minimum) < 0:
+			fail("minimum", "is %s, below the minimum of %s", v, n.minimum.RatString())
+		case n.exclusiveMinimum != nil && r.Cmp(n.exclusiveMinimum) <= 0:
+			fail("exclusiveMinimum", "is %s, not above %s", v, n.exclusiveMinimum.RatString())
+		}
+		switch {
+		case n.maximum != nil && r.Cmp(n.maximum) > 0:
+			fail("maximum", "is %s, above the maximum of %s", v, n.maximum.RatString())
+		case n.exclusiveMaximum != nil && r.Cmp(n.exclusiveMaximum) >= 0:
+			fail("exclusiveMaximum", "is %s, not below %s", v, n.exclusiveMaximum.RatString())
+		}
+		if n.multipleOf != nil && !new(big.Rat).Quo(r, n.multipleOf).IsInt() {
+			fail("multipleOf", "is %s, not a multiple of %s", v, n.multipleOf.RatString())
+		}
+	}
+
+	for _, sub := range n.allOf {
+		sub.validate(v, path, errs)
+	}
+	if n.anyOf != nil && countMatches(n.anyOf, v, path, 1) == 0 {
+		fail("anyOf", "matches none of the anyOf schemas")
+	}
+	if n.oneOf != nil {
+		if matched := countMatches(n.oneOf, v, path, 2); matched != 1 {
+			if matched == 0 {
+				fail("oneOf", "matches none of the oneOf schemas")
+			} else {
+				fail("oneOf", "matches more than one of the oneOf schemas")
+			}
+		}
+	}
+	if n.not != nil && countMatches([]*schemaNode{n.not}, v, path, 1) == 1 {
+		fail("not", "matches the schema it must not")
+	}
+}
+
+func (n *schemaNode) validateObject(m map[string]any, path []pathSegment, errs *[]error) {
+	for _, name := range n.required {
+		if _, ok := m[name]; !ok {
+			*errs = append(*errs, newSchemaError(append(path, pathSegment{key: name, index: -1}), "required", "is missing"))
+		}
+	}
+	if n.minProps >= 0 && len(m) < n.minProps {
+		*errs = append(*errs, newSchemaError(path, "minProperties", fmt.Sprintf("has %d keys, below the minimum of %d", len(m), n.minProps)))
+	}
+	if n.maxProps >= 0 && len(m) > n.maxProps {
+		*errs = append(*errs, newSchemaError(path, "maxProperties", fmt.Sprintf("has %d keys, above the maximum of %d", len(m), n.maxProps)))
+	}
+
+	for _, key := range sortedKeys(m) {
+		keyPath := append(path[:len(path):len(path)], pathSegment{key: key, index: -1})
+		matched := false
+		if sub, ok := n.properties[key]; ok {
+			sub.validate(m[key], keyPath, errs)
+			matched = true
+		}
+		for _, p := range n.patternProps {
+			if p.re.MatchString(key) {
+				p.schema.validate(m[key], keyPath, errs)
+				matched = true
+			}
+		}
+		if !matched && n.additional != nil {
+			if n.additional.always != nil && !*n.additional.always {
+				*errs = append(*errs, newSchemaError(keyPath, "additionalProperties", "is not a property the schema allows"))
+				continue
+			}
+			n.additional.validate(m[key], keyPath, errs)
+		}
+	}
+}
+
+func (n *schemaNode) validateArray(list []any, path []pathSegment, errs *[]error) {
+	if n.minItems >= 0 && len(list) < n.minItems {
+		*errs = append(*errs, newSchemaError(path, "minItems", fmt.Sprintf("has length %d, below the minimum of %d", len(list), n.minItems)))
+	}
+	if n.maxItems >= 0 && len(list) > n.maxItems {
+		*errs = append(*errs, newSchemaError(path, "maxItems", fmt.Sprintf("has length %d, above the maximum of %d", len(list), n.maxItems)))
+	}
+	for i, elem := range list {
+		sub := n.items
+		if i < len(n.prefixItems) {
+			sub = n.prefixItems[i]
+		}
+		if sub != nil {
+			sub.validate(elem, append(path[:len(path):len(path)], pathSegment{index: i}), errs)
+		}
+	}
+	if n.uniqueItems {
+		for i := range list {
+			for j := i + 1; j < len(list); j++ {
+				if equalJSON(list[i], list[j]) {
+					*errs = append(*errs, newSchemaError(path, "uniqueItems", fmt.Sprintf("repeats element [%d] at [%d]", i, j)))
+					return
+				}
+			}
+		}
+	}
+}
+
+// countMatches returns how many of schemas v matches, counting no further
+// than limit.
+func countMatches(schemas []*schemaNode, v any, path []pathSegment, limit int) int {
+	matched := 0
+	for _, sub := range schemas {
+		var errs []error
+		sub.validate(v, path, &errs)
+		if len(errs) == 0 {
+			if matched++; matched == limit {
+				break
+			}
+		}
+	}
+	return matched
+}
+
+func hasSchemaType(types []string, v any) bool {
+	for _, t := range types {
+		switch v := v.(type) {
+		case nil:
+			if t == "null" {
+				return true
+			}
+		case bool:
+			if t == "boolean" {
+				return true
+			}
+		case map[string]any:
+			if t == "object" {
+				return true
+			}
+		case []any:
+			if t == "array" {
+				return true
+			}
+		case string:
+			if t == "string" {
+				return true
+			}
+		case json.Number:
+			if t == "number" {
+				return true
+			}
+			if r, ok := new(big.Rat).SetString(v.String()); t == "integer" && ok && r.IsInt() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// describeJSON names the kind of v for messages, e.g. "a string".
+func describeJSON(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case json.Number:
+		return "the number " + v.String()
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// showJSON returns v as JSON, shortened for messages.
+func showJSON(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	if len(b) > 64 {
+		return string(b[:61]) + "..."
+	}
+	return string(b)
+}
+
+func containsJSON(list []any, v any) bool {
+	for _, e := range list {
+		if equalJSON(e, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalJSON reports whether a and b are the same JSON value, comparing
+// numbers by value, so that 1 and 1.0 are equal.
+func equalJSON(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ra, _ := new(big.Rat).SetString(a.String())
+		rb, _ := new(big.Rat).SetString(b.String())
+		return ra != nil && rb != nil && ra.Cmp(rb) == 0
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalJSON(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			if w, ok := b[k]; !ok || !equalJSON(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+)
+
+// validFormat reports whether s is valid in the format named format.
+// Formats it does not know are taken to be valid.
+func validFormat(format, s string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339Nano, s)
+		return err == nil
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05.999999999Z07:00", s)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s
+	case "uri":
+		u, err := url.Parse(s)
+		return err == nil && u.IsAbs()
+	case "uuid":
+		return uuidPattern.MatchString(s)
+	case "hostname":
+		return len(s) <= 253 && hostnamePattern.MatchString(s)
+	case "ipv4":
+		ip := net.ParseIP(s)
+		return ip != nil && ip.To4() != nil && !strings.Contains(s, ":")
+	case "ipv6":
+		return net.ParseIP(s) != nil && strings.Contains(s, ":")
+	}
+	return true
+}
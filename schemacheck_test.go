@@ -0,0 +1,300 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const userSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"email": {"type": "string", "format": "email"},
+		"role": {"enum": ["admin", "user"]},
+		"age": {"type": "integer", "minimum": 0, "maximum": 150},
+		"address": {"$ref": "#/$defs/address"}
+	},
+	"required": ["name", "email"],
+	"$defs": {
+		"address": {
+			"type": "object",
+			"properties": {"city": {"type": "string"}},
+			"required": ["city"]
+		}
+	}
+}`
+
+type schemaCheckUser struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Age     int    `json:"age"`
+	Address *struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func TestWithSchema(t *testing.T) {
+	d := NewDecoder(WithSchema([]byte(userSchema)))
+
+	var u schemaCheckUser
+	if err := d.Unmarshal([]byte(`{"name": "Ann", "email": "ann@example.com", "role": "admin", "address": {"city": "Oslo"}}`), &u); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if u.Address == nil || u.Address.City != "Oslo" {
+		t.Errorf("Unexpected result %+v", u)
+	}
+
+	err := d.Unmarshal([]byte(`{"name": "Ann"}`), &schemaCheckUser{})
+	var se *SchemaError
+	if !errors.As(err, &se) || se.Path() != "email" || se.Keyword() != "required" {
+		t.Fatalf("Expected required *SchemaError at email, got %v", err)
+	}
+	if want := `strictjson: value at "email" is missing (schema required)`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if ErrorCode(err) != CodeSchema || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected code %q wrapping ErrDecode, got %q", CodeSchema, ErrorCode(err))
+	}
+
+	err = d.Unmarshal([]byte(`{"name": "", "email": "nope", "role": "root", "age": 200, "address": {}}`), &schemaCheckUser{})
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("Expected joined errors, got %v", err)
+	}
+	var got []string
+	for _, e := range joined.Unwrap() {
+		got = append(got, e.(*SchemaError).Path()+" "+e.(*SchemaError).Keyword())
+	}
+	want := []string{"address.city required", "age maximum", "email format", "name minLength", "role enum"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Errors = %v, want %v", got, want)
+	}
+
+	// Strict-rule failures come first and are joined with the schema's.
+	var ufe *UnknownFieldError
+	if err := d.Unmarshal([]byte(`{"Name": "Ann"}`), &schemaCheckUser{}); !errors.As(err, &ufe) {
+		t.Errorf("Expected *UnknownFieldError, got %v", err)
+	}
+
+	if err := d.Validate([]byte(`{"name": "Ann", "email": "ann@example.com", "age": -1}`), (*schemaCheckUser)(nil)); !errors.As(err, &se) || se.Path() != "age" {
+		t.Errorf("Validate(): expected *SchemaError at age, got %v", err)
+	}
+}
+
+func TestWithSchemaJoinsStrictErrors(t *testing.T) {
+	data := []byte(`{"Name": "Ann", "email": "x", "address": {"city": "Oslo", "city": "Rome"}}`)
+	want := "Name unknown_field, address.city duplicate_key, name schema_violation, email schema_violation"
+	if ErrorCode(NewDecoder(WithDisallowDuplicateKeys(true)).Unmarshal(data, &schemaCheckUser{})) != CodeUnknownField {
+		t.Fatal("Expected the decoder without a schema to stop at the unknown field")
+	}
+	d := NewDecoder(WithDisallowDuplicateKeys(true)).WithSchema([]byte(userSchema))
+
+	for api, err := range map[string]error{
+		"Unmarshal":             d.Unmarshal(data, &schemaCheckUser{}),
+		"Validate":              d.Validate(data, (*schemaCheckUser)(nil)),
+		"TypeDecoder.Unmarshal": CompileFor[schemaCheckUser](WithDisallowDuplicateKeys(true), WithSchema([]byte(userSchema))).Unmarshal(data, &schemaCheckUser{}),
+	} {
+		joined, ok := err.(interface{ Unwrap() []error })
+		if !ok {
+			t.Errorf("%s: expected joined errors, got %v", api, err)
+			continue
+		}
+		var got []string
+		for _, e := range joined.Unwrap() {
+			var path string
+			var se *SchemaError
+			var ufe *UnknownFieldError
+			var dke *DuplicateKeyError
+			switch {
+			case errors.As(e, &se):
+				path = se.Path()
+			case errors.As(e, &ufe):
+				path = ufe.Path()
+			case errors.As(e, &dke):
+				path = dke.Path()
+			}
+			got = append(got, path+" "+ErrorCode(e))
+		}
+		if strings.Join(got, ", ") != want {
+			t.Errorf("%s: errors = %v, want %s", api, got, want)
+		}
+	}
+
+	// A syntax error still stops the decode, before the schema.
+	var syn *SyntaxError
+	if err := d.Unmarshal([]byte(`{"Name": "Ann", "email": }`), &schemaCheckUser{}); !errors.As(err, &syn) || ErrorCode(err) == CodeSchema {
+		t.Errorf("Expected the unknown field joined with a *SyntaxError, got %v", err)
+	}
+
+	base := NewDecoder()
+	if base.WithSchema([]byte(userSchema)).schema == nil || base.schema != nil {
+		t.Error("Decoder.WithSchema changed the receiver")
+	}
+}
+
+// TestSchemaTree checks that the value the schema is checked against, built
+// while decoding, matches the document as encoding/json reads it.
+func TestSchemaTree(t *testing.T) {
+	type Item struct {
+		ID   int             `json:"id"`
+		Tags []string        `json:"tags"`
+		Raw  json.RawMessage `json:"raw"`
+	}
+	type Doc struct {
+		Name  string          `json:"name"`
+		Items []Item          `json:"items"`
+		Index map[string]Item `json:"index"`
+		Pair  [2]Item         `json:"pair"`
+		Any   any             `json:"any"`
+	}
+	docs := []string{
+		`{"name": "a", "items": [{"id": 1, "tags": ["x", "y"], "raw": {"k": [1, 2.50, null]}}, {}], "index": {"k": {"id": 2}}}`,
+		` { "pair" : [ {"id": 1}, {"id": 2}, {"id": 3, "extra": {"deep": [[], {}]}} ] , "any": {"n": 1e3, "s": "\u00e9\n", "b": [true, false]} } `,
+		`{"items": null, "index": {}, "nam\u0065": "escaped key", "unknown": [1, {"a": "b"}]}`,
+		`null`,
+	}
+	d := NewDecoder(WithDisallowUnknownFields(false))
+	for _, data := range docs {
+		for _, validate := range []bool{false, true} {
+			s, err := d.newState([]byte(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.tree = &docTree{}
+			p := d.planFor(reflect.TypeOf(Doc{}))
+			if validate {
+				err = s.validateValue(p)
+			} else {
+				var doc Doc
+				err = s.value(reflect.ValueOf(&doc).Elem(), p)
+			}
+			if err != nil {
+				t.Fatalf("%s: %v", data, err)
+			}
+			got := s.tree.root(s.scan.data)
+			s.release()
+
+			var want any
+			dec := json.NewDecoder(strings.NewReader(data))
+			dec.UseNumber()
+			if err := dec.Decode(&want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s (validate %v): tree = %#v, want %#v", data, validate, got, want)
+			}
+		}
+	}
+}
+
+func TestWithSchemaCheck(t *testing.T) {
+	d := NewDecoder(WithSchema([]byte(userSchema)))
+	violations, err := d.Check([]byte(`{"Name": "Ann", "email": "x", "age": 200}`), &schemaCheckUser{})
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.Path)
+	}
+	// The unknown key, then the schema's view of the document as written.
+	if want := "Name, name, age, email"; strings.Join(got, ", ") != want {
+		t.Errorf("Violation paths = %v, want %s", got, want)
+	}
+}
+
+func TestWithSchemaRoundTrip(t *testing.T) {
+	schema, err := SchemaFor((*schemaUser)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := NewDecoder(WithSchema(schema))
+	data, err := Marshal(schemaUser{Name: "n", Role: "user", Friends: []*schemaUser{{Name: "f", Role: "admin"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Unmarshal(data, &schemaUser{}); err != nil {
+		t.Errorf("Unmarshal() of Marshal output unexpected error: %v", err)
+	}
+	// The decoder itself tolerates absent keys; the schema requires them.
+	var se *SchemaError
+	if err := d.Unmarshal([]byte(`{"name": "n", "age": 1, "role": "user", "home": null, "friends": null, "count": "1", "raw": null}`), &schemaUser{}); !errors.As(err, &se) || se.Path() != "born" {
+		t.Errorf("Expected *SchemaError at born, got %v", err)
+	}
+}
+
+func TestSchemaKeywords(t *testing.T) {
+	tests := []struct {
+		name    string
+		schema  string
+		data    string
+		wantErr string // "" for no error
+	}{
+		{"type", `{"type": "integer"}`, `1.5`, `value at "" is the number 1.5, not integer (schema type)`},
+		{"integer valued float", `{"type": "integer"}`, `2.0`, ``},
+		{"type list", `{"type": ["string", "null"]}`, `null`, ``},
+		{"const", `{"const": {"a": [1]}}`, `{"a": [1.0]}`, ``},
+		{"const mismatch", `{"const": 1}`, `2`, `value at "" is 2, not 1 (schema const)`},
+		{"pattern", `{"pattern": "^[a-z]+$"}`, `"Abc"`, `value at "" does not match the pattern "^[a-z]+$" (schema pattern)`},
+		{"maxLength counts characters", `{"maxLength": 2}`, `"éé"`, ``},
+		{"exclusiveMaximum", `{"exclusiveMaximum": 10}`, `10`, `value at "" is 10, not below 10 (schema exclusiveMaximum)`},
+		{"multipleOf", `{"multipleOf": 0.5}`, `1.25`, `value at "" is 1.25, not a multiple of 1/2 (schema multipleOf)`},
+		{"format date", `{"format": "date"}`, `"2024-02-30"`, `value at "" is not a valid date (schema format)`},
+		{"format uuid", `{"format": "uuid"}`, `"123e4567-e89b-12d3-a456-426614174000"`, ``},
+		{"unknown format", `{"format": "color"}`, `"red"`, ``},
+		{"items", `{"items": {"type": "string"}}`, `["a", 1]`, `value at "[1]" is the number 1, not string (schema type)`},
+		{"prefixItems", `{"prefixItems": [{"type": "string"}], "items": false}`, `["a", 1]`, `value at "[1]" is not allowed (schema false)`},
+		{"minItems", `{"minItems": 2}`, `[1]`, `value at "" has length 1, below the minimum of 2 (schema minItems)`},
+		{"uniqueItems", `{"uniqueItems": true}`, `[1, 2, 1.0]`, `value at "" repeats element [0] at [2] (schema uniqueItems)`},
+		{"additionalProperties", `{"properties": {"a": true}, "additionalProperties": false}`, `{"a": 1, "b": 2}`, `value at "b" is not a property the schema allows (schema additionalProperties)`},
+		{"patternProperties", `{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": false}`, `{"x-id": 1}`, `value at "x-id" is the number 1, not string (schema type)`},
+		{"maxProperties", `{"maxProperties": 1}`, `{"a": 1, "b": 2}`, `value at "" has 2 keys, above the maximum of 1 (schema maxProperties)`},
+		{"allOf", `{"allOf": [{"minimum": 1}, {"maximum": 2}]}`, `3`, `value at "" is 3, above the maximum of 2 (schema maximum)`},
+		{"anyOf", `{"anyOf": [{"type": "string"}, {"type": "boolean"}]}`, `1`, `value at "" matches none of the anyOf schemas (schema anyOf)`},
+		{"oneOf", `{"oneOf": [{"minimum": 1}, {"maximum": 5}]}`, `3`, `value at "" matches more than one of the oneOf schemas (schema oneOf)`},
+		{"not", `{"not": {"type": "null"}}`, `null`, `value at "" matches the schema it must not (schema not)`},
+		{"recursive ref", `{"type": "object", "properties": {"next": {"$ref": "#"}}, "required": ["v"]}`, `{"v": 1, "next": {"next": {"v": 2}}}`, `value at "next.v" is missing (schema required)`},
+		{"escaped ref", `{"$ref": "#/$defs/a~1b", "$defs": {"a/b": {"type": "string"}}}`, `true`, `value at "" is a boolean, not string (schema type)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewDecoder(WithSchema([]byte(tt.schema))).Validate([]byte(tt.data), (*any)(nil))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != "strictjson: "+tt.wantErr {
+				t.Errorf("Validate() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithSchemaInvalid(t *testing.T) {
+	tests := []struct {
+		schema string
+		want   string
+	}{
+		{`{"type": `, `strictjson: invalid schema: unexpected EOF`},
+		{`[]`, `strictjson: invalid schema: not an object or boolean`},
+		{`{"type": "text"}`, `strictjson: invalid schema at "#/type": unknown type "text"`},
+		{`{"$ref": "https://example.com/s.json"}`, `strictjson: invalid schema at "#/$ref": unsupported reference "https://example.com/s.json"`},
+		{`{"items": {"$ref": "#/$defs/missing"}}`, `strictjson: invalid schema at "#/items/$ref": reference "#/$defs/missing" not found`},
+		{`{"minLength": -1}`, `strictjson: invalid schema at "#/minLength": not a non-negative integer`},
+		{`{"pattern": "("}`, "strictjson: invalid schema at \"#/pattern\": error parsing regexp: missing closing ): `(`"},
+	}
+	for _, tt := range tests {
+		d := NewDecoder(WithSchema([]byte(tt.schema)))
+		var v any
+		if err := d.Unmarshal([]byte(`1`), &v); err == nil || err.Error() != tt.want {
+			t.Errorf("WithSchema(%s): Unmarshal() error = %v, want %s", tt.schema, err, tt.want)
+		}
+	}
+}
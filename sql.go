@@ -0,0 +1,102 @@
+package strictjson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// ScanError reports a database value that could not be strictly decoded.
+// Errors from the decoder remain reachable through errors.As.
+type ScanError struct {
+	typ reflect.Type
+	err error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("strictjson: scanning JSON column into %s: %v", e.typ, e.err)
+}
+
+func (e *ScanError) Unwrap() []error {
+	return []error{e.err, ErrDecode}
+}
+
+// Type returns the Go type the column was scanned into.
+func (e *ScanError) Type() reflect.Type {
+	return e.typ
+}
+
+// ScanInto returns a sql.Scanner that strictly decodes a JSON or JSONB column
+// into dst, which must be a non-nil pointer:
+//
+//	var settings Settings
+//	err := row.Scan(&id, strictjson.ScanInto(&settings))
+//
+// A NULL column leaves dst unchanged.
+func ScanInto(dst any) sql.Scanner {
+	return &jsonScanner{dst: dst}
+}
+
+type jsonScanner struct {
+	dst any
+}
+
+func (s *jsonScanner) Scan(src any) error {
+	if src == nil {
+		return nil
+	}
+	return scanJSON(src, s.dst)
+}
+
+// scanJSON decodes a driver value holding JSON text into dst.
+func scanJSON(src, dst any) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return &ScanError{typ: reflect.TypeOf(dst), err: fmt.Errorf("unsupported source type %T", src)}
+	}
+	if err := Unmarshal(data, dst); err != nil {
+		return &ScanError{typ: reflect.TypeOf(dst), err: err}
+	}
+	return nil
+}
+
+// JSONColumn holds a value stored as JSON in a database column, in the
+// shape of sql.Null. Scanning decodes it strictly; a NULL column sets Valid
+// to false.
+//
+//	var col strictjson.JSONColumn[Settings]
+//	err := row.Scan(&col)
+type JSONColumn[T any] struct {
+	V     T
+	Valid bool
+}
+
+// Scan implements sql.Scanner.
+func (c *JSONColumn[T]) Scan(src any) error {
+	var zero T
+	c.V, c.Valid = zero, false
+	if src == nil {
+		return nil
+	}
+	if err := scanJSON(src, &c.V); err != nil {
+		return err
+	}
+	c.Valid = true
+	return nil
+}
+
+// Value implements driver.Valuer, encoding V as JSON or returning NULL
+// when Valid is false.
+func (c JSONColumn[T]) Value() (driver.Value, error) {
+	if !c.Valid {
+		return nil, nil
+	}
+	return json.Marshal(c.V)
+}
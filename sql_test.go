@@ -0,0 +1,79 @@
+package strictjson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+)
+
+type columnSettings struct {
+	Theme string `json:"theme"`
+	Size  int    `json:"size"`
+}
+
+var (
+	_ sql.Scanner   = (*JSONColumn[columnSettings])(nil)
+	_ driver.Valuer = JSONColumn[columnSettings]{}
+)
+
+func TestScanInto(t *testing.T) {
+	var s columnSettings
+	if err := ScanInto(&s).Scan([]byte(`{"theme": "dark", "size": 2}`)); err != nil {
+		t.Fatalf("Scan() unexpected error: %v", err)
+	}
+	if s.Theme != "dark" || s.Size != 2 {
+		t.Errorf("Unexpected result %+v", s)
+	}
+	if err := ScanInto(&s).Scan(`{"theme": "light"}`); err != nil || s.Theme != "light" {
+		t.Errorf("Expected string source to decode, got %+v (%v)", s, err)
+	}
+	if err := ScanInto(&s).Scan(nil); err != nil || s.Theme != "light" {
+		t.Errorf("Expected NULL to leave dst unchanged, got %+v (%v)", s, err)
+	}
+}
+
+func TestScanIntoErrors(t *testing.T) {
+	var s columnSettings
+	err := ScanInto(&s).Scan([]byte(`{"Theme": "dark"}`))
+
+	var scanErr *ScanError
+	if !errors.As(err, &scanErr) {
+		t.Fatalf("Expected ScanError, got %v", err)
+	}
+	want := `strictjson: scanning JSON column into *strictjson.columnSettings: strictjson: unknown or mis-cased field "Theme"`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Path() != "Theme" || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected wrapped UnknownFieldError, got %v", err)
+	}
+
+	if err := ScanInto(&s).Scan(42); !errors.As(err, &scanErr) {
+		t.Errorf("Expected ScanError for unsupported source, got %v", err)
+	}
+}
+
+func TestJSONColumn(t *testing.T) {
+	var c JSONColumn[columnSettings]
+	if err := c.Scan([]byte(`{"theme": "dark"}`)); err != nil || !c.Valid || c.V.Theme != "dark" {
+		t.Fatalf("Unexpected result %+v (%v)", c, err)
+	}
+
+	v, err := c.Value()
+	if err != nil || string(v.([]byte)) != `{"theme":"dark","size":0}` {
+		t.Errorf("Value() = %s, %v", v, err)
+	}
+
+	if err := c.Scan(nil); err != nil || c.Valid || c.V.Theme != "" {
+		t.Errorf("Expected NULL to reset the column, got %+v (%v)", c, err)
+	}
+	if v, err := c.Value(); v != nil || err != nil {
+		t.Errorf("Expected NULL value, got %v (%v)", v, err)
+	}
+
+	if err := c.Scan([]byte(`{"theme": "dark", "extra": 1}`)); err == nil || c.Valid {
+		t.Errorf("Expected unknown key to fail the scan, got %+v", c)
+	}
+}
@@ -0,0 +1,301 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+)
+
+// StrictUnmarshaler is implemented by types with decoders generated by
+// cmd/strictjson-gen. When a JSON object is decoded into such a type the
+// decoder calls UnmarshalStrictJSON instead of walking the type with
+// reflection; all other types fall back to the reflective decoder.
+type StrictUnmarshaler interface {
+	UnmarshalStrictJSON(dec *Stream) error
+}
+
+var strictUnmarshalerType = reflect.TypeOf((*StrictUnmarshaler)(nil)).Elem()
+
+// Stream gives generated decoders access to the decoder's scanner. It is
+// only valid for the duration of the UnmarshalStrictJSON call it is passed
+// to.
+type Stream struct {
+	s *decodeState
+}
+
+// FieldSet is the set of JSON names a generated decoder accepts.
+type FieldSet struct {
+	sf *structFields
+}
+
+// NewFieldSet returns a FieldSet for the given JSON names. Generated code
+// builds one per type at package initialization.
+func NewFieldSet(names ...string) *FieldSet {
+	sf := &structFields{
+		fields:   make(map[string]*fieldInfo, len(names)),
+		allNames: names,
+	}
+	for _, name := range names {
+		sf.fields[name] = &fieldInfo{jsonName: name}
+	}
+	return &FieldSet{sf: sf}
+}
+
+// Object reads a JSON object, calling fn with each key that belongs to
+// fields while fn decodes the matching value. Keys outside fields are
+// handled by the decoder's unknown-field policy before fn would see them.
+func (st *Stream) Object(fields *FieldSet, fn func(key string) error) error {
+	s := st.s
+	if err := s.scan.expect('{', "looking for beginning of object"); err != nil {
+		return err
+	}
+	if err := s.enter(); err != nil {
+		return err
+	}
+	defer s.leave()
+	if s.stats != nil {
+		s.stats.Objects++
+	}
+	if s.scan.consume('}') {
+		return nil
+	}
+	seen := s.newSeenKeys()
+	var order keyOrder
+	for n := 1; ; n++ {
+		if err := s.countKey(n); err != nil {
+			return err
+		}
+		key, fi, err := s.readFieldKey(fields.sf)
+		if err != nil {
+			return err
+		}
+
+		s.pushKey(key)
+		err = s.seenKey(seen, key)
+		if err == nil {
+			err = s.orderedKey(&order, fields.sf, key, fi)
+		}
+		if err == nil {
+			err = st.member(fields, key, fi, fn)
+		}
+		s.pop()
+		if err != nil {
+			return err
+		}
+
+		if s.scan.consume(',') {
+			continue
+		}
+		return s.scan.expect('}', "after object key:value pair")
+	}
+}
+
+func (st *Stream) member(fields *FieldSet, key string, fi *fieldInfo, fn func(key string) error) error {
+	s := st.s
+	if fi == nil {
+		fi, _ = s.lookup(fields.sf, key)
+	}
+	if fi == nil {
+		fold, err := s.unknown(fields.sf, key)
+		if err != nil {
+			return err
+		}
+		if fold == nil {
+			_, err = s.skip()
+			return err
+		}
+		fi = fold
+	}
+	if s.report != nil {
+		s.report.add(s.pathString())
+	}
+	return fn(fi.jsonName)
+}
+
+// Skip consumes the next value without decoding it.
+func (st *Stream) Skip() error {
+	_, err := st.s.skip()
+	return err
+}
+
+// Decode decodes the next value into the value pointed to by v using the
+// full strict decoder, including generated decoders of nested types.
+func (st *Stream) Decode(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return newNonPointerError()
+	}
+	return st.s.value(rv.Elem(), st.s.d.planFor(rv.Elem().Type()))
+}
+
+// null consumes a null literal, applying the null policy for a value of
+// type t. It reports whether a null was consumed.
+func (st *Stream) null(t reflect.Type) (bool, error) {
+	s := st.s
+	if !s.scan.consumeNull() {
+		return false, nil
+	}
+	if s.d.DisallowNullForNonPointer {
+		return true, s.violation(newNullValueError(s.pathString(), t))
+	}
+	return true, nil
+}
+
+// scalar consumes the next value, which must be a token of the given kind
+// ('"' for strings, '0' for numbers, 't' for booleans).
+func (st *Stream) scalar(kind byte, t reflect.Type) ([]byte, error) {
+	s := st.s
+	start := s.scan.pos
+	raw, err := s.skip()
+	if err != nil {
+		return nil, err
+	}
+	got := raw[0]
+	switch got {
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		got = '0'
+	case 'f':
+		got = 't'
+	}
+	if got != kind {
+		return nil, st.typeError(describeToken(raw), raw, t, start)
+	}
+	return raw, nil
+}
+
+func (st *Stream) typeError(value string, raw []byte, t reflect.Type, offset int) error {
+	return newTypeMismatchError(&json.UnmarshalTypeError{
+		Value:  value,
+		Type:   t,
+		Offset: int64(offset),
+		Field:  st.s.pathString(),
+	}, st.s.snippet(raw))
+}
+
+// numberError reports the number raw, which does not fit t.
+func (st *Stream) numberError(raw []byte, t reflect.Type, offset int) error {
+	if st.s.d.ownsNumbers() {
+		return newNumberError(st.s.pathString(), t, string(raw))
+	}
+	return st.typeError("number "+string(raw), raw, t, offset)
+}
+
+// describeToken names the JSON kind of a raw value the way encoding/json
+// does in type errors.
+func describeToken(raw []byte) string {
+	switch raw[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "bool"
+	default:
+		return "number"
+	}
+}
+
+// String decodes a JSON string into p.
+func (st *Stream) String(p *string) error {
+	if null, err := st.null(stringType); null {
+		return err
+	}
+	raw, err := st.scalar('"', stringType)
+	if err != nil {
+		return err
+	}
+	*p = unquote(raw[1:len(raw)-1], needsUnquote(raw[1:len(raw)-1]))
+	return nil
+}
+
+// Bool decodes a JSON boolean into p.
+func (st *Stream) Bool(p *bool) error {
+	if null, err := st.null(boolType); null {
+		return err
+	}
+	raw, err := st.scalar('t', boolType)
+	if err != nil {
+		return err
+	}
+	*p = raw[0] == 't'
+	return nil
+}
+
+// Int decodes a JSON number into p.
+func (st *Stream) Int(p *int) error {
+	var n int64
+	if err := st.int(&n, intType, strconv.IntSize); err != nil {
+		return err
+	}
+	*p = int(n)
+	return nil
+}
+
+// Int64 decodes a JSON number into p.
+func (st *Stream) Int64(p *int64) error {
+	return st.int(p, int64Type, 64)
+}
+
+func (st *Stream) int(p *int64, t reflect.Type, bits int) error {
+	if null, err := st.null(t); null {
+		return err
+	}
+	start := st.s.scan.pos
+	raw, err := st.scalar('0', t)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseInt(string(raw), 10, bits)
+	if err != nil {
+		return st.numberError(raw, t, start)
+	}
+	*p = n
+	return nil
+}
+
+// Float64 decodes a JSON number into p.
+func (st *Stream) Float64(p *float64) error {
+	if null, err := st.null(float64Type); null {
+		return err
+	}
+	start := st.s.scan.pos
+	raw, err := st.scalar('0', float64Type)
+	if err != nil {
+		return err
+	}
+	f, err := strconv.ParseFloat(string(raw), 64)
+	if err != nil {
+		return st.numberError(raw, float64Type, start)
+	}
+	if st.s.d.RejectLossyFloats && !isExactFloat(string(raw), f) {
+		return newLossyFloatError(st.s.pathString(), float64Type, string(raw))
+	}
+	*p = f
+	return nil
+}
+
+var (
+	stringType  = reflect.TypeOf("")
+	boolType    = reflect.TypeOf(false)
+	intType     = reflect.TypeOf(0)
+	int64Type   = reflect.TypeOf(int64(0))
+	float64Type = reflect.TypeOf(float64(0))
+)
+
+// needsUnquote reports whether string contents contain escapes or non-ASCII
+// bytes.
+func needsUnquote(raw []byte) bool {
+	for _, c := range raw {
+		if c == '\\' || c >= 0x80 {
+			return true
+		}
+	}
+	return false
+}
+
+// generated decodes an object with the type's generated decoder.
+func (s *decodeState) generated(v reflect.Value) error {
+	return v.Addr().Interface().(StrictUnmarshaler).UnmarshalStrictJSON(&Stream{s: s})
+}
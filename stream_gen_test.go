@@ -0,0 +1,54 @@
+// Code generated by strictjson-gen. DO NOT EDIT.
+
+package strictjson
+
+var strictjsonFieldsGenUser = NewFieldSet("name", "age", "balance", "score", "admin", "tags", "address")
+
+// UnmarshalStrictJSON implements StrictUnmarshaler.
+func (v *genUser) UnmarshalStrictJSON(dec *Stream) error {
+	return dec.Object(strictjsonFieldsGenUser, func(key string) error {
+		switch key {
+		case "name":
+			return dec.String(&v.Name)
+		case "age":
+			return dec.Int(&v.Age)
+		case "balance":
+			return dec.Int64(&v.Balance)
+		case "score":
+			return dec.Float64(&v.Score)
+		case "admin":
+			return dec.Bool(&v.Admin)
+		case "tags":
+			return dec.Decode(&v.Tags)
+		case "address":
+			return dec.Decode(&v.Address)
+		}
+		return dec.Skip()
+	})
+}
+
+var strictjsonFieldsGenAddress = NewFieldSet("city")
+
+// UnmarshalStrictJSON implements StrictUnmarshaler.
+func (v *genAddress) UnmarshalStrictJSON(dec *Stream) error {
+	return dec.Object(strictjsonFieldsGenAddress, func(key string) error {
+		switch key {
+		case "city":
+			return dec.String(&v.City)
+		}
+		return dec.Skip()
+	})
+}
+
+var strictjsonFieldsGenLegacy = NewFieldSet("name")
+
+// UnmarshalStrictJSON implements StrictUnmarshaler.
+func (v *genLegacy) UnmarshalStrictJSON(dec *Stream) error {
+	return dec.Object(strictjsonFieldsGenLegacy, func(key string) error {
+		switch key {
+		case "name":
+			return dec.String(&v.Name)
+		}
+		return dec.Skip()
+	})
+}
@@ -0,0 +1,125 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// The decoders for these types in stream_gen_test.go were produced by
+// cmd/strictjson-gen.
+type genUser struct {
+	Name    string     `json:"name"`
+	Age     int        `json:"age"`
+	Balance int64      `json:"balance"`
+	Score   float64    `json:"score"`
+	Admin   bool       `json:"admin"`
+	Tags    []string   `json:"tags"`
+	Address genAddress `json:"address"`
+}
+
+type genAddress struct {
+	City string `json:"city"`
+}
+
+type genLegacy struct {
+	Name string `json:"name"`
+}
+
+func TestGeneratedDecoder(t *testing.T) {
+	if k := planFor(reflect.TypeOf(genUser{}), fieldConfig{}).kind; k != planGenerated {
+		t.Fatalf("Expected generated plan, got kind %d", k)
+	}
+
+	data := []byte(`{"name": "Ann é", "age": 41, "balance": -9000000000, "score": 1.5,
+		"admin": true, "tags": ["a", "b"], "address": {"city": "Oslo"}}`)
+	var got, want genUser
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+}
+
+func TestGeneratedDecoderStrictRules(t *testing.T) {
+	var u genUser
+	err := NewDecoder(WithSuggestClosest(true)).Unmarshal([]byte(`{"address": {"City": "Oslo"}}`), &u)
+	var unknown *UnknownFieldError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected UnknownFieldError, got %v", err)
+	}
+	if unknown.Path() != "address.City" || unknown.Suggestion() != "city" {
+		t.Errorf("Expected path 'address.City' suggesting 'city', got %q / %q", unknown.Path(), unknown.Suggestion())
+	}
+
+	err = Unmarshal([]byte(`{"age": "old"}`), &u)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) || typeErr.Field != "age" || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected wrapped UnmarshalTypeError at 'age', got %v", err)
+	}
+
+	err = Unmarshal([]byte(`{"age": 1.5}`), &u)
+	if !errors.As(err, &typeErr) {
+		t.Errorf("Expected UnmarshalTypeError for fractional int, got %v", err)
+	}
+
+	d := NewDecoder(WithDisallowDuplicateKeys(true), WithDisallowNullForNonPointer(true))
+	err = d.Unmarshal([]byte(`{"name": "a", "name": "b"}`), &u)
+	var dup *DuplicateKeyError
+	if !errors.As(err, &dup) || dup.Path() != "name" {
+		t.Errorf("Expected DuplicateKeyError at 'name', got %v", err)
+	}
+
+	err = d.Unmarshal([]byte(`{"score": null}`), &u)
+	var nullErr *NullValueError
+	if !errors.As(err, &nullErr) || nullErr.Path() != "score" {
+		t.Errorf("Expected NullValueError at 'score', got %v", err)
+	}
+
+	d = NewDecoder(WithDisallowUnknownFields(false))
+	if err := d.Unmarshal([]byte(`{"extra": {"x": [1]}, "name": "ok"}`), &u); err != nil || u.Name != "ok" {
+		t.Errorf("Expected unknown key to be skipped, got %+v (%v)", u, err)
+	}
+}
+
+func TestGeneratedDecoderCheckAndReport(t *testing.T) {
+	var u genUser
+	violations, err := NewDecoder().Check([]byte(`{"Name": "Ann", "age": 3}`), &u)
+	if err != nil {
+		t.Fatalf("Check() unexpected error: %v", err)
+	}
+	if len(violations) != 1 || violations[0].Path != "Name" {
+		t.Errorf("Expected one violation at 'Name', got %v", violations)
+	}
+	if u.Name != "Ann" || u.Age != 3 {
+		t.Errorf("Expected fold-matched decode, got %+v", u)
+	}
+
+	report, err := NewDecoder().UnmarshalWithReport([]byte(`{"address": {"city": "x"}}`), &u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Present("address.city") || report.Present("name") {
+		t.Errorf("Unexpected report fields %v", report.Fields())
+	}
+
+	if err := ValidateType[genUser]([]byte(`{"address": {"CITY": "x"}}`)); err == nil {
+		t.Error("Expected Validate to reject mis-cased key")
+	}
+}
+
+func TestGeneratedDecoderAliasFallback(t *testing.T) {
+	RegisterAlias(reflect.TypeOf(genLegacy{}), "name", "full_name")
+	if k := planFor(reflect.TypeOf(genLegacy{}), fieldConfig{}).kind; k != planStruct {
+		t.Fatalf("Expected reflective plan for aliased type, got kind %d", k)
+	}
+	var l genLegacy
+	if err := Unmarshal([]byte(`{"full_name": "x"}`), &l); err != nil || l.Name != "x" {
+		t.Errorf("Expected alias to apply, got %+v (%v)", l, err)
+	}
+}
@@ -0,0 +1,556 @@
+// Package strictjson5 decodes JSON5 documents, such as tooling configs, with
+// strictjson's field rules:
+//
+//	var cfg Config
+//	err := strictjson5.Unmarshal(data, &cfg, strictjson.WithSuggestClosest(true))
+//
+// JSON5 adds unquoted keys, single-quoted strings, hexadecimal numbers,
+// comments and trailing commas, among others, to JSON. The document is
+// converted to JSON with every key kept exactly as written and then decoded
+// by a strictjson.Decoder, so an unquoted key "logLevel" is rejected for a
+// LogLevel field tagged json:"LogLevel" just as the quoted key would be.
+//
+// Errors in the JSON5 syntax are reported with a *SyntaxError whose offset
+// points into the original document. Decode errors locate values by path;
+// byte offsets in them refer to the converted JSON.
+package strictjson5
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
+	"strictjson"
+)
+
+// Unmarshal decodes the JSON5 document data into v with a strictjson.Decoder
+// configured by opts.
+func Unmarshal(data []byte, v any, opts ...strictjson.DecoderOption) error {
+	js, err := ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return strictjson.NewDecoder(opts...).Unmarshal(js, v)
+}
+
+// Validate reports whether the JSON5 document data would decode into a value
+// of prototype's type, as strictjson.Decoder.Validate does for JSON.
+func Validate(data []byte, prototype any, opts ...strictjson.DecoderOption) error {
+	js, err := ToJSON(data)
+	if err != nil {
+		return err
+	}
+	return strictjson.NewDecoder(opts...).Validate(js, prototype)
+}
+
+// LoadFile reads the JSON5 file at path and decodes it into v.
+func LoadFile(path string, v any, opts ...strictjson.DecoderOption) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := Unmarshal(data, v, opts...); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// ToJSON converts the JSON5 document data to JSON. Keys and the contents of
+// strings are kept as written, hexadecimal numbers are converted to decimal,
+// and comments and trailing commas are dropped. Infinity and NaN, which JSON
+// cannot represent, are reported as errors.
+func ToJSON(data []byte) ([]byte, error) {
+	c := converter{data: data, out: make([]byte, 0, len(data))}
+	if err := c.document(); err != nil {
+		return nil, err
+	}
+	return c.out, nil
+}
+
+// SyntaxError reports invalid JSON5. It wraps strictjson.ErrDecode.
+type SyntaxError struct {
+	msg    string
+	Offset int64 // byte offset at which the error was detected
+}
+
+func (e *SyntaxError) Error() string {
+	return "strictjson5: " + e.msg
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return strictjson.ErrDecode
+}
+
+// maxDepth bounds the nesting of objects and arrays, so that hostile input
+// cannot exhaust the stack.
+const maxDepth = 10000
+
+// converter rewrites a JSON5 document as JSON.
+type converter struct {
+	data  []byte
+	pos   int
+	depth int
+	out   []byte
+}
+
+func (c *converter) document() error {
+	if err := c.skipSpace(); err != nil {
+		return err
+	}
+	if err := c.value(); err != nil {
+		return err
+	}
+	if err := c.skipSpace(); err != nil {
+		return err
+	}
+	if c.pos < len(c.data) {
+		return c.errorf("after top-level value")
+	}
+	return nil
+}
+
+// errorf builds a syntax error for the character at the current position.
+func (c *converter) errorf(context string) error {
+	if c.pos >= len(c.data) {
+		return c.errorAt("unexpected end of JSON5 input", c.pos)
+	}
+	r, _ := utf8.DecodeRune(c.data[c.pos:])
+	return c.errorAt("invalid character "+strconv.QuoteRune(r)+" "+context, c.pos)
+}
+
+func (c *converter) errorAt(msg string, offset int) error {
+	return &SyntaxError{msg: msg, Offset: int64(offset)}
+}
+
+// skipSpace skips white space, line terminators and comments.
+func (c *converter) skipSpace() error {
+	for c.pos < len(c.data) {
+		switch b := c.data[c.pos]; {
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\v' || b == '\f':
+			c.pos++
+		case b == '/' && c.pos+1 < len(c.data) && c.data[c.pos+1] == '/':
+			for c.pos < len(c.data) && !isLineTerminator(c.data[c.pos:]) {
+				c.pos++
+			}
+		case b == '/' && c.pos+1 < len(c.data) && c.data[c.pos+1] == '*':
+			start := c.pos
+			end := bytes.Index(c.data[c.pos+2:], []byte("*/"))
+			if end < 0 {
+				return c.errorAt("unterminated block comment", start)
+			}
+			c.pos += 2 + end + 2
+		case b >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(c.data[c.pos:])
+			if !isSpace(r) {
+				return nil
+			}
+			c.pos += size
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func isSpace(r rune) bool {
+	return r == '\u00a0' || r == '\ufeff' || r == '\u2028' || r == '\u2029' || unicode.Is(unicode.Zs, r)
+}
+
+// isLineTerminator reports whether data starts with a line terminator.
+func isLineTerminator(data []byte) bool {
+	switch data[0] {
+	case '\n', '\r':
+		return true
+	case 0xe2:
+		r, _ := utf8.DecodeRune(data)
+		return r == '\u2028' || r == '\u2029'
+	}
+	return false
+}
+
+func (c *converter) value() error {
+	if c.pos >= len(c.data) {
+		return c.errorf("")
+	}
+	switch b := c.data[c.pos]; {
+	case b == '{':
+		return c.object()
+	case b == '[':
+		return c.array()
+	case b == '"' || b == '\'':
+		return c.str()
+	case b == '-' || b == '+' || b == '.' || b == 'I' || b == 'N' || (b >= '0' && b <= '9'):
+		return c.number()
+	}
+	for _, lit := range []string{"true", "false", "null"} {
+		if c.literal(lit) {
+			c.out = append(c.out, lit...)
+			return nil
+		}
+	}
+	return c.errorf("looking for beginning of value")
+}
+
+// literal consumes word if the input continues with it as a whole word.
+func (c *converter) literal(word string) bool {
+	end := c.pos + len(word)
+	if end > len(c.data) || string(c.data[c.pos:end]) != word {
+		return false
+	}
+	if end < len(c.data) {
+		if r, _ := utf8.DecodeRune(c.data[end:]); isIdentifierPart(r) {
+			return false
+		}
+	}
+	c.pos = end
+	return true
+}
+
+func (c *converter) enter() error {
+	c.depth++
+	if c.depth > maxDepth {
+		return c.errorAt("exceeded max depth", c.pos)
+	}
+	return nil
+}
+
+func (c *converter) object() error {
+	if err := c.enter(); err != nil {
+		return err
+	}
+	c.pos++
+	c.out = append(c.out, '{')
+	for first := true; ; first = false {
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		if c.pos < len(c.data) && c.data[c.pos] == '}' {
+			break
+		}
+		if !first {
+			c.out = append(c.out, ',')
+		}
+		if err := c.key(); err != nil {
+			return err
+		}
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		if c.pos >= len(c.data) || c.data[c.pos] != ':' {
+			return c.errorf("after object key")
+		}
+		c.pos++
+		c.out = append(c.out, ':')
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		if err := c.value(); err != nil {
+			return err
+		}
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		if c.pos < len(c.data) && c.data[c.pos] == ',' {
+			c.pos++
+			continue
+		}
+		if c.pos >= len(c.data) || c.data[c.pos] != '}' {
+			return c.errorf("after object key:value pair")
+		}
+		break
+	}
+	c.pos++
+	c.out = append(c.out, '}')
+	c.depth--
+	return nil
+}
+
+func (c *converter) array() error {
+	if err := c.enter(); err != nil {
+		return err
+	}
+	c.pos++
+	c.out = append(c.out, '[')
+	for first := true; ; first = false {
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		if c.pos < len(c.data) && c.data[c.pos] == ']' {
+			break
+		}
+		if !first {
+			c.out = append(c.out, ',')
+		}
+		if err := c.value(); err != nil {
+			return err
+		}
+		if err := c.skipSpace(); err != nil {
+			return err
+		}
+		if c.pos < len(c.data) && c.data[c.pos] == ',' {
+			c.pos++
+			continue
+		}
+		if c.pos >= len(c.data) || c.data[c.pos] != ']' {
+			return c.errorf("after array element")
+		}
+		break
+	}
+	c.pos++
+	c.out = append(c.out, ']')
+	c.depth--
+	return nil
+}
+
+// key converts an object key, quoted or an identifier, to a JSON string.
+func (c *converter) key() error {
+	if c.pos < len(c.data) && (c.data[c.pos] == '"' || c.data[c.pos] == '\'') {
+		return c.str()
+	}
+	start := c.pos
+	c.out = append(c.out, '"')
+	for c.pos < len(c.data) {
+		r, size := utf8.DecodeRune(c.data[c.pos:])
+		at := c.pos
+		if r == '\\' {
+			if c.pos+1 >= len(c.data) || c.data[c.pos+1] != 'u' {
+				return c.errorAt(`invalid escape in object key, only \u is allowed`, at)
+			}
+			c.pos += 2
+			var err error
+			if r, err = c.hex(4); err != nil {
+				return err
+			}
+		} else {
+			c.pos += size
+		}
+		if !isIdentifierPart(r) || (at == start && !isIdentifierStart(r)) {
+			c.pos = at
+			break
+		}
+		c.out = appendRune(c.out, r)
+	}
+	if c.pos == start {
+		return c.errorf("looking for beginning of object key")
+	}
+	c.out = append(c.out, '"')
+	return nil
+}
+
+func isIdentifierStart(r rune) bool {
+	return r == '$' || r == '_' || unicode.In(r, unicode.Lu, unicode.Ll, unicode.Lt, unicode.Lm, unicode.Lo, unicode.Nl)
+}
+
+func isIdentifierPart(r rune) bool {
+	return isIdentifierStart(r) || r == '\u200c' || r == '\u200d' ||
+		unicode.In(r, unicode.Mn, unicode.Mc, unicode.Nd, unicode.Pc)
+}
+
+// hex reads n hexadecimal digits.
+func (c *converter) hex(n int) (rune, error) {
+	if c.pos+n > len(c.data) {
+		c.pos = len(c.data)
+		return 0, c.errorf("")
+	}
+	v, err := strconv.ParseUint(string(c.data[c.pos:c.pos+n]), 16, 32)
+	if err != nil {
+		return 0, c.errorAt("invalid hexadecimal escape", c.pos)
+	}
+	c.pos += n
+	return rune(v), nil
+}
+
+// str converts a single- or double-quoted string to a JSON string.
+func (c *converter) str() error {
+	quote := c.data[c.pos]
+	start := c.pos
+	c.pos++
+	c.out = append(c.out, '"')
+	for {
+		if c.pos >= len(c.data) {
+			return c.errorAt("unterminated string literal", start)
+		}
+		b := c.data[c.pos]
+		switch {
+		case b == quote:
+			c.pos++
+			c.out = append(c.out, '"')
+			return nil
+		case b == '\\':
+			if err := c.escape(); err != nil {
+				return err
+			}
+		case b == '\n' || b == '\r':
+			return c.errorf("in string literal")
+		case b == '"':
+			c.pos++
+			c.out = append(c.out, `\"`...)
+		case b < 0x20:
+			c.pos++
+			c.out = appendRune(c.out, rune(b))
+		default:
+			// Copy other bytes as they are, so that invalid UTF-8 is left
+			// for the decoder to judge.
+			c.pos++
+			c.out = append(c.out, b)
+		}
+	}
+}
+
+// escape converts the escape sequence at the current position.
+func (c *converter) escape() error {
+	at := c.pos
+	c.pos++
+	if c.pos >= len(c.data) {
+		return c.errorf("")
+	}
+	b := c.data[c.pos]
+	switch b {
+	case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		c.pos++
+		c.out = append(c.out, '\\', b)
+	case 'u':
+		// Keep \u escapes as written: JSON gives them the same meaning,
+		// surrogate pairs included.
+		c.pos++
+		if _, err := c.hex(4); err != nil {
+			return err
+		}
+		c.out = append(c.out, c.data[at:c.pos]...)
+	case 'x':
+		c.pos++
+		r, err := c.hex(2)
+		if err != nil {
+			return err
+		}
+		c.out = appendRune(c.out, r)
+	case 'v':
+		c.pos++
+		c.out = appendRune(c.out, '\v')
+	case '0':
+		c.pos++
+		if c.pos < len(c.data) && c.data[c.pos] >= '0' && c.data[c.pos] <= '9' {
+			return c.errorAt("invalid escape in string literal", at)
+		}
+		c.out = appendRune(c.out, 0)
+	case '\r':
+		// A line continuation, which contributes nothing to the string.
+		c.pos++
+		if c.pos < len(c.data) && c.data[c.pos] == '\n' {
+			c.pos++
+		}
+	case '\n':
+		c.pos++
+	default:
+		if b >= '1' && b <= '9' {
+			return c.errorAt("invalid escape in string literal", at)
+		}
+		r, size := utf8.DecodeRune(c.data[c.pos:])
+		c.pos += size
+		if r != '\u2028' && r != '\u2029' {
+			c.out = appendRune(c.out, r)
+		}
+	}
+	return nil
+}
+
+// appendRune appends r to dst as it appears inside a JSON string.
+func appendRune(dst []byte, r rune) []byte {
+	switch {
+	case r == '"' || r == '\\':
+		return append(dst, '\\', byte(r))
+	case r < 0x20:
+		return append(dst, fmt.Sprintf(`\u%04x`, r)...)
+	}
+	return utf8.AppendRune(dst, r)
+}
+
+// number converts a number to JSON: a leading plus sign is dropped, a
+// missing zero before or after the decimal point is supplied, and a
+// hexadecimal integer is written in decimal.
+func (c *converter) number() error {
+	start := c.pos
+	neg := false
+	if b := c.data[c.pos]; b == '-' || b == '+' {
+		neg = b == '-'
+		c.pos++
+	}
+	for _, word := range []string{"Infinity", "NaN"} {
+		if c.literal(word) {
+			return c.errorAt(word+" cannot be represented in JSON", start)
+		}
+	}
+	if neg {
+		c.out = append(c.out, '-')
+	}
+	if c.pos+1 < len(c.data) && c.data[c.pos] == '0' && (c.data[c.pos+1] == 'x' || c.data[c.pos+1] == 'X') {
+		c.pos += 2
+		digits := c.pos
+		for c.pos < len(c.data) && isHexDigit(c.data[c.pos]) {
+			c.pos++
+		}
+		n, ok := new(big.Int).SetString(string(c.data[digits:c.pos]), 16)
+		if !ok {
+			return c.errorf("in hexadecimal literal")
+		}
+		c.out = n.Append(c.out, 10)
+		return nil
+	}
+
+	intStart := c.pos
+	c.digits()
+	intPart := c.data[intStart:c.pos]
+	if len(intPart) > 1 && intPart[0] == '0' {
+		return c.errorAt("invalid leading zero in numeric literal", intStart)
+	}
+	var frac []byte
+	point := c.pos < len(c.data) && c.data[c.pos] == '.'
+	if point {
+		c.pos++
+		fracStart := c.pos
+		c.digits()
+		frac = c.data[fracStart:c.pos]
+	}
+	if len(intPart) == 0 && len(frac) == 0 {
+		if point {
+			c.pos--
+		}
+		return c.errorf("in numeric literal")
+	}
+	if len(intPart) == 0 {
+		c.out = append(c.out, '0')
+	}
+	c.out = append(c.out, intPart...)
+	if len(frac) > 0 {
+		c.out = append(c.out, '.')
+		c.out = append(c.out, frac...)
+	}
+	if c.pos < len(c.data) && (c.data[c.pos] == 'e' || c.data[c.pos] == 'E') {
+		expStart := c.pos
+		c.pos++
+		if c.pos < len(c.data) && (c.data[c.pos] == '+' || c.data[c.pos] == '-') {
+			c.pos++
+		}
+		digits := c.pos
+		c.digits()
+		if c.pos == digits {
+			return c.errorf("in exponent of numeric literal")
+		}
+		c.out = append(c.out, c.data[expStart:c.pos]...)
+	}
+	return nil
+}
+
+func (c *converter) digits() {
+	for c.pos < len(c.data) && c.data[c.pos] >= '0' && c.data[c.pos] <= '9' {
+		c.pos++
+	}
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
@@ -0,0 +1,153 @@
+package strictjson5
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"strictjson"
+)
+
+type toolConfig struct {
+	LogLevel string   `json:"logLevel"`
+	Port     int      `json:"port"`
+	Mask     uint32   `json:"mask"`
+	Ratio    float64  `json:"ratio"`
+	Tags     []string `json:"tags"`
+	Note     string   `json:"note"`
+}
+
+const config = `// Tool settings.
+{
+	logLevel: 'debug',
+	port: +8080,
+	mask: 0xFF,
+	ratio: .5,
+	/* trailing commas are fine */
+	tags: ['a', "b",],
+	note: 'it\'s "quoted" \
+and continued',
+}
+`
+
+func TestUnmarshal(t *testing.T) {
+	var cfg toolConfig
+	if err := Unmarshal([]byte(config), &cfg); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := toolConfig{LogLevel: "debug", Port: 8080, Mask: 255, Ratio: 0.5, Tags: []string{"a", "b"}, Note: `it's "quoted" and continued`}
+	if cfg.LogLevel != want.LogLevel || cfg.Port != want.Port || cfg.Mask != want.Mask ||
+		cfg.Ratio != want.Ratio || strings.Join(cfg.Tags, ",") != "a,b" || cfg.Note != want.Note {
+		t.Errorf("Unmarshal() = %+v, want %+v", cfg, want)
+	}
+	if err := Validate([]byte(config), &toolConfig{}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestUnmarshalRejectsMiscasedKeys(t *testing.T) {
+	var cfg toolConfig
+	err := Unmarshal([]byte(`{loglevel: 'debug'}`), &cfg, strictjson.WithSuggestClosest(true))
+	var unknown *strictjson.UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Field() != "loglevel" {
+		t.Fatalf("Expected *UnknownFieldError for loglevel, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "logLevel") {
+		t.Errorf("Expected a suggestion of logLevel, got %v", err)
+	}
+	if err := Validate([]byte(`{'Port': 1}`), &toolConfig{}); !errors.As(err, &unknown) {
+		t.Errorf("Validate() expected *UnknownFieldError, got %v", err)
+	}
+}
+
+func TestToJSON(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`{a: 1, $b_2: 2, ünï: 3}`, `{"a":1,"$b_2":2,"ünï":3}`},
+		{`{\u0061b: 1}`, `{"ab":1}`},
+		{`['it\'s', "say \"hi\"", 'a"b']`, `["it's","say \"hi\"","a\"b"]`},
+		{`'\x41\v\0 \u00e9 \/ \q'`, `"A\u000b\u0000 \u00e9 \/ q"`},
+		{`'tab	here'`, `"tab\u0009here"`},
+		{`[0x10, -0XfF, 0x10000000000000000]`, `[16,-255,18446744073709551616]`},
+		{`[+1, .5, 5., -.5e3, 1.5E+2, 0]`, `[1,0.5,5,-0.5e3,1.5E+2,0]`},
+		{`[true, false, null,]`, `[true,false,null]`},
+		{"\ufeff{} // end", `{}`},
+		{"{a:\u00a01}", `{"a":1}`},
+	}
+	for _, tt := range tests {
+		got, err := ToJSON([]byte(tt.in))
+		if err != nil {
+			t.Errorf("ToJSON(%s) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("ToJSON(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestToJSONErrors(t *testing.T) {
+	tests := []struct {
+		in         string
+		wantOffset int64
+		wantMsg    string
+	}{
+		{`{a: Infinity}`, 4, "strictjson5: Infinity cannot be represented in JSON"},
+		{`[-NaN]`, 1, "strictjson5: NaN cannot be represented in JSON"},
+		{`[01]`, 1, "strictjson5: invalid leading zero in numeric literal"},
+		{`[.]`, 1, `strictjson5: invalid character '.' in numeric literal`},
+		{`{1a: 1}`, 1, `strictjson5: invalid character '1' looking for beginning of object key`},
+		{`{a: 1,,}`, 6, `strictjson5: invalid character ',' looking for beginning of object key`},
+		{`[1,,]`, 3, `strictjson5: invalid character ',' looking for beginning of value`},
+		{"'line\nbreak'", 5, `strictjson5: invalid character '\n' in string literal`},
+		{`'open`, 0, "strictjson5: unterminated string literal"},
+		{`/* open`, 0, "strictjson5: unterminated block comment"},
+		{`{} {}`, 3, `strictjson5: invalid character '{' after top-level value`},
+		{`'\1'`, 1, "strictjson5: invalid escape in string literal"},
+		{`{a: 1`, 5, "strictjson5: unexpected end of JSON5 input"},
+		{``, 0, "strictjson5: unexpected end of JSON5 input"},
+	}
+	for _, tt := range tests {
+		_, err := ToJSON([]byte(tt.in))
+		var se *SyntaxError
+		if !errors.As(err, &se) || se.Offset != tt.wantOffset {
+			t.Errorf("ToJSON(%s): expected *SyntaxError at offset %d, got %v", tt.in, tt.wantOffset, err)
+			continue
+		}
+		if err.Error() != tt.wantMsg {
+			t.Errorf("ToJSON(%s) error = %q, want %q", tt.in, err.Error(), tt.wantMsg)
+		}
+		if !errors.Is(err, strictjson.ErrDecode) {
+			t.Errorf("ToJSON(%s): expected error to wrap ErrDecode", tt.in)
+		}
+	}
+}
+
+func TestToJSONDepth(t *testing.T) {
+	deep := strings.Repeat("[", maxDepth+1) + strings.Repeat("]", maxDepth+1)
+	var se *SyntaxError
+	if _, err := ToJSON([]byte(deep)); !errors.As(err, &se) {
+		t.Errorf("Expected *SyntaxError for deep nesting, got %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.json5")
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var cfg toolConfig
+	if err := LoadFile(path, &cfg); err != nil || cfg.Port != 8080 {
+		t.Errorf("LoadFile() = %+v, %v", cfg, err)
+	}
+	if err := os.WriteFile(path, []byte(`{Port: 1}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	err := LoadFile(path, &cfg)
+	if err == nil || !strings.HasPrefix(err.Error(), path+": ") {
+		t.Errorf("LoadFile() expected an error naming the file, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+// Package strictjsonconfig decodes settings loaded by configuration
+// libraries such as koanf or viper into structs with strictjson's field
+// rules, instead of mapstructure's case-insensitive matching. A config file
+// key "Loglevel" is rejected rather than matched to a LogLevel field.
+//
+//	k := koanf.New(".")
+//	_ = k.Load(file.Provider("config.yaml"), yaml.Parser())
+//	var cfg Config
+//	err := strictjsonconfig.FromKoanf(k, &cfg)
+//
+// The package has no dependency on either library; it relies only on the
+// methods they expose.
+package strictjsonconfig
+
+import (
+	"fmt"
+
+	"strictjson"
+)
+
+// RawProvider is implemented by *koanf.Koanf.
+type RawProvider interface {
+	Raw() map[string]any
+}
+
+// SettingsProvider is implemented by *viper.Viper.
+type SettingsProvider interface {
+	AllSettings() map[string]any
+}
+
+// Decode strictly decodes a nested settings map into v, which must be a
+// non-nil pointer. Field names and errors are those of strictjson.Unmarshal.
+func Decode(settings map[string]any, v any, opts ...strictjson.DecoderOption) error {
+	normalized, err := normalize(settings)
+	if err != nil {
+		return err
+	}
+	return strictjson.NewDecoder(opts...).DecodeMap(normalized.(map[string]any), v)
+}
+
+// FromKoanf decodes all of k's settings into v.
+func FromKoanf(k RawProvider, v any, opts ...strictjson.DecoderOption) error {
+	return Decode(k.Raw(), v, opts...)
+}
+
+// FromViper decodes all of vp's settings into v. Viper lower-cases every
+// key it loads, so the target struct's JSON names must be lower-case too;
+// prefer koanf, which preserves the casing of the file.
+func FromViper(vp SettingsProvider, v any, opts ...strictjson.DecoderOption) error {
+	return Decode(vp.AllSettings(), v, opts...)
+}
+
+// normalize converts the map[any]any values produced by some YAML parsers
+// into map[string]any so that DecodeMap can walk them.
+func normalize(v any) (any, error) {
+	switch m := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			n, err := normalize(val)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = n
+		}
+		return out, nil
+	case map[any]any:
+		out := make(map[string]any, len(m))
+		for k, val := range m {
+			key, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("strictjsonconfig: non-string key %v (%T)", k, k)
+			}
+			n, err := normalize(val)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = n
+		}
+		return out, nil
+	case []any:
+		out := make([]any, len(m))
+		for i, val := range m {
+			n, err := normalize(val)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = n
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
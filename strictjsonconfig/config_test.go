@@ -0,0 +1,72 @@
+package strictjsonconfig
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"strictjson"
+)
+
+type serverConfig struct {
+	LogLevel string        `json:"logLevel"`
+	Timeout  time.Duration `json:"timeout"`
+	Database struct {
+		Hosts []string `json:"hosts"`
+	} `json:"database"`
+}
+
+type fakeKoanf map[string]any
+
+func (k fakeKoanf) Raw() map[string]any { return k }
+
+type fakeViper map[string]any
+
+func (v fakeViper) AllSettings() map[string]any { return v }
+
+func TestDecode(t *testing.T) {
+	settings := map[string]any{
+		"logLevel": "debug",
+		"timeout":  int64(5 * time.Second),
+		"database": map[any]any{"hosts": []any{"a", "b"}},
+	}
+	var cfg serverConfig
+	if err := FromKoanf(fakeKoanf(settings), &cfg); err != nil {
+		t.Fatalf("FromKoanf() unexpected error: %v", err)
+	}
+	if cfg.LogLevel != "debug" || cfg.Timeout != 5*time.Second || len(cfg.Database.Hosts) != 2 {
+		t.Errorf("Unexpected result %+v", cfg)
+	}
+}
+
+func TestDecodeRejectsMiscasedKeys(t *testing.T) {
+	settings := map[string]any{
+		"Loglevel": "debug",
+		"database": map[string]any{"Hosts": []any{"a"}},
+	}
+	var cfg serverConfig
+	err := Decode(settings, &cfg, strictjson.WithSuggestClosest(true))
+	var unknown *strictjson.UnknownFieldError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Expected UnknownFieldError, got %v", err)
+	}
+	if unknown.Field() != "Loglevel" || unknown.Suggestion() != "logLevel" {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func TestFromViper(t *testing.T) {
+	var cfg struct {
+		LogLevel string `json:"loglevel"`
+	}
+	if err := FromViper(fakeViper{"loglevel": "info"}, &cfg); err != nil || cfg.LogLevel != "info" {
+		t.Errorf("Expected loglevel 'info', got %+v (%v)", cfg, err)
+	}
+}
+
+func TestDecodeNonStringKey(t *testing.T) {
+	var cfg serverConfig
+	if err := Decode(map[string]any{"database": map[any]any{1: "x"}}, &cfg); err == nil {
+		t.Error("Expected error for non-string key")
+	}
+}
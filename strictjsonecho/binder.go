@@ -0,0 +1,75 @@
+// Package strictjsonecho provides an echo.Binder backed by strictjson:
+//
+//	e := echo.New()
+//	e.Binder = strictjsonecho.New(strictjson.WithSuggestClosest(true))
+//
+// It lives in its own module so that the strictjson module does not depend
+// on Echo.
+package strictjsonecho
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"strictjson"
+)
+
+// Binder binds path and query parameters like echo.DefaultBinder and decodes
+// JSON request bodies with a strictjson.Decoder. Non-JSON bodies are left to
+// echo.DefaultBinder.
+type Binder struct {
+	decoder *strictjson.Decoder
+	echo.DefaultBinder
+}
+
+var _ echo.Binder = (*Binder)(nil)
+
+// New returns a Binder whose decoder is configured by opts.
+func New(opts ...strictjson.DecoderOption) *Binder {
+	return &Binder{decoder: strictjson.NewDecoder(opts...)}
+}
+
+// Bind implements echo.Binder.
+func (b *Binder) Bind(i any, c echo.Context) error {
+	if err := b.BindPathParams(c, i); err != nil {
+		return err
+	}
+	req := c.Request()
+	switch req.Method {
+	case http.MethodGet, http.MethodDelete, http.MethodHead:
+		if err := b.BindQueryParams(c, i); err != nil {
+			return err
+		}
+	}
+	if req.ContentLength == 0 {
+		return nil
+	}
+	if !isJSON(req.Header.Get(echo.HeaderContentType)) {
+		return b.BindBody(c, i)
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	if err := b.decoder.Unmarshal(data, i); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return nil
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json")
+}
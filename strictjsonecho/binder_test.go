@@ -0,0 +1,67 @@
+package strictjsonecho
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"strictjson"
+)
+
+type updateUser struct {
+	ID   int    `param:"id"`
+	Name string `json:"name"`
+}
+
+func newContext(method, target, contentType, body string) echo.Context {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues("42")
+	return c
+}
+
+func TestBind(t *testing.T) {
+	b := New()
+	var u updateUser
+	c := newContext(http.MethodPut, "/users/42", echo.MIMEApplicationJSON, `{"name": "Ann"}`)
+	if err := b.Bind(&u, c); err != nil {
+		t.Fatalf("Bind() unexpected error: %v", err)
+	}
+	if u.ID != 42 || u.Name != "Ann" {
+		t.Errorf("Expected path param and body to be bound, got %+v", u)
+	}
+}
+
+func TestBindRejects(t *testing.T) {
+	b := New(strictjson.WithSuggestClosest(true))
+	var u updateUser
+	err := b.Bind(&u, newContext(http.MethodPut, "/users/42", echo.MIMEApplicationJSON, `{"Name": "Ann"}`))
+
+	var he *echo.HTTPError
+	if !errors.As(err, &he) || he.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 HTTPError, got %v", err)
+	}
+	var unknown *strictjson.UnknownFieldError
+	if !errors.As(he.Internal, &unknown) || unknown.Suggestion() != "name" {
+		t.Errorf("Expected internal UnknownFieldError suggesting 'name', got %v", he.Internal)
+	}
+}
+
+func TestBindNonJSON(t *testing.T) {
+	var u struct {
+		Name string `form:"name"`
+	}
+	c := newContext(http.MethodPost, "/", echo.MIMEApplicationForm, `name=Ann`)
+	if err := New().Bind(&u, c); err != nil || u.Name != "Ann" {
+		t.Errorf("Expected form body to use the default binder, got %+v (%v)", u, err)
+	}
+}
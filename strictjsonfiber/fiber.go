@@ -0,0 +1,18 @@
+// Package strictjsonfiber plugs strictjson into Fiber's body parser:
+//
+//	app := fiber.New(fiber.Config{
+//		JSONDecoder: strictjsonfiber.Decoder(strictjson.WithSuggestClosest(true)),
+//	})
+//
+// With that configuration c.BodyParser decodes JSON bodies strictly. The
+// returned function has the signature of Fiber's utils.JSONUnmarshal, so this
+// package does not depend on Fiber.
+package strictjsonfiber
+
+import "strictjson"
+
+// Decoder returns a JSON decoding function backed by a strictjson.Decoder
+// configured by opts.
+func Decoder(opts ...strictjson.DecoderOption) func(data []byte, v any) error {
+	return strictjson.NewDecoder(opts...).Unmarshal
+}
@@ -0,0 +1,28 @@
+package strictjsonfiber
+
+import (
+	"errors"
+	"testing"
+
+	"strictjson"
+)
+
+// jsonUnmarshal mirrors Fiber's utils.JSONUnmarshal.
+type jsonUnmarshal = func(data []byte, v interface{}) error
+
+func TestDecoder(t *testing.T) {
+	var decode jsonUnmarshal = Decoder(strictjson.WithSuggestClosest(true))
+
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := decode([]byte(`{"name": "a"}`), &v); err != nil || v.Name != "a" {
+		t.Errorf("Expected name 'a', got %+v (%v)", v, err)
+	}
+
+	err := decode([]byte(`{"Name": "a"}`), &v)
+	var unknown *strictjson.UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Suggestion() != "name" {
+		t.Errorf("Expected UnknownFieldError suggesting 'name', got %v", err)
+	}
+}
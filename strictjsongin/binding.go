@@ -0,0 +1,101 @@
+// Package strictjsongin provides a Gin binding backed by strictjson:
+//
+//	var req CreateUserRequest
+//	if err := c.ShouldBindWith(&req, strictjsongin.JSON); err != nil {
+//		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+//		return
+//	}
+//
+// The binding satisfies gin's binding.Binding and binding.BindingBody
+// interfaces structurally, so this package does not depend on Gin.
+package strictjsongin
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"strictjson"
+)
+
+// JSON is a strict binding with did-you-mean suggestions enabled. Unlike
+// Gin's own JSON binding it does not run struct validation; set Validate to
+// gin's binding.Validator.ValidateStruct to keep `binding:"required"` tags
+// working:
+//
+//	strictjsongin.JSON.Validate = binding.Validator.ValidateStruct
+var JSON = New(strictjson.WithSuggestClosest(true))
+
+// Binding decodes request bodies with a strictjson.Decoder.
+type Binding struct {
+	decoder *strictjson.Decoder
+	// Validate, when set, is called with the bound object after a
+	// successful decode.
+	Validate func(obj any) error
+}
+
+// New returns a Binding whose decoder is configured by opts.
+func New(opts ...strictjson.DecoderOption) *Binding {
+	return &Binding{decoder: strictjson.NewDecoder(opts...)}
+}
+
+// Name implements binding.Binding.
+func (b *Binding) Name() string {
+	return "json"
+}
+
+// Bind implements binding.Binding.
+func (b *Binding) Bind(req *http.Request, obj any) error {
+	if req == nil || req.Body == nil {
+		return errors.New("strictjsongin: invalid request")
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	return b.BindBody(body, obj)
+}
+
+// BindBody implements binding.BindingBody.
+func (b *Binding) BindBody(body []byte, obj any) error {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return errors.New("strictjsongin: request body is empty")
+	}
+	violations, err := b.decoder.Check(body, obj)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return &BindError{Violations: violations}
+	}
+	if b.Validate != nil {
+		return b.Validate(obj)
+	}
+	return nil
+}
+
+// BindError lists every strict-rule violation found in a request body. Each
+// message carries the did-you-mean suggestion when one was found.
+type BindError struct {
+	Violations []strictjson.Violation
+}
+
+func (e *BindError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of all violations, so errors.As finds e.g. the
+// first *strictjson.UnknownFieldError.
+func (e *BindError) Unwrap() []error {
+	errs := make([]error, len(e.Violations))
+	for i, v := range e.Violations {
+		errs[i] = v.Err
+	}
+	return errs
+}
@@ -0,0 +1,85 @@
+package strictjsongin
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"strictjson"
+)
+
+// ginBinding mirrors gin's binding.Binding and binding.BindingBody.
+type ginBinding interface {
+	Name() string
+	Bind(*http.Request, any) error
+	BindBody([]byte, any) error
+}
+
+var _ ginBinding = JSON
+
+type loginRequest struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+}
+
+func TestBind(t *testing.T) {
+	var req loginRequest
+	r := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"user": "a", "password": "b"}`))
+	if err := JSON.Bind(r, &req); err != nil {
+		t.Fatalf("Bind() unexpected error: %v", err)
+	}
+	if req.User != "a" || req.Password != "b" {
+		t.Errorf("Unexpected result %+v", req)
+	}
+}
+
+func TestBindViolations(t *testing.T) {
+	var req loginRequest
+	err := JSON.BindBody([]byte(`{"User": "a", "pasword": "b"}`), &req)
+
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) || len(bindErr.Violations) != 2 {
+		t.Fatalf("Expected BindError with two violations, got %v", err)
+	}
+	want := `strictjson: unknown field "User" (did you mean "user"?); strictjson: unknown field "pasword" (did you mean "password"?)`
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	var unknown *strictjson.UnknownFieldError
+	if !errors.As(err, &unknown) || unknown.Field() != "User" {
+		t.Errorf("Expected first UnknownFieldError to be reachable, got %v", unknown)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	var req loginRequest
+	if err := JSON.BindBody([]byte(` `), &req); err == nil {
+		t.Error("Expected error for empty body")
+	}
+	if err := JSON.BindBody([]byte(`{"user": `), &req); !errors.Is(err, strictjson.ErrDecode) {
+		t.Errorf("Expected syntax error, got %v", err)
+	}
+	if err := JSON.Bind(nil, &req); err == nil {
+		t.Error("Expected error for nil request")
+	}
+}
+
+func TestBindValidate(t *testing.T) {
+	required := errors.New("password is required")
+	b := New()
+	b.Validate = func(obj any) error {
+		if obj.(*loginRequest).Password == "" {
+			return required
+		}
+		return nil
+	}
+	var req loginRequest
+	if err := b.BindBody([]byte(`{"user": "a"}`), &req); err != required {
+		t.Errorf("Expected validation error, got %v", err)
+	}
+	if err := b.BindBody([]byte(`{"user": "a", "password": "b"}`), &req); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Package strictjsonhttp decodes HTTP request bodies with strictjson and
+// reports failures as RFC 7807 problem details.
+package strictjsonhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"strictjson"
+)
+
+// MaxBodyBytes caps the size of request bodies read by DecodeRequest.
+var MaxBodyBytes int64 = 1 << 20
+
+// Problem is an RFC 7807 problem details object. Errors lists one entry per
+// offending location in the request body.
+type Problem struct {
+	Type   string         `json:"type"`
+	Title  string         `json:"title"`
+	Status int            `json:"status"`
+	Detail string         `json:"detail,omitempty"`
+	Errors []ProblemError `json:"errors,omitempty"`
+}
+
+// ProblemError describes one rejected location in a request body.
+type ProblemError struct {
+	Path       string `json:"path"`
+	Field      string `json:"field,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Detail     string `json:"detail"`
+}
+
+// RequestError is returned by DecodeRequest after it has written the problem
+// response. Err is the underlying decode error.
+type RequestError struct {
+	Problem Problem
+	Err     error
+}
+
+func (e *RequestError) Error() string {
+	if e.Err != nil {
+		return "strictjsonhttp: " + e.Err.Error()
+	}
+	return "strictjsonhttp: " + e.Problem.Detail
+}
+
+func (e *RequestError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeRequest strictly decodes the JSON body of r into v. The request must
+// have a JSON Content-Type (application/json or a +json type) and a body of
+// at most MaxBodyBytes. On failure DecodeRequest writes an
+// application/problem+json response to w listing every offending path and
+// returns a *RequestError; the caller should simply return.
+func DecodeRequest(w http.ResponseWriter, r *http.Request, v any, opts ...strictjson.DecoderOption) error {
+	data, p, err := readBody(w, r)
+	if p == nil {
+		p, err = checkBody(data, v, strictjson.NewDecoder(opts...))
+	}
+	if p == nil {
+		return nil
+	}
+	WriteProblem(w, *p)
+	return &RequestError{Problem: *p, Err: err}
+}
+
+// readBody reads the JSON body of r, returning the problem to report if it
+// cannot be accepted.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, *Problem, error) {
+	if !isJSON(r.Header.Get("Content-Type")) {
+		return nil, newProblem(http.StatusUnsupportedMediaType, "Content-Type must be application/json"), nil
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, newProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", MaxBodyBytes)), err
+		}
+		return nil, newProblem(http.StatusBadRequest, "failed to read request body"), err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, newProblem(http.StatusBadRequest, "request body is empty"), nil
+	}
+	return data, nil, nil
+}
+
+// checkBody strictly decodes data into v, returning the problem to report if
+// it does not match.
+func checkBody(data []byte, v any, d *strictjson.Decoder) (*Problem, error) {
+	violations, err := d.Check(data, v)
+	if err == nil && len(violations) == 0 {
+		return nil, nil
+	}
+
+	p := newProblem(http.StatusBadRequest, "request body does not match the expected schema")
+	for _, vi := range violations {
+		p.Errors = append(p.Errors, ProblemError{
+			Path:       vi.Path,
+			Field:      vi.Field,
+			Suggestion: vi.Suggestion,
+			Detail:     vi.Err.Error(),
+		})
+	}
+	if err != nil {
+		p.Errors = append(p.Errors, ProblemError{Path: errorPath(err), Detail: err.Error()})
+	} else {
+		err = violations[0].Err
+	}
+	return p, err
+}
+
+// errorPath returns the document path of a decode error, if it has one.
+func errorPath(err error) string {
+	var typeErr *strictjson.TypeMismatchError
+	if errors.As(err, &typeErr) {
+		return typeErr.Path()
+	}
+	return ""
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mt == "application/json" || strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+json")
+}
+
+func newProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// WriteProblem writes p as an application/problem+json response.
+func WriteProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
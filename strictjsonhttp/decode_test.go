@@ -0,0 +1,126 @@
+package strictjsonhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"strictjson"
+)
+
+type createUser struct {
+	Name    string `json:"name"`
+	Age     int    `json:"age"`
+	Address struct {
+		City string `json:"city"`
+	} `json:"address"`
+}
+
+func newRequest(contentType, body string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(body))
+	if contentType != "" {
+		r.Header.Set("Content-Type", contentType)
+	}
+	return r
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("Expected problem+json response, got %q", ct)
+	}
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestDecodeRequest(t *testing.T) {
+	w := httptest.NewRecorder()
+	var u createUser
+	r := newRequest("application/json; charset=utf-8", `{"name": "Ann", "age": 30, "address": {"city": "Oslo"}}`)
+	if err := DecodeRequest(w, r, &u); err != nil {
+		t.Fatalf("DecodeRequest() unexpected error: %v", err)
+	}
+	if u.Name != "Ann" || u.Address.City != "Oslo" {
+		t.Errorf("Unexpected result %+v", u)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("Expected nothing written, got %q", w.Body.String())
+	}
+
+	if err := DecodeRequest(httptest.NewRecorder(), newRequest("application/vnd.api+json", `{}`), &u); err != nil {
+		t.Errorf("Expected +json content type to be accepted, got %v", err)
+	}
+}
+
+func TestDecodeRequestViolations(t *testing.T) {
+	w := httptest.NewRecorder()
+	var u createUser
+	r := newRequest("application/json", `{"Name": "Ann", "address": {"citty": "Oslo"}}`)
+	err := DecodeRequest(w, r, &u, strictjson.WithSuggestClosest(true))
+
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("Expected RequestError, got %v", err)
+	}
+	var unknown *strictjson.UnknownFieldError
+	if !errors.As(err, &unknown) {
+		t.Errorf("Expected the first violation to be unwrappable, got %v", err)
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", w.Code)
+	}
+
+	p := decodeProblem(t, w)
+	if p.Status != http.StatusBadRequest || p.Title != "Bad Request" || len(p.Errors) != 2 {
+		t.Fatalf("Unexpected problem %+v", p)
+	}
+	if p.Errors[0].Path != "Name" || p.Errors[0].Suggestion != "name" {
+		t.Errorf("Unexpected first error %+v", p.Errors[0])
+	}
+	if p.Errors[1].Path != "address.citty" || p.Errors[1].Suggestion != "city" {
+		t.Errorf("Unexpected second error %+v", p.Errors[1])
+	}
+}
+
+func TestDecodeRequestRejections(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		path        string
+	}{
+		{"missing content type", "", `{}`, http.StatusUnsupportedMediaType, ""},
+		{"wrong content type", "text/plain", `{}`, http.StatusUnsupportedMediaType, ""},
+		{"empty body", "application/json", "  ", http.StatusBadRequest, ""},
+		{"syntax error", "application/json", `{"name": }`, http.StatusBadRequest, ""},
+		{"type mismatch", "application/json", `{"age": "old"}`, http.StatusBadRequest, "age"},
+		{"too large", "application/json", `{"name": "` + strings.Repeat("x", 64) + `"}`, http.StatusRequestEntityTooLarge, ""},
+	}
+
+	defer func(n int64) { MaxBodyBytes = n }(MaxBodyBytes)
+	MaxBodyBytes = 32
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			var u createUser
+			if err := DecodeRequest(w, newRequest(tt.contentType, tt.body), &u); err == nil {
+				t.Fatal("Expected error")
+			}
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			p := decodeProblem(t, w)
+			if tt.path != "" && (len(p.Errors) != 1 || p.Errors[0].Path != tt.path) {
+				t.Errorf("Expected one error at %q, got %+v", tt.path, p.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package strictjsonhttp
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"strictjson"
+)
+
+// Guard rejects request bodies that do not strictly match the type
+// registered for their route before the wrapped handler runs. Create one with
+// Middleware.
+type Guard struct {
+	routes []*route
+	d      *strictjson.Decoder
+}
+
+// route is one registered pattern and its counters.
+type route struct {
+	pattern  string
+	method   string // "" matches POST, PUT and PATCH
+	segments []string
+	prefix   bool // pattern ends in "/" and matches everything below it
+	typ      reflect.Type
+
+	checked    atomic.Int64
+	rejected   atomic.Int64
+	violations atomic.Int64
+}
+
+// RouteStats counts the requests a Guard has inspected for one route.
+type RouteStats struct {
+	Checked    int64 // bodies decoded
+	Rejected   int64 // bodies answered with a problem response
+	Violations int64 // individual errors reported across rejected bodies
+}
+
+// Middleware returns a Guard that validates request bodies per route.
+// routeTypes maps patterns to a value or nil pointer of the expected body
+// type, e.g.
+//
+//	guard := strictjsonhttp.Middleware(map[string]any{
+//		"POST /users":        CreateUser{},
+//		"PATCH /users/{id}":  (*UpdateUser)(nil),
+//	})
+//	http.ListenAndServe(addr, guard.Handler(mux))
+//
+// A pattern is an optional method followed by a path. "{name}" matches any
+// single path segment and a trailing "/" matches the whole subtree. Patterns
+// without a method apply to POST, PUT and PATCH requests. When several
+// patterns match, the longest one wins.
+//
+// Rejected requests get the same problem response as DecodeRequest. Accepted
+// bodies are passed on to the handler unchanged.
+func Middleware(routeTypes map[string]any, opts ...strictjson.DecoderOption) *Guard {
+	g := &Guard{d: strictjson.NewDecoder(opts...)}
+	for pattern, v := range routeTypes {
+		g.routes = append(g.routes, newRoute(pattern, v))
+	}
+	sort.Slice(g.routes, func(i, j int) bool {
+		a, b := g.routes[i], g.routes[j]
+		if len(a.segments) != len(b.segments) {
+			return len(a.segments) > len(b.segments)
+		}
+		if a.method != b.method {
+			return a.method != ""
+		}
+		return a.pattern < b.pattern
+	})
+	return g
+}
+
+func newRoute(pattern string, v any) *route {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		panic("strictjsonhttp: nil body type for route " + pattern)
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	r := &route{pattern: pattern, typ: t}
+	path := pattern
+	if method, rest, ok := strings.Cut(pattern, " "); ok {
+		r.method, path = method, strings.TrimSpace(rest)
+	}
+	if !strings.HasPrefix(path, "/") {
+		panic("strictjsonhttp: invalid route pattern " + pattern)
+	}
+	r.prefix = strings.HasSuffix(path, "/") && path != "/"
+	r.segments = strings.Split(strings.Trim(path, "/"), "/")
+	return r
+}
+
+func (r *route) matches(req *http.Request) bool {
+	switch r.method {
+	case "":
+		if req.Method != http.MethodPost && req.Method != http.MethodPut && req.Method != http.MethodPatch {
+			return false
+		}
+	default:
+		if req.Method != r.method {
+			return false
+		}
+	}
+
+	segs := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segs) < len(r.segments) || !r.prefix && len(segs) != len(r.segments) {
+		return false
+	}
+	for i, s := range r.segments {
+		if s != segs[i] && !(strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}")) {
+			return false
+		}
+	}
+	return true
+}
+
+// Handler wraps next so that bodies of registered routes are validated first.
+func (g *Guard) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rt := g.match(req)
+		if rt == nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		rt.checked.Add(1)
+		data, p, _ := readBody(w, req)
+		if p == nil {
+			p, _ = checkBody(data, reflect.New(rt.typ).Interface(), g.d)
+		}
+		if p != nil {
+			rt.rejected.Add(1)
+			if n := len(p.Errors); n > 0 {
+				rt.violations.Add(int64(n))
+			} else {
+				rt.violations.Add(1)
+			}
+			WriteProblem(w, *p)
+			return
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		next.ServeHTTP(w, req)
+	})
+}
+
+func (g *Guard) match(req *http.Request) *route {
+	for _, rt := range g.routes {
+		if rt.matches(req) {
+			return rt
+		}
+	}
+	return nil
+}
+
+// Stats returns a snapshot of the counters of every registered route, keyed
+// by pattern.
+func (g *Guard) Stats() map[string]RouteStats {
+	stats := make(map[string]RouteStats, len(g.routes))
+	for _, rt := range g.routes {
+		stats[rt.pattern] = RouteStats{
+			Checked:    rt.checked.Load(),
+			Rejected:   rt.rejected.Load(),
+			Violations: rt.violations.Load(),
+		}
+	}
+	return stats
+}
@@ -0,0 +1,92 @@
+package strictjsonhttp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type updateUser struct {
+	Name string `json:"name"`
+}
+
+func TestMiddleware(t *testing.T) {
+	guard := Middleware(map[string]any{
+		"POST /users":       createUser{},
+		"PATCH /users/{id}": (*updateUser)(nil),
+		"/admin/":           updateUser{},
+	})
+
+	var gotBody string
+	handler := guard.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		gotBody = ""
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		method, path, body string
+		status             int
+	}{
+		{"POST", "/users", `{"name": "a", "age": 1}`, http.StatusNoContent},
+		{"POST", "/users", `{"Name": "a"}`, http.StatusBadRequest},
+		{"PATCH", "/users/42", `{"name": "b"}`, http.StatusNoContent},
+		{"PATCH", "/users/42", `{"nmae": "b"}`, http.StatusBadRequest},
+		{"PATCH", "/users/42/extra", `{"nmae": "b"}`, http.StatusNoContent}, // unregistered
+		{"GET", "/users", `not json`, http.StatusNoContent},                 // method not registered
+		{"PUT", "/admin/settings/x", `{"bad": 1}`, http.StatusBadRequest},
+		{"GET", "/admin/settings", ``, http.StatusNoContent}, // no method: bodies only
+	}
+	for _, tt := range tests {
+		w := serve(tt.method, tt.path, tt.body)
+		if w.Code != tt.status {
+			t.Errorf("%s %s %s: expected status %d, got %d", tt.method, tt.path, tt.body, tt.status, w.Code)
+		}
+		if tt.status == http.StatusNoContent && gotBody != tt.body {
+			t.Errorf("%s %s: expected handler to see body %q, got %q", tt.method, tt.path, tt.body, gotBody)
+		}
+	}
+
+	stats := guard.Stats()
+	want := map[string]RouteStats{
+		"POST /users":       {Checked: 2, Rejected: 1, Violations: 1},
+		"PATCH /users/{id}": {Checked: 2, Rejected: 1, Violations: 1},
+		"/admin/":           {Checked: 1, Rejected: 1, Violations: 1},
+	}
+	for pattern, s := range want {
+		if stats[pattern] != s {
+			t.Errorf("Stats()[%q] = %+v, want %+v", pattern, stats[pattern], s)
+		}
+	}
+}
+
+func TestMiddlewareLongestMatch(t *testing.T) {
+	guard := Middleware(map[string]any{
+		"/api/":            updateUser{},
+		"POST /api/users/": createUser{},
+	})
+	r := httptest.NewRequest("POST", "/api/users/1", nil)
+	if rt := guard.match(r); rt == nil || rt.pattern != "POST /api/users/" {
+		t.Errorf("Expected the longer pattern to win, got %+v", rt)
+	}
+}
+
+func TestMiddlewareInvalidPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for pattern without leading slash")
+		}
+	}()
+	Middleware(map[string]any{"POST users": createUser{}})
+}
@@ -0,0 +1,484 @@
+// Package strictjsonlint provides an analysis.Analyzer that flags struct
+// types which strictjson will reject, or decode surprisingly, at runtime:
+//
+//	go install strictjson/strictjsonlint/cmd/strictjson-lint
+//	go vet -vettool=$(which strictjson-lint) ./...
+//
+// It reports
+//
+//   - strict tags that are malformed, name unknown options, or name options
+//     that do not apply to the field's type, which make strictjson return an
+//     *InvalidTagError;
+//   - JSON names provided by several fields at the same depth, usually
+//     through embedded structs, which make strictjson return a
+//     *FieldConflictError;
+//   - exported fields without a json tag in types passed to strictjson,
+//     which match only a key spelled exactly like the Go field name.
+//
+// Tag and conflict checks run on struct types declared with strict tags and
+// on every type passed to a strictjson function such as Unmarshal,
+// Decoder.Unmarshal or UnmarshalAs.
+//
+// It lives in its own module so that the strictjson module does not depend
+// on golang.org/x/tools.
+package strictjsonlint
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+// Analyzer checks struct tags and field tables for strictjson.
+var Analyzer = &analysis.Analyzer{
+	Name:     "strictjson",
+	Doc:      "check struct types for strictjson tag and field-name problems",
+	URL:      "https://pkg.go.dev/strictjson/strictjsonlint",
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// genericEntryPoints are the strictjson functions whose type argument, not
+// a v any argument, is the decoded type.
+var genericEntryPoints = map[string]bool{
+	"UnmarshalAs":  true,
+	"DecodeAs":     true,
+	"ValidateType": true,
+	"CompileFor":   true,
+}
+
+type checker struct {
+	pass *analysis.Pass
+	// checked records the struct types whose tags and field tables have
+	// been checked, and untagged the types checked for untagged fields.
+	checked  map[*types.Struct]bool
+	untagged map[types.Type]bool
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	c := &checker{
+		pass:     pass,
+		checked:  make(map[*types.Struct]bool),
+		untagged: make(map[types.Type]bool),
+	}
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.StructType)(nil), (*ast.CallExpr)(nil)}, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.StructType:
+			st, ok := pass.TypesInfo.TypeOf(n).(*types.Struct)
+			if ok && hasStrictTag(st) {
+				c.checkStruct(n, st)
+			}
+		case *ast.CallExpr:
+			c.checkCall(n)
+		}
+	})
+	return nil, nil
+}
+
+// isStrictJSON reports whether pkg is the strictjson package.
+func isStrictJSON(pkg *types.Package) bool {
+	return pkg != nil && (pkg.Path() == "strictjson" || strings.HasSuffix(pkg.Path(), "/strictjson"))
+}
+
+// checkCall checks the types passed to a strictjson function: arguments
+// for parameters v of type any, and the type arguments of the generic
+// entry points.
+func (c *checker) checkCall(call *ast.CallExpr) {
+	fun := ast.Unparen(call.Fun)
+	if index, ok := fun.(*ast.IndexExpr); ok {
+		fun = index.X
+	} else if index, ok := fun.(*ast.IndexListExpr); ok {
+		fun = index.X
+	}
+	var id *ast.Ident
+	switch f := fun.(type) {
+	case *ast.Ident:
+		id = f
+	case *ast.SelectorExpr:
+		id = f.Sel
+	default:
+		return
+	}
+	fn, ok := c.pass.TypesInfo.Uses[id].(*types.Func)
+	if !ok || !isStrictJSON(fn.Pkg()) {
+		return
+	}
+
+	if genericEntryPoints[fn.Name()] {
+		if inst, ok := c.pass.TypesInfo.Instances[id]; ok && inst.TypeArgs.Len() > 0 {
+			c.checkTarget(call, inst.TypeArgs.At(0))
+		}
+		return
+	}
+	sig := fn.Type().(*types.Signature)
+	params := sig.Params()
+	for i := 0; i < params.Len() && i < len(call.Args); i++ {
+		p := params.At(i)
+		if p.Name() != "v" || !isEmptyInterface(p.Type()) {
+			continue
+		}
+		if t := c.pass.TypesInfo.TypeOf(call.Args[i]); t != nil {
+			c.checkTarget(call.Args[i], t)
+		}
+	}
+}
+
+func isEmptyInterface(t types.Type) bool {
+	iface, ok := t.Underlying().(*types.Interface)
+	return ok && iface.Empty()
+}
+
+// checkTarget checks t, a type passed to strictjson, and the types of its
+// fields, reporting problems at node.
+func (c *checker) checkTarget(node ast.Node, t types.Type) {
+	if c.untagged[t] {
+		return
+	}
+	c.untagged[t] = true
+
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		c.checkTarget(node, u.Elem())
+	case *types.Slice:
+		c.checkTarget(node, u.Elem())
+	case *types.Array:
+		c.checkTarget(node, u.Elem())
+	case *types.Map:
+		c.checkTarget(node, u.Elem())
+	case *types.Struct:
+		if named, ok := t.(*types.Named); ok && (isStrictJSON(named.Obj().Pkg()) || decodesItself(t)) {
+			return
+		}
+		// Structs of this package with strict tags are checked where they
+		// are declared.
+		if !c.checked[u] && !(hasStrictTag(u) && declaredIn(t, c.pass.Pkg)) {
+			c.checkStruct(node, u)
+		}
+		c.checkUntagged(node, t, u)
+	}
+}
+
+// decodesItself reports whether t takes over its own decoding, so that
+// its fields are not matched to keys.
+func decodesItself(t types.Type) bool {
+	ptr := types.NewPointer(t)
+	for _, name := range []string{"UnmarshalJSON", "UnmarshalText", "UnmarshalStrictJSON"} {
+		obj, _, _ := types.LookupFieldOrMethod(ptr, false, nil, name)
+		if _, ok := obj.(*types.Func); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// checkUntagged reports the exported fields of st, the underlying struct of
+// t, that no json tag names, and checks the types of all its fields.
+func (c *checker) checkUntagged(node ast.Node, t types.Type, st *types.Struct) {
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		tag := reflect.StructTag(st.Tag(i))
+		if tag.Get("json") == "-" || parseStrict(tag.Get("strict")).remain {
+			continue
+		}
+		ft := f.Type()
+		if p, ok := ft.(*types.Pointer); ok {
+			ft = p.Elem()
+		}
+		if f.Anonymous() {
+			if _, isStruct := ft.Underlying().(*types.Struct); isStruct {
+				c.checkTarget(node, f.Type())
+				continue
+			}
+		}
+		if !f.Exported() {
+			continue
+		}
+		if _, tagged := tag.Lookup("json"); !tagged {
+			c.pass.ReportRangef(node, "field %s of %s has no json tag; strictjson matches it only to the key %q",
+				f.Name(), typeName(c.pass, t), f.Name())
+		}
+		c.checkTarget(node, f.Type())
+	}
+}
+
+// declaredIn reports whether t is declared in pkg, taking anonymous
+// structs to be.
+func declaredIn(t types.Type, pkg *types.Package) bool {
+	named, ok := t.(*types.Named)
+	return !ok || named.Obj().Pkg() == pkg
+}
+
+func typeName(pass *analysis.Pass, t types.Type) string {
+	return types.TypeString(t, types.RelativeTo(pass.Pkg))
+}
+
+// hasStrictTag reports whether a field of st has a strict tag.
+func hasStrictTag(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if strings.Contains(st.Tag(i), "strict:") {
+			return true
+		}
+	}
+	return false
+}
+
+// checkStruct reports the tag problems of the fields of st and the JSON
+// names its field table finds ambiguous.
+func (c *checker) checkStruct(node ast.Node, st *types.Struct) {
+	c.checked[st] = true
+	var fields *ast.FieldList
+	if n, ok := node.(*ast.StructType); ok {
+		fields = n.Fields
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		at := node
+		if fields != nil {
+			at = fieldNode(fields, i)
+		}
+		f, tag := st.Field(i), st.Tag(i)
+		if strings.Contains(tag, "strict:") {
+			if _, ok := reflect.StructTag(tag).Lookup("strict"); !ok {
+				c.pass.ReportRangef(at, "malformed struct tag on field %s: %s", f.Name(), tag)
+				continue
+			}
+		}
+		if reason := checkStrictTag(f.Type(), reflect.StructTag(tag).Get("strict")); reason != "" {
+			c.pass.ReportRangef(at, "invalid strict tag on field %s: %s", f.Name(), reason)
+		}
+	}
+	for _, conflict := range fieldConflicts(st) {
+		c.pass.ReportRangef(node, "JSON name %q is provided by %s at the same depth; strictjson returns a *FieldConflictError",
+			conflict.name, strings.Join(conflict.sources, " and "))
+	}
+}
+
+// fieldNode returns the node of the i-th field of fields, counting each
+// name of a multi-name field.
+func fieldNode(fields *ast.FieldList, i int) ast.Node {
+	for _, f := range fields.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		if i < n {
+			return f
+		}
+		i -= n
+	}
+	return fields
+}
+
+// strictOptions holds the options of a strict tag, as strictjson parses
+// them.
+type strictOptions struct {
+	remain      bool
+	format      string
+	oneof       bool
+	constraints []string
+	def         string
+	hasDefault  bool
+	unknown     []string
+}
+
+var base64Options = map[string]bool{"b64": true, "b64url": true, "b64raw": true, "b64rawurl": true}
+
+func parseStrict(tag string) strictOptions {
+	var opts strictOptions
+	for tag != "" {
+		var opt string
+		opt, tag, _ = strings.Cut(tag, ",")
+		name, _, _ := strings.Cut(opt, "=")
+		switch {
+		case opt == "" || opt == "nocase" || opt == "lenient" || opt == "strict":
+		case opt == "remain":
+			opts.remain = true
+		case opt == "nonzero" || opt == "nonempty" || name == "min" || name == "max" || name == "maxlen":
+			opts.constraints = append(opts.constraints, opt)
+		case name == "default":
+			opts.def, opts.hasDefault = strings.TrimPrefix(opt, "default="), true
+		case name == "oneof":
+			opts.oneof = true
+		case name == "time" || base64Options[opt]:
+			opts.format = opt
+		default:
+			opts.unknown = append(opts.unknown, opt)
+		}
+	}
+	return opts
+}
+
+// checkStrictTag returns why the strict tag does not apply to a field of
+// type t, or "" if it does.
+func checkStrictTag(t types.Type, tag string) string {
+	opts := parseStrict(tag)
+	if len(opts.unknown) > 0 {
+		return fmt.Sprintf("unknown option %q", opts.unknown[0])
+	}
+	if opts.remain {
+		if !isRawMessageMap(t) {
+			return `strict:",remain" requires type map[string]json.RawMessage`
+		}
+		return ""
+	}
+	ft := t
+	if p, ok := ft.(*types.Pointer); ok {
+		ft = p.Elem()
+	}
+	switch {
+	case base64Options[opts.format]:
+		if !isBytes(ft) {
+			return fmt.Sprintf(`strict:"%s" requires type []byte`, opts.format)
+		}
+	case opts.format != "":
+		if !isNamed(ft, "time", "Time") {
+			return `strict:"time=..." requires type time.Time`
+		}
+	}
+	if opts.oneof && !isKind(ft, types.IsString) {
+		return `strict:"oneof=..." requires a string type`
+	}
+	elem := indirect(t)
+	for _, opt := range opts.constraints {
+		if reason := checkConstraint(elem, opt); reason != "" {
+			return reason
+		}
+	}
+	if opts.hasDefault {
+		if reason := checkDefault(elem, opts.def); reason != "" {
+			return reason
+		}
+	}
+	return ""
+}
+
+// checkConstraint returns why the constraint option opt does not apply to
+// a field of type t, which is not a pointer, or "".
+func checkConstraint(t types.Type, opt string) string {
+	if opt == "nonzero" {
+		return ""
+	}
+	if opt == "nonempty" {
+		if !hasLength(t) {
+			return `strict:"nonempty" requires a string, slice or map type`
+		}
+		return ""
+	}
+	kind, arg, _ := strings.Cut(opt, "=")
+	invalid := func(reason string) string {
+		return fmt.Sprintf(`strict:"%s" %s`, opt, reason)
+	}
+	if kind == "maxlen" {
+		if !hasLength(t) {
+			return invalid("requires a string, slice or map type")
+		}
+		if n, err := strconv.Atoi(arg); err != nil || n < 0 {
+			return invalid("requires a non-negative integer")
+		}
+		return ""
+	}
+	switch {
+	case isKind(t, types.IsUnsigned):
+		if _, err := strconv.ParseUint(arg, 10, 64); err != nil {
+			return invalid("requires a non-negative integer")
+		}
+	case isKind(t, types.IsInteger):
+		if _, err := strconv.ParseInt(arg, 10, 64); err != nil {
+			return invalid("requires an integer")
+		}
+	case isKind(t, types.IsFloat):
+		if _, err := strconv.ParseFloat(arg, 64); err != nil {
+			return invalid("requires a number")
+		}
+	default:
+		return invalid("requires a numeric type")
+	}
+	return ""
+}
+
+// checkDefault returns why text is not a default for a field of type t,
+// which is not a pointer, or "". Only booleans and numbers are checked;
+// other types decode their default in ways only the runtime can tell.
+func checkDefault(t types.Type, text string) string {
+	if decodesItself(t) {
+		return ""
+	}
+	var err error
+	switch {
+	case isKind(t, types.IsBoolean):
+		_, err = strconv.ParseBool(text)
+	case isKind(t, types.IsUnsigned):
+		_, err = strconv.ParseUint(text, 10, 64)
+	case isKind(t, types.IsInteger):
+		_, err = strconv.ParseInt(text, 10, 64)
+	case isKind(t, types.IsFloat):
+		_, err = strconv.ParseFloat(text, 64)
+	}
+	if err != nil {
+		return fmt.Sprintf(`strict:"default=%s" is not a valid %s`, text, t)
+	}
+	return ""
+}
+
+func indirect(t types.Type) types.Type {
+	for {
+		p, ok := t.Underlying().(*types.Pointer)
+		if !ok {
+			return t
+		}
+		t = p.Elem()
+	}
+}
+
+func isKind(t types.Type, info types.BasicInfo) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&info != 0
+}
+
+func hasLength(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Slice, *types.Map:
+		return true
+	}
+	return isKind(t, types.IsString)
+}
+
+func isBytes(t types.Type) bool {
+	s, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		return false
+	}
+	b, ok := s.Elem().Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Byte
+}
+
+// isNamed reports whether t is the type, or an alias, declared as name in
+// the package with path pkg.
+func isNamed(t types.Type, pkg, name string) bool {
+	var obj *types.TypeName
+	switch t := t.(type) {
+	case *types.Named:
+		obj = t.Obj()
+	case *types.Alias:
+		obj = t.Obj()
+	default:
+		return false
+	}
+	return obj.Pkg() != nil && obj.Pkg().Path() == pkg && obj.Name() == name
+}
+
+func isRawMessageMap(t types.Type) bool {
+	m, ok := t.(*types.Map)
+	if !ok {
+		return false
+	}
+	key, ok := m.Key().(*types.Basic)
+	return ok && key.Kind() == types.String && isNamed(m.Elem(), "encoding/json", "RawMessage")
+}
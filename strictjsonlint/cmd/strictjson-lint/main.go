@@ -0,0 +1,17 @@
+// Command strictjson-lint checks struct types for problems that strictjson
+// reports at runtime. It runs under go vet:
+//
+//	go vet -vettool=$(which strictjson-lint) ./...
+//
+// See package strictjsonlint for the checks.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"strictjson/strictjsonlint"
+)
+
+func main() {
+	unitchecker.Main(strictjsonlint.Analyzer)
+}
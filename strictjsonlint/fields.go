@@ -0,0 +1,124 @@
+package strictjsonlint
+
+import (
+	"go/types"
+	"reflect"
+	"strings"
+)
+
+// conflict is a JSON name provided by several fields at the same depth.
+type conflict struct {
+	name    string
+	sources []string // Go paths of the fields, e.g. "Base.ID"
+}
+
+// fieldConflicts returns the ambiguous JSON names of st, found the way
+// strictjson builds its field table: breadth first through untagged
+// embedded structs, visiting each type once at the shallowest depth it is
+// embedded at. Of several fields with the same name, the shallowest wins;
+// at equal depth a single json-tagged field wins, and otherwise the name is
+// ambiguous.
+func fieldConflicts(st *types.Struct) []conflict {
+	type fieldScan struct {
+		st    *types.Struct
+		key   string // identifies the embedded type
+		names []string
+		also  [][]string
+	}
+	type candidate struct {
+		tagged  bool
+		sources []string
+	}
+
+	var result []conflict
+	current := []fieldScan{{st: st}}
+	visited := map[string]bool{}
+	seen := map[string]bool{}      // names resolved at shallower depths
+	ambiguous := map[string]bool{} // names found ambiguous
+	for len(current) > 0 {
+		var next []fieldScan
+		nextSeen := map[string]int{}
+		candidates := map[string][]candidate{}
+		var levelNames []string
+
+		for _, scan := range current {
+			if scan.key != "" {
+				if visited[scan.key] {
+					continue
+				}
+				visited[scan.key] = true
+			}
+			for i := 0; i < scan.st.NumFields(); i++ {
+				f := scan.st.Field(i)
+				tag := reflect.StructTag(scan.st.Tag(i))
+				ft := f.Type()
+				if p, ok := ft.(*types.Pointer); ok {
+					ft = p.Elem()
+				}
+				embedded, isStruct := ft.Underlying().(*types.Struct)
+				if !f.Exported() && (!f.Anonymous() || !isStruct) {
+					continue
+				}
+				if parseStrict(tag.Get("strict")).remain {
+					continue
+				}
+				jsonTag := tag.Get("json")
+				if jsonTag == "-" {
+					continue
+				}
+				name, _, _ := strings.Cut(jsonTag, ",")
+				names := append(scan.names[:len(scan.names):len(scan.names)], f.Name())
+				tagged := name != ""
+				if !tagged {
+					if f.Anonymous() && isStruct {
+						key := types.TypeString(ft, nil)
+						if pos, ok := nextSeen[key]; ok {
+							next[pos].also = append(next[pos].also, names)
+							continue
+						}
+						nextSeen[key] = len(next)
+						next = append(next, fieldScan{st: embedded, key: key, names: names})
+						continue
+					}
+					name = f.Name()
+				}
+				if !f.Exported() || seen[name] || ambiguous[name] {
+					continue
+				}
+				sources := []string{strings.Join(names, ".")}
+				for _, other := range scan.also {
+					sources = append(sources, strings.Join(append(other[:len(other):len(other)], f.Name()), "."))
+				}
+				if candidates[name] == nil {
+					levelNames = append(levelNames, name)
+				}
+				candidates[name] = append(candidates[name], candidate{tagged: tagged, sources: sources})
+			}
+		}
+
+		for _, name := range levelNames {
+			all := candidates[name]
+			var pool []candidate
+			for _, c := range all {
+				if c.tagged {
+					pool = append(pool, c)
+				}
+			}
+			if len(pool) == 0 {
+				pool = all
+			}
+			if len(pool) > 1 || len(pool[0].sources) > 1 {
+				c := conflict{name: name}
+				for _, p := range pool {
+					c.sources = append(c.sources, p.sources...)
+				}
+				ambiguous[name] = true
+				result = append(result, c)
+				continue
+			}
+			seen[name] = true
+		}
+		current = next
+	}
+	return result
+}
@@ -0,0 +1,64 @@
+package a
+
+import (
+	"encoding/json"
+	"time"
+
+	"models"
+	"strictjson"
+)
+
+type Tagged struct {
+	Name    string                     `json:"name" strict:"nonempty"`
+	Age     int                        `json:"age" strict:"min=0,max=150"`
+	Role    string                     `json:"role" strict:"oneof=admin|user,default=user"`
+	Born    time.Time                  `json:"born" strict:"time=2006-01-02"`
+	Avatar  []byte                     `json:"avatar" strict:"b64url"`
+	Limit   *uint                      `json:"limit" strict:"default=10"`
+	Extra   map[string]json.RawMessage `strict:",remain"`
+	private string
+}
+
+type BadTags struct {
+	Count  int               `json:"count" strict:"nonemtpy"`  // want `invalid strict tag on field Count: unknown option "nonemtpy"`
+	Level  int               `json:"level" strict:"oneof=a|b"` // want `invalid strict tag on field Level: strict:"oneof=..." requires a string type`
+	Size   int               `json:"size" strict:"nonempty"`   // want `invalid strict tag on field Size: strict:"nonempty" requires a string, slice or map type`
+	Max    uint              `json:"max" strict:"max=-1"`      // want `invalid strict tag on field Max: strict:"max=-1" requires a non-negative integer`
+	Ratio  float64           `json:"ratio" strict:"default=x"` // want `invalid strict tag on field Ratio: strict:"default=x" is not a valid float64`
+	When   string            `json:"when" strict:"time=15:04"` // want `invalid strict tag on field When: strict:"time=..." requires type time.Time`
+	Data   string            `json:"data" strict:"b64"`        // want `invalid strict tag on field Data: strict:"b64" requires type \[\]byte`
+	Rest   map[string]string `strict:",remain"`                // want `invalid strict tag on field Rest: strict:",remain" requires type map\[string\]json.RawMessage`
+	Broken string            `json:"broken" strict:nonzero`    // want `malformed struct tag on field Broken`
+}
+
+type Duplicated struct { // want `JSON name "id" is provided by ID and Key at the same depth; strictjson returns a \*FieldConflictError`
+	ID  string `json:"id" strict:"nonempty"`
+	Key string `json:"id"`
+}
+
+type Untagged struct {
+	Name  string
+	Inner struct {
+		Value int
+	} `json:"inner"`
+	Skipped string `json:"-"`
+	Self    Custom
+}
+
+type Custom struct {
+	Hidden string
+}
+
+func (c *Custom) UnmarshalJSON(data []byte) error { return nil }
+
+func decode(data []byte) {
+	var u Untagged
+	_ = strictjson.Unmarshal(data, &u) // want `field Name of Untagged has no json tag; strictjson matches it only to the key "Name"` `field Value of struct{Value int} has no json tag; strictjson matches it only to the key "Value"` `field Self of Untagged has no json tag; strictjson matches it only to the key "Self"`
+
+	var r []models.Record
+	_ = strictjson.NewDecoder().Unmarshal(data, &r) // want `JSON name "id" is provided by Base.ID and Audit.ID at the same depth; strictjson returns a \*FieldConflictError`
+
+	_, _ = strictjson.UnmarshalAs[Tagged](data)
+	_, _ = strictjson.UnmarshalAs[strictjson.Optional[Tagged]](data)
+	_ = strictjson.Unmarshal(data, new(map[string]any))
+}
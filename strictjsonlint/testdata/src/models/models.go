@@ -0,0 +1,16 @@
+package models
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type Audit struct {
+	ID string `json:"id"`
+}
+
+// Record embeds two structs that both provide "id".
+type Record struct {
+	Base
+	Audit
+	Name string `json:"name"`
+}
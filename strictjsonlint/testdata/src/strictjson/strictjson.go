@@ -0,0 +1,19 @@
+package strictjson
+
+type Decoder struct{}
+
+func NewDecoder() *Decoder { return &Decoder{} }
+
+func Unmarshal(data []byte, v any) error { return nil }
+
+func (d *Decoder) Unmarshal(data []byte, v any) error { return nil }
+
+func UnmarshalAs[T any](data []byte) (T, error) {
+	var v T
+	return v, nil
+}
+
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
@@ -0,0 +1,138 @@
+// Package strictjsonmetrics counts strictjson decode outcomes per target type
+// and violation kind, for rollout dashboards:
+//
+//	c := strictjsonmetrics.NewCollector()
+//	prometheus.MustRegister(c)
+//	d := strictjson.NewDecoder(strictjson.WithMetricsHook(c.Hook()))
+//
+// Without Prometheus, ExpvarHook publishes the same counters with expvar.
+//
+// It lives in its own module so that the strictjson module does not depend
+// on the Prometheus client.
+package strictjsonmetrics
+
+import (
+	"expvar"
+	"reflect"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"strictjson"
+)
+
+// Collector is a prometheus.Collector of the statistics reported to its
+// hook. It exports:
+//
+//   - strictjson_decodes_total{type}
+//   - strictjson_violations_total{type, kind}
+//   - strictjson_unknown_fields_total{type}
+//   - strictjson_decode_duration_seconds{type}
+//
+// where type is the Go type decoded into and kind the error code of a
+// violation, such as "unknown_field" or "type_mismatch". Violations are the
+// error a call returned and, for Check, each violation it reported.
+type Collector struct {
+	decodes       *prometheus.CounterVec
+	violations    *prometheus.CounterVec
+	unknownFields *prometheus.CounterVec
+	duration      *prometheus.HistogramVec
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector with no recorded decodes.
+func NewCollector() *Collector {
+	return &Collector{
+		decodes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "strictjson_decodes_total",
+			Help: "Decode calls by target type.",
+		}, []string{"type"}),
+		violations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "strictjson_violations_total",
+			Help: "Strict decoding violations by target type and kind.",
+		}, []string{"type", "kind"}),
+		unknownFields: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "strictjson_unknown_fields_total",
+			Help: "Unknown JSON keys by target type.",
+		}, []string{"type"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "strictjson_decode_duration_seconds",
+			Help:    "Decode call duration by target type.",
+			Buckets: prometheus.ExponentialBuckets(1e-6, 4, 10),
+		}, []string{"type"}),
+	}
+}
+
+// Hook returns the strictjson.MetricsHook that feeds c.
+func (c *Collector) Hook() strictjson.MetricsHook {
+	return c.Observe
+}
+
+// Observe records the statistics of one decode call.
+func (c *Collector) Observe(s strictjson.DecodeStats) {
+	typ := typeName(s.Type)
+	c.decodes.WithLabelValues(typ).Inc()
+	c.duration.WithLabelValues(typ).Observe(s.Duration.Seconds())
+	if s.UnknownFields > 0 {
+		c.unknownFields.WithLabelValues(typ).Add(float64(s.UnknownFields))
+	}
+	forEachViolation(s, func(kind string) {
+		c.violations.WithLabelValues(typ, kind).Inc()
+	})
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.decodes.Describe(ch)
+	c.violations.Describe(ch)
+	c.unknownFields.Describe(ch)
+	c.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.decodes.Collect(ch)
+	c.violations.Collect(ch)
+	c.unknownFields.Collect(ch)
+	c.duration.Collect(ch)
+}
+
+// ExpvarHook publishes an expvar.Map under name and returns a hook that
+// counts into it. The map holds "decodes" and "unknown_fields" maps keyed
+// by type, and a "violations" map keyed by "type kind". Like
+// expvar.Publish, it panics if name is already in use.
+func ExpvarHook(name string) strictjson.MetricsHook {
+	m := expvar.NewMap(name)
+	decodes, violations, unknownFields := new(expvar.Map), new(expvar.Map), new(expvar.Map)
+	m.Set("decodes", decodes)
+	m.Set("violations", violations)
+	m.Set("unknown_fields", unknownFields)
+
+	return func(s strictjson.DecodeStats) {
+		typ := typeName(s.Type)
+		decodes.Add(typ, 1)
+		if s.UnknownFields > 0 {
+			unknownFields.Add(typ, int64(s.UnknownFields))
+		}
+		forEachViolation(s, func(kind string) {
+			violations.Add(typ+" "+kind, 1)
+		})
+	}
+}
+
+// forEachViolation calls fn with the kind of every violation in s.
+func forEachViolation(s strictjson.DecodeStats, fn func(kind string)) {
+	for _, v := range s.Violations {
+		fn(strictjson.ErrorCode(v.Err))
+	}
+	if s.Err != nil {
+		fn(strictjson.ErrorCode(s.Err))
+	}
+}
+
+func typeName(t reflect.Type) string {
+	if t == nil {
+		return ""
+	}
+	return t.String()
+}
@@ -0,0 +1,67 @@
+package strictjsonmetrics
+
+import (
+	"expvar"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"strictjson"
+)
+
+type order struct {
+	ID  string `json:"id"`
+	Qty int    `json:"qty"`
+}
+
+func TestCollector(t *testing.T) {
+	c := NewCollector()
+	d := strictjson.NewDecoder(strictjson.WithMetricsHook(c.Hook()))
+
+	var o order
+	_ = d.Unmarshal([]byte(`{"id": "a", "qty": 1}`), &o)
+	_ = d.Unmarshal([]byte(`{"ID": "a"}`), &o)
+	_ = d.Unmarshal([]byte(`{"qty": "x"}`), &o)
+	_, _ = d.Check([]byte(`{"Id": "a", "extra": 1}`), &o)
+
+	want := `
+# HELP strictjson_decodes_total Decode calls by target type.
+# TYPE strictjson_decodes_total counter
+strictjson_decodes_total{type="strictjsonmetrics.order"} 4
+# HELP strictjson_unknown_fields_total Unknown JSON keys by target type.
+# TYPE strictjson_unknown_fields_total counter
+strictjson_unknown_fields_total{type="strictjsonmetrics.order"} 3
+# HELP strictjson_violations_total Strict decoding violations by target type and kind.
+# TYPE strictjson_violations_total counter
+strictjson_violations_total{kind="type_mismatch",type="strictjsonmetrics.order"} 1
+strictjson_violations_total{kind="unknown_field",type="strictjsonmetrics.order"} 3
+`
+	err := testutil.CollectAndCompare(c, strings.NewReader(want),
+		"strictjson_decodes_total", "strictjson_unknown_fields_total", "strictjson_violations_total")
+	if err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(c, "strictjson_decode_duration_seconds"); n != 1 {
+		t.Errorf("Expected 1 duration histogram, got %d", n)
+	}
+}
+
+func TestExpvarHook(t *testing.T) {
+	d := strictjson.NewDecoder(strictjson.WithMetricsHook(ExpvarHook("strictjson_test")))
+
+	var o order
+	_ = d.Unmarshal([]byte(`{"id": "a"}`), &o)
+	_ = d.Unmarshal([]byte(`{"ID": "a"}`), &o)
+
+	got := expvar.Get("strictjson_test").String()
+	for _, want := range []string{
+		`"decodes": {"strictjsonmetrics.order": 2}`,
+		`"violations": {"strictjsonmetrics.order unknown_field": 1}`,
+		`"unknown_fields": {"strictjsonmetrics.order": 1}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expvar = %s, missing %s", got, want)
+		}
+	}
+}
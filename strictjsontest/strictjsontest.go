@@ -0,0 +1,325 @@
+// Package strictjsontest provides test assertions for code that decodes
+// with strictjson: that a document decodes, that it is rejected at a given
+// path, that a value matches a golden file, and that a directory of
+// fixtures still decodes.
+//
+//	var cfg Config
+//	strictjsontest.RequireUnmarshal(t, data, &cfg)
+//	strictjsontest.RequireViolation(t, []byte(`{"port": 1, "prot": 2}`), &cfg, "prot")
+//	strictjsontest.RequireGolden(t, "testdata/config.golden.json", cfg)
+//	strictjsontest.ValidateDir(t, "testdata/configs", &Config{})
+//
+// Golden files are rewritten by running the tests with -strictjson.update.
+package strictjsontest
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"strictjson"
+)
+
+var update = flag.Bool("strictjson.update", false, "rewrite strictjsontest golden files")
+
+// RequireUnmarshal decodes data into v with a strictjson.Decoder configured
+// by opts and stops the test if decoding fails.
+func RequireUnmarshal(t testing.TB, data []byte, v any, opts ...strictjson.DecoderOption) {
+	t.Helper()
+	if err := strictjson.NewDecoder(opts...).Unmarshal(data, v); err != nil {
+		t.Fatalf("strictjson: unexpected decode error: %v", err)
+	}
+}
+
+// RequireViolation decodes data into v with a strictjson.Decoder configured
+// by opts and stops the test unless decoding fails with an error located at
+// wantPath, such as "items[0].name". An error without a location, like a
+// syntax error, matches only the empty path. The error is returned for
+// further assertions.
+func RequireViolation(t testing.TB, data []byte, v any, wantPath string, opts ...strictjson.DecoderOption) error {
+	t.Helper()
+	err := strictjson.NewDecoder(opts...).Unmarshal(data, v)
+	if err == nil {
+		t.Fatalf("strictjson: expected an error at %q, decoding succeeded", wantPath)
+	}
+	if path := errorPath(err); path != wantPath {
+		t.Fatalf("strictjson: expected an error at %q, got one at %q: %v", wantPath, path, err)
+	}
+	return err
+}
+
+// ValidateDir decodes every *.json file in dir into a new value of proto's
+// type, such as &CreateUserRequest{}, with a strictjson.Decoder configured
+// by opts. Each file with violations fails the test with all of them listed
+// by path, so that one run shows every fixture that no longer matches its
+// struct. A directory without *.json files stops the test, since that is
+// more likely a wrong path than a passing suite.
+func ValidateDir(t testing.TB, dir string, proto any, opts ...strictjson.DecoderOption) {
+	t.Helper()
+	typ := reflect.TypeOf(proto)
+	if typ == nil {
+		t.Fatalf("strictjson: ValidateDir(nil)")
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatalf("strictjson: %v", err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("strictjson: no *.json files in %s", dir)
+	}
+
+	d := strictjson.NewDecoder(opts...)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Errorf("strictjson: %v", err)
+			continue
+		}
+		violations, err := d.Check(data, reflect.New(typ).Interface())
+		if err != nil {
+			t.Errorf("strictjson: %s: %v", file, err)
+			continue
+		}
+		if len(violations) == 0 {
+			continue
+		}
+		lines := make([]string, len(violations))
+		for i, v := range violations {
+			msg := strings.TrimPrefix(v.Err.Error(), "strictjson: ")
+			if v.Path != "" {
+				msg = fmt.Sprintf("at %q: %s", v.Path, msg)
+			}
+			lines[i] = msg
+		}
+		t.Errorf("strictjson: %s does not decode strictly into %v:\n\t%s", file, typ, strings.Join(lines, "\n\t"))
+	}
+}
+
+// errorPath returns the location of err, or "" if it has none.
+func errorPath(err error) string {
+	var pe interface{ Path() string }
+	if errors.As(err, &pe) {
+		return pe.Path()
+	}
+	return ""
+}
+
+// RequireEqual stops the test if got differs from want, reporting each
+// difference with its JSON path. Structs are compared field by field under
+// the JSON names strictjson decodes them by, and values that encode
+// themselves, such as time.Time, by their JSON encoding.
+func RequireEqual(t testing.TB, got, want any) {
+	t.Helper()
+	if diffs := diff(reflect.ValueOf(got), reflect.ValueOf(want)); len(diffs) > 0 {
+		t.Fatalf("strictjson: values differ:\n\t%s", strings.Join(diffs, "\n\t"))
+	}
+}
+
+// RequireGolden compares got with the JSON in the golden file, as
+// RequireEqual does. The file is decoded strictly into a new value of got's
+// type, so a golden file with keys the type no longer has fails with the
+// decode error rather than passing. With -strictjson.update the file is
+// instead rewritten with got's deterministic encoding.
+func RequireGolden(t testing.TB, file string, got any) {
+	t.Helper()
+	if *update {
+		var buf bytes.Buffer
+		if err := strictjson.NewEncoder(&buf, strictjson.WithIndent("", "  ")).Encode(got); err != nil {
+			t.Fatalf("strictjson: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, buf.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("strictjson: %v (run with -strictjson.update to create it)", err)
+	}
+	if got == nil {
+		t.Fatalf("strictjson: RequireGolden(nil)")
+	}
+	want := reflect.New(reflect.TypeOf(got))
+	if err := strictjson.Unmarshal(data, want.Interface()); err != nil {
+		t.Fatalf("strictjson: golden file %s: %v", file, err)
+	}
+	if diffs := diff(reflect.ValueOf(got), want.Elem()); len(diffs) > 0 {
+		t.Fatalf("strictjson: value differs from golden file %s:\n\t%s", file, strings.Join(diffs, "\n\t"))
+	}
+}
+
+// diff returns one message per difference between got and want.
+func diff(got, want reflect.Value) []string {
+	var d differ
+	d.value(got, want)
+	return d.diffs
+}
+
+type differ struct {
+	path  []string
+	diffs []string
+}
+
+func (d *differ) report(got, want string) {
+	path := strings.Join(d.path, "")
+	if path == "" {
+		d.diffs = append(d.diffs, fmt.Sprintf("got %s, want %s", got, want))
+		return
+	}
+	d.diffs = append(d.diffs, fmt.Sprintf("at %q: got %s, want %s", strings.TrimPrefix(path, "."), got, want))
+}
+
+func (d *differ) push(seg string) { d.path = append(d.path, seg) }
+func (d *differ) pop()            { d.path = d.path[:len(d.path)-1] }
+
+func (d *differ) value(got, want reflect.Value) {
+	if !got.IsValid() || !want.IsValid() {
+		if got.IsValid() != want.IsValid() {
+			d.report(show(got), show(want))
+		}
+		return
+	}
+	if got.Type() != want.Type() {
+		d.report(fmt.Sprintf("%s of type %v", show(got), got.Type()), fmt.Sprintf("%s of type %v", show(want), want.Type()))
+		return
+	}
+	if encodesItself(got.Type()) {
+		if g, w := show(got), show(want); g != w {
+			d.report(g, w)
+		}
+		return
+	}
+
+	switch got.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if got.IsNil() || want.IsNil() {
+			if got.IsNil() != want.IsNil() {
+				d.report(show(got), show(want))
+			}
+			return
+		}
+		d.value(got.Elem(), want.Elem())
+	case reflect.Struct:
+		fields, err := strictjson.FieldsOf(got.Type())
+		if err != nil {
+			if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+				d.report(show(got), show(want))
+			}
+			return
+		}
+		for _, f := range fields {
+			g, gok := fieldByIndex(got, f.Index)
+			w, wok := fieldByIndex(want, f.Index)
+			if !gok && !wok {
+				continue
+			}
+			d.push("." + f.Name)
+			if gok && wok {
+				d.value(g, w)
+			} else {
+				d.value(validIf(g, gok), validIf(w, wok))
+			}
+			d.pop()
+		}
+	case reflect.Map:
+		if got.IsNil() != want.IsNil() {
+			d.report(show(got), show(want))
+			return
+		}
+		keys := append(got.MapKeys(), want.MapKeys()...)
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		for i, k := range keys {
+			if i > 0 && fmt.Sprint(k) == fmt.Sprint(keys[i-1]) {
+				continue
+			}
+			d.push("." + fmt.Sprint(k))
+			d.value(got.MapIndex(k), want.MapIndex(k))
+			d.pop()
+		}
+	case reflect.Slice, reflect.Array:
+		if got.Kind() == reflect.Slice && got.IsNil() != want.IsNil() {
+			d.report(show(got), show(want))
+			return
+		}
+		if got.Len() != want.Len() {
+			d.report(fmt.Sprintf("%d elements", got.Len()), fmt.Sprintf("%d elements", want.Len()))
+		}
+		for i := 0; i < got.Len() && i < want.Len(); i++ {
+			d.push("[" + strconv.Itoa(i) + "]")
+			d.value(got.Index(i), want.Index(i))
+			d.pop()
+		}
+	default:
+		if !reflect.DeepEqual(got.Interface(), want.Interface()) {
+			d.report(show(got), show(want))
+		}
+	}
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, reporting false instead of
+// panicking on a nil embedded pointer.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func validIf(v reflect.Value, ok bool) reflect.Value {
+	if ok {
+		return v
+	}
+	return reflect.Value{}
+}
+
+// encodesItself reports whether t, or a pointer to it, has its own JSON or
+// text encoding.
+func encodesItself(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	for _, m := range []string{"MarshalJSON", "MarshalText"} {
+		if _, ok := t.MethodByName(m); ok {
+			return true
+		}
+		if _, ok := reflect.PtrTo(t).MethodByName(m); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// show formats v as JSON for messages, falling back to Go syntax.
+func show(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nothing"
+	}
+	if v.CanInterface() {
+		if b, err := strictjson.Marshal(v.Interface()); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%#v", v.Interface())
+	}
+	return v.String()
+}
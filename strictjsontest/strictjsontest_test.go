@@ -0,0 +1,219 @@
+package strictjsontest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"strictjson"
+)
+
+type base struct {
+	ID      int       `json:"id"`
+	Created time.Time `json:"created"`
+}
+
+type order struct {
+	base
+	Customer string         `json:"customer"`
+	Items    []item         `json:"items"`
+	Labels   map[string]int `json:"labels,omitempty"`
+	Note     *string        `json:"note"`
+}
+
+type item struct {
+	SKU string `json:"sku"`
+	Qty int    `json:"qty"`
+}
+
+// recorder captures failures instead of failing the enclosing test.
+type recorder struct {
+	testing.TB
+	msg    string
+	failed bool
+	errors []string // messages of Errorf, which does not stop the test
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failed = true
+	r.msg = strings.TrimSpace(strings.ReplaceAll(fmt.Sprintf(format, args...), "\t", ""))
+	panic(r)
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.failed = true
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatal(args ...any) {
+	r.Fatalf("%s", fmt.Sprint(args...))
+}
+
+// run calls f with a recorder and returns it once f returns or fails.
+func run(f func(t testing.TB)) (r *recorder) {
+	r = &recorder{}
+	defer func() {
+		if p := recover(); p != nil && p != r {
+			panic(p)
+		}
+	}()
+	f(r)
+	return r
+}
+
+func TestRequireUnmarshal(t *testing.T) {
+	var o order
+	RequireUnmarshal(t, []byte(`{"id": 1, "customer": "c", "items": [{"sku": "a", "qty": 2}]}`), &o)
+	if o.ID != 1 || len(o.Items) != 1 {
+		t.Errorf("Unexpected result %+v", o)
+	}
+
+	r := run(func(t testing.TB) {
+		RequireUnmarshal(t, []byte(`{"customer": "c", "Customer": "d"}`), &order{})
+	})
+	if !r.failed || !strings.Contains(r.msg, `"Customer"`) {
+		t.Errorf("Expected a decode failure, got %q", r.msg)
+	}
+}
+
+func TestRequireViolation(t *testing.T) {
+	data := []byte(`{"items": [{"sku": "a", "qtty": 2}]}`)
+	err := RequireViolation(t, data, &order{}, "items[0].qtty")
+	var ufe *strictjson.UnknownFieldError
+	if !errors.As(err, &ufe) {
+		t.Errorf("Expected *UnknownFieldError, got %v", err)
+	}
+	RequireViolation(t, []byte(`{"id": 1} x`), &order{}, "")
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"no error", `{"id": 1}`, `expected an error at "items[0].qtty", decoding succeeded`},
+		{"wrong path", `{"itemz": []}`, `expected an error at "items[0].qtty", got one at "itemz"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := run(func(t testing.TB) {
+				RequireViolation(t, []byte(tt.data), &order{}, "items[0].qtty")
+			})
+			if !r.failed || !strings.Contains(r.msg, tt.want) {
+				t.Errorf("Failure = %q, want it to contain %q", r.msg, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireEqual(t *testing.T) {
+	note := "n"
+	got := order{
+		base:     base{ID: 1, Created: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		Customer: "c",
+		Items:    []item{{"a", 1}, {"b", 2}},
+		Labels:   map[string]int{"x": 1},
+		Note:     &note,
+	}
+	want := got
+	want.Items = []item{{"a", 1}, {"b", 3}}
+	want.Labels = map[string]int{"y": 1}
+	want.Created = got.Created.Add(time.Second)
+	want.Note = nil
+
+	RequireEqual(t, got, got)
+	r := run(func(t testing.TB) { RequireEqual(t, got, want) })
+	for _, line := range []string{
+		`at "created": got "2024-01-02T00:00:00Z", want "2024-01-02T00:00:01Z"`,
+		`at "items[1].qty": got 2, want 3`,
+		`at "labels.x": got 1, want nothing`,
+		`at "labels.y": got nothing, want 1`,
+		`at "note": got "n", want null`,
+	} {
+		if !strings.Contains(r.msg, line) {
+			t.Errorf("Failure %q does not contain %q", r.msg, line)
+		}
+	}
+
+	r = run(func(t testing.TB) { RequireEqual(t, []int{1}, []int{1, 2}) })
+	if !strings.Contains(r.msg, "got 1 elements, want 2 elements") {
+		t.Errorf("Unexpected failure %q", r.msg)
+	}
+}
+
+func TestRequireGolden(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "testdata", "order.json")
+	v := order{base: base{ID: 7}, Customer: "c", Items: []item{{"a", 1}}}
+
+	if r := run(func(t testing.TB) { RequireGolden(t, file, v) }); !r.failed || !strings.Contains(r.msg, "-strictjson.update") {
+		t.Errorf("Expected missing golden file failure, got %q", r.msg)
+	}
+
+	*update = true
+	RequireGolden(t, file, v)
+	*update = false
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "{\n  \"id\": 7,\n") {
+		t.Errorf("Golden file = %s", data)
+	}
+	RequireGolden(t, file, v)
+
+	v.Items[0].Qty = 2
+	if r := run(func(t testing.TB) { RequireGolden(t, file, v) }); !strings.Contains(r.msg, `at "items[0].qty": got 2, want 1`) {
+		t.Errorf("Unexpected failure %q", r.msg)
+	}
+
+	if err := os.WriteFile(file, []byte(`{"id": 7, "customer": "c", "items": [], "removed": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r := run(func(t testing.TB) { RequireGolden(t, file, v) }); !strings.Contains(r.msg, `"removed"`) {
+		t.Errorf("Expected unknown key failure, got %q", r.msg)
+	}
+}
+
+func TestValidateDir(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := map[string]string{
+		"a.json":     `{"id": 1, "customer": "c", "items": [{"sku": "a", "qty": 2}]}`,
+		"b.json":     `{"id": 2, "Customer": "c", "items": [{"sku": "a", "qtty": 2}]}`,
+		"c.json":     `{"id": `,
+		"notes.txt":  `not JSON`,
+		"d.json.bak": `{"removed": true}`,
+	}
+	for name, data := range fixtures {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := run(func(t testing.TB) { ValidateDir(t, dir, &order{}, strictjson.WithSuggestClosest(true)) })
+	if len(r.errors) != 2 {
+		t.Fatalf("Expected failures for b.json and c.json, got %q", r.errors)
+	}
+	want := "strictjson: " + filepath.Join(dir, "b.json") + " does not decode strictly into strictjsontest.order:\n" +
+		"\tat \"Customer\": unknown field \"Customer\" (did you mean \"customer\"?)\n" +
+		"\tat \"items[0].qtty\": unknown field \"qtty\" (did you mean \"qty\"?)"
+	if r.errors[0] != want {
+		t.Errorf("Failure =\n%s\nwant\n%s", r.errors[0], want)
+	}
+	if !strings.HasPrefix(r.errors[1], "strictjson: "+filepath.Join(dir, "c.json")+": ") {
+		t.Errorf("Unexpected failure %q", r.errors[1])
+	}
+
+	good := t.TempDir()
+	if r := run(func(t testing.TB) { ValidateDir(t, good, order{}) }); !r.failed || !strings.Contains(r.msg, "no *.json files") {
+		t.Errorf("Expected empty directory failure, got %q", r.msg)
+	}
+	if err := os.WriteFile(filepath.Join(good, "a.json"), []byte(fixtures["a.json"]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ValidateDir(t, good, order{})
+}
@@ -0,0 +1,84 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// WithTimeFormat makes time.Time values decode only from JSON strings in
+// the time layout layout, time.RFC3339 if it is empty, and reports any other
+// value with a *ValueError naming its location. encoding/json accepts any
+// RFC 3339 timestamp, and time.Parse tolerates some deviations from a
+// layout, such as fractional seconds it does not mention. With strict set,
+// a timestamp must also be written exactly as layout formats it, so that,
+// for RFC 3339, "2024-05-01T10:00:00.5Z" and "2024-05-01T10:00:00+00:00"
+// are rejected in favor of "2024-05-01T10:00:00Z".
+//
+// A field can require its own layout with the struct tag option time, as in
+// `strict:"time=2006-01-02"`, whatever the decoder's options; such layouts
+// cannot contain commas. strict applies to those layouts too.
+func WithTimeFormat(layout string, strict bool) DecoderOption {
+	return func(d *Decoder) {
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		d.TimeFormat = layout
+		d.StrictTimeFormat = strict
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timeLayout returns the layout time.Time values at the current position
+// must be written in, or "" if they are left to their UnmarshalJSON method.
+func (s *decodeState) timeLayout() string {
+	if s.current != nil && s.current.format != "" {
+		return s.current.format
+	}
+	return s.d.TimeFormat
+}
+
+// timeString decodes the next value, which must be a string in layout, into
+// v, a time.Time.
+func (s *decodeState) timeString(v reflect.Value, layout string) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	if raw[0] != '"' {
+		return s.timeError(raw, fmt.Sprintf("is not a string in the time format %q", layout), nil)
+	}
+	str := scanner{data: raw}
+	contents, escaped, _ := str.readString()
+	return s.parseTime(v, raw, unquote(contents, escaped), layout)
+}
+
+// parseTime parses str, written as raw, in layout into v, a time.Time.
+func (s *decodeState) parseTime(v reflect.Value, raw []byte, str, layout string) error {
+	t, err := time.Parse(layout, str)
+	if err != nil {
+		return s.timeError(raw, fmt.Sprintf("is not in the time format %q", layout), err)
+	}
+	if s.d.StrictTimeFormat {
+		if want := t.Format(layout); want != str {
+			return s.timeError(raw, fmt.Sprintf("is not written exactly in the time format %q (want %q)", layout, want), nil)
+		}
+	}
+	v.Set(reflect.ValueOf(t))
+	return nil
+}
+
+func (s *decodeState) timeError(raw []byte, reason string, err error) error {
+	return newValueError(s.pathString(), timeType, s.snippet(raw), reason, err)
+}
+
+// mapTime mirrors timeString for a generic source value.
+func (s *decodeState) mapTime(v reflect.Value, src any, layout string) error {
+	str, ok := src.(string)
+	if !ok {
+		return s.timeError([]byte(fmt.Sprint(src)), fmt.Sprintf("is not a string in the time format %q", layout), nil)
+	}
+	return s.parseTime(v, []byte(strconv.Quote(str)), str, layout)
+}
@@ -0,0 +1,112 @@
+package strictjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFormat(t *testing.T) {
+	type Event struct {
+		At      time.Time            `json:"at"`
+		Ends    *time.Time           `json:"ends"`
+		History []time.Time          `json:"history"`
+		Day     time.Time            `json:"day" strict:"time=2006-01-02"`
+		ByName  map[string]time.Time `json:"byName"`
+	}
+
+	tests := []struct {
+		name     string
+		opts     []DecoderOption
+		data     string
+		wantPath string
+		wantMsg  string
+	}{
+		{name: "default accepts RFC 3339 variants", data: `{"at": "2024-05-01T10:00:00.5+00:00"}`},
+		{name: "layout", opts: []DecoderOption{WithTimeFormat("", false)}, data: `{"at": "2024-05-01T10:00:00Z", "ends": null}`},
+		{
+			name:     "space instead of T",
+			opts:     []DecoderOption{WithTimeFormat("", false)},
+			data:     `{"history": ["2024-05-01 10:00:00Z"]}`,
+			wantPath: "history[0]",
+			wantMsg:  `strictjson: value at "history[0]" is not in the time format "2006-01-02T15:04:05Z07:00": parsing time`,
+		},
+		{
+			name:     "missing time zone",
+			opts:     []DecoderOption{WithTimeFormat(time.RFC3339, true)},
+			data:     `{"ends": "2024-05-01T10:00:00"}`,
+			wantPath: "ends",
+			wantMsg:  `strictjson: value at "ends" is not in the time format`,
+		},
+		{
+			name:     "not a string",
+			opts:     []DecoderOption{WithTimeFormat("", false)},
+			data:     `{"byName": {"a": 1714557600}}`,
+			wantPath: "byName.a",
+			wantMsg:  `strictjson: value at "byName.a" is not a string in the time format "2006-01-02T15:04:05Z07:00"`,
+		},
+		{name: "fraction tolerated", opts: []DecoderOption{WithTimeFormat("", false)}, data: `{"at": "2024-05-01T10:00:00.5Z"}`},
+		{
+			name:     "fraction in strict mode",
+			opts:     []DecoderOption{WithTimeFormat("", true)},
+			data:     `{"at": "2024-05-01T10:00:00.5Z"}`,
+			wantPath: "at",
+			wantMsg:  `strictjson: value at "at" is not written exactly in the time format "2006-01-02T15:04:05Z07:00" (want "2024-05-01T10:00:00Z")`,
+		},
+		{
+			name:     "zero offset in strict mode",
+			opts:     []DecoderOption{WithTimeFormat("", true)},
+			data:     `{"at": "2024-05-01T10:00:00+00:00"}`,
+			wantPath: "at",
+		},
+		{name: "field layout", data: `{"day": "2024-05-01"}`},
+		{
+			name:     "field layout without option",
+			data:     `{"day": "2024-05-01T10:00:00Z"}`,
+			wantPath: "day",
+			wantMsg:  `strictjson: value at "day" is not in the time format "2006-01-02"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(tt.opts...)
+			var v Event
+			err := d.Unmarshal([]byte(tt.data), &v)
+			verr := d.Validate([]byte(tt.data), &Event{})
+			if tt.wantPath == "" {
+				if err != nil || verr != nil {
+					t.Fatalf("Unexpected errors %v, %v", err, verr)
+				}
+				return
+			}
+			for _, err := range []error{err, verr} {
+				var ve *ValueError
+				if !errors.As(err, &ve) || ve.Path() != tt.wantPath || ve.Type() != timeType {
+					t.Fatalf("Expected *ValueError at %q, got %v", tt.wantPath, err)
+				}
+				if !strings.HasPrefix(err.Error(), tt.wantMsg) {
+					t.Errorf("Error() = %q, want prefix %q", err.Error(), tt.wantMsg)
+				}
+			}
+		})
+	}
+
+	var v Event
+	if err := NewDecoder(WithTimeFormat("", true)).DecodeMap(map[string]any{"day": "2024-05-01", "at": "2024-05-01T10:00:00Z"}, &v); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if v.Day.Day() != 1 || v.At.Hour() != 10 {
+		t.Errorf("Unexpected times %v, %v", v.Day, v.At)
+	}
+}
+
+func TestTimeLayoutTagRequiresTime(t *testing.T) {
+	var v struct {
+		Day string `json:"day" strict:"time=2006-01-02"`
+	}
+	var ite *InvalidTagError
+	if err := Unmarshal([]byte(`{"day": "x"}`), &v); !errors.As(err, &ite) {
+		t.Errorf("Expected *InvalidTagError, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package strictjson
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+var unionRegistry struct {
+	sync.RWMutex
+	byType map[reflect.Type]*union
+}
+
+// union is a registered discriminated union.
+type union struct {
+	field string
+	types map[string]reflect.Type
+}
+
+// unionPlan is the plan of a union: the plans of its member types by
+// discriminator value.
+type unionPlan struct {
+	field   string
+	members map[string]*typePlan
+	values  []string // sorted discriminator values, for errors
+}
+
+// RegisterUnion makes values of the interface type T decode as a
+// discriminated union: an object decoded into a T must have a string member
+// named discriminatorField whose value selects the struct type from
+// mapping, and the object is then decoded into a new value of that type
+// with every strict check. For example
+//
+//	strictjson.RegisterUnion[Payment]("type", map[string]reflect.Type{
+//		"card": reflect.TypeOf(Card{}),
+//		"bank": reflect.TypeOf(BankTransfer{}),
+//	})
+//
+// decodes {"type": "card", "number": "4242"} into a Card. The discriminator
+// is accepted whether or not the struct has a field for it. A missing or
+// unknown discriminator is reported with a *DiscriminatorError.
+//
+// Unions are decoded with any Decoder; unlike RegisterInterfaceImpl they
+// need no option. Registering an empty mapping removes the union.
+//
+// RegisterUnion panics if T is not an interface type, discriminatorField is
+// empty, or a mapped type is not a struct type, or pointer to one, that
+// implements T. It is intended to be called during program initialization.
+func RegisterUnion[T any](discriminatorField string, mapping map[string]reflect.Type) {
+	it := reflect.TypeOf((*T)(nil)).Elem()
+	if it.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("strictjson: RegisterUnion of non-interface type %s", it))
+	}
+	if discriminatorField == "" {
+		panic("strictjson: RegisterUnion with empty discriminator field")
+	}
+	u := &union{field: discriminatorField, types: make(map[string]reflect.Type, len(mapping))}
+	for value, t := range mapping {
+		base := t
+		if base != nil && base.Kind() == reflect.Ptr {
+			base = base.Elem()
+		}
+		if base == nil || base.Kind() != reflect.Struct {
+			panic(fmt.Sprintf("strictjson: RegisterUnion: %q maps to non-struct type %v", value, t))
+		}
+		if !t.Implements(it) {
+			if t != base || !reflect.PointerTo(t).Implements(it) {
+				panic(fmt.Sprintf("strictjson: RegisterUnion: %s does not implement %s", t, it))
+			}
+			t = reflect.PointerTo(t)
+		}
+		u.types[value] = t
+	}
+
+	unionRegistry.Lock()
+	if unionRegistry.byType == nil {
+		unionRegistry.byType = make(map[reflect.Type]*union)
+	}
+	if len(mapping) == 0 {
+		delete(unionRegistry.byType, it)
+	} else {
+		unionRegistry.byType[it] = u
+	}
+	unionRegistry.Unlock()
+
+	ClearCache()
+}
+
+// registeredUnion returns the union registered for the interface type t, or
+// nil.
+func registeredUnion(t reflect.Type) *union {
+	unionRegistry.RLock()
+	defer unionRegistry.RUnlock()
+	return unionRegistry.byType[t]
+}
+
+func buildUnionPlan(u *union, cfg fieldConfig, building map[reflect.Type]*typePlan) *unionPlan {
+	up := &unionPlan{field: u.field, members: make(map[string]*typePlan, len(u.types))}
+	for value, t := range u.types {
+		up.members[value] = buildPlan(t, cfg, building)
+		up.values = append(up.values, value)
+	}
+	sort.Strings(up.values)
+	return up
+}
+
+// discriminator is the key of a union's discriminator and the length of the
+// path of its member, which is tolerated as a key of the member object even
+// if the struct decoded from it has no field for it.
+type discriminator struct {
+	key   string
+	depth int
+}
+
+// isDiscriminator reports whether key, at the current path, is the
+// discriminator of the union member being decoded.
+func (s *decodeState) isDiscriminator(key string) bool {
+	return s.discriminator.depth == len(s.path) && s.discriminator.key == key
+}
+
+// unionMember reads the discriminator of the object at the scanner, without
+// consuming it, and returns the plan of the member type it selects.
+func (s *decodeState) unionMember(u *unionPlan) (*typePlan, error) {
+	scan := s.scan
+	scan.depthLimit, scan.maxKeys, scan.maxElements = 0, 0, 0
+	scan.pos++ // '{'
+	var raw []byte
+	if !scan.consume('}') {
+		for {
+			key, err := scan.readKey()
+			if err != nil {
+				return nil, err
+			}
+			value, err := scan.skipValue()
+			if err != nil {
+				return nil, err
+			}
+			if key == u.field {
+				raw = value
+				break
+			}
+			if !scan.consume(',') {
+				break
+			}
+		}
+	}
+
+	s.pushKey(u.field)
+	defer s.pop()
+	if raw == nil {
+		return nil, newDiscriminatorError(s.pathString(), "", "is missing")
+	}
+	if raw[0] != '"' {
+		return nil, newDiscriminatorError(s.pathString(), string(raw), "is "+string(raw)+", not a string")
+	}
+	str := scanner{data: raw}
+	contents, escaped, _ := str.readString()
+	value := unquote(contents, escaped)
+	if p, ok := u.members[value]; ok {
+		return p, nil
+	}
+	return nil, newDiscriminatorError(s.pathString(), value, fmt.Sprintf("is %q, not %s", value, quoteAlternatives(u.values)))
+}
+
+// mapUnionMember mirrors unionMember for a generic source object.
+func (s *decodeState) mapUnionMember(u *unionPlan, m map[string]any) (*typePlan, error) {
+	s.pushKey(u.field)
+	defer s.pop()
+	src, ok := m[u.field]
+	if !ok {
+		return nil, newDiscriminatorError(s.pathString(), "", "is missing")
+	}
+	value, ok := src.(string)
+	if !ok {
+		return nil, newDiscriminatorError(s.pathString(), fmt.Sprint(src), fmt.Sprintf("is %v, not a string", src))
+	}
+	if p, ok := u.members[value]; ok {
+		return p, nil
+	}
+	return nil, newDiscriminatorError(s.pathString(), value, fmt.Sprintf("is %q, not %s", value, quoteAlternatives(u.values)))
+}
+
+// inUnion runs decode, which decodes the object of a member of u, with the
+// discriminator of u tolerated among the keys of the object.
+func (s *decodeState) inUnion(u *unionPlan, decode func() error) error {
+	saved := s.discriminator
+	s.discriminator = discriminator{key: u.field, depth: len(s.path) + 1}
+	err := decode()
+	s.discriminator = saved
+	return err
+}
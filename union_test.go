@@ -0,0 +1,161 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type payment interface{ amount() int }
+
+type cardPayment struct {
+	Type   string `json:"type"`
+	Number string `json:"number"`
+	Cents  int    `json:"cents"`
+}
+
+func (c cardPayment) amount() int { return c.Cents }
+
+// bankPayment has no field for the discriminator.
+type bankPayment struct {
+	IBAN  string `json:"iban"`
+	Cents int    `json:"cents"`
+}
+
+func (b *bankPayment) amount() int { return b.Cents }
+
+type order struct {
+	ID       string    `json:"id"`
+	Payment  payment   `json:"payment"`
+	Refunds  []payment `json:"refunds"`
+	Optional payment   `json:"optional"`
+}
+
+func init() {
+	RegisterUnion[payment]("type", map[string]reflect.Type{
+		"card": reflect.TypeOf(cardPayment{}),
+		"bank": reflect.TypeOf(bankPayment{}),
+	})
+}
+
+func TestRegisterUnion(t *testing.T) {
+	data := []byte(`{
+		"id": "o1",
+		"payment": {"type": "card", "number": "4242", "cents": 500},
+		"refunds": [{"iban": "DE89", "type": "bank", "cents": 100}],
+		"optional": null
+	}`)
+
+	var got order
+	if err := Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	want := order{
+		ID:      "o1",
+		Payment: cardPayment{Type: "card", Number: "4242", Cents: 500},
+		Refunds: []payment{&bankPayment{IBAN: "DE89", Cents: 100}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Unmarshal() = %#v, want %#v", got, want)
+	}
+
+	var fromMap order
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if err := DecodeMap(m, &fromMap); err != nil {
+		t.Fatalf("DecodeMap() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(fromMap, want) {
+		t.Errorf("DecodeMap() = %#v, want %#v", fromMap, want)
+	}
+	if err := Validate(data, &order{}); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestRegisterUnionErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantPath string
+		wantMsg  string
+	}{
+		{
+			name:     "missing discriminator",
+			data:     `{"payment": {"number": "4242"}}`,
+			wantPath: "payment.type",
+			wantMsg:  `strictjson: discriminator at "payment.type" is missing`,
+		},
+		{
+			name:     "unknown discriminator",
+			data:     `{"payment": {"type": "crad", "number": "4242"}}`,
+			wantPath: "payment.type",
+			wantMsg:  `strictjson: discriminator at "payment.type" is "crad", not "bank" or "card"`,
+		},
+		{
+			name:     "discriminator not a string",
+			data:     `{"refunds": [{"type": 1}]}`,
+			wantPath: "refunds[0].type",
+			wantMsg:  `strictjson: discriminator at "refunds[0].type" is 1, not a string`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v order
+			err := Unmarshal([]byte(tt.data), &v)
+			var de *DiscriminatorError
+			if !errors.As(err, &de) || de.Path() != tt.wantPath || err.Error() != tt.wantMsg {
+				t.Fatalf("Expected %q, got %v", tt.wantMsg, err)
+			}
+			if !errors.Is(err, ErrDecode) {
+				t.Error("Expected error to wrap ErrDecode")
+			}
+			if err := Validate([]byte(tt.data), &v); !errors.As(err, &de) {
+				t.Errorf("Validate() expected *DiscriminatorError, got %v", err)
+			}
+		})
+	}
+
+	// Members are decoded strictly, and the discriminator is tolerated only
+	// directly in the member object.
+	var ufe *UnknownFieldError
+	var v order
+	err := Unmarshal([]byte(`{"payment": {"type": "bank", "IBAN": "DE89"}}`), &v)
+	if !errors.As(err, &ufe) || ufe.Path() != "payment.IBAN" {
+		t.Errorf("Expected unknown field at payment.IBAN, got %v", err)
+	}
+	err = Unmarshal([]byte(`{"payment": {"type": "bank"}, "type": "x"}`), &v)
+	if !errors.As(err, &ufe) || ufe.Path() != "type" {
+		t.Errorf("Expected unknown field at type, got %v", err)
+	}
+}
+
+func TestRegisterUnionPanics(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func()
+	}{
+		{name: "non-interface", register: func() { RegisterUnion[cardPayment]("type", nil) }},
+		{name: "empty field", register: func() { RegisterUnion[payment]("", nil) }},
+		{name: "non-struct", register: func() {
+			RegisterUnion[payment]("type", map[string]reflect.Type{"n": reflect.TypeOf(0)})
+		}},
+		{name: "not implemented", register: func() {
+			RegisterUnion[payment]("type", map[string]reflect.Type{"p": reflect.TypeOf(parallelItem{})})
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil || !strings.HasPrefix(r.(string), "strictjson: RegisterUnion") {
+					t.Errorf("Expected RegisterUnion panic, got %v", r)
+				}
+			}()
+			tt.register()
+		})
+	}
+}
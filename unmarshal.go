@@ -1,8 +1,13 @@
 package strictjson
 
 import (
+	"encoding"
 	"encoding/json"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Unmarshal and stores the result in the value pointed to by v.
@@ -12,174 +17,853 @@ import (
 //
 // Case-sensitive validation is applied recursively to -
 //   - Nested structs
-//   - Slices/arrays containing structs
+//   - Slices and arrays containing structs
 //   - Maps with struct values
+//
+// The document is read in a single pass: keys are checked as they are
+// scanned and values are decoded straight into their destination, so an
+// error part-way through leaves earlier fields populated unless the decoder
+// is atomic; see WithAtomic. Values are decoded in the order they appear in
+// the document, as with encoding/json: custom UnmarshalJSON methods run in
+// that order and, when duplicate keys are allowed, the last occurrence wins.
 func Unmarshal(data []byte, v any) error {
 	d := NewDecoder()
 	return d.Unmarshal(data, v)
 }
 
-func (d *Decoder) Unmarshal(data []byte, v any) error {
+func (d *Decoder) Unmarshal(data []byte, v any) (err error) {
+	defer func() { err = d.formatError(err) }()
 	rv := reflect.ValueOf(v)
 	if rv.Kind() != reflect.Ptr || rv.IsNil() {
 		return newNonPointerError()
 	}
 
-	return d.unmarshalValue(data, rv.Elem())
+	s, err := d.newState(data)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	defer s.observe(rv.Elem().Type(), &err)
+	target := d.target(rv.Elem())
+	err = s.document(func() error {
+		return s.value(target, s.d.planFor(rv.Elem().Type()))
+	})
+	if err != nil {
+		return err
+	}
+	d.commit(rv.Elem(), target)
+	return nil
 }
 
-func (d *Decoder) unmarshalValue(data []byte, v reflect.Value) error {
-	if string(data) == "null" {
-		return nil
+// newState returns the state for decoding data, failing if data is longer
+// than MaxBytes.
+func (d *Decoder) newState(data []byte) (*decodeState, error) {
+	if d.MaxBytes > 0 && len(data) > d.MaxBytes {
+		return nil, newLimitError(LimitBytes, "", d.MaxBytes)
+	}
+	if d.schema != nil && d.schema.err != nil {
+		return nil, d.schema.err
+	}
+	if d.RequireCanonical {
+		if err := checkCanonical(data); err != nil {
+			return nil, err
+		}
+	}
+	start, err := d.skipBOM(data)
+	if err != nil {
+		return nil, err
 	}
-	t := v.Type()
-	for t.Kind() == reflect.Ptr {
-		t = t.Elem()
+	if d.AllowComments {
+		data = stripComments(data)
 	}
-	if v.CanAddr() && implementsUnmarshaler(v.Addr().Type()) {
-		return json.Unmarshal(data, v.Addr().Interface())
+	if d.AllowTrailingCommas {
+		data = stripTrailingCommas(data)
 	}
+	s := statePool.Get().(*decodeState)
+	s.d = d
+	s.scan = scanner{
+		data:        data,
+		pos:         start,
+		maxKeys:     d.MaxKeysPerObject,
+		maxElements: d.MaxElements,
+		strictUTF8:  d.StrictUTF8,
+	}
+	if d.MetricsHook != nil {
+		s.stats = &DecodeStats{Bytes: len(data)}
+		s.start = time.Now()
+	}
+	return s, nil
+}
 
-	v = allocatePointers(v)
+// statePool recycles decodeStates, with their path and seen-key buffers,
+// across calls.
+var statePool = sync.Pool{New: func() any { return new(decodeState) }}
+
+// release returns s to statePool, keeping its buffers. Entry points defer it
+// once they are done with s; nothing may refer to s afterwards.
+func (s *decodeState) release() {
+	// A panic can leave frames behind; the pool must not keep their values.
+	s.stack.reset()
+	*s = decodeState{path: s.path[:0], seen: s.seen, stack: s.stack}
+	statePool.Put(s)
+}
 
-	switch v.Kind() {
-	case reflect.Struct:
-		return d.unmarshalStruct(data, v)
-	case reflect.Slice:
-		return d.unmarshalSlice(data, v)
-	case reflect.Map:
-		return d.unmarshalMap(data, v)
-	default:
-		return json.Unmarshal(data, v.Addr().Interface())
+// decodeState holds the per-call state of a decode so that a Decoder itself
+// is never mutated while decoding, which is what makes a Decoder safe for
+// concurrent use. Anything a call changes belongs here, not in Decoder.
+type decodeState struct {
+	d      *Decoder
+	scan   scanner
+	path   []pathSegment
+	report *DecodeReport
+	// policy is the unknown-key policy in effect, set by strict tags,
+	// registered type policies and IgnorePaths.
+	policy Policy
+	// violations is non-nil in Check mode, where strict-rule violations are
+	// collected instead of aborting the decode. collect is set when they are
+	// collected for a schema check instead, to be returned with its errors.
+	violations *[]Violation
+	collect    bool
+	// tree builds the document's generic value for the schema check.
+	tree *docTree
+	// depth is the number of objects and arrays currently open.
+	depth int
+	// suggested counts the unknown keys suggestions were computed for,
+	// against SuggestionBudget.
+	suggested int
+	// seen holds the duplicate-key set of the open object at each depth,
+	// reused by later objects at the same depth.
+	seen []map[string]struct{}
+	// stats collects the statistics of the call when a MetricsHook is set.
+	stats *DecodeStats
+	start time.Time
+	// discriminator is the discriminator key of the union member object
+	// being decoded.
+	discriminator discriminator
+	// current is the struct field whose value is being decoded, for the
+	// options of its strict tag, such as a time layout.
+	current *fieldInfo
+	// present records which fields of the struct object being decoded are
+	// present, indexed by fieldInfo.index, when ZeroMissing or a default
+	// needs to know.
+	present []bool
+	// stack holds a frame for each object and array being decoded member by
+	// member, innermost last.
+	stack frameStack
+}
+
+// pathSegment is one step of the document path: an object key, or an array
+// index when key is empty and index is non-negative.
+type pathSegment struct {
+	key   string
+	index int
+}
+
+func (s *decodeState) pushKey(key string) {
+	s.path = append(s.path, pathSegment{key: key, index: -1})
+}
+
+func (s *decodeState) pushIndex(i int) {
+	s.path = append(s.path, pathSegment{index: i})
+}
+
+func (s *decodeState) pop() {
+	s.path = s.path[:len(s.path)-1]
+}
+
+// pathString renders the current path, e.g. "contact.address[0].city".
+func (s *decodeState) pathString() string {
+	return formatPath(s.path)
+}
+
+func formatPath(path []pathSegment) string {
+	var b strings.Builder
+	for _, seg := range path {
+		if seg.index >= 0 {
+			b.WriteByte('[')
+			b.WriteString(strconv.Itoa(seg.index))
+			b.WriteByte(']')
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(seg.key)
 	}
+	return b.String()
 }
 
-func implementsUnmarshaler(t reflect.Type) bool {
-	unmarshalerType := reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
-	return t.Implements(unmarshalerType)
+// joinPath appends a dotted relative path, as reported by encoding/json, to
+// base. Numeric segments are taken to be array indices and written as
+// "[i]", matching the paths of strictjson errors.
+func joinPath(base, rel string) string {
+	var b strings.Builder
+	b.WriteString(base)
+	for rel != "" {
+		var seg string
+		seg, rel, _ = strings.Cut(rel, ".")
+		if _, err := strconv.Atoi(seg); err == nil {
+			b.WriteString("[" + seg + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(seg)
+	}
+	return b.String()
+}
+
+// value decodes the next value into v. Objects and arrays are decoded
+// without recursion: begin opens a frame for them on s.stack, and run
+// decodes their members, and those of everything nested in them, in a loop,
+// so that nesting is bounded by MaxDepth and the heap rather than by the
+// goroutine stack.
+func (s *decodeState) value(v reflect.Value, p *typePlan) error {
+	return s.complete(s.begin(v, p))
 }
 
-func (d *Decoder) unmarshalStruct(data []byte, v reflect.Value) error {
-	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err != nil {
+// complete runs the frame an opener such as begin reports it opened until
+// it is closed.
+func (s *decodeState) complete(opened bool, err error) error {
+	if err != nil || !opened {
 		return err
 	}
+	return s.run(s.stack.len() - 1)
+}
 
-	sf, err := getStructFields(v.Type())
-	if err != nil {
-		return err
+// begin starts decoding the next value into v. Scalars, and values decoded
+// by other means such as custom unmarshalers, are decoded completely; for an
+// object or array decoded member by member, begin pushes a frame onto
+// s.stack and reports true.
+func (s *decodeState) begin(v reflect.Value, p *typePlan) (bool, error) {
+	policy, scoped := s.scope(p)
+	if !scoped {
+		return s.open(v, p)
 	}
+	saved := s.enterPolicy(policy)
+	opened, err := s.open(v, p)
+	s.endScope(opened, saved)
+	return opened, err
+}
 
-	if d.DisallowUnknownFields {
-		for jsonKey := range raw {
-			if _, exists := sf.fields[jsonKey]; !exists {
-				suggestion := ""
-				if d.SuggestClosest {
-					suggestion = findSuggestion(jsonKey, sf.allNames)
-				}
-				return newUnknownFieldError(jsonKey, suggestion)
-			}
-		}
+// endScope restores saved, the policy in effect before a value's type
+// scoped its own, once the value is done: now, or if its opener opened a
+// frame, when that frame is closed.
+func (s *decodeState) endScope(opened bool, saved Policy) {
+	if !opened {
+		s.policy = saved
+		return
 	}
+	f := s.top()
+	f.scoped, f.policy = true, saved
+}
 
-	for jsonKey, rawValue := range raw {
-		fi, exists := sf.fields[jsonKey]
-		if !exists {
-			continue
+// open is begin without the unknown-key policy of p's type.
+func (s *decodeState) open(v reflect.Value, p *typePlan) (bool, error) {
+	if p.kind == planOptional {
+		return s.optional(v.Addr().Interface().(optional), p.elem)
+	}
+	if p.kind == planRaw && !p.indirect {
+		return false, s.raw(v)
+	}
+	if s.scan.consumeNull() {
+		if s.d.DisallowNullForNonPointer && !p.nullable {
+			return false, s.violation(newNullValueError(s.pathString(), v.Type()))
 		}
+		return false, nil
+	}
+	if p.indirect {
+		v = allocatePointers(v)
+	}
+	if err := s.checkEnum(p); err != nil {
+		return false, err
+	}
 
-		fieldValue := getFieldByIndex(v, fi.fieldIndex)
-		if !fieldValue.IsValid() || !fieldValue.CanSet() {
-			continue
+	switch p.kind {
+	case planStruct:
+		if s.scan.peek() == '{' {
+			return s.object(v, p)
+		}
+	case planSlice:
+		if s.scan.peek() == '[' {
+			return s.array(v, p.elem)
+		}
+	case planMap:
+		if s.scan.peek() == '{' {
+			return s.mapObject(v, p.elem)
+		}
+	case planGenerated:
+		if s.scan.peek() == '{' {
+			return false, s.generated(v)
+		}
+	case planText:
+		if c := s.scan.peek(); c == '-' || '0' <= c && c <= '9' {
+			return false, s.textNumber(v)
+		}
+	case planUnmarshaler:
+		if p.inner != nil {
+			if err := s.validateThrough(p.inner); err != nil {
+				return false, err
+			}
 		}
+	case planInterface:
+		if s.scan.peek() == '{' && (p.union != nil || s.d.ValidateInterfaceObjects) {
+			return false, s.iface(v, p)
+		}
+	case planFunc:
+		return false, s.decodeFunc(v, p)
+	case planTime:
+		if layout := s.timeLayout(); layout != "" {
+			return false, s.timeString(v, layout)
+		}
+	case planBytes:
+		if format := s.base64Format(); format != "" && s.scan.peek() == '"' {
+			return false, s.base64String(v, format)
+		}
+	case planRaw:
+		return false, s.raw(v)
+	case planLiteral:
+		if ok, err := s.container(v); ok {
+			return false, err
+		}
+	}
+	return false, s.literal(v)
+}
+
+// textNumber decodes a JSON number into v, an encoding.TextUnmarshaler, by
+// passing it the number as written. encoding/json passes such types only
+// strings, which would force numbers such as big.Float values through a
+// string or float64 first.
+func (s *decodeState) textNumber(v reflect.Value) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText(raw); err != nil {
+		return newInvalidNumberError(s.pathString(), v.Type(), string(raw), err)
+	}
+	return nil
+}
 
-		if err := d.unmarshalValue(rawValue, fieldValue); err != nil {
+// literal slices out the next value and delegates it to the backend.
+func (s *decodeState) literal(v reflect.Value) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	if s.d.ownsNumbers() {
+		if ok, err := s.number(v, raw); ok {
 			return err
 		}
 	}
+	err = s.d.backend().Unmarshal(raw, v.Addr().Interface())
+	if err != nil {
+		return s.typeMismatch(err, raw, int64(s.scan.pos-len(raw)))
+	}
+	if s.d.RejectLossyFloats {
+		return s.lossyFloats(raw, v.Type())
+	}
+	return nil
+}
+
+// skip consumes the next value without decoding it, still applying the
+// document-wide checks that do not depend on the target type.
+func (s *decodeState) skip() ([]byte, error) {
+	if s.d.MaxDepth > 0 {
+		s.scan.depthLimit = s.d.MaxDepth - s.depth + 1
+	}
+	raw, err := s.scan.skipValue()
+	if err != nil {
+		switch err {
+		case errDepthLimit:
+			return nil, newMaxDepthError(s.pathString(), s.d.MaxDepth)
+		case errKeyLimit:
+			return nil, newLimitError(LimitKeys, s.pathString(), s.d.MaxKeysPerObject)
+		case errElementLimit:
+			return nil, newLimitError(LimitElements, s.pathString(), s.d.MaxElements)
+		}
+		return nil, err
+	}
+	sorted := s.d.KeyOrder == KeyOrderSorted
+	if s.d.DisallowDuplicateKeys || s.d.RejectEscapedKeys || sorted {
+		rel, key, prev, problem := findKey(raw, s.d.DisallowDuplicateKeys, s.d.RejectEscapedKeys, sorted)
+		if problem != keyOK {
+			s.path = append(s.path, rel...)
+			switch problem {
+			case keyDuplicate:
+				err = s.violation(newDuplicateKeyError(key, s.pathString()))
+			case keyEscaped:
+				err = s.violation(newEscapedKeyError(key, s.pathString()))
+			default:
+				err = s.violation(newKeyOrderError(key, prev, s.pathString()))
+			}
+			s.path = s.path[:len(s.path)-len(rel)]
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return raw, nil
+}
 
+// enter records that an object or array is being opened, failing if that
+// exceeds MaxDepth. Every successful enter must be paired with leave.
+func (s *decodeState) enter() error {
+	if s.d.MaxDepth > 0 && s.depth >= s.d.MaxDepth {
+		return newMaxDepthError(s.pathString(), s.d.MaxDepth)
+	}
+	s.depth++
+	if s.stats != nil && s.depth > s.stats.MaxDepth {
+		s.stats.MaxDepth = s.depth
+	}
 	return nil
 }
 
-func (d *Decoder) unmarshalSlice(data []byte, v reflect.Value) error {
-	var rawSlice []json.RawMessage
-	if err := json.Unmarshal(data, &rawSlice); err != nil {
-		return err
+func (s *decodeState) leave() {
+	s.depth--
+}
+
+// countKey fails if the nth key of the object at the current path exceeds
+// MaxKeysPerObject.
+func (s *decodeState) countKey(n int) error {
+	if s.d.MaxKeysPerObject > 0 && n > s.d.MaxKeysPerObject {
+		return newLimitError(LimitKeys, s.pathString(), s.d.MaxKeysPerObject)
 	}
+	return nil
+}
 
-	elemType := v.Type().Elem()
-	needsValidation := containsStruct(elemType)
+// countElement fails if the nth element of the array at the current path
+// exceeds MaxElements.
+func (s *decodeState) countElement(n int) error {
+	if s.d.MaxElements > 0 && n > s.d.MaxElements {
+		return newLimitError(LimitElements, s.pathString(), s.d.MaxElements)
+	}
+	return nil
+}
 
-	if !needsValidation {
-		return json.Unmarshal(data, v.Addr().Interface())
+// arrayLength fails if a JSON array of n elements was decoded into the Go
+// array type t and StrictArrayLength requires exactly t.Len().
+func (s *decodeState) arrayLength(t reflect.Type, n int) error {
+	if !s.d.StrictArrayLength || n == t.Len() {
+		return nil
 	}
+	return s.violation(newArrayLengthError(s.pathString(), t, n))
+}
 
-	newSlice := reflect.MakeSlice(v.Type(), len(rawSlice), len(rawSlice))
+// seenKey records key as present in the current object and reports a
+// duplicate if it was already recorded. seen is nil unless duplicate keys are
+// disallowed.
+func (s *decodeState) seenKey(seen map[string]struct{}, key string) error {
+	if seen == nil {
+		return nil
+	}
+	if _, dup := seen[key]; dup {
+		return s.violation(newDuplicateKeyError(key, s.pathString()))
+	}
+	seen[key] = struct{}{}
+	return nil
+}
 
-	for i, rawElem := range rawSlice {
-		elem := newSlice.Index(i)
-		if err := d.unmarshalValue(rawElem, elem); err != nil {
-			return err
-		}
+// newSeenKeys returns an empty duplicate-key set for the object just
+// entered, or nil if duplicates are allowed.
+func (s *decodeState) newSeenKeys() map[string]struct{} {
+	if !s.d.DisallowDuplicateKeys {
+		return nil
+	}
+	for len(s.seen) <= s.depth {
+		s.seen = append(s.seen, nil)
+	}
+	seen := s.seen[s.depth]
+	if seen == nil {
+		seen = make(map[string]struct{})
+		s.seen[s.depth] = seen
+	}
+	for k := range seen {
+		delete(seen, k)
+	}
+	return seen
+}
+
+// isNullable reports whether values of kind k have a nil state that null can
+// map onto.
+func isNullable(k reflect.Kind) bool {
+	switch k {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		return true
 	}
+	return false
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func implementsUnmarshaler(t reflect.Type) bool {
+	return t.Implements(unmarshalerType)
+}
+
+// implementsTextUnmarshaler reports whether t decodes itself from text but
+// not from JSON.
+func implementsTextUnmarshaler(t reflect.Type) bool {
+	return t.Implements(textUnmarshalerType) && !t.Implements(unmarshalerType)
+}
 
-	v.Set(newSlice)
+// structErr returns the error that prevents decoding the struct of p: an
+// invalid tag or, unless IgnoreConflicts is set, an ambiguous field name.
+func (s *decodeState) structErr(p *typePlan) error {
+	if p.err != nil {
+		return p.err
+	}
+	if len(p.fields.conflicts) > 0 && !s.d.IgnoreConflicts {
+		return p.fields.conflicts[0]
+	}
 	return nil
 }
 
-func (d *Decoder) unmarshalMap(data []byte, v reflect.Value) error {
-	var rawMap map[string]json.RawMessage
-	if err := json.Unmarshal(data, &rawMap); err != nil {
-		return err
+// object opens a frame for the object at the scanner, to be decoded into
+// v, a struct.
+func (s *decodeState) object(v reflect.Value, p *typePlan) (bool, error) {
+	if err := s.structErr(p); err != nil {
+		return false, err
+	}
+	if s.stats != nil {
+		s.stats.Objects++
 	}
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+	s.scan.pos++ // '{'
+	s.push(frame{
+		kind:    frameObject,
+		v:       v,
+		fields:  p.fields,
+		present: s.beginFields(v, p.fields),
+		seen:    s.newSeenKeys(),
+	})
+	return true, nil
+}
 
-	valueType := v.Type().Elem()
-	needsValidation := containsStruct(valueType)
+// readFieldKey reads the next key of an object decoded with the field table
+// sf. A key without escapes is compared in place against the field names;
+// if it names a field, that field is returned along with its name, so known
+// keys allocate nothing. Other keys are unquoted into a new string,
+// normalized if KeyNormalizer is set, and fi is nil. With KeyTransform set,
+// every key is unquoted and transformed before it is matched.
+func (s *decodeState) readFieldKey(sf *structFields) (key string, fi *fieldInfo, err error) {
+	raw, escaped, err := s.scan.skipKey()
+	if err != nil {
+		return "", nil, err
+	}
+	if s.d.KeyTransform != nil {
+		key = s.normalizeKey(raw, unquote(raw, escaped))
+		err = s.escapedKey(raw, key)
+		return s.transformKey(key), nil, err
+	}
+	if !escaped {
+		if fi, ok := sf.fields[string(raw)]; ok {
+			if fi.jsonName == string(raw) {
+				return fi.jsonName, fi, nil
+			}
+			// An alias: the key differs from the field's name.
+			return string(raw), fi, nil
+		}
+		return string(raw), nil, nil
+	}
+	key = s.normalizeKey(raw, unquote(raw, escaped))
+	return key, nil, s.escapedKey(raw, key)
+}
 
-	if !needsValidation {
-		return json.Unmarshal(data, v.Addr().Interface())
+// readKey reads the next key of an object decoded as a map.
+func (s *decodeState) readKey() (string, error) {
+	raw, escaped, err := s.scan.skipKey()
+	if err != nil {
+		return "", err
+	}
+	key := unquote(raw, escaped)
+	if escaped {
+		return key, s.escapedKey(raw, key)
 	}
+	return key, nil
+}
 
-	if v.IsNil() {
-		v.Set(reflect.MakeMap(v.Type()))
+// escapedKey rejects key, read from the string contents raw, if it was
+// written with escapes and RejectEscapedKeys is set.
+func (s *decodeState) escapedKey(raw []byte, key string) error {
+	if !s.d.RejectEscapedKeys || !hasEscape(raw) {
+		return nil
 	}
+	s.pushKey(key)
+	err := s.violation(newEscapedKeyError(key, s.pathString()))
+	s.pop()
+	return err
+}
 
-	keyType := v.Type().Key()
+// lookup finds the field of sf for key. With CaseInsensitive set, a key
+// matching no field exactly falls back to a case-insensitive match, as in
+// encoding/json.
+func (s *decodeState) lookup(sf *structFields, key string) (*fieldInfo, bool) {
+	fi, ok := sf.lookup(key)
+	if !ok && s.d.CaseInsensitive {
+		fi, ok = sf.lookupFold(key)
+	}
+	return fi, ok
+}
 
-	for key, rawValue := range rawMap {
-		keyVal := reflect.ValueOf(key)
-		if keyType.Kind() != reflect.String {
-			keyVal = keyVal.Convert(keyType)
+// unknown applies the unknown-field policy to a key that matched no field.
+// It returns an error if the key must be rejected, or, in Check mode, the
+// field encoding/json would have matched case-insensitively.
+func (s *decodeState) unknown(sf *structFields, key string) (*fieldInfo, error) {
+	if !s.rejectsUnknown(key) {
+		return nil, nil
+	}
+	if s.stats != nil {
+		s.stats.UnknownFields++
+	}
+	suggestions := s.suggest(sf, key)
+	switch {
+	case s.violations != nil && (!s.collect || s.d.OnUnknownField == nil):
+		s.violation(newUnknownFieldError(key, suggestions, s.pathString()))
+		// Carry on like encoding/json would.
+		fi, _ := sf.lookupFold(key)
+		return fi, nil
+	case s.d.OnUnknownField != nil:
+		suggestion := ""
+		if len(suggestions) > 0 {
+			suggestion = suggestions[0]
 		}
-		elemVal := reflect.New(valueType).Elem()
-		if err := d.unmarshalValue(rawValue, elemVal); err != nil {
+		return nil, s.d.OnUnknownField(formatPath(s.path[:len(s.path)-1]), key, suggestion)
+	default:
+		return nil, newUnknownFieldError(key, suggestions, s.pathString())
+	}
+}
+
+// suggester is a set of names that suggestions are drawn from: the field
+// names of a struct, or the values of an enum.
+type suggester interface {
+	suggestions(key string, limit, maxDistance int) []string
+}
+
+// suggest returns the names of sf closest to key, or nil if SuggestClosest
+// is disabled or this decode has used up SuggestionBudget.
+func (s *decodeState) suggest(sf suggester, key string) []string {
+	d := s.d
+	if !d.SuggestClosest {
+		return nil
+	}
+	budget := d.SuggestionBudget
+	if budget <= 0 {
+		budget = defaultSuggestionBudget
+	}
+	if s.suggested >= budget {
+		return nil
+	}
+	s.suggested++
+
+	limit, maxDistance := d.SuggestionLimit, d.SuggestionMaxDistance
+	if limit <= 0 {
+		limit = 1
+	}
+	if maxDistance <= 0 {
+		maxDistance = 2
+	}
+	return sf.suggestions(key, limit, maxDistance)
+}
+
+// quoted decodes the value of a field with the ,string tag option, whose
+// scalar is encoded inside a JSON string as in {"count": "12"}. Bare values
+// are decoded as usual unless StrictStringOption is set.
+func (s *decodeState) quoted(v reflect.Value, p *typePlan) error {
+	if s.scan.peek() != '"' {
+		if err := s.checkQuoted(p, s.scan.peek()); err != nil {
 			return err
 		}
+		return s.value(v, p)
+	}
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	start := int64(s.scan.pos - len(raw))
+	inner := raw[1 : len(raw)-1]
+	if needsUnquote(inner) {
+		inner = []byte(unquote(inner, true))
+	}
+	if s.d.ownsNumbers() && len(inner) > 0 {
+		if ok, err := s.number(v, inner); ok {
+			return err
+		}
+	}
+	if err := s.d.backend().Unmarshal(inner, v.Addr().Interface()); err != nil {
+		return s.typeMismatch(&json.UnmarshalTypeError{Value: "string", Type: v.Type(), Offset: start}, raw, 0)
+	}
+	return nil
+}
 
-		v.SetMapIndex(keyVal, elemVal)
+// checkQuoted rejects a bare value, starting with c, for a field with the
+// ,string tag option if StrictStringOption is set. null is always allowed.
+func (s *decodeState) checkQuoted(p *typePlan, c byte) error {
+	if !s.d.StrictStringOption || c == 'n' {
+		return nil
 	}
+	return s.violation(newUnquotedValueError(s.pathString(), p.typ, describeToken([]byte{c})))
+}
 
+// remain stores the raw value of an unmatched key in the struct's remain map.
+func (s *decodeState) remain(v reflect.Value, sf *structFields, key string) error {
+	raw, err := s.skip()
+	if err != nil {
+		return err
+	}
+	m := getFieldByIndex(v, sf.remain)
+	if !m.IsValid() {
+		return nil
+	}
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(m.Type()))
+	}
+	m.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(json.RawMessage(append([]byte(nil), raw...))))
 	return nil
 }
 
-func containsStruct(t reflect.Type) bool {
-	for t.Kind() == reflect.Ptr {
-		t = t.Elem()
+// rejectsUnknown reports whether an unmatched key at the current path is an
+// error.
+func (s *decodeState) rejectsUnknown(key string) bool {
+	if s.d.isAllowedExtra(key) || s.isDiscriminator(key) {
+		return false
+	}
+	switch s.policy {
+	case PolicyLenient:
+		return false
+	case PolicyStrict:
+		return true
+	}
+	if !s.d.DisallowUnknownFields {
+		return false
 	}
+	return len(s.d.IgnorePaths) == 0 || !s.d.ignoresPath(s.path)
+}
 
-	switch t.Kind() {
-	case reflect.Struct:
-		ptrType := reflect.PointerTo(t)
-		if implementsUnmarshaler(ptrType) {
+// array opens a frame for the array at the scanner, to be decoded into v,
+// a slice or Go array. The elements of a top-level array are decoded in
+// parallel instead when Parallelism allows it, and no frame is opened.
+func (s *decodeState) array(v reflect.Value, elem *typePlan) (bool, error) {
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+	if v.Kind() == reflect.Array {
+		array := reflect.New(v.Type()).Elem()
+		if s.d.MergeExisting {
+			array.Set(v)
+		}
+		s.scan.pos++ // '['
+		s.push(frame{kind: frameArray, v: v, elem: elem, slice: array, array: v.Type()})
+		return true, nil
+	}
+	if s.d.Parallelism > 1 && s.depth == 1 && s.report == nil {
+		if ok, err := s.parallelArray(v, elem); ok {
+			s.leave()
+			return false, err
+		}
+	}
+	s.scan.pos++ // '['
+	s.push(frame{kind: frameArray, v: v, elem: elem, slice: reflect.MakeSlice(v.Type(), 0, 0)})
+	return true, nil
+}
+
+// mapObject opens a frame for the object at the scanner, to be decoded into
+// v, a map.
+func (s *decodeState) mapObject(v reflect.Value, elem *typePlan) (bool, error) {
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+	s.resetMap(v)
+	s.scan.pos++ // '{'
+	s.push(frame{kind: frameMap, v: v, elem: elem, seen: s.newSeenKeys()})
+	return true, nil
+}
+
+// mapKey converts key, the object key at the current path, to a map key of
+// type kt the way encoding/json does: through UnmarshalText if kt
+// implements encoding.TextUnmarshaler, by parsing it as a decimal number for
+// integer kinds, and as is for string kinds.
+func (s *decodeState) mapKey(kt reflect.Type, key string) (reflect.Value, error) {
+	if reflect.PointerTo(kt).Implements(textUnmarshalerType) {
+		kv := reflect.New(kt)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, newInvalidKeyError(key, s.pathString(), kt, err)
+		}
+		return kv.Elem(), nil
+	}
+	kv := reflect.New(kt).Elem()
+	switch kt.Kind() {
+	case reflect.String:
+		kv.SetString(key)
+		return kv, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err == nil && !kv.OverflowInt(n) {
+			kv.SetInt(n)
+			return kv, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err == nil && !kv.OverflowUint(n) {
+			kv.SetUint(n)
+			return kv, nil
+		}
+	}
+	return reflect.Value{}, newInvalidKeyError(key, s.pathString(), kt, nil)
+}
+
+// parsesKeys reports whether the keys of t, a map type, are converted by
+// mapKey rather than stored as written, so that keys it cannot parse are
+// reported as an *InvalidKeyError.
+func parsesKeys(t reflect.Type) bool {
+	kt := t.Key()
+	return kt.Kind() != reflect.String || reflect.PointerTo(kt).Implements(textUnmarshalerType)
+}
+
+// containsStruct reports whether values of t need decoding by strictjson
+// rather than by the backend as a whole. Structs with an UnmarshalJSON
+// method count only if throughUnmarshalers is set.
+func containsStruct(t reflect.Type, throughUnmarshalers bool) bool {
+	// seen stops the walk on recursive types such as type list []list.
+	var seen map[reflect.Type]bool
+	for {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		// Text unmarshalers are decoded here too, so that they can be given
+		// numbers.
+		if isOptional(t) || implementsTextUnmarshaler(reflect.PointerTo(t)) || registeredDecoder(t) != nil || t == timeType || isBytes(t) ||
+			reflect.PointerTo(t).Implements(enumerType) {
+			return true
+		}
+
+		switch t.Kind() {
+		case reflect.Struct:
+			ptrType := reflect.PointerTo(t)
+			if implementsUnmarshaler(ptrType) {
+				return throughUnmarshalers
+			}
+			return true
+		case reflect.Slice, reflect.Array, reflect.Map:
+			if t.Kind() == reflect.Map && parsesKeys(t) {
+				return true
+			}
+			if seen[t] {
+				return false
+			}
+			if seen == nil {
+				seen = make(map[reflect.Type]bool)
+			}
+			seen[t] = true
+			t = t.Elem()
+		case reflect.Interface:
+			return registeredUnion(t) != nil || len(interfaceImpls(t)) > 0
+		default:
 			return false
 		}
-		return true
-	case reflect.Slice, reflect.Array:
-		return containsStruct(t.Elem())
-	case reflect.Map:
-		return containsStruct(t.Elem())
-	default:
-		return false
 	}
 }
 
@@ -2,6 +2,13 @@ package strictjson
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -287,6 +294,72 @@ func TestMapWithPrimitiveValues(t *testing.T) {
 	}
 }
 
+func TestMapWithNonStringKeys(t *testing.T) {
+	type Config struct {
+		Name string `json:"name"`
+	}
+	type Shard uint8
+	type Cluster struct {
+		Nodes  map[int]Config     `json:"nodes"`
+		Shards map[Shard]*Config  `json:"shards"`
+		Ports  map[int]int        `json:"ports"`
+		Names  []map[uint8]string `json:"names"`
+	}
+
+	var c Cluster
+	err := Unmarshal([]byte(`{"nodes": {"-1": {"name": "a"}, "42": {"name": "b"}}, "shards": {"255": {"name": "c"}}, "ports": {"80": 8080}, "names": [{"7": "d"}]}`), &c)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if c.Nodes[-1].Name != "a" || c.Nodes[42].Name != "b" || c.Shards[255].Name != "c" || c.Ports[80] != 8080 || c.Names[0][7] != "d" {
+		t.Errorf("Unexpected result: %+v", c)
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		wantPath string
+		wantMsg  string
+	}{
+		{name: "not a number", json: `{"nodes": {"abc": {}}}`, wantPath: "nodes.abc", wantMsg: `strictjson: key "abc" at "nodes.abc" is not a valid Go int`},
+		{name: "fraction", json: `{"nodes": {"1.5": {}}}`, wantPath: "nodes.1.5"},
+		{name: "overflow", json: `{"shards": {"256": {}}}`, wantPath: "shards.256"},
+		{name: "negative unsigned", json: `{"shards": {"-1": {}}}`, wantPath: "shards.-1"},
+		{name: "scalar values", json: `{"ports": {"x": 1}}`, wantPath: "ports.x", wantMsg: `strictjson: key "x" at "ports.x" is not a valid Go int`},
+		{name: "nested scalar values", json: `{"names": [{"256": "d"}]}`, wantPath: "names[0].256"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c Cluster
+			var m map[string]any
+			if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+				t.Fatal(err)
+			}
+			for _, err := range []error{
+				Unmarshal([]byte(tt.json), &c),
+				Validate([]byte(tt.json), (*Cluster)(nil)),
+				NewDecoder().DecodeMap(m, &c),
+			} {
+				var ike *InvalidKeyError
+				if !errors.As(err, &ike) {
+					t.Fatalf("Expected *InvalidKeyError, got %T (%v)", err, err)
+				}
+				if ike.Path() != tt.wantPath {
+					t.Errorf("Expected path %q, got %q", tt.wantPath, ike.Path())
+				}
+				if tt.wantMsg != "" && err.Error() != tt.wantMsg {
+					t.Errorf("Expected %q, got %q", tt.wantMsg, err)
+				}
+			}
+
+			// encoding/json rejects the same keys.
+			if err := json.Unmarshal([]byte(tt.json), &c); err == nil {
+				t.Error("Expected encoding/json to reject the key too")
+			}
+		})
+	}
+}
+
 // =============================================================================
 // Embedded Struct Tests
 // =============================================================================
@@ -348,6 +421,287 @@ func TestEmbeddedStructConflict(t *testing.T) {
 	}
 }
 
+func TestTaggedEmbeddedStruct(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Audit struct {
+		By string `json:"by"`
+	}
+	type Tags []string
+	type Record struct {
+		Base   `json:"base"`
+		*Audit `json:"audit"`
+		Tags
+		Name string `json:"name"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr string
+	}{
+		{"tagged embedded is an object", `{"base": {"id": 1}, "audit": {"by": "x"}, "Tags": ["a"], "name": "n"}`, ""},
+		{"tagged embedded fields not promoted", `{"id": 1}`, "id"},
+		{"tagged embedded pointer fields not promoted", `{"by": "x"}`, "by"},
+		{"unknown key inside tagged embedded", `{"base": {"ID": 1}}`, "base.ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Record
+			err := Unmarshal([]byte(tt.json), &r)
+			var ufe *UnknownFieldError
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Unmarshal() unexpected error: %v", err)
+			case tt.wantErr != "" && (!errors.As(err, &ufe) || ufe.Path() != tt.wantErr):
+				t.Errorf("Expected unknown field at %q, got %v", tt.wantErr, err)
+			}
+
+			var want Record
+			if err := json.Unmarshal([]byte(tt.json), &want); err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr == "" && !reflect.DeepEqual(r, want) {
+				t.Errorf("Got %+v, encoding/json decoded %+v", r, want)
+			}
+		})
+	}
+}
+
+func TestEmbeddedStructPointer(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Extended struct {
+		*Base
+		Name string `json:"name"`
+	}
+
+	var e Extended
+	if err := Unmarshal([]byte(`{"name": "n"}`), &e); err != nil || e.Base != nil {
+		t.Errorf("Expected nil embedded pointer, got %+v, %v", e, err)
+	}
+	if err := Unmarshal([]byte(`{"id": 7}`), &e); err != nil || e.Base == nil || e.ID != 7 {
+		t.Errorf("Expected promoted field through allocated pointer, got %+v, %v", e, err)
+	}
+}
+
+func TestRepeatedEmbeddedType(t *testing.T) {
+	type Shared struct {
+		Shared string `json:"shared"`
+	}
+	type Left struct {
+		Shared
+		Left string `json:"left"`
+	}
+	type Right struct {
+		*Shared
+		Right string `json:"right"`
+	}
+
+	// Shared is reachable through both branches at the same depth,
+	// so "shared" is ambiguous, as it is for encoding/json.
+	type Both struct {
+		Left
+		*Right
+	}
+	var b Both
+	err := Unmarshal([]byte(`{"left": "l", "right": "r"}`), &b)
+	var fce *FieldConflictError
+	if !errors.As(err, &fce) || fce.Field() != "shared" {
+		t.Fatalf("Expected conflict on shared, got %v", err)
+	}
+	if want := []string{"Left.Shared.Shared", "Right.Shared.Shared"}; !reflect.DeepEqual(fce.Sources(), want) {
+		t.Errorf("Sources() = %q, want %q", fce.Sources(), want)
+	}
+
+	// Embedded again one level deeper, it is shadowed instead.
+	type Shallow struct {
+		Shared
+		*Right
+	}
+	var sh Shallow
+	if err := Unmarshal([]byte(`{"shared": "s", "right": "r"}`), &sh); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if sh.Shared.Shared != "s" || sh.Right == nil || sh.Right.Shared != nil {
+		t.Errorf("Expected shallow field set and deep pointer untouched, got %+v", sh)
+	}
+}
+
+type FlatRecA struct {
+	*FlatRecB
+	A    int
+	Name string `json:"name"`
+}
+
+type FlatRecB struct {
+	*FlatRecA
+	B    int
+	Name string
+}
+
+func TestEmbeddedFieldSetsMatchEncodingJSON(t *testing.T) {
+	type Inner struct {
+		X, Y int
+	}
+	type Tagged struct {
+		Name string `json:"name"`
+	}
+	type Untagged struct {
+		Name string
+		Only int
+	}
+	type Deep struct {
+		Inner
+	}
+	type Deep2 struct {
+		Inner
+		W int
+	}
+	type Node struct {
+		*Node
+		Value int
+	}
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"tag beats untagged at same depth", struct {
+			Tagged
+			Untagged
+		}{}},
+		{"two untagged conflict", struct {
+			Untagged
+			Deep
+			Other Untagged
+		}{}},
+		{"shallow beats deep", struct {
+			Deep
+			X string
+		}{}},
+		{"same type twice at one depth", struct {
+			Deep
+			Deep2
+		}{}},
+		{"self-recursive pointer", Node{Node: &Node{}}},
+		{"mutually recursive pointers", FlatRecA{FlatRecB: &FlatRecB{FlatRecA: &FlatRecA{}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m map[string]any
+			if err := json.Unmarshal(data, &m); err != nil {
+				t.Fatal(err)
+			}
+			want := make([]string, 0, len(m))
+			for k := range m {
+				want = append(want, k)
+			}
+			sort.Strings(want)
+
+			sf := buildStructFields(reflect.TypeOf(tt.value), fieldConfig{})
+			got := append([]string(nil), sf.allNames...)
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("fields = %q, encoding/json marshals %q", got, want)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Per-Field Case Policy Tests
+// =============================================================================
+
+func TestNocaseFieldOption(t *testing.T) {
+	type Response struct {
+		RequestID   string `json:"x-request-id" strict:"nocase"`
+		ContentType string `json:"contentType"`
+	}
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{name: "exact", json: `{"x-request-id": "abc"}`, wantErr: false},
+		{name: "mixed case", json: `{"X-Request-Id": "abc"}`, wantErr: false},
+		{name: "upper case", json: `{"X-REQUEST-ID": "abc"}`, wantErr: false},
+		{name: "strict sibling", json: `{"x-request-id": "abc", "ContentType": "json"}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r Response
+			err := Unmarshal([]byte(tt.json), &r)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && r.RequestID != "abc" {
+				t.Errorf("Expected RequestID='abc', got '%s'", r.RequestID)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// Remain Field Tests
+// =============================================================================
+
+func TestRemainFieldCollectsUnknownKeys(t *testing.T) {
+	type Event struct {
+		Name  string                     `json:"name"`
+		Extra map[string]json.RawMessage `strict:",remain"`
+	}
+
+	var e Event
+	err := Unmarshal([]byte(`{"name": "signup", "plan": "pro", "meta": {"a": 1}}`), &e)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if e.Name != "signup" {
+		t.Errorf("Expected Name='signup', got '%s'", e.Name)
+	}
+	if string(e.Extra["plan"]) != `"pro"` || string(e.Extra["meta"]) != `{"a": 1}` {
+		t.Errorf("Unexpected remain contents: %v", e.Extra)
+	}
+	if _, ok := e.Extra["Extra"]; ok {
+		t.Error("Remain field must not be matched by its own name")
+	}
+
+	// Known fields keep strict casing; mis-cased keys land in remain.
+	var e2 Event
+	if err := Unmarshal([]byte(`{"Name": "signup"}`), &e2); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if e2.Name != "" || string(e2.Extra["Name"]) != `"signup"` {
+		t.Errorf("Expected mis-cased key in remain, got %+v", e2)
+	}
+}
+
+func TestRemainFieldInvalidType(t *testing.T) {
+	type Event struct {
+		Name  string         `json:"name"`
+		Extra map[string]any `strict:",remain"`
+	}
+
+	var e Event
+	err := Unmarshal([]byte(`{"name": "signup"}`), &e)
+
+	var ite *InvalidTagError
+	if !errors.As(err, &ite) || ite.Field() != "Extra" {
+		t.Errorf("Expected *InvalidTagError for Extra, got %v", err)
+	}
+}
+
 // =============================================================================
 // Pointer Field Tests
 // =============================================================================
@@ -433,57 +787,1224 @@ func TestCustomUnmarshaler(t *testing.T) {
 	}
 }
 
-func TestStructImplementsUnmarshaler(t *testing.T) {
-	// If the entire struct implements Unmarshaler, we delegate completely
-	var ct CustomTime
-	err := Unmarshal([]byte(`"2024-01-15"`), &ct)
-	if err != nil {
-		t.Errorf("Unmarshal() unexpected error = %v", err)
+func TestStructImplementsUnmarshaler(t *testing.T) {
+	// If the entire struct implements Unmarshaler, we delegate completely
+	var ct CustomTime
+	err := Unmarshal([]byte(`"2024-01-15"`), &ct)
+	if err != nil {
+		t.Errorf("Unmarshal() unexpected error = %v", err)
+	}
+}
+
+// orderRecorder appends its JSON string value to a shared log when decoded.
+type orderRecorder struct {
+	log *[]string
+}
+
+func (r *orderRecorder) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*r.log = append(*r.log, s)
+	return nil
+}
+
+func TestDocumentOrder(t *testing.T) {
+	type Inner struct {
+		X orderRecorder `json:"x"`
+	}
+	type Doc struct {
+		A     orderRecorder            `json:"a"`
+		B     orderRecorder            `json:"b"`
+		C     orderRecorder            `json:"c"`
+		Inner Inner                    `json:"inner"`
+		Map   map[string]orderRecorder `json:"map"`
+	}
+
+	var log []string
+	doc := Doc{
+		A:     orderRecorder{&log},
+		B:     orderRecorder{&log},
+		C:     orderRecorder{&log},
+		Inner: Inner{X: orderRecorder{&log}},
+	}
+	data := `{"c": "c", "inner": {"x": "x"}, "a": "a", "b": "b"}`
+	if err := Unmarshal([]byte(data), &doc); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+	if want := []string{"c", "x", "a", "b"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("Expected decode order %v, got %v", want, log)
+	}
+
+	type Last struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	var last Last
+	data = `{"name": "first", "tags": ["a"], "name": "second", "tags": ["b"]}`
+	if err := Unmarshal([]byte(data), &last); err != nil {
+		t.Fatalf("Unmarshal() unexpected error = %v", err)
+	}
+	if last.Name != "second" || !reflect.DeepEqual(last.Tags, []string{"b"}) {
+		t.Errorf("Expected last value to win, got %+v", last)
+	}
+}
+
+// textLevel is an enum decoded from its name.
+type textLevel int
+
+func (l *textLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+// textID is a struct decoded from text, like uuid.UUID or netip.Addr.
+type textID struct {
+	Prefix string
+	N      int
+}
+
+func (id *textID) UnmarshalText(text []byte) error {
+	prefix, n, ok := strings.Cut(string(text), "-")
+	if !ok {
+		return errors.New("missing dash")
+	}
+	id.Prefix = prefix
+	_, err := fmt.Sscan(n, &id.N)
+	return err
+}
+
+func TestTextUnmarshalerFieldsAndKeys(t *testing.T) {
+	type Rule struct {
+		Action string `json:"action"`
+	}
+	type Policy struct {
+		Owner  textID               `json:"owner"`
+		IP     net.IP               `json:"ip"`
+		Level  textLevel            `json:"level"`
+		Rules  map[textLevel]Rule   `json:"rules"`
+		ByID   map[textID][]Rule    `json:"by_id"`
+		Levels map[textLevel]string `json:"levels"`
+	}
+
+	data := []byte(`{
+		"owner": "team-7",
+		"ip": "10.0.0.1",
+		"level": "high",
+		"rules": {"low": {"action": "log"}, "high": {"action": "deny"}},
+		"by_id": {"svc-1": [{"action": "allow"}]},
+		"levels": {"low": "x"}
+	}`)
+	var p Policy
+	if err := Unmarshal(data, &p); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if p.Owner != (textID{"team", 7}) || !p.IP.Equal(net.IPv4(10, 0, 0, 1)) || p.Level != 2 {
+		t.Errorf("Unexpected fields: %+v", p)
+	}
+	if p.Rules[1].Action != "log" || p.Rules[2].Action != "deny" {
+		t.Errorf("Unexpected rules: %v", p.Rules)
+	}
+	if p.ByID[textID{"svc", 1}][0].Action != "allow" || p.Levels[1] != "x" {
+		t.Errorf("Unexpected maps: %v %v", p.ByID, p.Levels)
+	}
+
+	// The key is checked like any other: rules values are still strict.
+	err := Unmarshal([]byte(`{"rules": {"low": {"Action": "log"}}}`), &p)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "rules.low.Action" {
+		t.Errorf("Expected unknown field at rules.low.Action, got %v", err)
+	}
+
+	// Text values are not validated as objects.
+	var tme *TypeMismatchError
+	if err := Unmarshal([]byte(`{"owner": {"Prefix": "x"}}`), &p); !errors.As(err, &tme) {
+		t.Errorf("Expected *TypeMismatchError for an object, got %v", err)
+	}
+
+	err = Unmarshal([]byte(`{"rules": {"medium": {"action": "log"}}}`), &p)
+	var ike *InvalidKeyError
+	if !errors.As(err, &ike) || ike.Path() != "rules.medium" || ike.Key() != "medium" {
+		t.Fatalf("Expected *InvalidKeyError at rules.medium, got %v", err)
+	}
+	want := `strictjson: key "medium" at "rules.medium" is not a valid Go strictjson.textLevel: unknown level "medium"`
+	if err.Error() != want {
+		t.Errorf("Expected %q, got %q", want, err)
+	}
+}
+
+// =============================================================================
+// Decoder Options Tests
+// =============================================================================
+
+func TestDisallowUnknownFieldsOption(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	// Default: DisallowUnknownFields = true
+	var p1 Person
+	err := Unmarshal([]byte(`{"name": "John", "extra": "field"}`), &p1)
+	if err == nil {
+		t.Error("Expected error for unknown field with default settings")
+	}
+
+	// With DisallowUnknownFields = false
+	d := NewDecoder(WithDisallowUnknownFields(false))
+	var p2 Person
+	err = d.Unmarshal([]byte(`{"name": "John", "extra": "field"}`), &p2)
+	if err != nil {
+		t.Errorf("Unmarshal() unexpected error with DisallowUnknownFields=false: %v", err)
+	}
+	if p2.Name != "John" {
+		t.Errorf("Expected Name='John', got '%s'", p2.Name)
+	}
+}
+
+func TestAllowedExtraFieldsOption(t *testing.T) {
+	type Link struct {
+		Href string `json:"href"`
+	}
+	type Resource struct {
+		Name  string `json:"name"`
+		Links []Link `json:"links"`
+	}
+
+	d := NewDecoder(WithAllowedExtraFields("$schema", "_debug"))
+
+	var r Resource
+	err := d.Unmarshal([]byte(`{"$schema": "x", "name": "a", "links": [{"href": "/", "_debug": true}]}`), &r)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if r.Name != "a" || r.Links[0].Href != "/" {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+
+	err = d.Unmarshal([]byte(`{"name": "a", "Schema": "x"}`), &r)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Field() != "Schema" {
+		t.Errorf("Expected unknown field \"Schema\", got %v", err)
+	}
+}
+
+func TestIgnorePathsOption(t *testing.T) {
+	type Internal struct {
+		Build string `json:"build"`
+	}
+	type Entry struct {
+		ID       int      `json:"id"`
+		Internal Internal `json:"internal"`
+	}
+	type Meta struct {
+		Page int `json:"page"`
+	}
+	type Payload struct {
+		Data []Entry `json:"data"`
+		Meta Meta    `json:"meta"`
+	}
+
+	d := NewDecoder(WithIgnorePaths("data.*.internal", "meta.**", "vendor"))
+
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{
+			name:    "unknown key in ignored subtree",
+			json:    `{"data": [{"id": 1, "internal": {"build": "x", "Commit": "y"}}]}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown key beside ignored subtree",
+			json:    `{"data": [{"id": 1, "ID": 2}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "double star covers root and descendants",
+			json:    `{"meta": {"Page": 1, "cursor": {"next": "abc"}}}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown key matched by pattern",
+			json:    `{"vendor": {"anything": true}}`,
+			wantErr: false,
+		},
+		{
+			name:    "unknown key at root stays strict",
+			json:    `{"data": [{"id": 1, "internal": {"build": "x"}}], "Data": []}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+			err := d.Unmarshal([]byte(tt.json), &p)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	indexed := NewDecoder(WithIgnorePaths("data[1]"))
+	var p Payload
+	if err := indexed.Unmarshal([]byte(`{"data": [{"id": 1}, {"id": 2, "extra": 3}]}`), &p); err != nil {
+		t.Errorf("Unmarshal() unexpected error with indexed pattern: %v", err)
+	}
+}
+
+func TestOnUnknownFieldOption(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Person struct {
+		Name    string  `json:"name"`
+		Address Address `json:"address"`
+	}
+
+	type call struct{ path, key, suggestion string }
+	var calls []call
+	d := NewDecoder(WithSuggestClosest(true), WithOnUnknownField(func(path, key, suggestion string) error {
+		calls = append(calls, call{path, key, suggestion})
+		if key == "fatal" {
+			return errors.New("rejected")
+		}
+		return nil
+	}))
+
+	var p Person
+	err := d.Unmarshal([]byte(`{"Name": "John", "name": "Jane", "address": {"CITY": "NYC", "city": "LA"}}`), &p)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if p.Name != "Jane" || p.Address.City != "LA" {
+		t.Errorf("Unexpected result: %+v", p)
+	}
+	want := []call{{"", "Name", "name"}, {"address", "CITY", "city"}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("Hook calls = %v, want %v", calls, want)
+	}
+
+	err = d.Unmarshal([]byte(`{"fatal": 1}`), &p)
+	if err == nil || err.Error() != "rejected" {
+		t.Errorf("Expected hook error to abort decoding, got %v", err)
+	}
+}
+
+func TestSuggestClosestOption(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	// With SuggestClosest = true
+	d := NewDecoder(WithSuggestClosest(true))
+	var p Person
+	err := d.Unmarshal([]byte(`{"Name": "John"}`), &p)
+	if err == nil {
+		t.Error("Expected error for wrong case field")
+	}
+	if err != nil && err.Error() != `strictjson: unknown field "Name" (did you mean "name"?)` {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+}
+
+func TestSuggestionLimitOption(t *testing.T) {
+	type Address struct {
+		Zip          string `json:"zip"`
+		ZipCode      string `json:"zipCode"`
+		Zone         string `json:"zone"`
+		AddressLine1 string `json:"address_line_1"`
+	}
+
+	tests := []struct {
+		name string
+		opts []DecoderOption
+		key  string
+		want []string
+		msg  string
+	}{
+		{
+			name: "default single suggestion",
+			key:  "zipp",
+			want: []string{"zip"},
+			msg:  `strictjson: unknown field "zipp" (did you mean "zip"?)`,
+		},
+		{
+			name: "ranked by distance",
+			opts: []DecoderOption{WithSuggestionLimit(3)},
+			key:  "zipCod",
+			want: []string{"zipCode"},
+		},
+		{
+			name: "case match ranks first",
+			opts: []DecoderOption{WithSuggestionLimit(3)},
+			key:  "ZIP",
+			want: []string{"zip"},
+		},
+		{
+			name: "several suggestions",
+			opts: []DecoderOption{WithSuggestionLimit(3), WithSuggestionMaxDistance(4)},
+			key:  "zipC",
+			want: []string{"zip", "zipCode", "zone"},
+			msg:  `strictjson: unknown field "zipC" (did you mean "zip", "zipCode" or "zone"?)`,
+		},
+		{
+			name: "wider distance",
+			opts: []DecoderOption{WithSuggestionMaxDistance(3)},
+			key:  "adres_line1",
+			want: []string{"address_line_1"},
+		},
+		{
+			name: "nothing close enough",
+			key:  "adres_line1",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(append([]DecoderOption{WithSuggestClosest(true)}, tt.opts...)...)
+			var a Address
+			err := d.Unmarshal([]byte(`{"`+tt.key+`": ""}`), &a)
+
+			var ufe *UnknownFieldError
+			if !errors.As(err, &ufe) {
+				t.Fatalf("Expected *UnknownFieldError, got %T (%v)", err, err)
+			}
+			if !reflect.DeepEqual(ufe.Suggestions(), tt.want) {
+				t.Errorf("Suggestions() = %q, want %q", ufe.Suggestions(), tt.want)
+			}
+			if tt.msg != "" && err.Error() != tt.msg {
+				t.Errorf("Error() = %q, want %q", err.Error(), tt.msg)
+			}
+		})
+	}
+}
+
+func TestSuggestionDistance(t *testing.T) {
+	type Item struct {
+		City  string `json:"city"`
+		Naive string `json:"naïve"`
+		Kind  string `json:"kind"`
+		Label string `json:"label"`
+		Lable string `json:"lable"`
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want []string
+	}{
+		{"transposition", "ctiy", []string{"city"}},
+		{"multi-byte rune", "naíve", []string{"naïve"}},
+		{"unicode case folding", "\u212AIND", []string{"kind"}}, // Kelvin sign
+		{"case-insensitive edit", "CITI", []string{"city"}},
+		{"fold match beats closer edit", "LABLE", []string{"lable", "label"}},
+	}
+
+	d := NewDecoder(WithSuggestClosest(true), WithSuggestionLimit(2), WithSuggestionMaxDistance(1))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var it Item
+			err := d.Unmarshal([]byte(`{"`+tt.key+`": ""}`), &it)
+
+			var ufe *UnknownFieldError
+			if !errors.As(err, &ufe) {
+				t.Fatalf("Expected *UnknownFieldError, got %T (%v)", err, err)
+			}
+			if !reflect.DeepEqual(ufe.Suggestions(), tt.want) {
+				t.Errorf("Suggestions() = %q, want %q", ufe.Suggestions(), tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestionBudget(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+
+	var keys []string
+	for i := 0; i < 5; i++ {
+		keys = append(keys, `"Name": ""`)
+	}
+	keys = append(keys, `"`+strings.Repeat("n", 1000)+`": ""`)
+	data := []byte("{" + strings.Join(keys, ", ") + "}")
+
+	d := NewDecoder(WithSuggestClosest(true), WithSuggestionBudget(3))
+	var it Item
+	violations, err := d.Check(data, &it)
+	if err != nil {
+		t.Fatalf("Check() unexpected error = %v", err)
+	}
+	var got []string
+	for _, v := range violations {
+		if v.Field == "Name" {
+			got = append(got, v.Suggestion)
+		}
+	}
+	if want := []string{"name", "name", "name", "", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected suggestions %q within the budget, got %q", want, got)
+	}
+}
+
+func TestTagKeyOption(t *testing.T) {
+	type User struct {
+		ID     string `api:"userId" json:"id"`
+		Email  string `json:"email"`
+		Secret string `api:"-" json:"secret"`
+		Note   string
+	}
+
+	tests := []struct {
+		name    string
+		keys    []string
+		data    string
+		wantErr string
+	}{
+		{"api names", []string{"api"}, `{"userId": "u", "Email": "e", "Note": "n"}`, ""},
+		{"json tag ignored", []string{"api"}, `{"id": "u"}`, "id"},
+		{"fallback to json", []string{"api", "json"}, `{"userId": "u", "email": "e"}`, ""},
+		{"api name wins over json", []string{"api", "json"}, `{"id": "u"}`, "id"},
+		{"dash skips field", []string{"api", "json"}, `{"secret": "s"}`, "secret"},
+		{"json only", []string{"json"}, `{"id": "u", "secret": "s"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var u User
+			err := NewDecoder(WithTagKey(tt.keys...)).Unmarshal([]byte(tt.data), &u)
+			var ufe *UnknownFieldError
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Unmarshal() unexpected error: %v", err)
+			case tt.wantErr != "" && (!errors.As(err, &ufe) || ufe.Field() != tt.wantErr):
+				t.Errorf("Expected unknown field %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	var u User
+	if err := NewDecoder(WithTagKey("api")).Unmarshal([]byte(`{"userId": "u1"}`), &u); err != nil || u.ID != "u1" {
+		t.Errorf("Expected ID decoded from api tag, got %+v, %v", u, err)
+	}
+}
+
+func TestStringTagOption(t *testing.T) {
+	type Order struct {
+		Count  int     `json:"count,string"`
+		Price  float64 `json:"price,omitempty,string"`
+		Paid   *bool   `json:"paid,string"`
+		Label  string  `json:"label,string"`
+		Amount int     `json:",string"`
+		Tags   []int   `json:"tags,string"` // not a scalar; the option is ignored
+	}
+
+	var o Order
+	err := Unmarshal([]byte(`{"count": "12", "price": "1.5", "paid": "true", "label": "\"x\"", "Amount": "7", "tags": [1]}`), &o)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if o.Count != 12 || o.Price != 1.5 || o.Paid == nil || !*o.Paid || o.Label != "x" || o.Amount != 7 || len(o.Tags) != 1 {
+		t.Errorf("Unexpected result %+v", o)
+	}
+
+	err = Unmarshal([]byte(`{"count": "twelve"}`), &o)
+	var tme *TypeMismatchError
+	if !errors.As(err, &tme) || tme.Path() != "count" || tme.Found() != "string" {
+		t.Errorf("Expected type mismatch at count, got %v", err)
+	}
+
+	// Bare values are accepted unless StrictStringOption is set.
+	data := []byte(`{"count": 3, "paid": null}`)
+	o = Order{}
+	if err := Unmarshal(data, &o); err != nil || o.Count != 3 || o.Paid != nil {
+		t.Errorf("Unmarshal() = %+v, %v", o, err)
+	}
+	d := NewDecoder(WithStrictStringOption(true))
+	var uve *UnquotedValueError
+	if err := d.Unmarshal(data, &o); !errors.As(err, &uve) || uve.Path() != "count" || uve.Found() != "number" {
+		t.Errorf("Expected unquoted value error at count, got %v", err)
+	}
+	if err := d.Validate(data, o); !errors.As(err, &uve) {
+		t.Errorf("Validate() expected unquoted value error, got %v", err)
+	}
+	if err := d.DecodeMap(map[string]any{"count": 3.0}, &o); !errors.As(err, &uve) {
+		t.Errorf("DecodeMap() expected unquoted value error, got %v", err)
+	}
+	if err := d.DecodeMap(map[string]any{"count": "5"}, &o); err != nil || o.Count != 5 {
+		t.Errorf("DecodeMap() = %+v, %v", o, err)
+	}
+	violations, err := d.Check(data, &o)
+	if err != nil || len(violations) != 1 || violations[0].Path != "count" || o.Count != 3 {
+		t.Errorf("Check() = %v, %v", violations, err)
+	}
+}
+
+func TestArrays(t *testing.T) {
+	type Point struct {
+		X int `json:"x"`
+	}
+	type Shape struct {
+		Corners [2]Point    `json:"corners"`
+		Coords  *[3]float64 `json:"coords"`
+		Grid    [2][2]int   `json:"grid"`
+	}
+
+	// Elements of arrays of structs are validated like those of slices.
+	var sh Shape
+	err := Unmarshal([]byte(`{"corners": [{"x": 1}, {"X": 2}]}`), &sh)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "corners[1].X" {
+		t.Errorf("Expected unknown field at corners[1].X, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		want   Shape
+		path   string
+		found  int
+		length int
+	}{
+		{"exact", `{"corners": [{"x": 1}, {"x": 2}], "coords": [1, 2, 3], "grid": [[1, 2], [3, 4]]}`,
+			Shape{Corners: [2]Point{{1}, {2}}, Coords: &[3]float64{1, 2, 3}, Grid: [2][2]int{{1, 2}, {3, 4}}}, "", 0, 0},
+		{"short struct array", `{"corners": [{"x": 1}]}`, Shape{Corners: [2]Point{{1}}}, "corners", 1, 2},
+		{"long struct array", `{"corners": [{"x": 1}, {"x": 2}, {"x": 3}]}`, Shape{Corners: [2]Point{{1}, {2}}}, "corners", 3, 2},
+		{"short scalar array", `{"coords": [1, 2]}`, Shape{Coords: &[3]float64{1, 2}}, "coords", 2, 3},
+		{"empty", `{"coords": []}`, Shape{Coords: &[3]float64{}}, "coords", 0, 3},
+		{"nested", `{"grid": [[1, 2], [3]]}`, Shape{Grid: [2][2]int{{1, 2}, {3}}}, "grid[1]", 1, 2},
+	}
+
+	strict := NewDecoder(WithStrictArrayLength(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// By default arrays are truncated and zero-filled like
+			// encoding/json does.
+			var got, want Shape
+			if err := Unmarshal([]byte(tt.data), &got); err != nil {
+				t.Fatalf("Unmarshal() unexpected error: %v", err)
+			}
+			if err := json.Unmarshal([]byte(tt.data), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || !reflect.DeepEqual(got, want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+
+			var m map[string]any
+			if err := json.Unmarshal([]byte(tt.data), &m); err != nil {
+				t.Fatal(err)
+			}
+			for api, err := range map[string]error{
+				"Unmarshal": strict.Unmarshal([]byte(tt.data), &Shape{}),
+				"Validate":  strict.Validate([]byte(tt.data), Shape{}),
+				"DecodeMap": strict.DecodeMap(m, &Shape{}),
+			} {
+				if tt.path == "" {
+					if err != nil {
+						t.Errorf("%s: unexpected error: %v", api, err)
+					}
+					continue
+				}
+				var ale *ArrayLengthError
+				if !errors.As(err, &ale) || !errors.Is(err, ErrDecode) {
+					t.Errorf("%s: expected *ArrayLengthError, got %v", api, err)
+					continue
+				}
+				if ale.Path() != tt.path || ale.Found() != tt.found || ale.Len() != tt.length {
+					t.Errorf("%s: expected %d of %d elements at %q, got %v", api, tt.found, tt.length, tt.path, err)
+				}
+			}
+		})
+	}
+
+	violations, err := strict.Check([]byte(`{"corners": [], "grid": [[1], [2, 3, 4]]}`), &sh)
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path)
+	}
+	if err != nil || !reflect.DeepEqual(paths, []string{"corners", "grid[0]", "grid[1]"}) {
+		t.Errorf("Check() = %v, %v", violations, err)
+	}
+}
+
+func TestArraySurplusElements(t *testing.T) {
+	type Point struct {
+		X int `json:"x"`
+	}
+	type Shape struct {
+		Corners [2]Point `json:"corners"`
+	}
+
+	// Elements beyond the Go array's length are discarded, but checked
+	// first.
+	data := `{"corners": [{}, {}, {"X": 1}]}`
+	var m map[string]any
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatal(err)
+	}
+	for api, err := range map[string]error{
+		"Unmarshal": Unmarshal([]byte(data), &Shape{}),
+		"Validate":  Validate([]byte(data), Shape{}),
+		"DecodeMap": DecodeMap(m, &Shape{}),
+	} {
+		var ufe *UnknownFieldError
+		if !errors.As(err, &ufe) || ufe.Path() != "corners[2].X" {
+			t.Errorf("%s: expected unknown field at corners[2].X, got %v", api, err)
+		}
+	}
+
+	var sh Shape
+	violations, err := NewDecoder().Check([]byte(`{"corners": [{"x": 1}, {"x": 2}, {"X": 3}]}`), &sh)
+	if err != nil || len(violations) != 1 || violations[0].Path != "corners[2].X" {
+		t.Errorf("Check() = %v, %v", violations, err)
+	}
+	if sh.Corners != [2]Point{{1}, {2}} {
+		t.Errorf("Expected the surplus element discarded, got %+v", sh)
+	}
+}
+
+func TestCaseSensitiveOption(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Person struct {
+		Name    string    `json:"name"`
+		NAME    string    `json:"NAME"`
+		Address []Address `json:"address"`
+	}
+
+	d := NewDecoder(WithCaseSensitive(false))
+	var p Person
+	err := d.Unmarshal([]byte(`{"Name": "a", "NAME": "b", "Address": [{"CITY": "NYC"}]}`), &p)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if p.Name != "a" || p.NAME != "b" || len(p.Address) != 1 || p.Address[0].City != "NYC" {
+		t.Errorf("Expected keys matched case-insensitively, got %+v", p)
+	}
+
+	err = d.Unmarshal([]byte(`{"address": [{"city": "NYC", "zip": "10001"}]}`), &p)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Path() != "address[0].zip" {
+		t.Errorf("Expected unknown field at address[0].zip, got %v", err)
+	}
+	if err := d.Validate([]byte(`{"nAmE": "a"}`), p); err != nil {
+		t.Errorf("Validate() unexpected error: %v", err)
+	}
+}
+
+func TestDisallowDuplicateKeysOption(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	type Payload struct {
+		Name  string            `json:"name"`
+		Items []Item            `json:"items"`
+		Tags  map[string]string `json:"tags"`
+		Extra any               `json:"extra"`
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		wantKey  string
+		wantPath string
+	}{
+		{
+			name:     "struct object",
+			json:     `{"name": "a", "name": "b"}`,
+			wantKey:  "name",
+			wantPath: "name",
+		},
+		{
+			name:     "nested struct in slice",
+			json:     `{"items": [{"name": "a"}, {"name": "a", "name": "b"}]}`,
+			wantKey:  "name",
+			wantPath: "items[1].name",
+		},
+		{
+			name:     "delegated map",
+			json:     `{"tags": {"env": "prod", "env": "dev"}}`,
+			wantKey:  "env",
+			wantPath: "tags.env",
+		},
+		{
+			name:     "delegated interface value",
+			json:     `{"extra": {"a": [1, {"b": 1, "c": 2, "b": 3}]}}`,
+			wantKey:  "b",
+			wantPath: "extra.a[1].b",
+		},
+		{
+			name:     "escaped duplicate",
+			json:     `{"name": "a", "\u006eame": "b"}`,
+			wantKey:  "name",
+			wantPath: "name",
+		},
+	}
+
+	d := NewDecoder(WithDisallowDuplicateKeys(true))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+			err := d.Unmarshal([]byte(tt.json), &p)
+
+			var dke *DuplicateKeyError
+			if !errors.As(err, &dke) {
+				t.Fatalf("Expected *DuplicateKeyError, got %T (%v)", err, err)
+			}
+			if dke.Key() != tt.wantKey || dke.Path() != tt.wantPath {
+				t.Errorf("Got key %q at %q, want %q at %q", dke.Key(), dke.Path(), tt.wantKey, tt.wantPath)
+			}
+
+			// Default decoder keeps last-write-wins semantics.
+			if err := Unmarshal([]byte(tt.json), &p); err != nil {
+				t.Errorf("Default decoder unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestRejectEscapedKeysOption(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	type Payload struct {
+		Name  string            `json:"name"`
+		Items []Item            `json:"items"`
+		Index map[string]Item   `json:"index"`
+		Tags  map[string]string `json:"tags"`
+		Extra any               `json:"extra"`
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		wantKey  string
+		wantPath string
+	}{
+		{name: "struct key", json: `{"n\u0061me": "a"}`, wantKey: "name", wantPath: "name"},
+		{name: "unknown key", json: `{"\u0062ogus": 1}`, wantKey: "bogus", wantPath: "bogus"},
+		{name: "nested struct in slice", json: `{"items": [{"name": "a"}, {"\u006eame": "b"}]}`, wantKey: "name", wantPath: "items[1].name"},
+		{name: "map of structs", json: `{"index": {"a\/b": {"name": "c"}}}`, wantKey: "a/b", wantPath: "index.a/b"},
+		{name: "delegated map", json: `{"tags": {"env": "prod", "\u0065nv2": "dev"}}`, wantKey: "env2", wantPath: "tags.env2"},
+		{name: "delegated interface value", json: `{"extra": {"a": [1, {"\u0062": 1}]}}`, wantKey: "b", wantPath: "extra.a[1].b"},
+	}
+
+	d := NewDecoder(WithRejectEscapedKeys(true), WithDisallowUnknownFields(false))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+			err := d.Unmarshal([]byte(tt.json), &p)
+
+			var eke *EscapedKeyError
+			if !errors.As(err, &eke) {
+				t.Fatalf("Expected *EscapedKeyError, got %T (%v)", err, err)
+			}
+			if eke.Key() != tt.wantKey || eke.Path() != tt.wantPath {
+				t.Errorf("Got key %q at %q, want %q at %q", eke.Key(), eke.Path(), tt.wantKey, tt.wantPath)
+			}
+			if err := d.Validate([]byte(tt.json), &p); !errors.As(err, &eke) {
+				t.Errorf("Validate() expected *EscapedKeyError, got %v", err)
+			}
+		})
+	}
+
+	// Escaped strings in values and unescaped non-ASCII keys are unaffected.
+	var p Payload
+	ok := `{"name": "\u0061", "tags": {"k": "\n", "café": "x"}, "index": {"ü": {}}, "naïve": 1}`
+	if err := d.Unmarshal([]byte(ok), &p); err != nil {
+		t.Errorf("Unmarshal() unexpected error: %v", err)
+	}
+
+	// Check reports the key and still matches it after unescaping.
+	violations, err := d.Check([]byte(`{"n\u0061me": "a"}`), &p)
+	if err != nil || len(violations) != 1 || violations[0].Field != "name" {
+		t.Errorf("Check() = %v (%v), want one violation for \"name\"", violations, err)
+	}
+	if p.Name != "a" {
+		t.Errorf("Expected Name='a' after Check, got %q", p.Name)
+	}
+}
+
+func TestUseNumberOption(t *testing.T) {
+	type Line struct {
+		Amount any `json:"amount"`
+	}
+	type Invoice struct {
+		Total any            `json:"total"`
+		Lines []Line         `json:"lines"`
+		Attrs map[string]any `json:"attrs"`
+		Count int            `json:"count"`
+	}
+	data := []byte(`{"total": 12345678901234567890, "lines": [{"amount": 0.1}], "attrs": {"rate": [1.50]}, "count": 3}`)
+
+	var inv Invoice
+	if err := NewDecoder(WithUseNumber(true)).Unmarshal(data, &inv); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if inv.Total != json.Number("12345678901234567890") {
+		t.Errorf("Expected Total=json.Number(12345678901234567890), got %#v", inv.Total)
+	}
+	if inv.Lines[0].Amount != json.Number("0.1") {
+		t.Errorf("Expected Amount=json.Number(0.1), got %#v", inv.Lines[0].Amount)
+	}
+	if rate := inv.Attrs["rate"].([]any)[0]; rate != json.Number("1.50") {
+		t.Errorf("Expected rate=json.Number(1.50), got %#v", rate)
+	}
+	if inv.Count != 3 {
+		t.Errorf("Expected Count=3, got %d", inv.Count)
+	}
+
+	// Without the option interface values hold float64, as in encoding/json.
+	inv = Invoice{}
+	if err := Unmarshal(data, &inv); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if _, ok := inv.Total.(float64); !ok {
+		t.Errorf("Expected float64 by default, got %T", inv.Total)
+	}
+}
+
+func TestDisallowNullForNonPointerOption(t *testing.T) {
+	type Item struct {
+		Count int `json:"count"`
+	}
+	type Person struct {
+		Age   int               `json:"age"`
+		Nick  *string           `json:"nick"`
+		Tags  []string          `json:"tags"`
+		Attrs map[string]string `json:"attrs"`
+		Extra any               `json:"extra"`
+		Items []Item            `json:"items"`
+		Sizes []int             `json:"sizes"`
+		Pair  [2]int            `json:"pair"`
+		Grid  [][]int           `json:"grid"`
+		Quota map[string]int    `json:"quota"`
+		Ptrs  []*int            `json:"ptrs"`
+		Anys  []any             `json:"anys"`
+	}
+
+	d := NewDecoder(WithDisallowNullForNonPointer(true))
+
+	var ok Person
+	err := d.Unmarshal([]byte(`{"nick": null, "tags": null, "attrs": null, "extra": null, "ptrs": [null, 1], "anys": [null, {"a": null}]}`), &ok)
+	if err != nil {
+		t.Errorf("Unmarshal() unexpected error for nullable fields: %v", err)
+	}
+	if ok.Ptrs[0] != nil || *ok.Ptrs[1] != 1 || ok.Anys[0] != nil {
+		t.Errorf("Unexpected result %+v", ok)
+	}
+
+	tests := []struct {
+		json     string
+		wantPath string
+	}{
+		{json: `{"age": null}`, wantPath: "age"},
+		{json: `{"items": [{"count": 1}, {"count": null}]}`, wantPath: "items[1].count"},
+		{json: `{"sizes": [null, 1]}`, wantPath: "sizes[0]"},
+		{json: `{"pair": [1, null]}`, wantPath: "pair[1]"},
+		{json: `{"grid": [[1], [2, null]]}`, wantPath: "grid[1][1]"},
+		{json: `{"quota": {"cpu": null}}`, wantPath: "quota.cpu"},
+	}
+	for _, tt := range tests {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(tt.json), &m); err != nil {
+			t.Fatal(err)
+		}
+		var p Person
+		for _, err := range []error{
+			d.Unmarshal([]byte(tt.json), &p),
+			d.Validate([]byte(tt.json), (*Person)(nil)),
+			d.DecodeMap(m, &p),
+		} {
+			var nve *NullValueError
+			if !errors.As(err, &nve) {
+				t.Fatalf("Expected *NullValueError for %s, got %T (%v)", tt.json, err, err)
+			}
+			if nve.Path() != tt.wantPath || nve.Type().Kind() != reflect.Int {
+				t.Errorf("Got path %q type %v, want %q int", nve.Path(), nve.Type(), tt.wantPath)
+			}
+		}
+
+		if err := Unmarshal([]byte(tt.json), &p); err != nil {
+			t.Errorf("Default decoder unexpected error: %v", err)
+		}
+	}
+
+	var n int
+	err = d.Unmarshal([]byte(`null`), &n)
+	if want := "strictjson: null is not allowed at top level for type int"; err == nil || err.Error() != want {
+		t.Errorf("Expected %q, got %v", want, err)
+	}
+}
+
+// =============================================================================
+// Error Type Tests
+// =============================================================================
+
+func TestUnknownFieldErrorAccessors(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Contact struct {
+		Address Address `json:"address"`
+	}
+	type Person struct {
+		Contact Contact   `json:"contact"`
+		Items   []Address `json:"items"`
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		wantPath string
+	}{
+		{
+			name:     "nested object",
+			json:     `{"contact": {"address": {"CITY": "NYC"}}}`,
+			wantPath: "contact.address.CITY",
+		},
+		{
+			name:     "slice element",
+			json:     `{"items": [{"city": "A"}, {"City": "B"}]}`,
+			wantPath: "items[1].City",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Person
+			err := NewDecoder(WithSuggestClosest(true)).Unmarshal([]byte(tt.json), &p)
+
+			var ufe *UnknownFieldError
+			if !errors.As(err, &ufe) {
+				t.Fatalf("Expected *UnknownFieldError, got %T (%v)", err, err)
+			}
+			if ufe.Path() != tt.wantPath {
+				t.Errorf("Path() = %q, want %q", ufe.Path(), tt.wantPath)
+			}
+			if ufe.Suggestion() != "city" {
+				t.Errorf("Suggestion() = %q, want %q", ufe.Suggestion(), "city")
+			}
+			if !errors.Is(err, ErrDecode) {
+				t.Error("Expected error to wrap ErrDecode")
+			}
+		})
+	}
+}
+
+func TestFieldConflictErrorType(t *testing.T) {
+	type A struct {
+		Name string
+	}
+	type B struct {
+		Name string
+	}
+	type Conflict struct {
+		A
+		B
+	}
+
+	var c Conflict
+	err := Unmarshal([]byte(`{"Name": "test"}`), &c)
+
+	var fce *FieldConflictError
+	if !errors.As(err, &fce) || !errors.Is(err, ErrDecode) {
+		t.Fatalf("Expected *FieldConflictError, got %T (%v)", err, err)
+	}
+	if fce.Field() != "Name" {
+		t.Errorf("Field() = %q, want %q", fce.Field(), "Name")
+	}
+}
+
+func TestFieldConflictSources(t *testing.T) {
+	type A struct {
+		Name string
+	}
+	type B struct {
+		Name string
+	}
+	type C struct {
+		B
+	}
+	type Conflict struct {
+		A
+		B
+		C
+		ID int `json:"id"`
+	}
+
+	var c Conflict
+	err := Unmarshal([]byte(`{"id": 1}`), &c)
+	var fce *FieldConflictError
+	if !errors.As(err, &fce) {
+		t.Fatalf("Expected *FieldConflictError, got %v", err)
+	}
+	if want := []string{"A.Name", "B.Name"}; !reflect.DeepEqual(fce.Sources(), want) {
+		t.Errorf("Sources() = %q, want %q", fce.Sources(), want)
+	}
+	if want := `strictjson: field conflict: "Name" provided by both A.Name and B.Name`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	// Like encoding/json, the ambiguous name matches no field, including
+	// the deeper C.B.Name.
+	d := NewDecoder(WithIgnoreConflicts(true))
+	if err := d.Unmarshal([]byte(`{"id": 1}`), &c); err != nil || c.ID != 1 {
+		t.Errorf("Unmarshal() = %+v, %v", c, err)
+	}
+	var ufe *UnknownFieldError
+	if err := d.Unmarshal([]byte(`{"Name": "x"}`), &c); !errors.As(err, &ufe) {
+		t.Errorf("Expected ambiguous name to be unknown, got %v", err)
+	}
+	lax := NewDecoder(WithIgnoreConflicts(true), WithDisallowUnknownFields(false))
+	if err := lax.Unmarshal([]byte(`{"Name": "x"}`), &c); err != nil || c.A.Name != "" || c.B.Name != "" || c.C.B.Name != "" {
+		t.Errorf("Expected ambiguous name dropped, got %+v, %v", c, err)
+	}
+}
+
+func TestAllErrorsWrapSentinel(t *testing.T) {
+	type Person struct {
+		Age int `json:"age"`
+	}
+
+	var p Person
+	errs := map[string]error{
+		"non-pointer": Unmarshal([]byte(`{}`), p),
+		"syntax":      Unmarshal([]byte(`{invalid}`), &p),
+		"type":        Unmarshal([]byte(`{"age": "thirty"}`), &p),
+	}
+	for name, err := range errs {
+		var base *Error
+		if !errors.As(err, &base) {
+			t.Errorf("%s: expected error to wrap *Error, got %T (%v)", name, err, err)
+		}
+	}
+
+	var ute *json.UnmarshalTypeError
+	if !errors.As(errs["type"], &ute) {
+		t.Errorf("Expected underlying *json.UnmarshalTypeError to remain reachable")
+	}
+}
+
+func TestTypeErrorLocation(t *testing.T) {
+	type Item struct {
+		Qty int `json:"qty"`
+	}
+	var v struct {
+		Items []Item `json:"items"`
 	}
-}
 
-// =============================================================================
-// Decoder Options Tests
-// =============================================================================
+	data := []byte(`{"items": [{"qty": 1}, {"qty": "two"}]}`)
+	err := Unmarshal(data, &v)
+	var ute *json.UnmarshalTypeError
+	if !errors.As(err, &ute) {
+		t.Fatalf("Expected *json.UnmarshalTypeError, got %v", err)
+	}
+	if ute.Field != "items[1].qty" {
+		t.Errorf("Field = %q, want %q", ute.Field, "items[1].qty")
+	}
+	if want := int64(len(`{"items": [{"qty": 1}, {"qty": "two"`)); ute.Offset != want {
+		t.Errorf("Offset = %d, want %d", ute.Offset, want)
+	}
+}
 
-func TestDisallowUnknownFieldsOption(t *testing.T) {
-	type Person struct {
-		Name string `json:"name"`
+func TestTypeMismatchError(t *testing.T) {
+	type Item struct {
+		Qty   int            `json:"qty"`
+		Tags  []string       `json:"tags"`
+		Attrs map[string]int `json:"attrs"`
+	}
+	var v struct {
+		Items []Item `json:"items"`
 	}
 
-	// Default: DisallowUnknownFields = true
-	var p1 Person
-	err := Unmarshal([]byte(`{"name": "John", "extra": "field"}`), &p1)
-	if err == nil {
-		t.Error("Expected error for unknown field with default settings")
+	tests := []struct {
+		name     string
+		data     string
+		path     string
+		expected string
+		found    string
+	}{
+		{"string into int", `{"items": [{"qty": "two"}]}`, "items[0].qty", "int", "string"},
+		{"number into string", `{"items": [{"tags": ["a", 2]}]}`, "items[0].tags[1]", "string", "number"},
+		{"array into map", `{"items": [{"attrs": [1]}]}`, "items[0].attrs", "map[string]int", "array"},
+		{"object into slice", `{"items": {}}`, "items", "[]strictjson.Item", "object"},
 	}
 
-	// With DisallowUnknownFields = false
-	d := NewDecoder(WithDisallowUnknownFields(false))
-	var p2 Person
-	err = d.Unmarshal([]byte(`{"name": "John", "extra": "field"}`), &p2)
-	if err != nil {
-		t.Errorf("Unmarshal() unexpected error with DisallowUnknownFields=false: %v", err)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal([]byte(tt.data), &v)
+			var tme *TypeMismatchError
+			if !errors.As(err, &tme) || !errors.Is(err, ErrDecode) {
+				t.Fatalf("Expected *TypeMismatchError, got %T (%v)", err, err)
+			}
+			if tme.Path() != tt.path || tme.Found() != tt.found || tme.Expected().String() != tt.expected {
+				t.Errorf("Got JSON %s into %s at %q, want JSON %s into %s at %q",
+					tme.Found(), tme.Expected(), tme.Path(), tt.found, tt.expected, tt.path)
+			}
+		})
 	}
-	if p2.Name != "John" {
-		t.Errorf("Expected Name='John', got '%s'", p2.Name)
+
+	err := Unmarshal([]byte(`{"items": [{"qty": true}]}`), &v)
+	if want := `strictjson: cannot decode JSON bool into Go int at "items[0].qty"`; err == nil || err.Error() != want {
+		t.Errorf("Error() = %v, want %q", err, want)
 	}
 }
 
-func TestSuggestClosestOption(t *testing.T) {
+func TestErrorValueSnippets(t *testing.T) {
 	type Person struct {
-		Name string `json:"name"`
+		Age    int      `json:"age"`
+		Scores []int    `json:"scores"`
+		Nested struct{} `json:"nested"`
 	}
 
-	// With SuggestClosest = true
-	d := NewDecoder(WithSuggestClosest(true))
-	var p Person
-	err := d.Unmarshal([]byte(`{"Name": "John"}`), &p)
-	if err == nil {
-		t.Error("Expected error for wrong case field")
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"scalar", `{"age": "thirty"}`, `"thirty"`},
+		{"inside delegated value", `{"scores": [1, {"a": 1}, 3]}`, `{"a": 1}`},
+		{"truncated", `{"age": "` + strings.Repeat("x", 40) + `"}`, `"` + strings.Repeat("x", 15) + `...`},
+		{"truncated on rune boundary", `{"age": "` + strings.Repeat("é", 20) + `"}`, `"` + strings.Repeat("é", 7) + `...`},
 	}
-	if err != nil && err.Error() != `strictjson: unknown field "Name" (did you mean "name"?)` {
-		t.Errorf("Unexpected error message: %v", err)
+
+	d := NewDecoder(WithErrorValueSnippets(16))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Person
+			err := d.Unmarshal([]byte(tt.data), &p)
+			var tme *TypeMismatchError
+			if !errors.As(err, &tme) {
+				t.Fatalf("Expected *TypeMismatchError, got %T (%v)", err, err)
+			}
+			if tme.Value() != tt.want {
+				t.Errorf("Value() = %q, want %q", tme.Value(), tt.want)
+			}
+			if !strings.HasSuffix(err.Error(), " (got "+tt.want+")") {
+				t.Errorf("Error() = %q, want the snippet appended", err.Error())
+			}
+		})
+	}
+
+	var p Person
+	err := Unmarshal([]byte(`{"age": "thirty"}`), &p)
+	var tme *TypeMismatchError
+	if !errors.As(err, &tme) || tme.Value() != "" {
+		t.Errorf("Expected no snippet by default, got %v", err)
 	}
 }
 
@@ -564,6 +2085,322 @@ func TestJsonTagDash(t *testing.T) {
 	}
 }
 
+// =============================================================================
+// Scanner Tests
+// =============================================================================
+
+func TestScannerSyntaxErrors(t *testing.T) {
+	type Item struct {
+		Name string `json:"name"`
+	}
+	type Payload struct {
+		Items []Item          `json:"items"`
+		Index map[string]Item `json:"index"`
+	}
+
+	tests := []struct {
+		name       string
+		json       string
+		wantOffset int64
+	}{
+		{name: "trailing garbage", json: `{"items": []} x`, wantOffset: 14},
+		{name: "unterminated object", json: `{"items": [{"name": "a"}]`, wantOffset: 25},
+		{name: "missing colon", json: `{"items" []}`, wantOffset: 9},
+		{name: "bad literal", json: `{"index": {"a": {"name": nul}}}`, wantOffset: 28},
+		{name: "doubled literal", json: `{"items": [{"name": nnull}]}`, wantOffset: 21},
+		{name: "unknown key with bad value", json: `{"items": [], "Extra": [1,]}`, wantOffset: 26},
+		{name: "non-string key", json: `{"items": [{name: "a"}]}`, wantOffset: 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+			err := NewDecoder(WithDisallowUnknownFields(false)).Unmarshal([]byte(tt.json), &p)
+
+			var se *SyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("Expected *SyntaxError, got %T (%v)", err, err)
+			}
+			if se.Offset != tt.wantOffset {
+				t.Errorf("Offset = %d, want %d (%v)", se.Offset, tt.wantOffset, err)
+			}
+		})
+	}
+}
+
+func TestScannerEscapedKeys(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+		City string `json:"city"`
+	}
+
+	var p Person
+	err := Unmarshal([]byte(`{"\u006eame": "John", "ci\u0074y": "NYC"}`), &p)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if p.Name != "John" || p.City != "NYC" {
+		t.Errorf("Unexpected result: %+v", p)
+	}
+
+	err = Unmarshal([]byte(`{"\u004eame": "John"}`), &p)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Field() != "Name" {
+		t.Errorf("Expected unknown field \"Name\" after unescaping, got %v", err)
+	}
+}
+
+func TestScannerSkipsUnknownSubtrees(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	d := NewDecoder(WithDisallowUnknownFields(false))
+	var p Person
+	err := d.Unmarshal([]byte(`{"extra": {"a": [1, {"b": "}"}], "c": null}, "name": "John"}`), &p)
+	if err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if p.Name != "John" {
+		t.Errorf("Expected Name='John', got '%s'", p.Name)
+	}
+}
+
+func TestScannerDeepUnknownNesting(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	depth := 100000
+	data := make([]byte, 0, 2*depth+32)
+	data = append(data, `{"extra": `...)
+	for i := 0; i < depth; i++ {
+		data = append(data, '[')
+	}
+	for i := 0; i < depth; i++ {
+		data = append(data, ']')
+	}
+	data = append(data, `, "name": "John"}`...)
+
+	d := NewDecoder(WithDisallowUnknownFields(false))
+	var p Person
+	if err := d.Unmarshal(data, &p); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+}
+
+func TestScannerKeyMatching(t *testing.T) {
+	type Item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	// Escaped keys are unquoted before matching.
+	var it Item
+	if err := Unmarshal([]byte(`{"\u0069d": 1, "n\u0061me": "a"}`), &it); err != nil {
+		t.Fatalf("Unmarshal() unexpected error: %v", err)
+	}
+	if it.ID != 1 || it.Name != "a" {
+		t.Errorf("Expected {1 a}, got %+v", it)
+	}
+	err := Unmarshal([]byte(`{"n\u0061mes": "a"}`), &it)
+	var ufe *UnknownFieldError
+	if !errors.As(err, &ufe) || ufe.Field() != "names" {
+		t.Errorf("Expected unknown field \"names\", got %v", err)
+	}
+
+	// Keys without escapes that name a field are matched in place, so
+	// validating more objects allocates nothing more.
+	small := []byte(`[{"id": 1, "name": "a"}]`)
+	large := []byte(`[` + strings.TrimSuffix(strings.Repeat(`{"id": 1, "name": "a"}, `, 100), ", ") + `]`)
+	proto := (*[]Item)(nil)
+	allocs := func(data []byte) float64 {
+		return testing.AllocsPerRun(100, func() {
+			if err := Validate(data, proto); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if a, b := allocs(small), allocs(large); b > a {
+		t.Errorf("Expected allocations independent of element count, got %v for 1 and %v for 100", a, b)
+	}
+}
+
+// =============================================================================
+// Resource Limit Tests
+// =============================================================================
+
+func TestMaxDepth(t *testing.T) {
+	type Node struct {
+		Child *Node `json:"child"`
+		Data  any   `json:"data"`
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+		path    string
+	}{
+		{"within limit", `{"child": {"child": {}}}`, false, ""},
+		{"struct nesting", `{"child": {"child": {"child": {}}}}`, true, "child.child.child"},
+		{"skipped subtree", `{"data": {"a": [[1]]}}`, true, "data"},
+		{"skipped within limit", `{"data": {"a": [1]}}`, false, ""},
+		{"unknown key subtree", `{"child": {"x": [[[]]]}}`, true, "child.x"},
+	}
+
+	d := NewDecoder(WithMaxDepth(3), WithDisallowUnknownFields(false))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var n Node
+			err := d.Unmarshal([]byte(tt.data), &n)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Unmarshal() unexpected error: %v", err)
+				}
+				return
+			}
+			var mde *MaxDepthError
+			if !errors.As(err, &mde) {
+				t.Fatalf("Expected *MaxDepthError, got %T (%v)", err, err)
+			}
+			if mde.Path() != tt.path || mde.Limit() != 3 {
+				t.Errorf("Expected limit 3 at %q, got %d at %q", tt.path, mde.Limit(), mde.Path())
+			}
+		})
+	}
+}
+
+func TestMaxDepthDeepInput(t *testing.T) {
+	type Node struct {
+		Child *Node `json:"child"`
+	}
+	deep := []byte(strings.Repeat(`{"child": `, 100000) + "null" + strings.Repeat("}", 100000))
+
+	var n Node
+	err := NewDecoder(WithMaxDepth(64)).Unmarshal(deep, &n)
+	var mde *MaxDepthError
+	if !errors.As(err, &mde) || !errors.Is(err, ErrDecode) {
+		t.Errorf("Expected *MaxDepthError, got %v", err)
+	}
+	if err := NewDecoder(WithMaxDepth(64)).Validate(deep, n); !errors.As(err, &mde) {
+		t.Errorf("Validate: expected *MaxDepthError, got %v", err)
+	}
+}
+
+func TestSizeLimits(t *testing.T) {
+	type Item struct {
+		Tags []string `json:"tags"`
+	}
+	type Order struct {
+		ID    string            `json:"id"`
+		Items []Item            `json:"items"`
+		Meta  map[string]string `json:"meta"`
+		Extra any               `json:"extra"`
+	}
+
+	d := NewDecoder(WithMaxBytes(200), WithMaxKeysPerObject(3), WithMaxElements(2))
+	tests := []struct {
+		name string
+		data string
+		kind LimitKind
+		path string
+	}{
+		{"too many bytes", `{"id": "` + strings.Repeat("x", 200) + `"}`, LimitBytes, ""},
+		{"too many struct keys", `{"id": "a", "items": [], "meta": {}, "extra": 1}`, LimitKeys, ""},
+		{"too many map keys", `{"meta": {"a": "1", "b": "2", "c": "3", "d": "4"}}`, LimitKeys, "meta"},
+		{"too many skipped keys", `{"extra": {"a": 1, "b": 2, "c": 3, "d": 4}}`, LimitKeys, "extra"},
+		{"too many struct elements", `{"items": [{}, {}, {}]}`, LimitElements, "items"},
+		{"too many literal elements", `{"items": [{"tags": ["a", "b", "c"]}]}`, LimitElements, "items[0].tags"},
+		{"too many skipped elements", `{"extra": [1, 2, 3]}`, LimitElements, "extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var o Order
+			err := d.Unmarshal([]byte(tt.data), &o)
+			var le *LimitError
+			if !errors.As(err, &le) || !errors.Is(err, ErrDecode) {
+				t.Fatalf("Expected *LimitError, got %T (%v)", err, err)
+			}
+			if le.Kind() != tt.kind || le.Path() != tt.path {
+				t.Errorf("Expected kind %d at %q, got %d at %q", tt.kind, tt.path, le.Kind(), le.Path())
+			}
+			if err := d.Validate([]byte(tt.data), o); !errors.As(err, &le) {
+				t.Errorf("Validate: expected *LimitError, got %v", err)
+			}
+		})
+	}
+
+	var o Order
+	ok := `{"id": "a", "items": [{"tags": ["a", "b"]}, {}], "meta": {"a": "1", "b": "2", "c": "3"}}`
+	if err := d.Unmarshal([]byte(ok), &o); err != nil {
+		t.Errorf("Unmarshal() unexpected error at the limits: %v", err)
+	}
+}
+
+// =============================================================================
+// Concurrency Tests
+// =============================================================================
+
+func TestDecoderConcurrentUse(t *testing.T) {
+	type Item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type Order struct {
+		ID    string         `json:"id"`
+		Items []Item         `json:"items"`
+		Meta  map[string]int `json:"meta"`
+	}
+
+	d := NewDecoder(
+		WithSuggestClosest(true),
+		WithDisallowDuplicateKeys(true),
+		WithDisallowNullForNonPointer(true),
+		WithIgnorePaths("meta"),
+		WithMaxDepth(8),
+	)
+	before := *d
+
+	docs := []struct {
+		data    string
+		wantErr bool
+	}{
+		{data: `{"id": "a", "items": [{"id": 1, "name": "x"}], "meta": {"n": 1}}`},
+		{data: `{"id": "b", "items": [{"id": 2, "nmae": "y"}]}`, wantErr: true},
+		{data: `{"id": "c", "id": "d"}`, wantErr: true},
+		{data: `{"id": "e", "items": [{"id": null}]}`, wantErr: true},
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				doc := docs[(g+i)%len(docs)]
+				var o Order
+				err := d.Unmarshal([]byte(doc.data), &o)
+				if (err != nil) != doc.wantErr {
+					t.Errorf("Unmarshal(%s) error = %v, wantErr %v", doc.data, err, doc.wantErr)
+					return
+				}
+				if _, err := d.Check([]byte(doc.data), &o); err != nil {
+					t.Errorf("Check(%s) unexpected error: %v", doc.data, err)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Decoding must leave the Decoder exactly as configured.
+	if !reflect.DeepEqual(before, *d) {
+		t.Errorf("Decoder was modified by decoding:\nbefore %+v\nafter  %+v", before, *d)
+	}
+}
+
 // =============================================================================
 // Real World Scenario Tests
 // =============================================================================
@@ -903,6 +2740,42 @@ func BenchmarkUnmarshalSlice(b *testing.B) {
 	}
 }
 
+func BenchmarkUnmarshalDeeplyNested(b *testing.B) {
+	type Leaf struct {
+		ID    int    `json:"id"`
+		Label string `json:"label"`
+	}
+	type Branch struct {
+		Name   string `json:"name"`
+		Leaves []Leaf `json:"leaves"`
+	}
+	type Tree struct {
+		Root     Branch            `json:"root"`
+		Branches map[string]Branch `json:"branches"`
+	}
+
+	data := []byte(`{
+		"root": {"name": "r", "leaves": [{"id": 1, "label": "a"}, {"id": 2, "label": "b"}]},
+		"branches": {
+			"x": {"name": "x", "leaves": [{"id": 3, "label": "c"}, {"id": 4, "label": "d"}]},
+			"y": {"name": "y", "leaves": [{"id": 5, "label": "e"}, {"id": 6, "label": "f"}]}
+		}
+	}`)
+
+	b.Run("strictjson", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var tr Tree
+			_ = Unmarshal(data, &tr)
+		}
+	})
+	b.Run("stdlib", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var tr Tree
+			_ = json.Unmarshal(data, &tr)
+		}
+	})
+}
+
 func BenchmarkStdlibUnmarshalSimple(b *testing.B) {
 	type Person struct {
 		Name string `json:"name"`
@@ -917,3 +2790,94 @@ func BenchmarkStdlibUnmarshalSimple(b *testing.B) {
 		_ = json.Unmarshal(data, &p)
 	}
 }
+
+func BenchmarkSuggestAdversarialKeys(b *testing.B) {
+	type Wide struct {
+		A string `json:"alpha_field_name"`
+		B string `json:"beta_field_name"`
+		C string `json:"gamma_field_name"`
+		D string `json:"delta_field_name"`
+	}
+	var keys []string
+	for i := 0; i < 1000; i++ {
+		keys = append(keys, fmt.Sprintf(`"%d%s": 0`, i, strings.Repeat("x", 4096)))
+	}
+	data := []byte("{" + strings.Join(keys, ", ") + "}")
+	d := NewDecoder(WithSuggestClosest(true))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var w Wide
+		if _, err := d.Check(data, &w); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalDisallowDuplicateKeys(b *testing.B) {
+	type Item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	type List struct {
+		Items []Item `json:"items"`
+	}
+
+	data := []byte(`{"items": [{"id": 1, "name": "a"}, {"id": 2, "name": "b"}, {"id": 3, "name": "c"}]}`)
+	d := NewDecoder(WithDisallowDuplicateKeys(true))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var l List
+		if err := d.Unmarshal(data, &l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshalLargeArray(b *testing.B) {
+	type Item struct {
+		ID   int      `json:"id"`
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	var elems []string
+	for i := 0; i < 10000; i++ {
+		elems = append(elems, fmt.Sprintf(`{"id": %d, "name": "item-%d", "tags": ["a", "b"]}`, i, i))
+	}
+	data := []byte("[" + strings.Join(elems, ",") + "]")
+
+	for _, n := range []int{1, 4} {
+		b.Run(fmt.Sprintf("parallelism=%d", n), func(b *testing.B) {
+			d := NewDecoder(WithParallelism(n))
+			b.SetBytes(int64(len(data)))
+			for i := 0; i < b.N; i++ {
+				var items []Item
+				if err := d.Unmarshal(data, &items); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalConcurrent(b *testing.B) {
+	type Person struct {
+		Name string `json:"name"`
+		Age  int    `json:"age"`
+	}
+
+	data := []byte(`{"name": "John", "age": 30}`)
+	d := NewDecoder(WithDisallowDuplicateKeys(true))
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var p Person
+			if err := d.Unmarshal(data, &p); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
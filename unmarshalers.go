@@ -0,0 +1,32 @@
+package strictjson
+
+// WithValidateThroughUnmarshalers(true) checks values of types with an
+// UnmarshalJSON method against the types' own fields before the method is
+// called, as if the method did not exist: unknown and mis-cased keys,
+// duplicates and the other strict rules are reported at their path inside
+// the value. The value is then decoded by UnmarshalJSON as usual.
+//
+// By default such values are passed to UnmarshalJSON unchecked, so a type
+// whose method decodes into an alias of itself with encoding/json, a common
+// way to add defaults or post-processing, silently accepts keys strict
+// decoding would reject. The option closes that gap for code that needs
+// strictness it cannot opt out of. It does not suit types whose object keys
+// differ from their field names; leave it off for those, or give them a
+// DecodeFunc.
+func WithValidateThroughUnmarshalers(validate bool) DecoderOption {
+	return func(d *Decoder) {
+		d.ValidateThroughUnmarshalers = validate
+	}
+}
+
+// validateThrough validates the value at the scanner against inner, the
+// plan of a json.Unmarshaler type without its method, and rewinds the
+// scanner so that the value is then passed to the method.
+func (s *decodeState) validateThrough(inner *typePlan) error {
+	start := s.scan.pos
+	if err := s.validateValue(inner); err != nil {
+		return err
+	}
+	s.scan.pos = start
+	return nil
+}
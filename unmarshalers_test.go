@@ -0,0 +1,107 @@
+package strictjson
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type throughAddress struct {
+	City string `json:"city"`
+}
+
+// throughUser decodes itself through an alias with encoding/json, which
+// ignores case and unknown keys.
+type throughUser struct {
+	Name    string         `json:"name"`
+	Address throughAddress `json:"address"`
+	calls   int
+}
+
+func (u *throughUser) UnmarshalJSON(data []byte) error {
+	type plain throughUser
+	calls := u.calls
+	if err := json.Unmarshal(data, (*plain)(u)); err != nil {
+		return err
+	}
+	u.calls = calls + 1
+	return nil
+}
+
+// throughColor unmarshals from a string, so it has no fields to check.
+type throughColor struct{ rgb string }
+
+func (c *throughColor) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &c.rgb)
+}
+
+func TestValidateThroughUnmarshalers(t *testing.T) {
+	type Payload struct {
+		User  throughUser    `json:"user"`
+		Users []*throughUser `json:"users"`
+		Color throughColor   `json:"color"`
+	}
+
+	tests := []struct {
+		name string
+		data string
+		path string
+	}{
+		{"valid", `{"user": {"name": "a", "address": {"city": "b"}}, "users": [{"name": "c"}], "color": "#fff"}`, ""},
+		{"mis-cased key", `{"user": {"name": "a", "address": {"City": "b"}}}`, "user.address.City"},
+		{"unknown key in slice", `{"users": [{"name": "c"}, {"nmae": "d"}]}`, "users[1].nmae"},
+		{"null", `{"user": null, "users": [null]}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Payload
+			if err := Unmarshal([]byte(tt.data), &p); err != nil {
+				t.Fatalf("Unmarshal() without the option: %v", err)
+			}
+
+			d := NewDecoder(WithValidateThroughUnmarshalers(true))
+			p = Payload{}
+			err := d.Unmarshal([]byte(tt.data), &p)
+			verr := d.Validate([]byte(tt.data), (*Payload)(nil))
+			if tt.path == "" {
+				if err != nil || verr != nil {
+					t.Fatalf("Unexpected errors %v, %v", err, verr)
+				}
+				if strings.Contains(tt.data, `"user": {`) && p.User.calls != 1 {
+					t.Errorf("Expected UnmarshalJSON to run once, ran %d times", p.User.calls)
+				}
+				return
+			}
+			for _, err := range []error{err, verr} {
+				var ufe *UnknownFieldError
+				if !errors.As(err, &ufe) || ufe.Path() != tt.path {
+					t.Errorf("Expected an unknown field at %q, got %v", tt.path, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateThroughUnmarshalersCheck(t *testing.T) {
+	type Payload struct {
+		User throughUser `json:"user"`
+	}
+	var p Payload
+	d := NewDecoder(WithValidateThroughUnmarshalers(true))
+	violations, err := d.Check([]byte(`{"user": {"Name": "a", "address": {"city": "b", "zip": 1}}}`), &p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, v := range violations {
+		paths = append(paths, v.Path)
+	}
+	if got := strings.Join(paths, " "); got != "user.Name user.address.zip" {
+		t.Errorf("Expected violations at user.Name and user.address.zip, got %q", got)
+	}
+	if p.User.Name != "a" || p.User.Address.City != "b" || p.User.calls != 1 {
+		t.Errorf("Expected UnmarshalJSON to decode the value once, got %+v", p.User)
+	}
+}
@@ -0,0 +1,13 @@
+package strictjson
+
+// WithStrictUTF8(true) rejects strings, keys as well as values, that hold
+// invalid UTF-8 byte sequences or \u escapes of unpaired UTF-16 surrogates,
+// such as "\ud800", with a *SyntaxError at the offending byte. encoding/json
+// silently replaces both with U+FFFD, so that different inputs decode to the
+// same string and the decoded value no longer matches the bytes that were
+// accepted.
+func WithStrictUTF8(strict bool) DecoderOption {
+	return func(d *Decoder) {
+		d.StrictUTF8 = strict
+	}
+}
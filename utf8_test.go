@@ -0,0 +1,88 @@
+package strictjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStrictUTF8(t *testing.T) {
+	type doc struct {
+		Name string         `json:"name"`
+		Meta map[string]any `json:"meta"`
+	}
+	valid := []string{
+		`{"name": "café ☕"}`,
+		`{"name": "é😀"}`,
+		`{"meta": {"k": ["😀", "x"]}}`,
+	}
+	tests := []struct {
+		name       string
+		data       string
+		wantOffset int64
+		wantMsg    string
+	}{
+		{
+			name:       "invalid byte in value",
+			data:       "{\"name\": \"ab\xffc\"}",
+			wantOffset: 12,
+			wantMsg:    "strictjson: invalid UTF-8 in string literal",
+		},
+		{
+			name:       "truncated sequence in key",
+			data:       "{\"meta\": {\"k\xc3\": 1}}",
+			wantOffset: 12,
+			wantMsg:    "strictjson: invalid UTF-8 in string literal",
+		},
+		{
+			name:       "invalid byte in skipped subtree",
+			data:       "{\"meta\": {\"k\": [\"\xed\xa0\x80\"]}}",
+			wantOffset: 17,
+			wantMsg:    "strictjson: invalid UTF-8 in string literal",
+		},
+		{
+			name:       "lone high surrogate",
+			data:       `{"name": "a\ud800b"}`,
+			wantOffset: 11,
+			wantMsg:    `strictjson: unpaired UTF-16 surrogate in \u escape`,
+		},
+		{
+			name:       "lone low surrogate",
+			data:       `{"name": "\udc00"}`,
+			wantOffset: 10,
+			wantMsg:    `strictjson: unpaired UTF-16 surrogate in \u escape`,
+		},
+		{
+			name:       "high surrogate followed by non-surrogate",
+			data:       `{"name": "\ud800A"}`,
+			wantOffset: 10,
+			wantMsg:    `strictjson: unpaired UTF-16 surrogate in \u escape`,
+		},
+	}
+
+	d := NewDecoder(WithStrictUTF8(true))
+	for _, data := range valid {
+		if err := d.Unmarshal([]byte(data), &doc{}); err != nil {
+			t.Errorf("Unmarshal(%s) unexpected error: %v", data, err)
+		}
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// encoding/json replaces the offending bytes with U+FFFD.
+			if err := Unmarshal([]byte(tt.data), &doc{}); err != nil {
+				t.Fatalf("Unmarshal() without WithStrictUTF8 unexpected error: %v", err)
+			}
+
+			err := d.Unmarshal([]byte(tt.data), &doc{})
+			verr := d.Validate([]byte(tt.data), &doc{})
+			for _, err := range []error{err, verr} {
+				var se *SyntaxError
+				if !errors.As(err, &se) || se.Offset != tt.wantOffset {
+					t.Fatalf("Expected *SyntaxError at offset %d, got %v", tt.wantOffset, err)
+				}
+				if err.Error() != tt.wantMsg {
+					t.Errorf("Error() = %q, want %q", err.Error(), tt.wantMsg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,247 @@
+package strictjson
+
+import "reflect"
+
+// Validate runs the key, casing and unknown-field checks of Unmarshal against
+// the type of prototype without allocating or populating a value. prototype
+// may be a value or a pointer, e.g. Validate(data, (*Request)(nil)).
+//
+// Scalar values are checked for syntax only; whether they fit the Go type of
+// their field is left to the eventual decode.
+func Validate(data []byte, prototype any) error {
+	return NewDecoder().Validate(data, prototype)
+}
+
+// ValidateType is the generic form of Validate.
+func ValidateType[T any](data []byte) error {
+	return NewDecoder().validateType(data, reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// Validate is like the package-level Validate but applies d's options.
+func (d *Decoder) Validate(data []byte, prototype any) error {
+	t := reflect.TypeOf(prototype)
+	if t == nil {
+		return newNonPointerError()
+	}
+	return d.validateType(data, t)
+}
+
+func (d *Decoder) validateType(data []byte, t reflect.Type) (err error) {
+	defer func() { err = d.formatError(err) }()
+	s, err := d.newState(data)
+	if err != nil {
+		return err
+	}
+	defer s.release()
+	defer s.observe(t, &err)
+	return s.document(func() error {
+		return s.validateValue(d.planFor(t))
+	})
+}
+
+// validateValue mirrors value, following the plan of the destination type
+// without a destination value.
+func (s *decodeState) validateValue(p *typePlan) error {
+	return s.complete(s.beginValidate(p))
+}
+
+// beginValidate mirrors begin: objects and arrays validated member by member
+// push a frame with validate set.
+func (s *decodeState) beginValidate(p *typePlan) (bool, error) {
+	policy, scoped := s.scope(p)
+	if !scoped {
+		return s.openValidate(p)
+	}
+	saved := s.enterPolicy(policy)
+	opened, err := s.openValidate(p)
+	s.endScope(opened, saved)
+	return opened, err
+}
+
+// openValidate is beginValidate without the unknown-key policy of p's type.
+func (s *decodeState) openValidate(p *typePlan) (bool, error) {
+	if p.kind == planOptional {
+		if s.scan.consumeNull() {
+			return false, nil
+		}
+		return s.beginValidate(p.elem)
+	}
+	if s.scan.consumeNull() {
+		if s.d.DisallowNullForNonPointer && !p.nullable {
+			return false, s.violation(newNullValueError(s.pathString(), p.typ))
+		}
+		return false, nil
+	}
+	if err := s.checkEnum(p); err != nil {
+		return false, err
+	}
+
+	switch p.kind {
+	case planStruct:
+		if s.scan.peek() == '{' {
+			return s.validateObject(p)
+		}
+	case planSlice:
+		if s.scan.peek() == '[' {
+			return s.validateArray(p.elem, arrayType(p.typ))
+		}
+	case planMap:
+		if s.scan.peek() == '{' {
+			return s.validateMap(p.typ, p.elem)
+		}
+	case planGenerated:
+		if s.scan.peek() == '{' {
+			// Generated decoders have no field table to validate against, so
+			// decode into a scratch value instead.
+			t := p.typ
+			for t.Kind() == reflect.Ptr {
+				t = t.Elem()
+			}
+			return false, s.generated(reflect.New(t).Elem())
+		}
+	case planTime:
+		if layout := s.timeLayout(); layout != "" {
+			return false, s.timeString(reflect.New(timeType).Elem(), layout)
+		}
+	case planBytes:
+		if format := s.base64Format(); format != "" && s.scan.peek() == '"' {
+			return false, s.base64String(reflect.New(p.typ).Elem(), format)
+		}
+	case planUnmarshaler:
+		if p.inner != nil {
+			return s.beginValidate(p.inner)
+		}
+	case planLiteral:
+		if t := arrayType(p.typ); t != nil && s.d.StrictArrayLength && s.scan.peek() == '[' {
+			return s.validateArray(s.d.planFor(t.Elem()), t)
+		}
+		if s.d.DisallowNullForNonPointer {
+			// Check the members for nulls, as decoding would.
+			switch t := indirectType(p.typ); {
+			case t.Kind() == reflect.Map && s.scan.peek() == '{':
+				return s.validateMap(t, s.d.planFor(t.Elem()))
+			case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && s.scan.peek() == '[':
+				return s.validateArray(s.d.planFor(t.Elem()), arrayType(t))
+			}
+		}
+	case planFunc:
+		t := p.typ
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		return false, s.decodeFunc(reflect.New(t).Elem(), p)
+	case planInterface:
+		if s.scan.peek() != '{' {
+			break
+		}
+		if p.union != nil {
+			member, err := s.unionMember(p.union)
+			if err != nil {
+				return false, err
+			}
+			return false, s.inUnion(p.union, func() error { return s.validateValue(member) })
+		}
+		if s.d.ValidateInterfaceObjects {
+			return s.beginValidate(s.implFor(p, s.objectKeys))
+		}
+	}
+	_, err := s.skip()
+	return false, err
+}
+
+// validateObject opens a frame to validate the object at the scanner
+// against the fields of p.
+func (s *decodeState) validateObject(p *typePlan) (bool, error) {
+	if err := s.structErr(p); err != nil {
+		return false, err
+	}
+	if s.stats != nil {
+		s.stats.Objects++
+	}
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+	s.scan.pos++ // '{'
+	s.push(frame{kind: frameObject, validate: true, fields: p.fields, seen: s.newSeenKeys()})
+	return true, nil
+}
+
+// beginValidateField begins validating the value of key, the next key of
+// f's object, against the matching field fi, if any.
+func (s *decodeState) beginValidateField(f *frame, key string, fi *fieldInfo) error {
+	sf := f.fields
+	if fi == nil {
+		fi, _ = s.lookup(sf, key)
+	}
+	if fi == nil && sf.remain == nil {
+		fold, err := s.unknown(sf, key)
+		if err != nil {
+			return err
+		}
+		fi = fold
+	}
+	if fi == nil {
+		_, err := s.skip()
+		return err
+	}
+	if fi.quoted && s.scan.peek() != '"' {
+		if err := s.checkQuoted(fi.plan, s.scan.peek()); err != nil {
+			return err
+		}
+	}
+	f.field = fi
+	f.fieldPolicy, f.fieldCurrent = s.enterPolicy(fi.policy), s.current
+	s.current = fi
+	if !s.constrained(fi) {
+		_, err := s.beginValidate(fi.plan)
+		return err
+	}
+	// Constraints need a value to check, which endMember does once it is
+	// decoded.
+	f.member = reflect.New(fi.typ).Elem()
+	if fi.quoted {
+		return s.quoted(f.member, fi.plan)
+	}
+	_, err := s.begin(f.member, fi.plan)
+	return err
+}
+
+// validateArray opens a frame to validate an array with elements of plan
+// elem, to be decoded into a slice or, if array is not nil, into a Go array
+// of that type.
+func (s *decodeState) validateArray(elem *typePlan, array reflect.Type) (bool, error) {
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+	s.scan.pos++ // '['
+	s.push(frame{kind: frameArray, validate: true, elem: elem, array: array})
+	return true, nil
+}
+
+// arrayType returns t, or the type t points to, if that is a Go array, and
+// nil otherwise.
+func arrayType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Array {
+		return nil
+	}
+	return t
+}
+
+// validateMap opens a frame to validate an object with values of plan elem,
+// to be decoded into a map of type t, checking its keys if they need
+// parsing.
+func (s *decodeState) validateMap(t reflect.Type, elem *typePlan) (bool, error) {
+	if err := s.enter(); err != nil {
+		return false, err
+	}
+	s.scan.pos++ // '{'
+	f := frame{kind: frameMap, validate: true, elem: elem, seen: s.newSeenKeys()}
+	if t := indirectType(t); parsesKeys(t) {
+		f.key = t.Key()
+	}
+	s.push(f)
+	return true, nil
+}
@@ -0,0 +1,113 @@
+package strictjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type Address struct {
+		City string `json:"city"`
+	}
+	type Person struct {
+		Name      string             `json:"name"`
+		Addresses []Address          `json:"addresses"`
+		Index     map[string]Address `json:"index"`
+		Home      *Address           `json:"home"`
+		Work      Optional[Address]  `json:"work"`
+	}
+
+	tests := []struct {
+		name     string
+		json     string
+		wantPath string
+	}{
+		{name: "valid", json: `{"name": "a", "addresses": [{"city": "x"}], "index": {"k": {"city": "y"}}, "home": null}`},
+		{name: "top-level case", json: `{"Name": "a"}`, wantPath: "Name"},
+		{name: "slice element", json: `{"addresses": [{"city": "x"}, {"City": "y"}]}`, wantPath: "addresses[1].City"},
+		{name: "map value", json: `{"index": {"k": {"CITY": "y"}}}`, wantPath: "index.k.CITY"},
+		{name: "pointer", json: `{"home": {"town": "z"}}`, wantPath: "home.town"},
+		{name: "optional", json: `{"work": {"City": "z"}}`, wantPath: "work.City"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, err := range []error{
+				Validate([]byte(tt.json), Person{}),
+				Validate([]byte(tt.json), (*Person)(nil)),
+				ValidateType[Person]([]byte(tt.json)),
+			} {
+				if tt.wantPath == "" {
+					if err != nil {
+						t.Errorf("Unexpected error: %v", err)
+					}
+					continue
+				}
+				var ufe *UnknownFieldError
+				if !errors.As(err, &ufe) || ufe.Path() != tt.wantPath {
+					t.Errorf("Expected unknown field at %q, got %v", tt.wantPath, err)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAppliesDecoderOptions(t *testing.T) {
+	type Person struct {
+		Name string `json:"name"`
+	}
+
+	d := NewDecoder(WithDisallowUnknownFields(false), WithDisallowDuplicateKeys(true))
+	if err := d.Validate([]byte(`{"extra": 1, "name": "a"}`), Person{}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	var dke *DuplicateKeyError
+	if err := d.Validate([]byte(`{"name": "a", "name": "b"}`), Person{}); !errors.As(err, &dke) {
+		t.Errorf("Expected *DuplicateKeyError, got %v", err)
+	}
+
+	if err := Validate([]byte(`{"name": }`), Person{}); err == nil {
+		t.Error("Expected syntax error")
+	}
+}
+
+func TestValidateDeepNesting(t *testing.T) {
+	const depth = 1000000
+
+	type rec struct {
+		C []rec `json:"c"`
+	}
+	data := strings.Repeat(`{"c": [`, depth) + "{}" + strings.Repeat("]}", depth)
+	if err := Validate([]byte(data), (*rec)(nil)); err != nil {
+		t.Fatalf("Validate() unexpected error: %v", err)
+	}
+
+	data = strings.Repeat(`{"c": [`, depth) + `{"C": []}` + strings.Repeat("]}", depth)
+	var ufe *UnknownFieldError
+	if err := Validate([]byte(data), (*rec)(nil)); !errors.As(err, &ufe) {
+		t.Fatalf("Expected *UnknownFieldError, got %v", err)
+	}
+	if want := strings.Repeat("c[0].", depth) + "C"; ufe.Path() != want {
+		t.Errorf("Expected the error at a path of %d bytes, got %d bytes", len(want), len(ufe.Path()))
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	type Item struct {
+		Name  string `json:"name"`
+		Price int    `json:"price"`
+	}
+	type Order struct {
+		ID    string `json:"id"`
+		Items []Item `json:"items"`
+	}
+
+	data := []byte(`{"id": "o1", "items": [{"name": "A", "price": 1}, {"name": "B", "price": 2}]}`)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ValidateType[Order](data)
+	}
+}